@@ -27,7 +27,9 @@ type Alignment interface {
 	BuildBootstrap(frac float64, rand *mathrand.Rand) Alignment // Bootstrap alignment
 	CharStatsSite(site int) (map[uint8]int, error)
 	Clone() (Alignment, error)
-	CodonAlign(ntseqs SeqBag) (codonAl *align, err error)
+	CodonAlign(ntseqs SeqBag, geneticcode int) (codonAl *align, err error)
+	CodonAlignDiagnostics(ntseqs SeqBag, geneticcode int, maxmismatches int, fixframeshifts bool) (codonAl *align, issues []CodonAlignIssue, err error)
+	ReverseTranslate(geneticcode int) (rtAl *align, err error)
 	// Remove identical patterns/sites and return number of occurence
 	// of each pattern (order of patterns/sites may have changed)
 	Compress() []int
@@ -92,6 +94,7 @@ type Alignment interface {
 	MaxCharStats(excludeGaps, excludeNs bool) (out []uint8, occur []int, total []int)
 	Mutate(rate float64, rand *mathrand.Rand) // Adds uniform substitutions in the alignment (~sequencing errors)
 	NbVariableSites() int                     // Nb of variable sites
+	VariableSites() (sites []int)             // Indexes of variable sites
 	// Number of Gaps in each sequence that are unique in their alignment site
 	NumGapsUniquePerSequence(countProfile *CountProfile) (numuniques []int, numnew []int, numboth []int, err error)
 	// returns the number of characters in each sequence that are unique in their alignment site (gaps or others)
@@ -119,6 +122,8 @@ type Alignment interface {
 	// Replaces match characters (.) by their corresponding characters on the first sequence
 	ReplaceMatchChars()
 	Sample(nb int, rand *mathrand.Rand) (Alignment, error) // generate a sub sample of the sequences
+	// generate a sub sample of at most maxPerGroup sequences per group (optionally weighted)
+	StratifiedSample(groups map[string][]string, maxPerGroup int, weights map[string]float64, rand *mathrand.Rand) (Alignment, error)
 	ShuffleSites(rate float64, roguerate float64, randroguefirst bool, rand *mathrand.Rand) []string
 	SimulateRogue(prop float64, proplen float64, rand *mathrand.Rand) ([]string, []string) // add "rogue" sequences
 	SiteConservation(position int) (int, error)                                            // If the site is conserved:
@@ -162,6 +167,7 @@ type Alignment interface {
 	// ref codon: [0,1,6]
 	// seq      : ACTTTG : Insertion not OK : Frameshift? => Replaced by "T-" in ref and "XX" in seq
 	TranslateByReference(phase int, geneticcode int, refseq string) (err error)
+	TranslateByReferenceIUPAC(phase int, geneticcode int, refseq string, aaclasses, altstart bool) (stats []TranslationStats, err error)
 	Transpose() (Alignment, error) // Output sequences are made of sites and output sites are sequences
 	TrimSequences(trimsize int, fromStart bool) error
 }
@@ -777,7 +783,14 @@ func (a *align) ReplaceMatchChars() {
 // Translates the alignment, and update the length of
 // the alignment
 func (a *align) Translate(phase int, geneticcode int) (err error) {
-	err = a.seqbag.Translate(phase, geneticcode)
+	_, err = a.TranslateIUPAC(phase, geneticcode, false, false)
+	return
+}
+
+// TranslateIUPAC translates the alignment as seqbag.TranslateIUPAC, and
+// updates the length of the alignment
+func (a *align) TranslateIUPAC(phase int, geneticcode int, aaclasses, altstart bool) (stats []TranslationStats, err error) {
+	stats, err = a.seqbag.TranslateIUPAC(phase, geneticcode, aaclasses, altstart)
 	if len(a.seqs) > 0 {
 		a.length = len(a.seqs[0].sequence)
 	} else {
@@ -815,10 +828,21 @@ func (a *align) Translate(phase int, geneticcode int) (err error) {
 // ref codon: [0,1,6]
 // seq      : ACTTTG : Insertion not OK : Frameshift? => Replaced by "T-" in ref and "XX" in seq
 func (a *align) TranslateByReference(phase int, geneticcode int, refseq string) (err error) {
+	_, err = a.TranslateByReferenceIUPAC(phase, geneticcode, refseq, false, false)
+	return
+}
+
+// TranslateByReferenceIUPAC translates the alignment as TranslateByReference, and returns,
+// for each sequence, the number of codons that contained ambiguous nucleotides and how they
+// were translated. If aaclasses is true, amino acid ambiguity codes B, Z and J are used,
+// and if altstart is true, the codons at the first position of the alignment (after phase)
+// are translated into M if they are initiation codons of the genetic code (see seqbag.TranslateIUPAC).
+func (a *align) TranslateByReferenceIUPAC(phase int, geneticcode int, refseq string, aaclasses, altstart bool) (stats []TranslationStats, err error) {
 	var refId int                   // Index of the reference sequence
 	var oldseqs []*seq              // We backup the sequences of the alignment
 	var alen, nseq int              // Length and Size of the alignment
 	var code map[string]uint8       // Genetic code
+	var starts map[string]bool      // Initiation codons (if altstart)
 	var newseqbuffer []bytes.Buffer // The buffers where the temp translated sequences are written
 
 	// We take the reference sequence ID from the alignment
@@ -839,11 +863,20 @@ func (a *align) TranslateByReference(phase int, geneticcode int, refseq string)
 	if code, err = geneticCode(geneticcode); err != nil {
 		return
 	}
+	if altstart {
+		if starts, err = startCodons(geneticcode); err != nil {
+			return
+		}
+	}
 
 	alen = a.Length()
 	nseq = a.NbSequences()
 	oldseqs = a.seqs
 	newseqbuffer = make([]bytes.Buffer, nseq)
+	stats = make([]TranslationStats, nseq)
+	for i, s := range oldseqs {
+		stats[i].Name = s.name
+	}
 	// remove all sequences from the alignment
 	a.Clear()
 
@@ -883,7 +916,12 @@ func (a *align) TranslateByReference(phase int, geneticcode int, refseq string)
 				break
 			}
 			// We then translate the 3 nt of the ref codon in 1 aa
-			refaa = translateCodon(oldseqs[refId].sequence[refcodonidx[0]], oldseqs[refId].sequence[refcodonidx[1]], oldseqs[refId].sequence[refcodonidx[2]], code)
+			// Alternative start codons are translated into M only at the first position
+			firststarts := starts
+			if refcodonidx[0] != phase {
+				firststarts = nil
+			}
+			refaa = translateFirstCodonStats(oldseqs[refId].sequence[refcodonidx[0]], oldseqs[refId].sequence[refcodonidx[1]], oldseqs[refId].sequence[refcodonidx[2]], code, firststarts, aaclasses, &stats[refId])
 			// Number of potential amino acids
 			naa = (refcodonidx[2] + 1 - refcodonidx[0]) / 3
 			// We write this aa in the reference sequence buffer
@@ -924,8 +962,17 @@ func (a *align) TranslateByReference(phase int, geneticcode int, refseq string)
 					// + several "-" corresp to potential additional aa in other sequence
 					// We find all corresponding codons of the target sequence, between refcodonidx[0] and refcodonidx[2]
 					n := 0
+					firststarts := starts
+					if refcodonidx[0] != phase || oldseqs[compseqId].sequence[phase] == GAP {
+						firststarts = nil
+					}
 					for si := 0; si <= len(tmpseq)-2; si += 3 {
-						aa := translateCodon(tmpseq[si], tmpseq[si+1], tmpseq[si+2], code)
+						var aa uint8
+						if si == 0 {
+							aa = translateFirstCodonStats(tmpseq[si], tmpseq[si+1], tmpseq[si+2], code, firststarts, aaclasses, &stats[compseqId])
+						} else {
+							aa = translateCodonStats(tmpseq[si], tmpseq[si+1], tmpseq[si+2], code, aaclasses, &stats[compseqId])
+						}
 						newseqbuffer[compseqId].WriteByte(aa)
 						n++
 					}
@@ -1207,6 +1254,25 @@ func (a *align) Sample(nb int, rand *mathrand.Rand) (al Alignment, err error) {
 	return
 }
 
+// StratifiedSample samples at most maxPerGroup sequences in each group of sequences
+// and returns this new alignment (see SeqBag.StratifiedSampleSeqBag).
+func (a *align) StratifiedSample(groups map[string][]string, maxPerGroup int, weights map[string]float64, rand *mathrand.Rand) (al Alignment, err error) {
+	var sampleSeqBag *seqbag
+	var ali *align
+
+	if sampleSeqBag, err = a.stratifiedSampleSeqBag(groups, maxPerGroup, weights, rand); err != nil {
+		return
+	}
+
+	if ali, err = seqBagToAlignment(sampleSeqBag); err != nil {
+		return
+	}
+
+	al = ali
+
+	return
+}
+
 /*
 Each sequence in the alignment has an associated number of occurence. The sum s of the counts
 represents the number of sequences in the underlying initial dataset.
@@ -1476,6 +1542,8 @@ func (a *align) MaskUnique(refseq string, maskreplace string) (err error) {
 // for each site of the alignment.
 // if ignoreGaps is true, then gaps are not taken into account (except if only Gaps)
 // if ignoreNs is true, then Ns are not taken into account (except if only Ns)
+// In case of ties, the character with the lowest code is returned (ASCII order,
+// so '-' < 'A' < 'C' < ...), which makes the output deterministic.
 // Returns
 //   - out: The character with the highest occurence at each site
 //   - occur: The number of occurences of the most common character at each site
@@ -1510,7 +1578,7 @@ func (a *align) MaxCharStats(ignoreGaps, ignoreNs bool) (out []uint8, occur []in
 			// Otherwise, if v > max, we update max occurence char
 			if !(ignoreGaps && k == GAP) && !(ignoreNs && (k == all || k == allc)) {
 				total[site] += v
-				if v > max {
+				if v > max || (v == max && k < out[site]) {
 					out[site] = k
 					occur[site] = v
 					max = v
@@ -2210,24 +2278,27 @@ func (a *align) CountDifferences() (alldiffs []string, diffs []map[string]int) {
 It does not take into account gaps and other charactes like "."
 */
 func (a *align) NbVariableSites() int {
-	nbinfo := 0
+	return len(a.VariableSites())
+}
+
+// VariableSites returns the indexes of variable sites of the alignment,
+// i.e. sites having at least two different characters.
+// It does not take into account gaps and other charactes like "."
+func (a *align) VariableSites() (sites []int) {
+	sites = make([]int, 0)
 	for site := 0; site < a.Length(); site++ {
 		charmap := make(map[uint8]bool)
-		variable := false
 		for _, seq := range a.seqs {
 			if seq.sequence[site] != GAP && seq.sequence[site] != POINT && seq.sequence[site] != OTHER {
 				charmap[seq.sequence[site]] = true
 			}
 			if len(charmap) > 1 {
-				variable = true
+				sites = append(sites, site)
 				break
 			}
 		}
-		if variable {
-			nbinfo++
-		}
 	}
-	return nbinfo
+	return
 }
 
 // NumGapsUniquePerSequence returns the number of Gaps in the sequence that are unique in their alignment site
@@ -2354,8 +2425,16 @@ func (a *align) NumMutationsUniquePerSequence(countProfile *CountProfile) (numun
 //
 // Once gaps are added, if the nucleotide alignment length does not match
 // the protein alignment length * 3, returns an error.
-func (a *align) CodonAlign(ntseqs SeqBag) (rtAl *align, err error) {
+//
+// The genetic code is used to detect additional stop codons at the end of
+// the nucleotide sequences, which are dropped.
+func (a *align) CodonAlign(ntseqs SeqBag, geneticcode int) (rtAl *align, err error) {
 	var buffer bytes.Buffer
+	var code map[string]uint8
+
+	if code, err = geneticCode(geneticcode); err != nil {
+		return
+	}
 
 	if a.Alphabet() != AMINOACIDS {
 		return nil, errors.New("wrong alphabet, cannot reverse translate nucleotides")
@@ -2391,7 +2470,15 @@ func (a *align) CodonAlign(ntseqs SeqBag) (rtAl *align, err error) {
 		if ntseqindex < len(ntseq) {
 			// At most 2 remaining nucleotides that could not be part of the last codon
 			if (len(ntseq)-ntseqindex)%3 == 0 {
-				log.Printf("%s: Dropping %s additional nucleotides: stop codon(s)?", name, string(ntseq[ntseqindex:ntseqindex+(len(ntseq)-ntseqindex)]))
+				stops := true
+				for i := ntseqindex; i < len(ntseq); i += 3 {
+					stops = stops && translateCodon(ntseq[i], ntseq[i+1], ntseq[i+2], code) == '*'
+				}
+				if stops {
+					log.Printf("%s: Dropping %s additional nucleotides: stop codon(s)", name, string(ntseq[ntseqindex:]))
+				} else {
+					log.Printf("%s: Dropping %s additional nucleotides: stop codon(s)?", name, string(ntseq[ntseqindex:ntseqindex+(len(ntseq)-ntseqindex)]))
+				}
 			} else if len(ntseq)-ntseqindex <= 2 {
 				log.Printf("%s: Dropping %d additional nucleotides", name, len(ntseq)-ntseqindex)
 			} else {
@@ -1184,7 +1184,7 @@ func TestCodonAlign(t *testing.T) {
 	exp.AddSequence("Seq0002", "GAGAGGACTAGTTCATACTTTTTAAACACT", "")
 	exp.AutoAlphabet()
 
-	res, err := a.CodonAlign(n)
+	res, err := a.CodonAlign(n, GENETIC_CODE_STANDARD)
 	if err != nil {
 		t.Error(err)
 	}
@@ -2567,3 +2567,146 @@ func Test_align_InformativeSites(t *testing.T) {
 		})
 	}
 }
+
+func Test_align_StratifiedSample(t *testing.T) {
+	var a, s Alignment
+	var err error
+
+	a = NewAlign(NUCLEOTIDS)
+	a.AddSequence("A1", "ACGT", "")
+	a.AddSequence("B1", "ACGA", "")
+	a.AddSequence("A2", "ACGC", "")
+	a.AddSequence("A3", "ACGG", "")
+	a.AddSequence("B2", "ACTT", "")
+	a.AddSequence("C1", "ACTA", "")
+
+	groups := map[string][]string{
+		"A": {"A1", "A2", "A3"},
+		"B": {"B1", "B2"},
+	}
+
+	if s, err = a.StratifiedSample(groups, 2, nil, rand.New(rand.NewSource(10))); err != nil {
+		t.Error(err)
+	}
+	if s.NbSequences() != 4 {
+		t.Errorf("Stratified sample should contain 4 sequences, has %d", s.NbSequences())
+	}
+	if _, ok := s.GetSequence("C1"); ok {
+		t.Error("Sequence C1 does not belong to any group and should not be sampled")
+	}
+	for _, n := range []string{"B1", "B2"} {
+		if _, ok := s.GetSequence(n); !ok {
+			t.Errorf("Sequence %s should be sampled", n)
+		}
+	}
+
+	// Weighted: A2 and B1 have no weight, and should never be sampled
+	weights := map[string]float64{"A1": 1.0, "A2": 0.0, "A3": 5.0, "B1": 0, "B2": 1.0}
+	for i := 0; i < 10; i++ {
+		if s, err = a.StratifiedSample(groups, 2, weights, rand.New(rand.NewSource(int64(i)))); err != nil {
+			t.Error(err)
+		}
+		if s.NbSequences() != 3 {
+			t.Errorf("Weighted stratified sample should contain 3 sequences, has %d", s.NbSequences())
+		}
+		if _, ok := s.GetSequence("A2"); ok {
+			t.Error("Sequence A2 has a 0 weight and should not be sampled")
+		}
+	}
+
+	// Reproducibility
+	s1, _ := a.StratifiedSample(groups, 1, nil, rand.New(rand.NewSource(10)))
+	s2, _ := a.StratifiedSample(groups, 1, nil, rand.New(rand.NewSource(10)))
+	if !s1.Identical(s2) {
+		t.Error("Stratified samples with the same seed should be identical")
+	}
+
+	if _, err = a.StratifiedSample(map[string][]string{"A": {"Z"}}, 1, nil, rand.New(rand.NewSource(10))); err == nil {
+		t.Error("Sampling an unknown sequence should return an error")
+	}
+}
+
+func Test_align_VariableSites(t *testing.T) {
+	a := NewAlign(NUCLEOTIDS)
+	a.AddSequence("A", "AAAA-AC*", "")
+	a.AddSequence("B", "AACA-AC.", "")
+	a.AddSequence("C", "AAGA-CCA", "")
+	a.AddSequence("D", "A-GAT-CT", "")
+
+	want := []int{2, 5, 7}
+	if got := a.VariableSites(); !reflect.DeepEqual(got, want) {
+		t.Errorf("align.VariableSites() = %v, want %v", got, want)
+	}
+	if got := a.NbVariableSites(); got != len(want) {
+		t.Errorf("align.NbVariableSites() = %v, want %v", got, len(want))
+	}
+}
+
+func Test_align_MaxCharStatsTies(t *testing.T) {
+	a := NewAlign(NUCLEOTIDS)
+	a.AddSequence("A", "TG-A", "")
+	a.AddSequence("B", "CG-T", "")
+	a.AddSequence("C", "TA-A", "")
+	a.AddSequence("D", "CA-T", "")
+	a.AddSequence("E", "GCAG", "")
+	a.AddSequence("F", "AC-G", "")
+
+	// Ties are resolved by taking the lowest character
+	for i := 0; i < 20; i++ {
+		out, occur, _ := a.MaxCharStats(false, false)
+		if string(out) != "CA-A" {
+			t.Fatalf("align.MaxCharStats() majority = %s, want CA-A", string(out))
+		}
+		if !reflect.DeepEqual(occur, []int{2, 2, 5, 2}) {
+			t.Fatalf("align.MaxCharStats() occurences = %v, want [2 2 5 2]", occur)
+		}
+	}
+	out, _, _ := a.MaxCharStats(true, false)
+	if string(out) != "CAAA" {
+		t.Errorf("align.MaxCharStats(ignoreGaps) majority = %s, want CAAA", string(out))
+	}
+}
+
+func TestPhaseAltStart(t *testing.T) {
+	bacterial, _ := GeneticCodeFromNCBI(11)
+	orf := "ATGGATGACTTTTTCTGTTGCCCTCCCCCACCGCAACAGTCTTCCTCATCGTAA"
+	in := NewSeqBag(UNKNOWN)
+	in.AddSequence("Seq0000", "CCGTGAAACCCGGGTTT"+orf, "")
+	in.AddSequence("Seq0001", "AAAAGTGAAACCCGGGTTT"+orf+"GG", "")
+	in.AutoAlphabet()
+
+	for _, translate := range []bool{true, false} {
+		for _, altstart := range []bool{false, true} {
+			start := "ATGGATGAC"
+			if altstart {
+				// GTG is an initiation codon of the bacterial code:
+				// the longest ORF starts 15 nt upstream of ATG
+				start = "GTGAAACCC"
+			}
+			phaser := NewPhaser()
+			phaser.SetLenCutoff(-1.0)
+			phaser.SetMatchCutoff(0.5)
+			phaser.SetCpus(1)
+			if err := phaser.SetTranslate(translate, bacterial); err != nil {
+				t.Fatal(err)
+			}
+			phaser.SetAltStart(altstart)
+
+			phased, err := phaser.Phase(nil, in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for ph := range phased {
+				if ph.Err != nil {
+					t.Fatal(ph.Err)
+				}
+				if ph.Removed || !strings.HasPrefix(ph.NtSeq.Sequence(), start) {
+					t.Errorf("%s (translate=%v, altstart=%v) should be phased at %s: %s", ph.NtSeq.Name(), translate, altstart, start, ph.NtSeq.Sequence())
+				}
+				if !strings.HasPrefix(ph.AaSeq.Sequence(), "M") {
+					t.Errorf("%s (translate=%v, altstart=%v) should be translated with M first: %s", ph.NtSeq.Name(), translate, altstart, ph.AaSeq.Sequence())
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package align
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Types of disagreements between a protein sequence and its coding sequence
+// found by CodonAlignDiagnostics
+const (
+	CODONALIGN_MISMATCH       = "mismatch"             // The codon does not encode the residue
+	CODONALIGN_INTERNAL_STOP  = "internal_stop"        // The codon is a stop codon
+	CODONALIGN_SELENOCYSTEINE = "selenocysteine"       // U residue encoded by a stop codon (tolerated)
+	CODONALIGN_PYRROLYSINE    = "pyrrolysine"          // O residue encoded by a stop codon (tolerated)
+	CODONALIGN_ALT_START      = "alt_start"            // First M residue encoded by an alternative start codon (tolerated)
+	CODONALIGN_PARTIAL_CODON  = "partial_codon"        // Last codon is incomplete, padded with N (tolerated)
+	CODONALIGN_MISSING_CODON  = "missing_codon"        // No more nucleotide for the residue, replaced by NNN
+	CODONALIGN_FS_INSERTION   = "frameshift_insertion" // Additional nucleotides removed to restore the frame
+	CODONALIGN_FS_DELETION    = "frameshift_deletion"  // Missing nucleotides replaced by N to restore the frame
+	CODONALIGN_TERMINAL_STOP  = "terminal_stop"        // Terminal stop codon(s) removed (tolerated)
+	CODONALIGN_TRAILING_NT    = "trailing_nt"          // Additional nucleotides at the end of the sequence, removed
+)
+
+// Number of residues after a mismatch that must be encoded
+// by the shifted frame to detect a frameshift
+const codonAlignFrameshiftWindow = 5
+
+// CodonAlignIssue describes a disagreement between a residue of the protein
+// alignment and its codon in the coding sequence (see CodonAlignDiagnostics).
+type CodonAlignIssue struct {
+	Name        string // Name of the sequence
+	Position    int    // Position of the residue in the protein alignment (0-based), -1 if not applicable
+	Residue     uint8  // Residue of the protein alignment, 0 if not applicable
+	Codon       string // Corresponding codon (or nucleotides) of the coding sequence
+	Translation uint8  // Translation of the codon, 0 if not applicable
+	Type        string // Type of the issue: CODONALIGN_MISMATCH, CODONALIGN_INTERNAL_STOP, etc.
+}
+
+// Mismatch returns true if the issue is a real disagreement between the
+// protein and the coding sequence (mismatch, internal stop or missing codon),
+// and false if it is tolerated or fixed.
+func (i CodonAlignIssue) Mismatch() bool {
+	return i.Type == CODONALIGN_MISMATCH || i.Type == CODONALIGN_INTERNAL_STOP || i.Type == CODONALIGN_MISSING_CODON
+}
+
+// CodonAlignDiagnostics aligns the given nucleotide sequences using the current
+// amino acid alignment, as CodonAlign, but checks that each codon encodes its
+// residue, and tolerates differences between the protein and the coding sequence:
+//   - Terminal stop codons and trailing nucleotides are removed;
+//   - An incomplete last codon is padded with N;
+//   - Selenocysteine (U) and pyrrolysine (O) residues may be encoded by stop codons;
+//   - The first M may be encoded by an alternative start codon of the genetic code;
+//   - X residues, ambiguity codes (B, Z, J) and ambiguous codons (IUPAC) match if
+//     at least one possibility is compatible;
+//   - At most maxmismatches mismatches (other residue, internal stop or missing codon)
+//     per sequence. If maxmismatches < 0, then any number of mismatches is tolerated.
+//
+// If fixframeshifts is true, when a codon does not encode its residue, but shifting
+// the frame by 1 or 2 nucleotides makes the next residues match, then a frameshift is
+// assumed: additional nucleotides are removed (insertion), or missing nucleotides
+// are replaced by N (deletion).
+//
+// All the disagreements are returned in issues, in the order of the sequences. If a sequence
+// has more than maxmismatches mismatches, an error is returned (issues are still returned).
+func (a *align) CodonAlignDiagnostics(ntseqs SeqBag, geneticcode int, maxmismatches int, fixframeshifts bool) (rtAl *align, issues []CodonAlignIssue, err error) {
+	var buffer bytes.Buffer
+	var code map[string]uint8
+	var starts map[string]bool
+
+	if code, err = geneticCode(geneticcode); err != nil {
+		return
+	}
+	if starts, err = startCodons(geneticcode); err != nil {
+		return
+	}
+	if a.Alphabet() != AMINOACIDS {
+		err = errors.New("wrong alphabet, cannot reverse translate nucleotides")
+		return
+	}
+	if ntseqs.Alphabet() != NUCLEOTIDS {
+		err = errors.New("wrong nucleotidic alignment alphabet, cannot reverse translate")
+		return
+	}
+
+	rtAl = NewAlign(NUCLEOTIDS)
+	for _, s := range a.seqs {
+		var seqissues []CodonAlignIssue
+		ntseq, ok := ntseqs.GetSequenceChar(s.name)
+		if !ok {
+			err = fmt.Errorf("sequence %s is not present in the nucleotidic sequence, cannot reverse translate", s.name)
+			return
+		}
+		buffer.Reset()
+		seqissues = codonAlignSequence(s.name, s.sequence, ungapNt(ntseq), code, starts, fixframeshifts, &buffer)
+		if err = rtAl.AddSequence(s.name, buffer.String(), s.comment); err != nil {
+			return
+		}
+		issues = append(issues, seqissues...)
+
+		nbmismatches := 0
+		for _, i := range seqissues {
+			if i.Mismatch() {
+				nbmismatches++
+			}
+		}
+		if maxmismatches >= 0 && nbmismatches > maxmismatches {
+			err = fmt.Errorf("sequence %s: %d mismatches between protein and nucleotide sequences (max %d)", s.name, nbmismatches, maxmismatches)
+			return
+		}
+	}
+	return
+}
+
+// codonAlignSequence threads the nucleotides of nt onto the aligned protein sequence aa,
+// writes the codon aligned sequence in the buffer, and returns the disagreements.
+func codonAlignSequence(name string, aa, nt []uint8, code map[string]uint8, starts map[string]bool, fixframeshifts bool, buffer *bytes.Buffer) (issues []CodonAlignIssue) {
+	var ntindex, nbresidues int
+	var residues []uint8
+
+	for _, r := range aa {
+		if r != GAP {
+			residues = append(residues, r)
+		}
+	}
+
+	addissue := func(pos int, res uint8, codon string, tr uint8, t string) {
+		issues = append(issues, CodonAlignIssue{Name: name, Position: pos, Residue: res, Codon: codon, Translation: tr, Type: t})
+	}
+
+	for pos, r := range aa {
+		if r == GAP {
+			buffer.WriteString("---")
+			continue
+		}
+		first := nbresidues == 0
+		nbresidues++
+
+		rem := len(nt) - ntindex
+		if rem <= 0 {
+			addissue(pos, r, "", 0, CODONALIGN_MISSING_CODON)
+			buffer.WriteString("NNN")
+			continue
+		}
+		if rem < 3 {
+			codon := string(nt[ntindex:]) + strings.Repeat("N", 3-rem)
+			addissue(pos, r, codon, translateCodon(codon[0], codon[1], codon[2], code), CODONALIGN_PARTIAL_CODON)
+			buffer.WriteString(codon)
+			ntindex = len(nt)
+			continue
+		}
+
+		tr := translateCodon(nt[ntindex], nt[ntindex+1], nt[ntindex+2], code)
+		match, t := codonEncodesResidue(r, nt[ntindex:ntindex+3], tr, first, code, starts)
+		if !match && fixframeshifts {
+			// Number of nucleotides to skip (>0) or to add (<0) to restore the frame
+			if shift := codonAlignFrameshift(residues[nbresidues-1:], nt, ntindex, code, starts); shift > 0 {
+				addissue(pos, r, string(nt[ntindex:ntindex+shift]), 0, CODONALIGN_FS_INSERTION)
+				ntindex += shift
+				tr = translateCodon(nt[ntindex], nt[ntindex+1], nt[ntindex+2], code)
+				match, t = codonEncodesResidue(r, nt[ntindex:ntindex+3], tr, first, code, starts)
+			} else if shift < 0 {
+				codon := string(nt[ntindex:ntindex+3+shift]) + strings.Repeat("N", -shift)
+				addissue(pos, r, codon, 0, CODONALIGN_FS_DELETION)
+				buffer.WriteString(codon)
+				ntindex += 3 + shift
+				continue
+			}
+		}
+		if t != "" {
+			addissue(pos, r, string(nt[ntindex:ntindex+3]), tr, t)
+		}
+		buffer.Write(nt[ntindex : ntindex+3])
+		ntindex += 3
+	}
+
+	if rem := len(nt) - ntindex; rem > 0 {
+		stops := rem%3 == 0
+		for i := ntindex; stops && i < len(nt); i += 3 {
+			stops = translateCodon(nt[i], nt[i+1], nt[i+2], code) == '*'
+		}
+		if stops {
+			addissue(-1, 0, string(nt[ntindex:]), '*', CODONALIGN_TERMINAL_STOP)
+		} else {
+			addissue(-1, 0, string(nt[ntindex:]), 0, CODONALIGN_TRAILING_NT)
+		}
+	}
+	return
+}
+
+// codonAlignFrameshift searches a frameshift at position ntindex of nt, such that
+// the following residues are encoded by the shifted frame.
+// It returns the number of nucleotides to skip (insertion: 1 or 2), to pad with N
+// (deletion: -1 or -2), or 0 if no frameshift restores the frame.
+func codonAlignFrameshift(residues []uint8, nt []uint8, ntindex int, code map[string]uint8, starts map[string]bool) (shift int) {
+	// Number of residues encoded from nt position ntstart, starting at residue resstart
+	nbmatches := func(resstart, ntstart int) (nb int) {
+		for i := resstart; i < len(residues) && i < resstart+codonAlignFrameshiftWindow; i++ {
+			ntpos := ntstart + 3*(i-resstart)
+			if ntpos+3 > len(nt) {
+				break
+			}
+			tr := translateCodon(nt[ntpos], nt[ntpos+1], nt[ntpos+2], code)
+			if ok, _ := codonEncodesResidue(residues[i], nt[ntpos:ntpos+3], tr, false, code, starts); !ok {
+				break
+			}
+			nb++
+		}
+		return
+	}
+
+	window := len(residues)
+	if window > codonAlignFrameshiftWindow {
+		window = codonAlignFrameshiftWindow
+	}
+	// Not enough residues to detect a frameshift reliably
+	if window < 3 {
+		return 0
+	}
+	for _, s := range []int{1, -1, 2, -2} {
+		if s > 0 && nbmatches(0, ntindex+s) == window {
+			return s
+		}
+		// Deletion: the current codon is padded, the next residues must match
+		if s < 0 && nbmatches(1, ntindex+3+s) == window-1 {
+			return s
+		}
+	}
+	return 0
+}
+
+// codonEncodesResidue returns true if the given codon, translated as tr, may encode the
+// residue r. If the codon is tolerated but does not exactly encode the residue, t gives
+// the type of tolerance. If not, t gives the type of mismatch.
+func codonEncodesResidue(r uint8, codon []uint8, tr uint8, first bool, code map[string]uint8, starts map[string]bool) (match bool, t string) {
+	r = uint8(unicode.ToUpper(rune(r)))
+	switch {
+	case r == tr || r == 'X':
+		return true, ""
+	case r == 'U' && tr == '*':
+		return true, CODONALIGN_SELENOCYSTEINE
+	case r == 'O' && tr == '*':
+		return true, CODONALIGN_PYRROLYSINE
+	case first && r == 'M' && starts[strings.ToUpper(strings.ReplaceAll(string(codon), "U", "T"))]:
+		return true, CODONALIGN_ALT_START
+	}
+
+	// Possible amino acids of the residue
+	possible := []uint8{r}
+	for k, v := range aaAmbiguityClasses {
+		if v == r {
+			possible = []uint8{k[0], k[1]}
+		}
+	}
+	// Possible translations of the codon
+	for _, c := range GenAllPossibleCodons(codon[0], codon[1], codon[2]) {
+		for _, p := range possible {
+			if code[c] == p {
+				return true, ""
+			}
+		}
+	}
+
+	if tr == '*' {
+		return false, CODONALIGN_INTERNAL_STOP
+	}
+	return false, CODONALIGN_MISMATCH
+}
+
+// ungapNt returns a copy of the given sequence without gaps
+func ungapNt(seq []uint8) (ungapped []uint8) {
+	ungapped = make([]uint8, 0, len(seq))
+	for _, c := range seq {
+		if c != GAP {
+			ungapped = append(ungapped, c)
+		}
+	}
+	return
+}
+
+// IUPAC nucleotide corresponding to a combination of NT_A, NT_C, NT_G and NT_T
+var iupacFromCode = []uint8("-ACMGRSVTWYHKDBN")
+
+// ReverseTranslate generates a degenerate nucleotide alignment from the current
+// amino acid alignment, when no coding sequence is available.
+//
+// Each residue is replaced by the IUPAC codon representing all the codons encoding
+// it in the given genetic code, position by position (ex: GGN for G, AAY for N,
+// and YTN for L, which also represents TTY, encoding F). Gaps are replaced by "---",
+// X by NNN, * by the union of the stop codons, B, Z and J by the union of the codons
+// of their amino acids, U (selenocysteine) by TGA, and O (pyrrolysine) by TAG.
+func (a *align) ReverseTranslate(geneticcode int) (rtAl *align, err error) {
+	var code map[string]uint8
+	var buffer bytes.Buffer
+
+	if a.Alphabet() != AMINOACIDS {
+		err = errors.New("wrong alphabet, cannot reverse translate nucleotides")
+		return
+	}
+	if code, err = geneticCode(geneticcode); err != nil {
+		return
+	}
+
+	// IUPAC codes of the 3 positions of the codons for each amino acid
+	codons := make(map[uint8][3]uint8)
+	for codon, aa := range code {
+		if aa == GAP {
+			continue
+		}
+		c := codons[aa]
+		for i := 0; i < 3; i++ {
+			c[i] |= iupacToInt[codon[i]]
+		}
+		codons[aa] = c
+	}
+	for k, v := range aaAmbiguityClasses {
+		c1, c2 := codons[k[0]], codons[k[1]]
+		codons[v] = [3]uint8{c1[0] | c2[0], c1[1] | c2[1], c1[2] | c2[2]}
+	}
+	codons['X'] = [3]uint8{NT_N, NT_N, NT_N}
+	codons['U'] = [3]uint8{NT_T, NT_G, NT_A}
+	codons['O'] = [3]uint8{NT_T, NT_A, NT_G}
+
+	rtAl = NewAlign(NUCLEOTIDS)
+	for _, s := range a.seqs {
+		buffer.Reset()
+		for _, r := range s.sequence {
+			if r == GAP {
+				buffer.WriteString("---")
+				continue
+			}
+			c, ok := codons[uint8(unicode.ToUpper(rune(r)))]
+			if !ok {
+				err = fmt.Errorf("sequence %s: cannot reverse translate character %c", s.name, r)
+				return
+			}
+			buffer.Write([]uint8{iupacFromCode[c[0]], iupacFromCode[c[1]], iupacFromCode[c[2]]})
+		}
+		if err = rtAl.AddSequence(s.name, buffer.String(), s.comment); err != nil {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,86 @@
+package align
+
+import (
+	"testing"
+)
+
+func TestCodonAlignDiagnostics(t *testing.T) {
+	a := NewAlign(AMINOACIDS)
+	a.AddSequence("s1", "MK-FLG", "")
+	a.AddSequence("s2", "MKUFLG", "")
+	a.AddSequence("s3", "MKPFLG", "")
+	a.AddSequence("s4", "MKPFLG", "")
+
+	n := NewSeqBag(NUCLEOTIDS)
+	// Terminal stop
+	n.AddSequence("s1", "TTGAAATTTCTGGGGTAA", "")
+	// Selenocysteine and partial codon
+	n.AddSequence("s2", "ATGAAATGATTTCTGGG", "")
+	// Mismatch (P -> CAA)
+	n.AddSequence("s3", "ATGAAACAATTTCTGGGG", "")
+	// Frameshift: one nucleotide missing in AAA
+	n.AddSequence("s4", "ATGAACCCTTTCTGGGG", "")
+
+	res, issues, err := a.CodonAlignDiagnostics(n, GENETIC_CODE_STANDARD, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedseqs := map[string]string{
+		"s1": "TTGAAA---TTTCTGGGG",
+		"s2": "ATGAAATGATTTCTGGGN",
+		"s3": "ATGAAACAATTTCTGGGG",
+		"s4": "ATGAANCCCTTTCTGGGG",
+	}
+	for name, exp := range expectedseqs {
+		if s, ok := res.GetSequence(name); !ok || s != exp {
+			t.Errorf("Sequence %s: expected %s, got %s", name, exp, s)
+		}
+	}
+
+	expected := []CodonAlignIssue{
+		{"s1", 0, 'M', "TTG", 'L', CODONALIGN_ALT_START},
+		{"s1", -1, 0, "TAA", '*', CODONALIGN_TERMINAL_STOP},
+		{"s2", 2, 'U', "TGA", '*', CODONALIGN_SELENOCYSTEINE},
+		{"s2", 5, 'G', "GGN", 'G', CODONALIGN_PARTIAL_CODON},
+		{"s3", 2, 'P', "CAA", 'Q', CODONALIGN_MISMATCH},
+		{"s4", 1, 'K', "AAN", 0, CODONALIGN_FS_DELETION},
+	}
+	if len(issues) != len(expected) {
+		t.Fatalf("Expected %d issues, got %d: %v", len(expected), len(issues), issues)
+	}
+	for i, e := range expected {
+		if issues[i] != e {
+			t.Errorf("Issue %d: expected %v, got %v", i, e, issues[i])
+		}
+	}
+
+	// Too many mismatches
+	if _, _, err = a.CodonAlignDiagnostics(n, GENETIC_CODE_STANDARD, 0, true); err == nil {
+		t.Errorf("Expected an error with too many mismatches")
+	}
+	// Frameshift not fixed
+	if _, _, err = a.CodonAlignDiagnostics(n, GENETIC_CODE_STANDARD, 1, false); err == nil {
+		t.Errorf("Expected an error with frameshift not fixed")
+	}
+}
+
+func TestReverseTranslate(t *testing.T) {
+	a := NewAlign(AMINOACIDS)
+	a.AddSequence("s1", "MGL-NX*", "")
+	a.AddSequence("s2", "WSRBZJK", "")
+
+	res, err := a.ReverseTranslate(GENETIC_CODE_STANDARD)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"s1": "ATGGGNYTN---AAYNNNTRR",
+		"s2": "TGGWSNMGNRAYSARHTNAAR",
+	}
+	for name, exp := range expected {
+		if s, ok := res.GetSequence(name); !ok || s != exp {
+			t.Errorf("Sequence %s: expected %s, got %s", name, exp, s)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package align
+
+import (
+	"fmt"
+	"math"
+)
+
+// CodonUsage counts the codons of a set of coding sequences, and computes
+// codon usage statistics (RSCU, ENC, CAI, GC content at each codon position)
+// using a given genetic code.
+//
+// Codons are indexed from 0 to 63, by 16*n1+4*n2+n3, with nucleotides
+// in the order A, C, G, T (see CodonString).
+type CodonUsage struct {
+	aa     [64]uint8   // Amino acid of each codon ('*' for stops)
+	counts [64]float64 // Number of occurences of each codon (may be frequencies)
+}
+
+// NewCodonUsage initializes an empty codon usage with the given genetic code
+func NewCodonUsage(geneticcode int) (cu *CodonUsage, err error) {
+	var code map[string]uint8
+	var ok bool
+
+	if code, err = geneticCode(geneticcode); err != nil {
+		return
+	}
+	cu = &CodonUsage{}
+	for c := 0; c < 64; c++ {
+		if cu.aa[c], ok = code[CodonString(c)]; !ok {
+			err = fmt.Errorf("codon %s does not exist in the genetic code", CodonString(c))
+			return
+		}
+	}
+	return
+}
+
+// CodonString returns the codon corresponding to the given index (0-63)
+func CodonString(c int) string {
+	return codonString(c)
+}
+
+// AddSequence counts the codons of the given sequence, read in frame from
+// its first position. Codons containing gaps or ambiguous nucleotides are
+// ignored, as well as the last incomplete codon.
+func (cu *CodonUsage) AddSequence(sequence []uint8) {
+	for i := 0; i+2 < len(sequence); i += 3 {
+		if c := codonIndex(sequence[i], sequence[i+1], sequence[i+2]); c >= 0 {
+			cu.counts[c]++
+		}
+	}
+}
+
+// AddCount adds the given number of occurences (or frequency) to the given codon (ex: "ATG").
+func (cu *CodonUsage) AddCount(codon string, count float64) (err error) {
+	var c int
+	if len(codon) != 3 {
+		err = fmt.Errorf("%s is not a codon", codon)
+		return
+	}
+	if c = codonIndex(codon[0], codon[1], codon[2]); c < 0 {
+		err = fmt.Errorf("%s is not a valid codon", codon)
+		return
+	}
+	cu.counts[c] += count
+	return
+}
+
+// Add adds the counts of the given codon usage
+func (cu *CodonUsage) Add(other *CodonUsage) {
+	for c := range cu.counts {
+		cu.counts[c] += other.counts[c]
+	}
+}
+
+// AminoAcid returns the amino acid encoded by the given codon index
+func (cu *CodonUsage) AminoAcid(c int) uint8 {
+	return cu.aa[c]
+}
+
+// Count returns the number of occurences of the given codon index
+func (cu *CodonUsage) Count(c int) float64 {
+	return cu.counts[c]
+}
+
+// NbCodons returns the total number of counted codons
+func (cu *CodonUsage) NbCodons() (n float64) {
+	for _, c := range cu.counts {
+		n += c
+	}
+	return
+}
+
+// synonymous returns the groups of codons encoding the same amino acid
+// (or stop: '*'), ordered by their first codon
+func (cu *CodonUsage) synonymous() (syn [][]int) {
+	var index map[uint8]int = make(map[uint8]int)
+	for c := 0; c < 64; c++ {
+		i, ok := index[cu.aa[c]]
+		if !ok {
+			i = len(syn)
+			index[cu.aa[c]] = i
+			syn = append(syn, nil)
+		}
+		syn[i] = append(syn[i], c)
+	}
+	return
+}
+
+// RSCU returns the relative synonymous codon usage of each codon (Sharp & Li 1986):
+// its number of occurences divided by the average number of occurences of the
+// codons of the same amino acid. It is NaN for codons of absent amino acids.
+func (cu *CodonUsage) RSCU() (rscu [64]float64) {
+	for _, codons := range cu.synonymous() {
+		total := 0.0
+		for _, c := range codons {
+			total += cu.counts[c]
+		}
+		for _, c := range codons {
+			if total == 0 {
+				rscu[c] = math.NaN()
+			} else {
+				rscu[c] = cu.counts[c] * float64(len(codons)) / total
+			}
+		}
+	}
+	return
+}
+
+// ENC returns the effective number of codons (Wright 1990).
+//
+// Amino acids are grouped by degeneracy class (number of codons), and the
+// average homozygosity F of the amino acids of each class that are present at
+// least twice is computed. ENC is the sum over classes of the number of amino acids
+// in the class divided by their average F. If no amino acid of the 3-fold class
+// is present (Ile in the standard code), its F is the average of 2-fold and 4-fold
+// F values. ENC is NaN if the F of another class can not be computed, and it is
+// at most the number of sense codons.
+func (cu *CodonUsage) ENC() (enc float64) {
+	var fsum map[int]float64 = make(map[int]float64)
+	var fnb map[int]int = make(map[int]int)
+	var naa map[int]int = make(map[int]int)
+	var sense int = 0
+
+	for _, codons := range cu.synonymous() {
+		if cu.aa[codons[0]] == '*' {
+			continue
+		}
+		k := len(codons)
+		sense += k
+		naa[k]++
+		if k == 1 {
+			continue
+		}
+		n := 0.0
+		for _, c := range codons {
+			n += cu.counts[c]
+		}
+		if n < 2 {
+			continue
+		}
+		hom := 0.0
+		for _, c := range codons {
+			p := cu.counts[c] / n
+			hom += p * p
+		}
+		fsum[k] += (n*hom - 1.0) / (n - 1)
+		fnb[k]++
+	}
+
+	for k := 1; k <= 64; k++ {
+		nb := naa[k]
+		if nb == 0 {
+			continue
+		}
+		if k == 1 {
+			enc += float64(nb)
+			continue
+		}
+		var f float64
+		switch {
+		case fnb[k] > 0:
+			f = fsum[k] / float64(fnb[k])
+		case k == 3 && fnb[2] > 0 && fnb[4] > 0:
+			f = (fsum[2]/float64(fnb[2]) + fsum[4]/float64(fnb[4])) / 2.0
+		default:
+			return math.NaN()
+		}
+		enc += float64(nb) / f
+	}
+	return math.Min(enc, float64(sense))
+}
+
+// CAI returns the codon adaptation index of the codons (Sharp & Li 1987), given
+// a reference codon usage (ex: of highly expressed genes). The relative adaptiveness
+// w of each codon is its number of occurences in the reference divided by the number
+// of occurences of the most frequent synonymous codon (codons absent from the reference
+// are given a count of 0.5). CAI is the geometric mean of w over all counted codons,
+// excluding stop codons and codons of amino acids encoded by a single codon.
+// It is NaN if no codon is considered.
+func (cu *CodonUsage) CAI(reference *CodonUsage) float64 {
+	var w [64]float64
+	var sum, n float64
+
+	for _, codons := range reference.synonymous() {
+		if reference.aa[codons[0]] == '*' || len(codons) == 1 {
+			continue
+		}
+		max := 0.5
+		for _, c := range codons {
+			max = math.Max(max, reference.counts[c])
+		}
+		for _, c := range codons {
+			w[c] = math.Max(0.5, reference.counts[c]) / max
+		}
+	}
+	for c := 0; c < 64; c++ {
+		if w[c] > 0 && cu.counts[c] > 0 {
+			sum += cu.counts[c] * math.Log(w[c])
+			n += cu.counts[c]
+		}
+	}
+	if n == 0 {
+		return math.NaN()
+	}
+	return math.Exp(sum / n)
+}
+
+// GC returns the GC content at the given codon position (0, 1 or 2), or at all
+// positions if pos < 0. It is NaN if no codon is counted.
+func (cu *CodonUsage) GC(pos int) float64 {
+	gc, total := 0.0, 0.0
+	for c := 0; c < 64; c++ {
+		for p := 0; p < 3; p++ {
+			if pos >= 0 && p != pos {
+				continue
+			}
+			if nt := codonNt(c, p); nt == 1 || nt == 2 {
+				gc += cu.counts[c]
+			}
+			total += cu.counts[c]
+		}
+	}
+	if total == 0 {
+		return math.NaN()
+	}
+	return gc / total
+}
+
+// GC3s returns the GC content at synonymous third positions, i.e. at third
+// positions of codons of amino acids encoded by several codons (excluding stops).
+// It is NaN if no such codon is counted.
+func (cu *CodonUsage) GC3s() float64 {
+	gc, total := 0.0, 0.0
+	for _, codons := range cu.synonymous() {
+		if cu.aa[codons[0]] == '*' || len(codons) == 1 {
+			continue
+		}
+		for _, c := range codons {
+			if nt := codonNt(c, 2); nt == 1 || nt == 2 {
+				gc += cu.counts[c]
+			}
+			total += cu.counts[c]
+		}
+	}
+	if total == 0 {
+		return math.NaN()
+	}
+	return gc / total
+}
@@ -0,0 +1,69 @@
+package align
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCodonUsage(t *testing.T) {
+	var cu, ref *CodonUsage
+	var err error
+
+	closeTo := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+	if cu, err = NewCodonUsage(GENETIC_CODE_STANDARD); err != nil {
+		t.Fatal(err)
+	}
+	cu.AddSequence([]uint8("ATGGCTGCTGCCGCAGCGTTTTTCTAAGC-NNNAT"))
+	if cu.NbCodons() != 9 {
+		t.Errorf("There should be 9 codons, there are %f", cu.NbCodons())
+	}
+
+	rscu := cu.RSCU()
+	for _, tt := range []struct {
+		codon string
+		rscu  float64
+	}{{"GCT", 1.6}, {"GCC", 0.8}, {"TTT", 1.0}, {"ATG", 1.0}, {"TAA", 3.0}} {
+		c := codonIndex(tt.codon[0], tt.codon[1], tt.codon[2])
+		if !closeTo(rscu[c], tt.rscu) {
+			t.Errorf("RSCU of %s should be %f, is %f", tt.codon, tt.rscu, rscu[c])
+		}
+	}
+	if c := codonIndex('T', 'G', 'G'); !math.IsNaN(rscu[c]) {
+		t.Errorf("RSCU of absent amino acid should be NaN, is %f", rscu[c])
+	}
+
+	// GC: 14 G/C over 27 nucleotides
+	if !closeTo(cu.GC(-1), 14.0/27.0) || !closeTo(cu.GC(0), 5.0/9.0) || !closeTo(cu.GC(1), 5.0/9.0) || !closeTo(cu.GC(2), 4.0/9.0) {
+		t.Errorf("Wrong GC contents: %f %f %f %f", cu.GC(-1), cu.GC(0), cu.GC(1), cu.GC(2))
+	}
+	if !closeTo(cu.GC3s(), 3.0/7.0) {
+		t.Errorf("Wrong GC3s: %f", cu.GC3s())
+	}
+
+	// CAI against itself
+	if !closeTo(cu.CAI(cu), math.Pow(0.5, 3.0/7.0)) {
+		t.Errorf("Wrong CAI: %f", cu.CAI(cu))
+	}
+
+	// ENC: one codon per amino acid => 20, uniform usage => 61
+	ref, _ = NewCodonUsage(GENETIC_CODE_STANDARD)
+	cu, _ = NewCodonUsage(GENETIC_CODE_STANDARD)
+	seen := make(map[uint8]bool)
+	for c := 0; c < 64; c++ {
+		if !seen[ref.AminoAcid(c)] {
+			ref.AddCount(CodonString(c), 10)
+			seen[ref.AminoAcid(c)] = true
+		}
+		cu.AddCount(CodonString(c), 10)
+	}
+	if !closeTo(ref.ENC(), 20) {
+		t.Errorf("ENC should be 20, is %f", ref.ENC())
+	}
+	if !closeTo(cu.ENC(), 61) {
+		t.Errorf("ENC should be 61, is %f", cu.ENC())
+	}
+	if err = cu.AddCount("AT-", 1); err == nil {
+		t.Errorf("Adding an invalid codon should return an error")
+	}
+}
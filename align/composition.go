@@ -0,0 +1,159 @@
+package align
+
+import (
+	"fmt"
+	"math"
+	mathrand "math/rand"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// CompositionTest gives the result of the chi-square test of composition
+// homogeneity of a sequence, or of all the sequences
+type CompositionTest struct {
+	Name       string
+	Counts     []int   // Number of occurences of each character of the alphabet (see AlphabetCharacters)
+	Total      int     // Number of unambiguous characters
+	ChiSquare  float64 // Chi-square statistic
+	DF         int     // Degrees of freedom
+	PValue     float64 // P-value of the chi-square statistic under the chi-square distribution
+	BootPValue float64 // P-value of the chi-square statistic under the parametric bootstrap null (NaN if not computed)
+}
+
+// Frequencies returns the frequency of each character of the alphabet
+// (see AlphabetCharacters), NaN if the sequence has no unambiguous character.
+func (c CompositionTest) Frequencies() (freqs []float64) {
+	freqs = make([]float64, len(c.Counts))
+	for i, n := range c.Counts {
+		freqs[i] = float64(n) / float64(c.Total)
+	}
+	return
+}
+
+// CompositionTest tests the homogeneity of character frequencies across sequences,
+// as in IQ-TREE and PAUP* composition tests. Only unambiguous characters of the
+// alphabet are counted (see CharStatsSeq).
+//
+// For each sequence, the chi-square statistic compares its character counts to the
+// counts expected given its number of characters and the character frequencies of all
+// the sequences together. Degrees of freedom are the number of observed characters
+// minus 1. The global test (returned in all) is the chi-square test of the contingency
+// table sequences x characters, whose statistic is the sum of the per-sequence statistics,
+// with (nseqs-1)*(nchars-1) degrees of freedom.
+//
+// If nboot > 0, p-values are also computed under a parametric bootstrap null: nboot
+// replicates are simulated by drawing the same number of characters for each sequence
+// from the global frequencies, and the bootstrap p-value is the proportion of replicates
+// giving a chi-square statistic >= observed one: (count+1)/(nboot+1).
+func (sb *seqbag) CompositionTest(nboot int, rand *mathrand.Rand) (tests []CompositionTest, all CompositionTest, err error) {
+	var stats map[uint8]int
+
+	chars := sb.AlphabetCharacters()
+	if len(chars) == 0 {
+		err = fmt.Errorf("composition test needs nucleotide or amino acid sequences")
+		return
+	}
+	all = CompositionTest{Name: "all", Counts: make([]int, len(chars))}
+	tests = make([]CompositionTest, sb.NbSequences())
+	for i, s := range sb.seqs {
+		if stats, err = sb.CharStatsSeq(i); err != nil {
+			return
+		}
+		tests[i] = CompositionTest{Name: s.name, Counts: make([]int, len(chars))}
+		for j, c := range chars {
+			tests[i].Counts[j] = stats[c]
+			tests[i].Total += stats[c]
+			all.Counts[j] += stats[c]
+		}
+		all.Total += tests[i].Total
+	}
+
+	freqs := all.Frequencies()
+	nchars := 0
+	for _, n := range all.Counts {
+		if n > 0 {
+			nchars++
+		}
+	}
+	nseqs := 0
+	for i := range tests {
+		tests[i].ChiSquare = compositionChiSquare(tests[i].Counts, tests[i].Total, freqs)
+		tests[i].DF = nchars - 1
+		tests[i].PValue = chiSquarePValue(tests[i].ChiSquare, tests[i].DF)
+		tests[i].BootPValue = math.NaN()
+		all.ChiSquare += tests[i].ChiSquare
+		if tests[i].Total > 0 {
+			nseqs++
+		}
+	}
+	all.DF = (nseqs - 1) * (nchars - 1)
+	all.PValue = chiSquarePValue(all.ChiSquare, all.DF)
+	all.BootPValue = math.NaN()
+
+	if nboot <= 0 || all.Total == 0 {
+		return
+	}
+
+	// Parametric bootstrap
+	cumfreqs := make([]float64, len(freqs))
+	sum := 0.0
+	for i, f := range freqs {
+		sum += f
+		cumfreqs[i] = sum
+	}
+	counts := make([]int, len(chars))
+	nge := make([]int, len(tests))
+	allnge := 0
+	for b := 0; b < nboot; b++ {
+		bootall := 0.0
+		for i, t := range tests {
+			for j := range counts {
+				counts[j] = 0
+			}
+			for k := 0; k < t.Total; k++ {
+				r := rand.Float64()
+				j := 0
+				for j < len(cumfreqs)-1 && r >= cumfreqs[j] {
+					j++
+				}
+				counts[j]++
+			}
+			chi2 := compositionChiSquare(counts, t.Total, freqs)
+			if chi2 >= t.ChiSquare-1e-9 {
+				nge[i]++
+			}
+			bootall += chi2
+		}
+		if bootall >= all.ChiSquare-1e-9 {
+			allnge++
+		}
+	}
+	for i := range tests {
+		if tests[i].Total > 0 {
+			tests[i].BootPValue = float64(nge[i]+1) / float64(nboot+1)
+		}
+	}
+	all.BootPValue = float64(allnge+1) / float64(nboot+1)
+	return
+}
+
+// compositionChiSquare returns the chi-square statistic comparing the given character
+// counts to the expected counts given the character frequencies
+func compositionChiSquare(counts []int, total int, freqs []float64) (chi2 float64) {
+	for j, n := range counts {
+		expected := freqs[j] * float64(total)
+		if expected > 0 {
+			chi2 += (float64(n) - expected) * (float64(n) - expected) / expected
+		}
+	}
+	return
+}
+
+// chiSquarePValue returns the probability that a chi-square distributed variable
+// with df degrees of freedom is >= chi2, NaN if df < 1
+func chiSquarePValue(chi2 float64, df int) float64 {
+	if df < 1 {
+		return math.NaN()
+	}
+	return distuv.ChiSquared{K: float64(df)}.Survival(chi2)
+}
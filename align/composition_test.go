@@ -0,0 +1,52 @@
+package align
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestCompositionTest(t *testing.T) {
+	sb := NewSeqBag(NUCLEOTIDS)
+	sb.AddSequence("s1", "ACGTACGTAC", "")
+	sb.AddSequence("s2", "ACGTACGTTC", "")
+	sb.AddSequence("s3", "GGGCGCGCGG", "")
+	sb.AddSequence("s4", "ACGAAC-TNC", "")
+
+	tests, all, err := sb.CompositionTest(0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tests) != 4 {
+		t.Fatalf("Expected 4 tests, got %d", len(tests))
+	}
+	if tests[3].Total != 8 || all.Total != 38 || all.Counts[0] != 8 || all.Counts[3] != 6 {
+		t.Errorf("Wrong character counts: %v %v", tests[3], all)
+	}
+	// s3: counts 0,3,7,0 vs frequencies 8/38,12/38,12/38,6/38
+	exp := 0.0
+	for i, n := range []float64{0, 3, 7, 0} {
+		e := 10 * float64(all.Counts[i]) / 38
+		exp += (n - e) * (n - e) / e
+	}
+	if math.Abs(tests[2].ChiSquare-exp) > 1e-9 || tests[2].DF != 3 || tests[2].PValue > 0.05 {
+		t.Errorf("Wrong chi-square test for s3: %v, expected chi2=%f", tests[2], exp)
+	}
+	sum := 0.0
+	for _, test := range tests {
+		sum += test.ChiSquare
+	}
+	if math.Abs(all.ChiSquare-sum) > 1e-9 || all.DF != 9 {
+		t.Errorf("Wrong global chi-square test: %v", all)
+	}
+	if !math.IsNaN(all.BootPValue) {
+		t.Errorf("Bootstrap p-value should be NaN without bootstrap")
+	}
+
+	if tests, all, err = sb.CompositionTest(100, rand.New(rand.NewSource(10))); err != nil {
+		t.Fatal(err)
+	}
+	if tests[2].BootPValue > 0.2 || tests[0].BootPValue < 0.2 || all.BootPValue <= 0 || all.BootPValue > 1 {
+		t.Errorf("Wrong bootstrap p-values: %f %f %f", tests[0].BootPValue, tests[2].BootPValue, all.BootPValue)
+	}
+}
@@ -35,9 +35,15 @@ const (
 	POSITION_SEMI_CONSERVED = 2 // Same weak group
 	POSITION_NOT_CONSERVED  = 3 // None of the above values
 
-	GENETIC_CODE_STANDARD         = 0 // Standard genetic code
-	GENETIC_CODE_VETEBRATE_MITO   = 1 // Vertebrate mitochondrial genetic code
-	GENETIC_CODE_INVETEBRATE_MITO = 2 // Invertebrate mitochondrial genetic code
+	// Genetic codes. Other NCBI translation tables (3, 4, 5, 6, 9-16, 21-33)
+	// are identified by their NCBI number (see GeneticCodeFromNCBI)
+	GENETIC_CODE_STANDARD         = 0  // Standard genetic code (NCBI 1)
+	GENETIC_CODE_VETEBRATE_MITO   = 1  // Vertebrate mitochondrial genetic code (NCBI 2)
+	GENETIC_CODE_INVETEBRATE_MITO = 2  // Invertebrate mitochondrial genetic code (NCBI 5)
+	GENETIC_CODE_YEAST_MITO       = 3  // Yeast mitochondrial genetic code (NCBI 3)
+	GENETIC_CODE_MYCOPLASMA       = 4  // Mold, protozoan, coelenterate mitochondrial and mycoplasma/spiroplasma genetic code (NCBI 4)
+	GENETIC_CODE_CILIATE          = 6  // Ciliate, dasycladacean and hexamita nuclear genetic code (NCBI 6)
+	GENETIC_CODE_BACTERIAL        = 11 // Bacterial, archaeal and plant plastid genetic code (NCBI 11)
 
 	IGNORE_NONE     = 0
 	IGNORE_NAME     = 1
@@ -65,95 +71,6 @@ const (
 var stdaminoacid = []uint8{'A', 'R', 'N', 'D', 'C', 'Q', 'E', 'G', 'H', 'I', 'L', 'K', 'M', 'F', 'P', 'S', 'T', 'W', 'Y', 'V'}
 var stdnucleotides = []uint8{'A', 'C', 'G', 'T'}
 
-var standardcode = map[string]uint8{
-	"---": '-',
-	"GCT": 'A', "GCC": 'A', "GCA": 'A', "GCG": 'A',
-	"TTA": 'L', "TTG": 'L', "CTT": 'L', "CTC": 'L', "CTA": 'L', "CTG": 'L',
-	"CGT": 'R', "CGC": 'R', "CGA": 'R', "CGG": 'R', "AGA": 'R', "AGG": 'R',
-	"AAA": 'K', "AAG": 'K',
-	"AAT": 'N', "AAC": 'N',
-	"ATG": 'M',
-	"GAT": 'D', "GAC": 'D',
-	"TTT": 'F', "TTC": 'F',
-	"TGT": 'C', "TGC": 'C',
-	"CCT": 'P', "CCC": 'P', "CCA": 'P', "CCG": 'P',
-	"CAA": 'Q', "CAG": 'Q',
-	"TCT": 'S', "TCC": 'S', "TCA": 'S', "TCG": 'S', "AGT": 'S', "AGC": 'S',
-	"GAA": 'E', "GAG": 'E',
-	"ACT": 'T', "ACC": 'T', "ACA": 'T', "ACG": 'T',
-	"GGT": 'G', "GGC": 'G', "GGA": 'G', "GGG": 'G',
-	"TGG": 'W',
-	"CAT": 'H', "CAC": 'H',
-	"TAT": 'Y', "TAC": 'Y',
-	"ATT": 'I', "ATC": 'I', "ATA": 'I',
-	"GTT": 'V', "GTC": 'V', "GTA": 'V', "GTG": 'V',
-	"TAA": '*', "TGA": '*', "TAG": '*',
-}
-
-var vertebratemitocode = map[string]uint8{
-	"---": '-',
-	"GCT": 'A', "GCC": 'A', "GCA": 'A', "GCG": 'A',
-	"TTA": 'L', "TTG": 'L', "CTT": 'L', "CTC": 'L', "CTA": 'L', "CTG": 'L',
-	"CGT": 'R', "CGC": 'R', "CGA": 'R', "CGG": 'R',
-	"AAA": 'K', "AAG": 'K',
-	"AAT": 'N', "AAC": 'N',
-	"ATG": 'M', "ATA": 'M',
-	"GAT": 'D', "GAC": 'D',
-	"TTT": 'F', "TTC": 'F',
-	"TGT": 'C', "TGC": 'C',
-	"CCT": 'P', "CCC": 'P', "CCA": 'P', "CCG": 'P',
-	"CAA": 'Q', "CAG": 'Q',
-	"TCT": 'S', "TCC": 'S', "TCA": 'S', "TCG": 'S', "AGT": 'S', "AGC": 'S',
-	"GAA": 'E', "GAG": 'E',
-	"ACT": 'T', "ACC": 'T', "ACA": 'T', "ACG": 'T',
-	"GGT": 'G', "GGC": 'G', "GGA": 'G', "GGG": 'G',
-	"TGG": 'W', "TGA": 'W',
-	"CAT": 'H', "CAC": 'H',
-	"TAT": 'Y', "TAC": 'Y',
-	"ATT": 'I', "ATC": 'I',
-	"GTT": 'V', "GTC": 'V', "GTA": 'V', "GTG": 'V',
-	"AGA": '*', "AGG": '*', "TAA": '*', "TAG": '*',
-}
-
-var invertebratemitocode = map[string]uint8{
-	"---": '-',
-	"GCT": 'A', "GCC": 'A', "GCA": 'A', "GCG": 'A',
-	"TTA": 'L', "TTG": 'L', "CTT": 'L', "CTC": 'L', "CTA": 'L', "CTG": 'L',
-	"CGT": 'R', "CGC": 'R', "CGA": 'R', "CGG": 'R',
-	"AAA": 'K', "AAG": 'K',
-	"AAT": 'N', "AAC": 'N',
-	"ATG": 'M', "ATA": 'M',
-	"GAT": 'D', "GAC": 'D',
-	"TTT": 'F', "TTC": 'F',
-	"TGT": 'C', "TGC": 'C',
-	"CCT": 'P', "CCC": 'P', "CCA": 'P', "CCG": 'P',
-	"CAA": 'Q', "CAG": 'Q',
-	"AGA": 'S', "AGG": 'S', "TCT": 'S', "TCC": 'S', "TCA": 'S', "TCG": 'S', "AGT": 'S', "AGC": 'S',
-	"GAA": 'E', "GAG": 'E',
-	"ACT": 'T', "ACC": 'T', "ACA": 'T', "ACG": 'T',
-	"GGT": 'G', "GGC": 'G', "GGA": 'G', "GGG": 'G',
-	"TGG": 'W', "TGA": 'W',
-	"CAT": 'H', "CAC": 'H',
-	"TAT": 'Y', "TAC": 'Y',
-	"ATT": 'I', "ATC": 'I',
-	"GTT": 'V', "GTC": 'V', "GTA": 'V', "GTG": 'V',
-	"TAA": '*', "TAG": '*',
-}
-
-func geneticCode(code int) (gencode map[string]uint8, err error) {
-	switch code {
-	case GENETIC_CODE_STANDARD:
-		gencode = standardcode
-	case GENETIC_CODE_VETEBRATE_MITO:
-		gencode = vertebratemitocode
-	case GENETIC_CODE_INVETEBRATE_MITO:
-		gencode = invertebratemitocode
-	default:
-		err = fmt.Errorf("this genetic code does not exis")
-	}
-	return
-}
-
 // Amino Acid strong groups for clustal format
 // conservation line
 //
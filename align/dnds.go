@@ -0,0 +1,447 @@
+package align
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	DNDS_NG86  = 0 // Nei & Gojobori (1986) method
+	DNDS_LWL85 = 1 // Li, Wu & Luo (1985) method
+)
+
+// DnDs gathers synonymous and non-synonymous statistics computed
+// between two coding sequences.
+type DnDs struct {
+	Codons int     // Number of compared codons (without gaps, ambiguities or stops)
+	S, N   float64 // Number of synonymous and non-synonymous sites
+	Sd, Nd float64 // Number of synonymous and non-synonymous differences
+	PS, PN float64 // Proportions of synonymous and non-synonymous differences: Sd/S and Nd/N
+	DS, DN float64 // Synonymous and non-synonymous substitution rates (corrected, if asked)
+}
+
+// DnDsRatio returns DN/DS
+func (d DnDs) DnDsRatio() float64 {
+	return d.DN / d.DS
+}
+
+// DnDsSite gathers synonymous and non-synonymous statistics computed at a
+// given codon site of an alignment.
+type DnDsSite struct {
+	Codons int     // Number of sequences having a valid codon (without gaps, ambiguities or stops)
+	S, N   float64 // Average number of synonymous and non-synonymous sites of the valid codons
+	Sd, Nd float64 // Average number of synonymous and non-synonymous differences between pairs of valid codons
+}
+
+// codonTable precomputes, for a given genetic code, the information needed by
+// the dN/dS computations for the 64 codons, indexed by 16*n1+4*n2+n3 (ACGT order).
+type codonTable struct {
+	aa   [64]uint8      // Amino acid of each codon ('*' for stops)
+	s, n [64]float64    // Nei-Gojobori synonymous and non-synonymous sites
+	fold [64][3]float64 // Li-Wu-Luo degeneracy class of each position (0, 2 or 4)
+}
+
+var ntOrder = []uint8{'A', 'C', 'G', 'T'}
+
+func newCodonTable(geneticcode int) (t *codonTable, err error) {
+	var code map[string]uint8
+	var ok bool
+
+	if code, err = geneticCode(geneticcode); err != nil {
+		return
+	}
+	t = &codonTable{}
+	for c := 0; c < 64; c++ {
+		codon := codonString(c)
+		if t.aa[c], ok = code[codon]; !ok {
+			err = fmt.Errorf("codon %s does not exist in the genetic code", codon)
+			return
+		}
+	}
+	for c := 0; c < 64; c++ {
+		if t.aa[c] == '*' {
+			continue
+		}
+		nsyn, nnonsyn := 0, 0
+		for pos := 0; pos < 3; pos++ {
+			syn := 0
+			for _, m := range codonMutants(c, pos) {
+				if t.aa[m] == '*' {
+					// Changes to stop codons are not counted
+					continue
+				}
+				if t.aa[m] == t.aa[c] {
+					syn++
+				} else {
+					nnonsyn++
+				}
+			}
+			switch syn {
+			case 0:
+				t.fold[c][pos] = 0
+			case 3:
+				t.fold[c][pos] = 4
+			default:
+				t.fold[c][pos] = 2
+			}
+			nsyn += syn
+		}
+		t.s[c] = float64(nsyn) / 3.0
+		t.n[c] = float64(nnonsyn) / 3.0
+	}
+	return
+}
+
+// codonString returns the codon corresponding to the given index
+func codonString(c int) string {
+	return string([]uint8{ntOrder[c/16], ntOrder[(c/4)%4], ntOrder[c%4]})
+}
+
+// codonNt returns the index (ACGT order) of the nucleotide at position pos of the codon
+func codonNt(c, pos int) int {
+	switch pos {
+	case 0:
+		return c / 16
+	case 1:
+		return (c / 4) % 4
+	default:
+		return c % 4
+	}
+}
+
+// codonMutate returns the codon c whose nucleotide at position pos is replaced by nt
+func codonMutate(c, pos, nt int) int {
+	mult := []int{16, 4, 1}[pos]
+	return c - codonNt(c, pos)*mult + nt*mult
+}
+
+// codonMutants returns the 3 codons differing from c at position pos
+func codonMutants(c, pos int) (mutants []int) {
+	mutants = make([]int, 0, 3)
+	for nt := 0; nt < 4; nt++ {
+		if nt != codonNt(c, pos) {
+			mutants = append(mutants, codonMutate(c, pos, nt))
+		}
+	}
+	return
+}
+
+// isTransition returns true if the change between the two nucleotide
+// indices (ACGT order) is a transition
+func isTransition(nt1, nt2 int) bool {
+	return (nt1 == 0 && nt2 == 2) || (nt1 == 2 && nt2 == 0) || (nt1 == 1 && nt2 == 3) || (nt1 == 3 && nt2 == 1)
+}
+
+// codonIndex returns the index of the codon, or -1 if it contains
+// a gap or an ambiguous nucleotide.
+func codonIndex(n1, n2, n3 uint8) int {
+	var i1, i2, i3 int
+	var err error
+	if i1, err = Nt2Index(n1); err != nil {
+		return -1
+	}
+	if i2, err = Nt2Index(n2); err != nil {
+		return -1
+	}
+	if i3, err = Nt2Index(n3); err != nil {
+		return -1
+	}
+	return 16*i1 + 4*i2 + i3
+}
+
+// codonPathways returns the evolutionary pathways between codons c1 and c2:
+// all orders of the differing positions, excluding pathways going through
+// stop codons (unless all pathways do). Each pathway is the list of successive
+// codons from c1 to c2.
+func (t *codonTable) codonPathways(c1, c2 int) (paths [][]int) {
+	var diffs []int
+	var all [][]int
+
+	for pos := 0; pos < 3; pos++ {
+		if codonNt(c1, pos) != codonNt(c2, pos) {
+			diffs = append(diffs, pos)
+		}
+	}
+	for _, order := range permutations(diffs) {
+		path := []int{c1}
+		cur := c1
+		stop := false
+		for i, pos := range order {
+			cur = codonMutate(cur, pos, codonNt(c2, pos))
+			path = append(path, cur)
+			if i < len(order)-1 && t.aa[cur] == '*' {
+				stop = true
+			}
+		}
+		all = append(all, path)
+		if !stop {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		paths = all
+	}
+	return
+}
+
+// permutations returns all the permutations of the given positions
+func permutations(positions []int) (perms [][]int) {
+	if len(positions) <= 1 {
+		return [][]int{append([]int{}, positions...)}
+	}
+	for i, p := range positions {
+		rest := make([]int, 0, len(positions)-1)
+		rest = append(rest, positions[:i]...)
+		rest = append(rest, positions[i+1:]...)
+		for _, perm := range permutations(rest) {
+			perms = append(perms, append([]int{p}, perm...))
+		}
+	}
+	return
+}
+
+// ngDifferences returns the numbers of synonymous and non-synonymous
+// differences between two codons, averaged over pathways (Nei & Gojobori 1986)
+func (t *codonTable) ngDifferences(c1, c2 int) (sd, nd float64) {
+	if c1 == c2 {
+		return
+	}
+	paths := t.codonPathways(c1, c2)
+	for _, path := range paths {
+		for i := 1; i < len(path); i++ {
+			if t.aa[path[i-1]] == t.aa[path[i]] {
+				sd++
+			} else {
+				nd++
+			}
+		}
+	}
+	sd /= float64(len(paths))
+	nd /= float64(len(paths))
+	return
+}
+
+// lwlCounts adds to l the number of 0-, 2- and 4-fold degenerate sites of the
+// codon, and to p and q, the number of transitions and transversions at 0-,
+// 2- and 4-fold degenerate sites between codons c1 and c2, averaged over pathways
+// (Li, Wu & Luo 1985). Degeneracy classes of the differing sites are averaged
+// between the codons before and after each change.
+func (t *codonTable) lwlCounts(c1, c2 int, l, p, q *[5]float64) {
+	for pos := 0; pos < 3; pos++ {
+		l[int(t.fold[c1][pos])] += 0.5
+		l[int(t.fold[c2][pos])] += 0.5
+	}
+	if c1 == c2 {
+		return
+	}
+	paths := t.codonPathways(c1, c2)
+	w := 1.0 / float64(len(paths))
+	for _, path := range paths {
+		for i := 1; i < len(path); i++ {
+			for pos := 0; pos < 3; pos++ {
+				nt1, nt2 := codonNt(path[i-1], pos), codonNt(path[i], pos)
+				if nt1 == nt2 {
+					continue
+				}
+				for _, fold := range []float64{t.fold[path[i-1]][pos], t.fold[path[i]][pos]} {
+					if isTransition(nt1, nt2) {
+						p[int(fold)] += 0.5 * w
+					} else {
+						q[int(fold)] += 0.5 * w
+					}
+				}
+			}
+		}
+	}
+}
+
+// codonSequences returns, for each sequence of the alignment, the index of
+// each of its codons (-1 if the codon contains gaps/ambiguities or is a stop)
+func codonSequences(al Alignment, t *codonTable) (codons [][]int, err error) {
+	if al.Alphabet() != NUCLEOTIDS {
+		err = fmt.Errorf("alignment must be nucleotidic to compute dN/dS")
+		return
+	}
+	if al.Length()%3 != 0 {
+		err = fmt.Errorf("alignment length (%d) is not a multiple of 3", al.Length())
+		return
+	}
+	codons = make([][]int, al.NbSequences())
+	for i := 0; i < al.NbSequences(); i++ {
+		seq, _ := al.GetSequenceCharById(i)
+		codons[i] = make([]int, al.Length()/3)
+		for c := range codons[i] {
+			codons[i][c] = codonIndex(seq[3*c], seq[3*c+1], seq[3*c+2])
+			if codons[i][c] >= 0 && t.aa[codons[i][c]] == '*' {
+				codons[i][c] = -1
+			}
+		}
+	}
+	return
+}
+
+// PairwiseDnDs computes synonymous and non-synonymous statistics between all
+// pairs of sequences of the given codon alignment (nucleotides, in frame), using
+// the given method (DNDS_NG86 or DNDS_LWL85) and genetic code.
+//
+// Codons containing gaps or ambiguous nucleotides, and stop codons, are ignored
+// (pairwise deletion).
+//
+// With DNDS_NG86 (Nei & Gojobori 1986), synonymous and non-synonymous sites are
+// counted for each codon (changes to stop codons are not counted), and differences
+// are averaged over evolutionary pathways (excluding pathways through stop codons).
+// If correction is true, DS and DN are corrected with the Jukes-Cantor formula,
+// otherwise DS=PS and DN=PN.
+//
+// With DNDS_LWL85 (Li, Wu & Luo 1985), sites are classified as 0-fold, 2-fold and
+// 4-fold degenerate, and differences as transitions or transversions. S and N are
+// L2/3+L4 and 2*L2/3+L0. If correction is true, DS and DN are computed with the
+// Kimura two-parameter formulas of LWL85, otherwise DS=PS and DN=PN.
+//
+// The result is a symmetric matrix (diagonal elements are zero-valued).
+func PairwiseDnDs(al Alignment, method int, geneticcode int, correction bool) (dnds [][]DnDs, err error) {
+	var t *codonTable
+	var codons [][]int
+
+	if method != DNDS_NG86 && method != DNDS_LWL85 {
+		err = fmt.Errorf("unknown dN/dS method: %d", method)
+		return
+	}
+	if t, err = newCodonTable(geneticcode); err != nil {
+		return
+	}
+	if codons, err = codonSequences(al, t); err != nil {
+		return
+	}
+
+	dnds = make([][]DnDs, al.NbSequences())
+	for i := range dnds {
+		dnds[i] = make([]DnDs, al.NbSequences())
+	}
+	for i := 0; i < al.NbSequences(); i++ {
+		for j := i + 1; j < al.NbSequences(); j++ {
+			if method == DNDS_NG86 {
+				dnds[i][j] = t.ng86(codons[i], codons[j], correction)
+			} else {
+				dnds[i][j] = t.lwl85(codons[i], codons[j], correction)
+			}
+			dnds[j][i] = dnds[i][j]
+		}
+	}
+	return
+}
+
+func (t *codonTable) ng86(codons1, codons2 []int, correction bool) (d DnDs) {
+	for c := range codons1 {
+		c1, c2 := codons1[c], codons2[c]
+		if c1 < 0 || c2 < 0 {
+			continue
+		}
+		d.Codons++
+		d.S += (t.s[c1] + t.s[c2]) / 2.0
+		d.N += (t.n[c1] + t.n[c2]) / 2.0
+		sd, nd := t.ngDifferences(c1, c2)
+		d.Sd += sd
+		d.Nd += nd
+	}
+	d.PS = d.Sd / d.S
+	d.PN = d.Nd / d.N
+	d.DS, d.DN = d.PS, d.PN
+	if correction {
+		d.DS = jukesCantor(d.PS)
+		d.DN = jukesCantor(d.PN)
+	}
+	return
+}
+
+func (t *codonTable) lwl85(codons1, codons2 []int, correction bool) (d DnDs) {
+	var l, p, q [5]float64 // Indexed by degeneracy class: 0, 2 and 4
+
+	for c := range codons1 {
+		c1, c2 := codons1[c], codons2[c]
+		if c1 < 0 || c2 < 0 {
+			continue
+		}
+		d.Codons++
+		t.lwlCounts(c1, c2, &l, &p, &q)
+	}
+	d.S = l[2]/3.0 + l[4]
+	d.N = 2.0*l[2]/3.0 + l[0]
+	d.Sd = p[2] + p[4] + q[4]
+	d.Nd = q[2] + p[0] + q[0]
+	d.PS = d.Sd / d.S
+	d.PN = d.Nd / d.N
+	d.DS, d.DN = d.PS, d.PN
+	if correction {
+		var a, b, k [5]float64
+		for _, i := range []int{0, 2, 4} {
+			pi, qi := p[i]/l[i], q[i]/l[i]
+			a[i] = 0.5*math.Log(1.0/(1.0-2.0*pi-qi)) - 0.25*math.Log(1.0/(1.0-2.0*qi))
+			b[i] = 0.5 * math.Log(1.0/(1.0-2.0*qi))
+			k[i] = a[i] + b[i]
+		}
+		d.DS = (l[2]*a[2] + l[4]*k[4]) / (l[2]/3.0 + l[4])
+		d.DN = (l[2]*b[2] + l[0]*k[0]) / (2.0*l[2]/3.0 + l[0])
+	}
+	return
+}
+
+// jukesCantor returns the Jukes-Cantor corrected distance
+// given the proportion of differences p (NaN if p >= 3/4)
+func jukesCantor(p float64) float64 {
+	if p >= 0.75 {
+		return math.NaN()
+	}
+	if p == 0 {
+		return 0
+	}
+	return -0.75 * math.Log(1.0-4.0/3.0*p)
+}
+
+// SiteDnDs computes, for each codon site of the given codon alignment, the average
+// numbers of synonymous and non-synonymous sites (Nei & Gojobori 1986) of the valid
+// codons, and the average numbers of synonymous and non-synonymous differences
+// between all pairs of valid codons (see PairwiseDnDs).
+func SiteDnDs(al Alignment, geneticcode int) (sites []DnDsSite, err error) {
+	var t *codonTable
+	var codons [][]int
+
+	if t, err = newCodonTable(geneticcode); err != nil {
+		return
+	}
+	if codons, err = codonSequences(al, t); err != nil {
+		return
+	}
+	sites = make([]DnDsSite, al.Length()/3)
+	for c := range sites {
+		pairs := 0
+		for i := 0; i < al.NbSequences(); i++ {
+			c1 := codons[i][c]
+			if c1 < 0 {
+				continue
+			}
+			sites[c].Codons++
+			sites[c].S += t.s[c1]
+			sites[c].N += t.n[c1]
+			for j := i + 1; j < al.NbSequences(); j++ {
+				c2 := codons[j][c]
+				if c2 < 0 {
+					continue
+				}
+				sd, nd := t.ngDifferences(c1, c2)
+				sites[c].Sd += sd
+				sites[c].Nd += nd
+				pairs++
+			}
+		}
+		if sites[c].Codons > 0 {
+			sites[c].S /= float64(sites[c].Codons)
+			sites[c].N /= float64(sites[c].Codons)
+		}
+		if pairs > 0 {
+			sites[c].Sd /= float64(pairs)
+			sites[c].Nd /= float64(pairs)
+		}
+	}
+	return
+}
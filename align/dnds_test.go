@@ -0,0 +1,110 @@
+package align
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_newCodonTable(t *testing.T) {
+	var ct *codonTable
+	var err error
+
+	if ct, err = newCodonTable(GENETIC_CODE_STANDARD); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		codon string
+		s, n  float64
+		fold  [3]float64
+	}{
+		{"ATG", 0, 3, [3]float64{0, 0, 0}},
+		{"TTT", 1.0 / 3.0, 8.0 / 3.0, [3]float64{0, 0, 2}},
+		{"CTA", 4.0 / 3.0, 5.0 / 3.0, [3]float64{2, 0, 4}},
+		{"TGG", 0, 7.0 / 3.0, [3]float64{0, 0, 0}},
+	}
+	for _, tt := range tests {
+		c := codonIndex(tt.codon[0], tt.codon[1], tt.codon[2])
+		if math.Abs(ct.s[c]-tt.s) > 1e-9 || math.Abs(ct.n[c]-tt.n) > 1e-9 {
+			t.Errorf("Codon %s: wrong sites s=%f n=%f, want s=%f n=%f", tt.codon, ct.s[c], ct.n[c], tt.s, tt.n)
+		}
+		if ct.fold[c] != tt.fold {
+			t.Errorf("Codon %s: wrong degeneracy %v, want %v", tt.codon, ct.fold[c], tt.fold)
+		}
+	}
+}
+
+func Test_PairwiseDnDs(t *testing.T) {
+	var dnds [][]DnDs
+	var err error
+
+	a := NewAlign(NUCLEOTIDS)
+	a.AddSequence("s1", "ATGTTTCTAGCTGCTGCTGCT", "")
+	a.AddSequence("s2", "ATGCTCCTGGCTGCTGCTGCC", "")
+	a.AddSequence("s3", "ATGCTCCTGGCTGCTGCT---", "")
+
+	closeTo := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+	if dnds, err = PairwiseDnDs(a, DNDS_NG86, GENETIC_CODE_STANDARD, true); err != nil {
+		t.Fatal(err)
+	}
+	d := dnds[0][1]
+	if d.Codons != 7 || !closeTo(d.S, 6) || !closeTo(d.N, 15) || !closeTo(d.Sd, 3) || !closeTo(d.Nd, 1) {
+		t.Errorf("Wrong NG86 counts: %+v", d)
+	}
+	if !closeTo(d.DS, 0.75*math.Log(3)) || !closeTo(d.DN, -0.75*math.Log(1-4.0/45.0)) {
+		t.Errorf("Wrong NG86 rates: %+v", d)
+	}
+	if dnds[1][0] != d || dnds[0][0].Codons != 0 {
+		t.Errorf("dN/dS matrix should be symmetric with a zero diagonal")
+	}
+	if dnds[0][2].Codons != 6 {
+		t.Errorf("Codons with gaps should be ignored: %d", dnds[0][2].Codons)
+	}
+
+	if dnds, err = PairwiseDnDs(a, DNDS_LWL85, GENETIC_CODE_STANDARD, false); err != nil {
+		t.Fatal(err)
+	}
+	d = dnds[0][1]
+	if !closeTo(d.S, 6) || !closeTo(d.N, 15) || !closeTo(d.Sd, 3) || !closeTo(d.Nd, 1) || !closeTo(d.DS, 0.5) {
+		t.Errorf("Wrong LWL85 counts: %+v", d)
+	}
+	if dnds, err = PairwiseDnDs(a, DNDS_LWL85, GENETIC_CODE_STANDARD, true); err != nil {
+		t.Fatal(err)
+	}
+	d = dnds[0][1]
+	a2, a4, k0 := 0.5*math.Log(3), 0.5*math.Log(11), 0.5*math.Log(14.0/12.0)
+	if !closeTo(d.DS, (1.5*a2+5.5*a4)/6.0) || !closeTo(d.DN, 14.0*k0/15.0) {
+		t.Errorf("Wrong LWL85 rates: %+v", d)
+	}
+
+	b := NewAlign(NUCLEOTIDS)
+	b.AddSequence("s1", "ATGCTCCTGGCTGCTGCTGC", "")
+	if _, err = PairwiseDnDs(b, DNDS_NG86, GENETIC_CODE_STANDARD, true); err == nil {
+		t.Errorf("An error should be returned if sequences are not codon aligned")
+	}
+}
+
+func Test_SiteDnDs(t *testing.T) {
+	var sites []DnDsSite
+	var err error
+
+	a := NewAlign(NUCLEOTIDS)
+	a.AddSequence("s1", "TTTCTA", "")
+	a.AddSequence("s2", "CTCCTG", "")
+	a.AddSequence("s3", "TTTN--", "")
+
+	if sites, err = SiteDnDs(a, GENETIC_CODE_STANDARD); err != nil {
+		t.Fatal(err)
+	}
+	if len(sites) != 2 {
+		t.Fatalf("There should be 2 codon sites, there are %d", len(sites))
+	}
+	// Codon 0: TTT, CTC, TTT: pairs (TTT,CTC): 1 syn 1 nonsyn, twice, (TTT,TTT): 0
+	if sites[0].Codons != 3 || math.Abs(sites[0].Sd-2.0/3.0) > 1e-9 || math.Abs(sites[0].Nd-2.0/3.0) > 1e-9 ||
+		math.Abs(sites[0].S-(2.0/3.0+1.0)/3.0) > 1e-9 {
+		t.Errorf("Wrong statistics for codon site 0: %+v", sites[0])
+	}
+	if sites[1].Codons != 2 || sites[1].Sd != 1 || sites[1].Nd != 0 {
+		t.Errorf("Wrong statistics for codon site 1: %+v", sites[1])
+	}
+}
@@ -0,0 +1,238 @@
+package align
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// geneticCodeTable describes an NCBI genetic code (translation table, see
+// https://www.ncbi.nlm.nih.gov/Taxonomy/Utils/wprintgc.cgi).
+//
+// aas and starts give the amino acid encoded by each of the 64 codons, and
+// the initiation codons ('M'), in the NCBI order: TTT, TTC, TTA, TTG, TCT, ...
+type geneticCodeTable struct {
+	id     int
+	name   string
+	aas    string
+	starts string
+	code   map[string]uint8
+	start  map[string]bool
+}
+
+var ncbiGeneticCodes = []*geneticCodeTable{
+	{id: 1, name: "Standard",
+		aas:    "FFLLSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		starts: "---M------**--*----M---------------M----------------------------"},
+	{id: 2, name: "Vertebrate Mitochondrial",
+		aas:    "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNKKSS**VVVVAAAADDEEGGGG",
+		starts: "----------**--------------------MMMM----------**---M------------"},
+	{id: 3, name: "Yeast Mitochondrial",
+		aas:    "FFLLSSSSYY**CCWWTTTTPPPPHHQQRRRRIIMMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		starts: "----------**----------------------MM---------------M------------"},
+	{id: 4, name: "Mold, Protozoan, and Coelenterate Mitochondrial and Mycoplasma/Spiroplasma",
+		aas:    "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		starts: "--MM------**-------M------------MMMM---------------M------------"},
+	{id: 5, name: "Invertebrate Mitochondrial",
+		aas:    "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNKKSSSSVVVVAAAADDEEGGGG",
+		starts: "---M------**--------------------MMMM---------------M------------"},
+	{id: 6, name: "Ciliate, Dasycladacean and Hexamita Nuclear",
+		aas:    "FFLLSSSSYYQQCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		starts: "--------------*--------------------M----------------------------"},
+	{id: 9, name: "Echinoderm and Flatworm Mitochondrial",
+		aas:    "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNNKSSSSVVVVAAAADDEEGGGG",
+		starts: "-----------------------------------M---------------M------------"},
+	{id: 10, name: "Euplotid Nuclear",
+		aas:    "FFLLSSSSYY**CCCWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		starts: "-----------------------------------M----------------------------"},
+	{id: 11, name: "Bacterial, Archaeal and Plant Plastid",
+		aas:    "FFLLSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		starts: "---M------**--*----M------------MMMM---------------M------------"},
+	{id: 12, name: "Alternative Yeast Nuclear",
+		aas:    "FFLLSSSSYY**CC*WLLLSPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		starts: "-------------------M---------------M----------------------------"},
+	{id: 13, name: "Ascidian Mitochondrial",
+		aas:    "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNKKSSGGVVVVAAAADDEEGGGG",
+		starts: "---M------------------------------MM---------------M------------"},
+	{id: 14, name: "Alternative Flatworm Mitochondrial",
+		aas:    "FFLLSSSSYYY*CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNNKSSSSVVVVAAAADDEEGGGG",
+		starts: "-----------------------------------M----------------------------"},
+	{id: 15, name: "Blepharisma Macronuclear",
+		aas:    "FFLLSSSSYY*QCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		starts: "-----------------------------------M----------------------------"},
+	{id: 16, name: "Chlorophycean Mitochondrial",
+		aas:    "FFLLSSSSYY*LCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		starts: "-----------------------------------M----------------------------"},
+	{id: 21, name: "Trematode Mitochondrial",
+		aas:    "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNNKSSSSVVVVAAAADDEEGGGG",
+		starts: "-----------------------------------M---------------M------------"},
+	{id: 22, name: "Scenedesmus obliquus Mitochondrial",
+		aas:    "FFLLSS*SYY*LCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		starts: "-----------------------------------M----------------------------"},
+	{id: 23, name: "Thraustochytrium Mitochondrial",
+		aas:    "FF*LSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		starts: "--------------------------------M--M---------------M------------"},
+	{id: 24, name: "Rhabdopleuridae Mitochondrial",
+		aas:    "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSSKVVVVAAAADDEEGGGG",
+		starts: "---M---------------M---------------M---------------M------------"},
+	{id: 25, name: "Candidate Division SR1 and Gracilibacteria",
+		aas:    "FFLLSSSSYY**CCGWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		starts: "---M-------------------------------M---------------M------------"},
+	{id: 26, name: "Pachysolen tannophilus Nuclear",
+		aas:    "FFLLSSSSYY**CC*WLLLAPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		starts: "-------------------M---------------M----------------------------"},
+	{id: 27, name: "Karyorelict Nuclear",
+		aas:    "FFLLSSSSYYQQCCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		starts: "--------------*--------------------M----------------------------"},
+	{id: 28, name: "Condylostoma Nuclear",
+		aas:    "FFLLSSSSYYQQCCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		starts: "----------**--*--------------------M----------------------------"},
+	{id: 29, name: "Mesodinium Nuclear",
+		aas:    "FFLLSSSSYYYYCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		starts: "--------------*--------------------M----------------------------"},
+	{id: 30, name: "Peritrich Nuclear",
+		aas:    "FFLLSSSSYYEECC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		starts: "--------------*--------------------M----------------------------"},
+	{id: 31, name: "Blastocrithidia Nuclear",
+		aas:    "FFLLSSSSYYEECCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		starts: "----------**-----------------------M----------------------------"},
+	{id: 32, name: "Balanophoraceae Plastid",
+		aas:    "FFLLSSSSYY*WCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		starts: "---M------*---*----M------------MMMM---------------M------------"},
+	{id: 33, name: "Cephalodiscidae Mitochondrial",
+		aas:    "FFLLSSSSYYY*CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSSKVVVVAAAADDEEGGGG",
+		starts: "---M-------*-------M---------------M---------------M------------"},
+}
+
+// NCBI genetic codes, indexed by their NCBI identifier
+var ncbiGeneticCodeIndex map[int]*geneticCodeTable
+
+func init() {
+	var ncbiorder = "TCAG"
+	ncbiGeneticCodeIndex = make(map[int]*geneticCodeTable)
+	for _, t := range ncbiGeneticCodes {
+		// Gap codons are translated as gaps
+		t.code = map[string]uint8{"---": '-'}
+		t.start = make(map[string]bool)
+		for i := 0; i < 64; i++ {
+			codon := string([]uint8{ncbiorder[i/16], ncbiorder[(i/4)%4], ncbiorder[i%4]})
+			t.code[codon] = t.aas[i]
+			if t.starts[i] == 'M' {
+				t.start[codon] = true
+			}
+		}
+		ncbiGeneticCodeIndex[t.id] = t
+	}
+}
+
+// geneticCodeNCBI returns the NCBI genetic code corresponding to the given
+// goalign genetic code identifier
+func geneticCodeNCBI(code int) (t *geneticCodeTable, err error) {
+	var ok bool
+	var id int = code
+	switch code {
+	case GENETIC_CODE_STANDARD:
+		id = 1
+	case GENETIC_CODE_VETEBRATE_MITO:
+		id = 2
+	case GENETIC_CODE_INVETEBRATE_MITO:
+		id = 5
+	}
+	if t, ok = ncbiGeneticCodeIndex[id]; !ok {
+		err = fmt.Errorf("genetic code %d does not exist", code)
+	}
+	return
+}
+
+func geneticCode(code int) (gencode map[string]uint8, err error) {
+	var t *geneticCodeTable
+	if t, err = geneticCodeNCBI(code); err != nil {
+		return
+	}
+	gencode = t.code
+	return
+}
+
+// startCodons returns the initiation codons of the given genetic code
+func startCodons(code int) (starts map[string]bool, err error) {
+	var t *geneticCodeTable
+	if t, err = geneticCodeNCBI(code); err != nil {
+		return
+	}
+	starts = t.start
+	return
+}
+
+// GeneticCodeFromNCBI returns the goalign identifier of the genetic code
+// having the given NCBI translation table number (ex: 11 for bacterial code).
+//
+// goalign identifiers are the NCBI numbers, except for the historical
+// GENETIC_CODE_STANDARD (NCBI 1) and GENETIC_CODE_VETEBRATE_MITO (NCBI 2).
+func GeneticCodeFromNCBI(id int) (code int, err error) {
+	switch id {
+	case 1:
+		code = GENETIC_CODE_STANDARD
+	case 2:
+		code = GENETIC_CODE_VETEBRATE_MITO
+	default:
+		if _, ok := ncbiGeneticCodeIndex[id]; !ok {
+			err = fmt.Errorf("NCBI genetic code %d does not exist", id)
+			return
+		}
+		code = id
+	}
+	return
+}
+
+// GeneticCodeFromString returns the goalign identifier of the given genetic code,
+// which may be "standard", "mitov" (vertebrate mitochondrial), "mitoi" (invertebrate
+// mitochondrial), or an NCBI translation table number (ex: "4", "11", "25").
+func GeneticCodeFromString(name string) (code int, err error) {
+	var id int
+	switch strings.ToLower(name) {
+	case "standard":
+		code = GENETIC_CODE_STANDARD
+	case "mitov":
+		code = GENETIC_CODE_VETEBRATE_MITO
+	case "mitoi":
+		code = GENETIC_CODE_INVETEBRATE_MITO
+	default:
+		if id, err = strconv.Atoi(name); err != nil {
+			err = fmt.Errorf("unknown genetic code : %s", name)
+			return
+		}
+		code, err = GeneticCodeFromNCBI(id)
+	}
+	return
+}
+
+// GeneticCodeName returns the NCBI number and name of the given genetic code
+func GeneticCodeName(code int) (id int, name string, err error) {
+	var t *geneticCodeTable
+	if t, err = geneticCodeNCBI(code); err != nil {
+		return
+	}
+	return t.id, t.name, nil
+}
+
+// NCBIGeneticCodes returns the NCBI numbers of all available genetic codes
+func NCBIGeneticCodes() (ids []int) {
+	for _, t := range ncbiGeneticCodes {
+		ids = append(ids, t.id)
+	}
+	return
+}
+
+// StartCodons returns the sorted list of initiation codons of the given genetic code
+func StartCodons(code int) (codons []string, err error) {
+	var starts map[string]bool
+	if starts, err = startCodons(code); err != nil {
+		return
+	}
+	for c := range starts {
+		codons = append(codons, c)
+	}
+	sort.Strings(codons)
+	return
+}
@@ -0,0 +1,101 @@
+package align
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGeneticCodeFromString(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    int
+		wantErr bool
+	}{
+		{"standard", GENETIC_CODE_STANDARD, false},
+		{"mitov", GENETIC_CODE_VETEBRATE_MITO, false},
+		{"mitoi", GENETIC_CODE_INVETEBRATE_MITO, false},
+		{"1", GENETIC_CODE_STANDARD, false},
+		{"2", GENETIC_CODE_VETEBRATE_MITO, false},
+		{"4", GENETIC_CODE_MYCOPLASMA, false},
+		{"5", 5, false},
+		{"11", GENETIC_CODE_BACTERIAL, false},
+		{"25", 25, false},
+		{"7", 0, true},
+		{"34", 0, true},
+		{"mito", 0, true},
+	}
+	for _, tt := range tests {
+		code, err := GeneticCodeFromString(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("GeneticCodeFromString(%s) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && code != tt.code {
+			t.Errorf("GeneticCodeFromString(%s) = %d, want %d", tt.name, code, tt.code)
+		}
+	}
+
+	if id, name, _ := GeneticCodeName(GENETIC_CODE_INVETEBRATE_MITO); id != 5 || name != "Invertebrate Mitochondrial" {
+		t.Errorf("Wrong genetic code name: %d %s", id, name)
+	}
+	if len(NCBIGeneticCodes()) != 27 {
+		t.Errorf("There should be 27 NCBI genetic codes, there are %d", len(NCBIGeneticCodes()))
+	}
+}
+
+func TestGeneticCodeTables(t *testing.T) {
+	tests := []struct {
+		code   int
+		codons string
+		aas    string
+	}{
+		{GENETIC_CODE_STANDARD, "TGAATAAGATAATAGCTG", "*IR**L"},
+		{GENETIC_CODE_VETEBRATE_MITO, "TGAATAAGATAATAGCTG", "WM***L"},
+		{GENETIC_CODE_YEAST_MITO, "TGAATAAGATAATAGCTG", "WMR**T"},
+		{GENETIC_CODE_MYCOPLASMA, "TGAATAAGATAATAGCTG", "WIR**L"},
+		{GENETIC_CODE_CILIATE, "TGAATAAGATAATAGCTG", "*IRQQL"},
+		{25, "TGAATAAGATAATAGCTG", "GIR**L"},
+		{GENETIC_CODE_BACTERIAL, "TGAATAAGATAATAGCTG", "*IR**L"},
+	}
+	for _, tt := range tests {
+		s := NewSequence("s", []uint8(tt.codons), "")
+		tr, err := s.Translate(0, tt.code)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if tr.Sequence() != tt.aas {
+			t.Errorf("Translation with code %d of %s should be %s, got %s", tt.code, tt.codons, tt.aas, tr.Sequence())
+		}
+	}
+
+	starts, _ := StartCodons(GENETIC_CODE_BACTERIAL)
+	if exp := []string{"ATA", "ATC", "ATG", "ATT", "CTG", "GTG", "TTG"}; !reflect.DeepEqual(starts, exp) {
+		t.Errorf("Start codons of bacterial code should be %v, got %v", exp, starts)
+	}
+}
+
+func TestLongestORFGeneticCode(t *testing.T) {
+	s := NewSequence("s", []uint8("CCGTGAAAATGATTTGATAGCC"), "")
+	tests := []struct {
+		code       int
+		altstart   bool
+		start, end int
+	}{
+		{GENETIC_CODE_STANDARD, false, 8, 17},
+		{GENETIC_CODE_STANDARD, true, 8, 17},
+		{GENETIC_CODE_BACTERIAL, true, 2, 17},
+		{GENETIC_CODE_MYCOPLASMA, false, 8, 20},
+		{GENETIC_CODE_MYCOPLASMA, true, 2, 20},
+	}
+	for _, tt := range tests {
+		start, end, err := s.LongestORF(tt.code, tt.altstart)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if start != tt.start || end != tt.end {
+			t.Errorf("LongestORF(%d, %v) should be [%d,%d[, got [%d,%d[", tt.code, tt.altstart, tt.start, tt.end, start, end)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package align
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	ORF_START_ATG = 0 // ORFs start with ATG
+	ORF_START_ALT = 1 // ORFs start with any initiation codon of the genetic code
+	ORF_START_ANY = 2 // ORFs go from stop to stop (start with any sense codon)
+)
+
+// ORF describes an open reading frame found in a nucleotide sequence
+type ORF struct {
+	Name       string  // Name of the sequence
+	Start, End int     // Coordinates on the forward strand (0-based, End excluded)
+	Strand     int     // 1: forward strand, -1: reverse strand
+	Frame      int     // Frame on its strand (1, 2 or 3), starting from the start of the strand
+	StartCodon string  // First codon of the ORF
+	Partial    bool    // True if the ORF has no stop codon (end of the sequence)
+	Nested     bool    // True if the ORF is included in a longer ORF of the same frame
+	Nt         []uint8 // Nucleotide sequence of the ORF, on its strand (with the stop codon)
+	Aa         []uint8 // Amino acid sequence of the ORF (without the stop codon)
+}
+
+// Length returns the length of the ORF in nucleotides (including the stop codon)
+func (o ORF) Length() int {
+	return o.End - o.Start
+}
+
+// FindORFs searches all the open reading frames of the sequences, in the 3 frames
+// of the forward strand (and in the 3 frames of the reverse strand if reverse is true).
+//
+// Stop codons are given by the genetic code, and start codons by startmode:
+//   - ORF_START_ATG: ORFs start at ATG;
+//   - ORF_START_ALT: ORFs start at any initiation codon of the genetic code;
+//   - ORF_START_ANY: ORFs go from the codon following a stop (or the start of the frame)
+//     to the next stop.
+//
+// Only ORFs of at least minlen nucleotides (including the stop codon) are reported.
+// By default, for each stop codon, only the longest ORF is reported (first start codon
+// after the previous stop). If nested is true, ORFs starting at following start codons
+// are reported too (ignored with ORF_START_ANY). If partial is true, ORFs without stop
+// codon at the end of the sequences are also reported.
+//
+// Amino acid sequences are translated using the genetic code. The first codon is
+// translated into M if it is a start codon (ORF_START_ATG and ORF_START_ALT).
+//
+// ORFs are sorted by sequence, then by start position on the forward strand.
+// Sequences containing gaps are unaligned first.
+func (sb *seqbag) FindORFs(geneticcode int, startmode int, minlen int, reverse, nested, partial bool) (orfs []ORF, err error) {
+	var code map[string]uint8
+	var starts map[string]bool
+
+	if sb.Alphabet() != NUCLEOTIDS {
+		err = fmt.Errorf("ORFs can only be searched in nucleotide sequences")
+		return
+	}
+	if code, err = geneticCode(geneticcode); err != nil {
+		return
+	}
+	switch startmode {
+	case ORF_START_ATG:
+		starts = map[string]bool{"ATG": true}
+	case ORF_START_ALT:
+		if starts, err = startCodons(geneticcode); err != nil {
+			return
+		}
+	case ORF_START_ANY:
+		nested = false
+	default:
+		err = fmt.Errorf("unknown ORF start mode: %d", startmode)
+		return
+	}
+
+	for _, s := range sb.seqs {
+		var seqorfs []ORF
+		ungapped := make([]uint8, 0, len(s.sequence))
+		for _, c := range s.sequence {
+			if c != GAP {
+				ungapped = append(ungapped, c)
+			}
+		}
+		seqorfs = strandORFs(s.name, ungapped, 1, code, starts, minlen, nested, partial)
+		if reverse {
+			rev := make([]uint8, len(ungapped))
+			copy(rev, ungapped)
+			Reverse(rev)
+			if err = Complement(rev); err != nil {
+				return
+			}
+			seqorfs = append(seqorfs, strandORFs(s.name, rev, -1, code, starts, minlen, nested, partial)...)
+		}
+		sort.SliceStable(seqorfs, func(i, j int) bool {
+			if seqorfs[i].Start != seqorfs[j].Start {
+				return seqorfs[i].Start < seqorfs[j].Start
+			}
+			return seqorfs[i].Strand > seqorfs[j].Strand
+		})
+		orfs = append(orfs, seqorfs...)
+	}
+	return
+}
+
+// strandORFs returns the ORFs found in the 3 frames of the given strand.
+// If starts is nil, ORFs go from stop to stop.
+func strandORFs(name string, sequence []uint8, strand int, code map[string]uint8, starts map[string]bool, minlen int, nested, partial bool) (orfs []ORF) {
+	var l = len(sequence)
+	var upper = []uint8(strings.ToUpper(strings.ReplaceAll(string(sequence), "U", "T")))
+
+	addorf := func(start, end int, isnested, ispartial bool) {
+		if end-start < minlen || end-start < 3 {
+			return
+		}
+		o := ORF{
+			Name:       name,
+			Start:      start,
+			End:        end,
+			Strand:     strand,
+			Frame:      start%3 + 1,
+			StartCodon: string(upper[start : start+3]),
+			Partial:    ispartial,
+			Nested:     isnested,
+			Nt:         make([]uint8, end-start),
+		}
+		copy(o.Nt, sequence[start:end])
+		for i := start; i+3 <= end; i += 3 {
+			aa := translateCodon(upper[i], upper[i+1], upper[i+2], code)
+			if i == start && starts != nil && starts[o.StartCodon] {
+				aa = 'M'
+			}
+			if aa == '*' && i+3 == end && !ispartial {
+				break
+			}
+			o.Aa = append(o.Aa, aa)
+		}
+		if strand < 0 {
+			o.Start, o.End = l-end, l-start
+		}
+		orfs = append(orfs, o)
+	}
+
+	for frame := 0; frame < 3; frame++ {
+		// Start positions of ORFs since the last stop codon
+		var open []int
+		if starts == nil && frame+3 <= l {
+			open = []int{frame}
+		}
+		i := frame
+		for ; i+3 <= l; i += 3 {
+			codon := string(upper[i : i+3])
+			if starts != nil && starts[codon] && (nested || len(open) == 0) {
+				open = append(open, i)
+			}
+			if translateCodon(upper[i], upper[i+1], upper[i+2], code) == '*' {
+				for j, start := range open {
+					if starts == nil && start == i {
+						// Stop right after a stop: empty ORF
+						continue
+					}
+					addorf(start, i+3, j > 0, false)
+				}
+				open = nil
+				if starts == nil {
+					open = []int{i + 3}
+				}
+			}
+		}
+		if partial {
+			for j, start := range open {
+				if start < i {
+					addorf(start, i, j > 0, true)
+				}
+			}
+		}
+	}
+	return
+}
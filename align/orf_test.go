@@ -0,0 +1,96 @@
+package align
+
+import (
+	"testing"
+)
+
+func TestFindORFs(t *testing.T) {
+	sb := NewSeqBag(NUCLEOTIDS)
+	sb.AddSequence("s1", "CCATGAAATTTTAGGGATGCCCTGAAACTAAAAA", "")
+	sb.AddSequence("s2", "TTTCTACATCATTT", "")
+
+	orfs, err := sb.FindORFs(GENETIC_CODE_STANDARD, ORF_START_ATG, 6, true, true, false)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expected := []ORF{
+		{Name: "s1", Start: 2, End: 14, Strand: 1, Frame: 3, Aa: []uint8("MKF")},
+		{Name: "s1", Start: 16, End: 25, Strand: 1, Frame: 2, Aa: []uint8("MP")},
+		{Name: "s2", Start: 3, End: 12, Strand: -1, Frame: 3, Aa: []uint8("MM")},
+		{Name: "s2", Start: 3, End: 9, Strand: -1, Frame: 3, Aa: []uint8("M"), Nested: true},
+	}
+	if len(orfs) != len(expected) {
+		t.Fatalf("Expected %d ORFs, got %d", len(expected), len(orfs))
+	}
+	for i, o := range orfs {
+		e := expected[i]
+		if o.Name != e.Name || o.Start != e.Start || o.End != e.End || o.Strand != e.Strand ||
+			o.Frame != e.Frame || o.Nested != e.Nested || string(o.Aa) != string(e.Aa) {
+			t.Errorf("ORF %d: expected %v, got %v", i, e, o)
+		}
+	}
+
+	// Without nested ORFs, and only forward strand
+	if orfs, err = sb.FindORFs(GENETIC_CODE_STANDARD, ORF_START_ATG, 6, false, false, false); err != nil {
+		t.Error(err)
+	}
+	if len(orfs) != 2 {
+		t.Errorf("Expected 2 ORFs, got %d", len(orfs))
+	}
+
+	// Minimum length
+	if orfs, err = sb.FindORFs(GENETIC_CODE_STANDARD, ORF_START_ATG, 10, true, true, false); err != nil {
+		t.Error(err)
+	}
+	if len(orfs) != 1 || orfs[0].Length() != 12 {
+		t.Errorf("Expected 1 ORF of length 12, got %v", orfs)
+	}
+}
+
+func TestFindORFsStopToStop(t *testing.T) {
+	sb := NewSeqBag(NUCLEOTIDS)
+	sb.AddSequence("s1", "AAACCCTAGGGGTTTTGACCC", "")
+
+	orfs, err := sb.FindORFs(GENETIC_CODE_STANDARD, ORF_START_ANY, 3, false, false, true)
+	if err != nil {
+		t.Error(err)
+	}
+	// Frame 1 codons: AAA CCC TAG GGG TTT TGA CCC
+	expected := [][2]int{{0, 9}, {9, 18}}
+	found := 0
+	for _, o := range orfs {
+		if o.Frame != 1 {
+			continue
+		}
+		if found < len(expected) && (o.Start != expected[found][0] || o.End != expected[found][1]) {
+			t.Errorf("Expected ORF %v, got [%d,%d)", expected[found], o.Start, o.End)
+		}
+		if found == 2 && (!o.Partial || o.Start != 18 || o.End != 21 || string(o.Aa) != "P") {
+			t.Errorf("Expected partial ORF [18,21) P, got %v", o)
+		}
+		found++
+	}
+	if found != 3 {
+		t.Errorf("Expected 3 ORFs in frame 1, got %d", found)
+	}
+}
+
+func TestFindORFsAltStart(t *testing.T) {
+	sb := NewSeqBag(NUCLEOTIDS)
+	sb.AddSequence("s1", "TTGAAATAA", "")
+
+	orfs, err := sb.FindORFs(GENETIC_CODE_STANDARD, ORF_START_ATG, 3, false, false, false)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(orfs) != 0 {
+		t.Errorf("Expected no ORF, got %v", orfs)
+	}
+	if orfs, err = sb.FindORFs(GENETIC_CODE_STANDARD, ORF_START_ALT, 3, false, false, false); err != nil {
+		t.Error(err)
+	}
+	if len(orfs) != 1 || string(orfs[0].Aa) != "MK" || orfs[0].StartCodon != "TTG" {
+		t.Errorf("Expected 1 ORF MK starting with TTG, got %v", orfs)
+	}
+}
@@ -9,7 +9,8 @@ import (
 //
 // align all sequences to the given ORF and trims sequences to the start
 // position
-// If orf is nil, searches for the longest ORF (in 3 or 6 phases depending on reverse arg) in all sequences
+// If orf is nil, searches for the longest ORF (in 3 or 6 phases depending on reverse arg) in all sequences,
+// starting with ATG, or with any initiation codon of the genetic code if SetAltStart(true)
 //
 // To do so, Phase() will:
 //
@@ -33,7 +34,8 @@ import (
 // align all sequences to the given ORF and trims sequences to the start
 // position, it does not take into account protein information
 //
-// If orf is nil, searches for the longest ORF (in forward only or both strands depending on reverse arg) in all sequences
+// If orf is nil, searches for the longest ORF (in forward only or both strands depending on reverse arg) in all sequences,
+// starting with ATG, or with any initiation codon of the genetic code if SetAltStart(true)
 //
 // To do so:
 //
@@ -50,6 +52,7 @@ type Phaser interface {
 	SetCutEnd(cutend bool)
 	SetCpus(cpus int)
 	SetTranslate(translate bool, geneticcode int) (err error)
+	SetAltStart(altstart bool)
 	SetAlignScores(match, mismatch float64)
 	SetGapOpen(float64)
 	SetGapExtend(float64)
@@ -71,6 +74,9 @@ type phaser struct {
 	// as is
 	translate   bool
 	geneticcode int
+	// Longest ORF may start with any initiation
+	// codon of the genetic code, not only ATG
+	altstart bool
 	//
 	// For Pairwise alignment
 	changedscores bool
@@ -107,6 +113,7 @@ func NewPhaser() Phaser {
 		cpus:          1,
 		translate:     true,
 		geneticcode:   GENETIC_CODE_STANDARD,
+		altstart:      false,
 		changedscores: false,
 		matchscore:    -1,
 		mismatchscore: -1,
@@ -138,6 +145,9 @@ func (p *phaser) SetTranslate(translate bool, geneticcode int) (err error) {
 	p.geneticcode = geneticcode
 	return
 }
+func (p *phaser) SetAltStart(altstart bool) {
+	p.altstart = altstart
+}
 func (p *phaser) SetAlignScores(match, mismatch float64) {
 	p.matchscore = match
 	p.mismatchscore = mismatch
@@ -170,7 +180,7 @@ func (p *phaser) Phase(orfs, seqs SeqBag) (phased chan PhasedSequence, err error
 
 	// If no orf given, then we find the longest among the sequences
 	if orfs == nil {
-		if orf, err = seqs.LongestORF(p.reverse); err != nil {
+		if orf, err = seqs.LongestORF(p.reverse, p.geneticcode, p.altstart); err != nil {
 			return
 		}
 		orfs = NewSeqBag(UNKNOWN)
@@ -328,7 +338,11 @@ func (p *phaser) alignAgainstRefsAA(seq Sequence, orfsaa []Sequence) (ph PhasedS
 			bestseqaa.SequenceChar()[beststartaa:bestendaa],
 			bestseqaa.Comment()),
 		Ali: bestali,
-	} // We set a threshold for matches over the alignment length...
+	}
+	if err = p.altStartAa(&ph); err != nil {
+		return
+	}
+	// We set a threshold for matches over the alignment length...
 	if (p.matchcutoff > .0 && bestratematches <= p.matchcutoff) ||
 		(p.lencutoff > .0 && bestlen <= p.lencutoff) {
 		ph.Removed = true
@@ -430,7 +444,12 @@ func (p *phaser) alignAgainstRefsNT(seq Sequence, orfs []Sequence) (ph PhasedSeq
 		Ali: bestali,
 	}
 
-	ph.AaSeq, err = ph.AaSeq.Translate(0, p.geneticcode)
+	if ph.AaSeq, err = ph.AaSeq.Translate(0, p.geneticcode); err != nil {
+		return
+	}
+	if err = p.altStartAa(&ph); err != nil {
+		return
+	}
 
 	// We set a threshold for matches over the alignment length...
 	if (p.matchcutoff > .0 && bestratematches <= p.matchcutoff) ||
@@ -439,3 +458,26 @@ func (p *phaser) alignAgainstRefsNT(seq Sequence, orfs []Sequence) (ph PhasedSeq
 	}
 	return
 }
+
+// altStartAa translates the first codon of the phased sequence into M if it is an
+// initiation codon of the genetic code (only if altstart), as the phased sequence
+// starts at the start codon of the ORF
+func (p *phaser) altStartAa(ph *PhasedSequence) (err error) {
+	var starts map[string]bool
+
+	codon := ph.CodonSeq.SequenceChar()
+	aa := ph.AaSeq.SequenceChar()
+	if !p.altstart || len(codon) < 3 || len(aa) == 0 {
+		return
+	}
+	if starts, err = startCodons(p.geneticcode); err != nil {
+		return
+	}
+	if isStartCodon(codon[0], codon[1], codon[2], starts) {
+		newaa := make([]uint8, len(aa))
+		copy(newaa, aa)
+		newaa[0] = 'M'
+		ph.AaSeq = NewSequence(ph.AaSeq.Name(), newaa, ph.AaSeq.Comment())
+	}
+	return
+}
@@ -9,9 +9,11 @@ import (
 	mathrand "math/rand"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"unicode"
 
+	"github.com/evolbioinfo/goalign/gutils"
 	"github.com/evolbioinfo/goalign/io"
 )
 
@@ -51,6 +53,8 @@ type SeqBag interface {
 	// Otherwise, sets IGNORE_NONE
 	IgnoreIdentical(int)
 	SampleSeqBag(nb int, rand *mathrand.Rand) (SeqBag, error) // generate a sub sample of the sequences
+	// generate a sub sample of at most maxPerGroup sequences per group (optionally weighted)
+	StratifiedSampleSeqBag(groups map[string][]string, maxPerGroup int, weights map[string]float64, rand *mathrand.Rand) (SeqBag, error)
 	Sequence(ith int) (Sequence, bool)
 	SequenceByName(name string) (Sequence, bool)
 	Identical(SeqBag) bool
@@ -59,7 +63,9 @@ type SeqBag interface {
 	IterateAll(it func(name string, sequence []uint8, comment string) bool)
 	Sequences() []Sequence
 	SequencesChan() chan Sequence
-	LongestORF(reverse bool) (orf Sequence, err error)
+	LongestORF(reverse bool, geneticcode int, altstart bool) (orf Sequence, err error)
+	FindORFs(geneticcode int, startmode int, minlen int, reverse, nested, partial bool) (orfs []ORF, err error)
+	CompositionTest(nboot int, rand *mathrand.Rand) (tests []CompositionTest, all CompositionTest, err error)
 	MaxNameLength() int // maximum sequence name length
 	NbSequences() int
 	RarefySeqBag(nb int, counts map[string]int, rand *mathrand.Rand) (SeqBag, error) // Take a new rarefied sample taking into accounts weights
@@ -69,15 +75,17 @@ type SeqBag interface {
 	RemoveCharacterSeqs(c uint8, cutoff float64, ignoreCase, ignoreGaps, ignoreNs bool) int
 	Rename(namemap map[string]string)
 	RenameRegexp(regex, replace string, namemap map[string]string) error
-	Replace(old, new string, regex bool) error             // Replaces old string with new string in sequences of the alignment
-	ReplaceStops(phase int, geneticode int) error          // Replaces stop codons in the given phase using the given genetic code
-	ShuffleSequences(rand *mathrand.Rand)                  // Shuffle sequence order
-	String() string                                        // Raw string representation (just write all sequences)
-	Translate(phase int, geneticcode int) (err error)      // Translates nt sequence in aa
-	ToUpper()                                              // replaces lower case characters by upper case characters
-	ToLower()                                              // replaces upper case characters by lower case characters
-	ReverseComplement() (err error)                        // Reverse-complements the alignment
-	ReverseComplementSequences(name ...string) (err error) // Reverse-complements some sequences in the alignment
+	RenameTemplate(sep, regex, template string, namemap map[string]string) error                               // Renames sequences using fields of their names (see NameFields)
+	Replace(old, new string, regex bool) error                                                                 // Replaces old string with new string in sequences of the alignment
+	ReplaceStops(phase int, geneticode int) error                                                              // Replaces stop codons in the given phase using the given genetic code
+	ShuffleSequences(rand *mathrand.Rand)                                                                      // Shuffle sequence order
+	String() string                                                                                            // Raw string representation (just write all sequences)
+	Translate(phase int, geneticcode int) (err error)                                                          // Translates nt sequence in aa
+	TranslateIUPAC(phase int, geneticcode int, aaclasses, altstart bool) (stats []TranslationStats, err error) // Translates nt sequence in aa, with ambiguous codon stats
+	ToUpper()                                                                                                  // replaces lower case characters by upper case characters
+	ToLower()                                                                                                  // replaces upper case characters by lower case characters
+	ReverseComplement() (err error)                                                                            // Reverse-complements the alignment
+	ReverseComplementSequences(name ...string) (err error)                                                     // Reverse-complements some sequences in the alignment
 	TrimNames(namemap map[string]string, size int) error
 	TrimNamesAuto(namemap map[string]string, curid *int) error
 	Sort() // Sorts the sequences by name
@@ -821,6 +829,118 @@ func (sb *seqbag) rarefySeqBag(nb int, counts map[string]int, rand *mathrand.Ran
 	return
 }
 
+// StratifiedSampleSeqBag samples at most maxPerGroup sequences in each group of sequences.
+//
+// - groups: group name => names of the sequences of the group. Sequences that do not
+// belong to any group are not sampled. If a sequence does not exist, returns an error;
+// - maxPerGroup: maximum number of sequences to sample per group. If a group has less
+// sequences, all of them are kept;
+// - weights: if nil, sequences are sampled uniformly in each group. Otherwise, sequences
+// are sampled without replacement with probabilities proportional to their weights
+// (Efraimidis & Spirakis, 2006). Sequences with a weight <= 0 are never sampled. If a
+// sequence of a group has no weight, returns an error.
+//
+// Groups are processed in lexicographic order, so that the sample is reproducible given
+// the random generator. Sampled sequences are kept in the order of the input seqbag.
+func (sb *seqbag) StratifiedSampleSeqBag(groups map[string][]string, maxPerGroup int, weights map[string]float64, rand *mathrand.Rand) (sample SeqBag, err error) {
+	sample, err = sb.stratifiedSampleSeqBag(groups, maxPerGroup, weights, rand)
+	return
+}
+
+// stratifiedSampleSeqBag is a private function to allow manipulation of the structure and not the interface
+func (sb *seqbag) stratifiedSampleSeqBag(groups map[string][]string, maxPerGroup int, weights map[string]float64, rand *mathrand.Rand) (sample *seqbag, err error) {
+	var selected map[string]bool = make(map[string]bool)
+	var keys []string = make([]string, 0, len(groups))
+
+	if maxPerGroup < 1 {
+		err = fmt.Errorf("cannot sample less than 1 sequence per group")
+		return
+	}
+
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		var group []string = groups[k]
+		var sampled []string
+
+		for _, name := range group {
+			if _, ok := sb.seqmap[name]; !ok {
+				err = fmt.Errorf("sequence %s does not exist in the alignment", name)
+				return
+			}
+		}
+
+		if weights == nil {
+			if len(group) <= maxPerGroup {
+				sampled = group
+			} else {
+				perm := rand.Perm(len(group))
+				sampled = make([]string, maxPerGroup)
+				for i := 0; i < maxPerGroup; i++ {
+					sampled[i] = group[perm[i]]
+				}
+			}
+		} else {
+			if sampled, err = weightedSample(group, maxPerGroup, weights, rand); err != nil {
+				return
+			}
+		}
+		for _, name := range sampled {
+			selected[name] = true
+		}
+	}
+
+	sample = NewSeqBag(sb.alphabet)
+	sb.IterateAll(func(name string, sequence []uint8, comment string) bool {
+		if _, ok := selected[name]; ok {
+			sample.AddSequenceChar(name, sequence, comment)
+		}
+		return false
+	})
+	return
+}
+
+// weightedSample samples at most nb names without replacement, with probabilities
+// proportional to their weights, using the algorithm of Efraimidis & Spirakis (2006):
+// each name receives a key u^(1/w), u being uniform in [0,1], and the nb names with the
+// largest keys are kept. Names with weights <= 0 are never sampled.
+func weightedSample(names []string, nb int, weights map[string]float64, rand *mathrand.Rand) (sampled []string, err error) {
+	type weightedName struct {
+		name string
+		key  float64
+	}
+	var w float64
+	var ok bool
+
+	candidates := make([]weightedName, 0, len(names))
+	for _, name := range names {
+		if w, ok = weights[name]; !ok {
+			err = fmt.Errorf("no weight for sequence %s", name)
+			return
+		}
+		// We draw the random number anyway, to keep the generator
+		// state independent of the weights
+		u := rand.Float64()
+		if w > 0 {
+			candidates = append(candidates, weightedName{name, math.Pow(u, 1.0/w)})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].key > candidates[j].key
+	})
+	if len(candidates) > nb {
+		candidates = candidates[:nb]
+	}
+	sampled = make([]string, len(candidates))
+	for i, c := range candidates {
+		sampled[i] = c.name
+	}
+	return
+}
+
 // Removes sequences constituted of [cutoff*100%,100%] Gaps
 // Exception fo a cutoff of 0: does not remove sequences with 0% gaps
 // Cutoff must be between 0 and 1, otherwise set to 0.
@@ -912,18 +1032,128 @@ func (sb *seqbag) ShuffleSequences(rand *mathrand.Rand) {
 	}
 }
 
-// This function renames sequences of the alignment based on the given regex and replace strings
+// This function renames sequences of the alignment based on the given regex and replace strings.
+// Returns an error (and does not rename anything) if several sequences would get the same name.
 func (sb *seqbag) RenameRegexp(regex, replace string, namemap map[string]string) error {
 	r, err := regexp.Compile(regex)
 	if err != nil {
 		return err
 	}
-	for seq := 0; seq < sb.NbSequences(); seq++ {
-		newname := r.ReplaceAllString(sb.seqs[seq].name, replace)
-		namemap[sb.seqs[seq].name] = newname
-		sb.seqs[seq].name = newname
+	return sb.renameWith(func(name string) (string, error) {
+		return r.ReplaceAllString(name, replace), nil
+	}, namemap)
+}
+
+// renameWith renames all sequences with the names given by newname, and adds the
+// mapping between old and new names to namemap.
+// Sequences are renamed only if all new names could be computed, and are all different
+// (otherwise the mapping between old and new names could not be reversed).
+func (sb *seqbag) renameWith(newname func(name string) (string, error), namemap map[string]string) (err error) {
+	newnames := make([]string, sb.NbSequences())
+	oldnames := make(map[string]string, sb.NbSequences())
+	for i, seq := range sb.seqs {
+		if newnames[i], err = newname(seq.name); err != nil {
+			return
+		}
+		if old, ok := oldnames[newnames[i]]; ok {
+			err = fmt.Errorf("sequences %s and %s would both be renamed %s", old, seq.name, newnames[i])
+			return
+		}
+		oldnames[newnames[i]] = seq.name
 	}
-	return nil
+	for i, seq := range sb.seqs {
+		namemap[seq.name] = newnames[i]
+		seq.name = newnames[i]
+	}
+	return
+}
+
+// This function renames sequences of the alignment using a template whose
+// placeholders refer to fields of the current names (see NameFields):
+//   - {1}, {2}, ...: field 1, 2, ... (1-based);
+//   - {-1}, {-2}, ...: last field, second to last field, ...;
+//   - {0} or {name}: the whole current name.
+//
+// Placeholders may be followed by a modifier (see gutils.Template), ex: {3:date}
+// normalizes the date given in the third field.
+//
+// For example, with sep="|" and template "{3:date}_{1}", the sequence
+// "hCoV-19/France/XYZ/2021|EPI_ISL_123|2021/03/01" is renamed "2021-03-01_hCoV-19/France/XYZ/2021".
+//
+// Returns an error if a field does not exist in a name, if a date can not be
+// normalized, or if several sequences would get the same name. In that case, no
+// sequence is renamed. The mapping between old and new names is added to namemap.
+func (sb *seqbag) RenameTemplate(sep, regex, template string, namemap map[string]string) (err error) {
+	var r *regexp.Regexp
+	var t *gutils.Template
+
+	if regex != "" {
+		if r, err = regexp.Compile(regex); err != nil {
+			return
+		}
+	}
+	if t, err = gutils.ParseTemplate(template); err != nil {
+		return
+	}
+	for _, k := range t.Keys() {
+		if _, errconv := strconv.Atoi(k); errconv != nil && k != "name" {
+			err = fmt.Errorf("template key {%s} is not a field number", k)
+			return
+		}
+	}
+
+	return sb.renameWith(func(name string) (newname string, err error) {
+		var fields []string
+		if fields, err = NameFields(name, sep, r); err != nil {
+			return
+		}
+		return t.Render(func(key string) (string, error) { return nameField(name, fields, key) })
+	}, namemap)
+}
+
+// NameFields extracts fields from the given sequence name:
+//   - If regex is not nil, fields are the groups captured by the regex
+//     (an error is returned if the name does not match);
+//   - Otherwise, fields are obtained by splitting the name with sep.
+func NameFields(name, sep string, regex *regexp.Regexp) (fields []string, err error) {
+	if regex != nil {
+		var match []string
+		if match = regex.FindStringSubmatch(name); match == nil {
+			err = fmt.Errorf("sequence name %s does not match regexp %s", name, regex.String())
+			return
+		}
+		fields = match[1:]
+		return
+	}
+	if sep == "" {
+		err = errors.New("a separator or a regexp must be given to extract name fields")
+		return
+	}
+	fields = strings.Split(name, sep)
+	return
+}
+
+// nameField returns the field of the name corresponding to the given template key
+func nameField(name string, fields []string, key string) (field string, err error) {
+	var i int
+	if key == "name" {
+		return name, nil
+	}
+	if i, err = strconv.Atoi(key); err != nil {
+		return
+	}
+	if i == 0 {
+		return name, nil
+	}
+	if i < 0 {
+		i = len(fields) + i + 1
+	}
+	if i < 1 || i > len(fields) {
+		err = fmt.Errorf("field {%s} does not exist in sequence name %s (%d fields)", key, name, len(fields))
+		return
+	}
+	field = fields[i-1]
+	return
 }
 
 // Replace an old string in sequences by a new string
@@ -1010,16 +1240,40 @@ sequence names. At the end 3x more sequences in the seqbag.
 The seqbag is cleared and old sequences are replaced with aminoacid sequences
 */
 func (sb *seqbag) Translate(phase int, geneticcode int) (err error) {
+	_, err = sb.TranslateIUPAC(phase, geneticcode, false, false)
+	return
+}
+
+/*
+TranslateIUPAC translates the sequences as Translate, and returns, for each translated
+sequence, the number of codons that contained ambiguous nucleotides, and how they
+were translated (see TranslationStats).
+
+Ambiguous codons whose possible codons all encode the same amino acid are translated
+into this amino acid (ex: GGN=G). If aaclasses is true, ambiguous codons encoding
+either D or N, E or Q, I or L are translated into B, Z and J respectively. Other ambiguous
+codons are translated into X.
+
+If altstart is true, the first codon of each sequence is translated into M if it is an
+initiation codon of the genetic code (ex: TTG or GTG with the bacterial code 11).
+*/
+func (sb *seqbag) TranslateIUPAC(phase int, geneticcode int, aaclasses, altstart bool) (stats []TranslationStats, err error) {
 	var oldseqs []*seq
 	var buffer bytes.Buffer
 	var firststart, laststart int
 	var name string
 	var suffix bool
 	var code map[string]uint8
+	var starts map[string]bool
 
 	if code, err = geneticCode(geneticcode); err != nil {
 		return
 	}
+	if altstart {
+		if starts, err = startCodons(geneticcode); err != nil {
+			return
+		}
+	}
 
 	if sb.Alphabet() != NUCLEOTIDS {
 		err = errors.New("wrong alphabet, cannot translate to AA")
@@ -1046,9 +1300,11 @@ func (sb *seqbag) Translate(phase int, geneticcode int) (err error) {
 				name = fmt.Sprintf("%s_%d", seq.name, phase)
 			}
 
-			if err = bufferTranslate(seq, phase, code, &buffer); err != nil {
+			st := TranslationStats{Name: name}
+			if err = bufferTranslate(seq, phase, code, starts, aaclasses, &st, &buffer); err != nil {
 				return
 			}
+			stats = append(stats, st)
 
 			if err = sb.AddSequence(name, buffer.String(), seq.comment); err != nil {
 				return
@@ -1132,8 +1388,11 @@ func (sb *seqbag) ReverseComplementSequences(names ...string) (err error) {
 }
 
 // Translate sequences in 3 phases (or 6 phases if reverse strand is true)
-// And return the longest orf found
-func (sb *seqbag) LongestORF(reverse bool) (orf Sequence, err error) {
+// And return the longest orf found.
+//
+// Stop codons are given by the genetic code, and start codons are ATG, or
+// all the initiation codons of the genetic code if altstart is true.
+func (sb *seqbag) LongestORF(reverse bool, geneticcode int, altstart bool) (orf Sequence, err error) {
 	var beststart, bestend int
 	var start, end int
 	var bestseq Sequence
@@ -1143,7 +1402,9 @@ func (sb *seqbag) LongestORF(reverse bool) (orf Sequence, err error) {
 	found = false
 	// Search for the longest orf in all sequences
 	for _, seq := range sb.seqs {
-		start, end = seq.LongestORF()
+		if start, end, err = seq.LongestORF(geneticcode, altstart); err != nil {
+			return
+		}
 		if start != -1 && end-start > bestend-beststart {
 			beststart, bestend = start, end
 			bestseq = seq
@@ -1154,7 +1415,9 @@ func (sb *seqbag) LongestORF(reverse bool) (orf Sequence, err error) {
 			rev := seq.Clone()
 			rev.Reverse()
 			rev.Complement()
-			start, end = rev.LongestORF()
+			if start, end, err = rev.LongestORF(geneticcode, altstart); err != nil {
+				return
+			}
 			if start != -1 && end-start > bestend-beststart {
 				beststart, bestend = start, end
 				bestseq = rev
@@ -2,6 +2,7 @@ package align
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -81,3 +82,96 @@ func Test_seqbag_ReverseComplement(t *testing.T) {
 		})
 	}
 }
+
+func Test_seqbag_RenameTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		names    []string
+		sep      string
+		regex    string
+		template string
+		want     []string
+		wantErr  bool
+	}{
+		{name: "split",
+			names:    []string{"hCoV-19/France/XYZ/2021|EPI_ISL_123|2021-03-01", "hCoV-19/UK/ABC/2021|EPI_ISL_456|2021/3/2"},
+			sep:      "|",
+			template: "{3:date}_{1}",
+			want:     []string{"2021-03-01_hCoV-19/France/XYZ/2021", "2021-03-02_hCoV-19/UK/ABC/2021"}},
+		{name: "negative",
+			names:    []string{"A|B|C", "D|E"},
+			sep:      "|",
+			template: "{-1}-{0}",
+			want:     []string{"C-A|B|C", "E-D|E"}},
+		{name: "regexp",
+			names:    []string{"seq_12_fr", "seq_3_uk"},
+			regex:    `^seq_(\d+)_(\w+)$`,
+			template: "{2:upper}{{{1}}}",
+			want:     []string{"FR{12}", "UK{3}"}},
+		{name: "missing field",
+			names:    []string{"A|B|C", "D|E"},
+			sep:      "|",
+			template: "{3}",
+			wantErr:  true},
+		{name: "bad date",
+			names:    []string{"A|2021-13-45"},
+			sep:      "|",
+			template: "{2:date}",
+			wantErr:  true},
+		{name: "duplicate names",
+			names:    []string{"A|2021-03-01", "B|2021-03-01", "C|2021-03-02"},
+			sep:      "|",
+			template: "{2:date}",
+			wantErr:  true},
+		{name: "no match",
+			names:    []string{"seq_12_fr", "other"},
+			regex:    `^seq_(\d+)_(\w+)$`,
+			template: "{1}",
+			wantErr:  true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sb := NewSeqBag(NUCLEOTIDS)
+			for _, n := range tt.names {
+				sb.AddSequence(n, "ACGT", "")
+			}
+			namemap := make(map[string]string)
+			err := sb.RenameTemplate(tt.sep, tt.regex, tt.template, namemap)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("seqbag.RenameTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				// Names must not be changed
+				for i, s := range sb.seqs {
+					if s.name != tt.names[i] {
+						t.Errorf("seqbag.RenameTemplate() renamed %s into %s while returning an error", tt.names[i], s.name)
+					}
+				}
+				return
+			}
+			for i, s := range sb.seqs {
+				if s.name != tt.want[i] {
+					t.Errorf("seqbag.RenameTemplate() = %v, want %v", s.name, tt.want[i])
+				}
+				if namemap[tt.names[i]] != tt.want[i] {
+					t.Errorf("seqbag.RenameTemplate() namemap[%s] = %v, want %v", tt.names[i], namemap[tt.names[i]], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_seqbag_RenameTemplateDuplicates(t *testing.T) {
+	sb := NewSeqBag(NUCLEOTIDS)
+	sb.AddSequence("A|2021-03-01", "ACGT", "")
+	sb.AddSequence("B|2021/3/1", "ACGT", "")
+	err := sb.RenameTemplate("|", "", "{2:date}", make(map[string]string))
+	if err == nil {
+		t.Fatalf("seqbag.RenameTemplate() should fail when two sequences get the same name")
+	}
+	for _, s := range []string{"A|2021-03-01", "B|2021/3/1", "2021-03-01"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("seqbag.RenameTemplate() error %q should contain %q", err.Error(), s)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"math/bits"
 	mathrand "math/rand"
 	"regexp"
+	"sort"
 	"strings"
 	"unicode"
 )
@@ -20,7 +21,7 @@ type Sequence interface {
 	SetName(name string)
 	Comment() string
 	Length() int
-	LongestORF() (start, end int) // Detects the longest ORF in forward strand only
+	LongestORF(geneticcode int, altstart bool) (start, end int, err error) // Detects the longest ORF in forward strand only
 	Reverse()
 	Complement() error                                      // Returns an error if not nucleotide sequence
 	Translate(phase int, geneticcode int) (Sequence, error) // Translates the sequence using the given code
@@ -44,7 +45,7 @@ type Sequence interface {
 	// otherwise "/" ~frameshift?
 	//
 	// If lengths are different, returns an error
-	ListMutationsComparedToReferenceSequence(alphabet int, refseq Sequence, codon bool, translate bool) (mutations []Mutation, err error)
+	ListMutationsComparedToReferenceSequence(alphabet int, refseq Sequence, codon bool, translate bool, geneticcode int) (mutations []Mutation, err error)
 
 	Clone() Sequence
 }
@@ -108,13 +109,40 @@ func (s *seq) Length() int {
 }
 
 // Detects the position of ATG giving the longest ORF
-// Search is done in the forward strand only
+// Search is done in the forward strand only.
+//
+// Stop codons are given by the genetic code. If altstart is true, all
+// initiation codons of the genetic code are considered instead of ATG only
+// (ex: TTG, CTG and ATG for the standard code).
 //
 // returns -1 is no ATG...STOP has been found
-func (s *seq) LongestORF() (start, end int) {
+func (s *seq) LongestORF(geneticcode int, altstart bool) (start, end int, err error) {
+	var code map[string]uint8
+	var starts, stops []string
+
 	start = -1
 	end = -1
-	re, _ := regexp.Compile("(ATG)(.{3})*?(TAA|TGA|TAG)")
+	if code, err = geneticCode(geneticcode); err != nil {
+		return
+	}
+	if altstart {
+		if starts, err = StartCodons(geneticcode); err != nil {
+			return
+		}
+	} else {
+		starts = []string{"ATG"}
+	}
+	for codon, aa := range code {
+		if aa == '*' {
+			stops = append(stops, codon)
+		}
+	}
+	if len(stops) == 0 || len(starts) == 0 {
+		return
+	}
+	sort.Strings(stops)
+
+	re, _ := regexp.Compile("(" + strings.Join(starts, "|") + ")(.{3})*?(" + strings.Join(stops, "|") + ")")
 	//re.Longest()
 	idx := re.FindAllStringIndex(
 		strings.Replace(
@@ -127,7 +155,7 @@ func (s *seq) LongestORF() (start, end int) {
 			start = pos[0]
 		}
 	}
-	return start, end
+	return
 }
 
 func RandomSequence(alphabet, length int, rand *mathrand.Rand) ([]uint8, error) {
@@ -317,10 +345,12 @@ func (s *seq) NumMutationsComparedToReferenceSequence(alphabet int, refseq Seque
 // If a character is ambigous (IUPAC notation), then it is counted as a mutation only if it is incompatible with
 // the reference character.
 //
+// If codon and translate are true, codons are translated using the given genetic code.
+//
 // If lengths are different, returns an error
-func (s *seq) ListMutationsComparedToReferenceSequence(alphabet int, refseq Sequence, codon, translate bool) (mutations []Mutation, err error) {
+func (s *seq) ListMutationsComparedToReferenceSequence(alphabet int, refseq Sequence, codon, translate bool, geneticcode int) (mutations []Mutation, err error) {
 	if codon {
-		return s.listMutationsComparedToReferenceSequenceCodon(alphabet, refseq, translate)
+		return s.listMutationsComparedToReferenceSequenceCodon(alphabet, refseq, translate, geneticcode)
 	}
 	return s.listMutationsComparedToReferenceSequence(alphabet, refseq)
 }
@@ -393,7 +423,7 @@ func (s *seq) listMutationsComparedToReferenceSequence(alphabet int, refseq Sequ
 // listMutationsComparedToReferenceSequenceAA takes nucleotides codon by codon of the reference seqence
 // to list mutations. In case of insertion or a deletion in the target sequence: if %3==0: - or aa insert,
 // otherwise "/" ~frameshift?
-func (s *seq) listMutationsComparedToReferenceSequenceCodon(alphabet int, refseq Sequence, translate bool) (mutations []Mutation, err error) {
+func (s *seq) listMutationsComparedToReferenceSequenceCodon(alphabet int, refseq Sequence, translate bool, geneticcode int) (mutations []Mutation, err error) {
 	var refseqchar []uint8
 	var code map[string]uint8
 
@@ -407,7 +437,7 @@ func (s *seq) listMutationsComparedToReferenceSequenceCodon(alphabet int, refseq
 		return
 	}
 
-	if code, err = geneticCode(GENETIC_CODE_STANDARD); err != nil {
+	if code, err = geneticCode(geneticcode); err != nil {
 		return
 	}
 
@@ -536,7 +566,7 @@ func (s *seq) Translate(phase int, geneticcode int) (tr Sequence, err error) {
 		return
 	}
 
-	if err = bufferTranslate(s, phase, code, &buffer); err != nil {
+	if err = bufferTranslate(s, phase, code, nil, false, nil, &buffer); err != nil {
 		return
 	}
 
@@ -544,7 +574,11 @@ func (s *seq) Translate(phase int, geneticcode int) (tr Sequence, err error) {
 	return
 }
 
-func bufferTranslate(s *seq, phase int, code map[string]uint8, buffer *bytes.Buffer) (err error) {
+// bufferTranslate translates the sequence s from the given phase into the buffer.
+// Amino acid ambiguity codes are used if aaclasses is true (see translateCodonStats),
+// the first codon is translated into M if it is in starts (see translateFirstCodonStats),
+// and translated codons are counted in stats if not nil.
+func bufferTranslate(s *seq, phase int, code map[string]uint8, starts map[string]bool, aaclasses bool, stats *TranslationStats, buffer *bytes.Buffer) (err error) {
 	buffer.Reset()
 	if s.DetectAlphabet() != NUCLEOTIDS && s.DetectAlphabet() != BOTH {
 		err = fmt.Errorf("cannot translate this sequence, wrong alphabet")
@@ -557,7 +591,11 @@ func bufferTranslate(s *seq, phase int, code map[string]uint8, buffer *bytes.Buf
 	}
 	for i := phase; i < len(s.sequence)-2; i += 3 {
 		var aa uint8 = ' '
-		aa = translateCodon(s.sequence[i], s.sequence[i+1], s.sequence[i+2], code)
+		if i == phase {
+			aa = translateFirstCodonStats(s.sequence[i], s.sequence[i+1], s.sequence[i+2], code, starts, aaclasses, stats)
+		} else {
+			aa = translateCodonStats(s.sequence[i], s.sequence[i+1], s.sequence[i+2], code, aaclasses, stats)
+		}
 		buffer.WriteByte(aa)
 	}
 	return
@@ -568,30 +606,7 @@ func bufferTranslate(s *seq, phase int, code map[string]uint8, buffer *bytes.Buf
 // If all codons give the same amino acid: we take this one.
 // Otherwise we translate by 'X'
 func translateCodon(n1, n2, n3 uint8, code map[string]uint8) (aa uint8) {
-	var aatmp uint8 = ' '
-	var found bool = false
-	aa = ' '
-	// We handle possible IUPAC characters
-	codons := GenAllPossibleCodons(n1, n2, n3)
-	if len(codons) == 0 {
-		aa = 'X'
-	}
-	for _, codon := range codons {
-		// The codon i=s not found
-		// We return X
-		if aatmp, found = code[codon]; !found {
-			aa = 'X'
-			break
-		}
-		// Different codons give different AA
-		// We can not translate it uniquely
-		if aa != ' ' && aatmp != aa {
-			aa = 'X'
-			break
-		}
-		aa = aatmp
-	}
-	return
+	return translateCodonStats(n1, n2, n3, code, false, nil)
 }
 
 func (s *seq) Clone() Sequence {
@@ -0,0 +1,144 @@
+package align
+
+import (
+	"unicode"
+)
+
+// TranslationStats counts the codons of a sequence during its translation,
+// depending on the presence of ambiguous nucleotides (IUPAC codes):
+//   - Codons: Number of translated codons (gap codons "---" excluded);
+//   - Ambiguous: Number of codons having at least one ambiguous nucleotide (or gap);
+//   - Resolved: Number of ambiguous codons whose possible codons all encode
+//     the same amino acid (ex: GGN=G, YTR=L, TAR=*);
+//   - Classes: Number of ambiguous codons translated into an amino acid ambiguity
+//     code (B, Z or J, see TranslateIUPAC);
+//   - Unresolved: Number of ambiguous codons translated as X.
+type TranslationStats struct {
+	Name       string
+	Codons     int
+	Ambiguous  int
+	Resolved   int
+	Classes    int
+	Unresolved int
+}
+
+// Amino acid ambiguity codes: B=D|N, Z=E|Q, J=I|L
+var aaAmbiguityClasses = map[[2]uint8]uint8{
+	{'D', 'N'}: 'B',
+	{'E', 'Q'}: 'Z',
+	{'I', 'L'}: 'J',
+}
+
+// translateCodonStats translates the given codon using the given genetic code,
+// and updates the given stats if not nil.
+//
+// IUPAC codes are handled by generating all possible codons: if they all
+// encode the same amino acid, the codon is translated into this amino acid.
+// Otherwise, if aaclasses is true and the possible codons encode exactly the two
+// amino acids of an ambiguity code (D|N, E|Q or I|L), then the codon is
+// translated into this code (B, Z or J). Otherwise it is translated into X.
+func translateCodonStats(n1, n2, n3 uint8, code map[string]uint8, aaclasses bool, stats *TranslationStats) (aa uint8) {
+	var aatmp uint8
+	var found bool
+	var aas []uint8
+
+	// We handle possible IUPAC characters
+	codons := GenAllPossibleCodons(n1, n2, n3)
+	if len(codons) == 0 {
+		aa = 'X'
+	}
+	for _, codon := range codons {
+		// The codon is not found
+		// We return X
+		if aatmp, found = code[codon]; !found {
+			aa = 'X'
+			aas = nil
+			break
+		}
+		found = false
+		for _, a := range aas {
+			found = found || a == aatmp
+		}
+		if !found {
+			aas = append(aas, aatmp)
+		}
+	}
+
+	switch len(aas) {
+	case 0:
+		aa = 'X'
+	case 1:
+		aa = aas[0]
+	case 2:
+		// Different codons give different AA
+		// We can not translate it uniquely, except
+		// with amino acid ambiguity codes
+		aa = 'X'
+		if aaclasses {
+			if aas[0] > aas[1] {
+				aas[0], aas[1] = aas[1], aas[0]
+			}
+			if c, ok := aaAmbiguityClasses[[2]uint8{aas[0], aas[1]}]; ok {
+				aa = c
+			}
+		}
+	default:
+		aa = 'X'
+	}
+
+	if stats != nil && aa != '-' {
+		stats.Codons++
+		if !unambiguousNt(n1) || !unambiguousNt(n2) || !unambiguousNt(n3) {
+			stats.Ambiguous++
+			switch {
+			case aa == 'X':
+				stats.Unresolved++
+			case len(aas) == 2:
+				stats.Classes++
+			default:
+				stats.Resolved++
+			}
+		}
+	}
+	return
+}
+
+// translateFirstCodonStats translates the first codon of a sequence as translateCodonStats,
+// except that it is translated into M if all its possible codons are initiation codons
+// (starts, may be nil): alternative start codons (ex: GTG or TTG in the bacterial code)
+// initiate the translation with a methionine.
+func translateFirstCodonStats(n1, n2, n3 uint8, code map[string]uint8, starts map[string]bool, aaclasses bool, stats *TranslationStats) (aa uint8) {
+	if !isStartCodon(n1, n2, n3, starts) {
+		return translateCodonStats(n1, n2, n3, code, aaclasses, stats)
+	}
+	aa = 'M'
+	if stats != nil {
+		stats.Codons++
+		if !unambiguousNt(n1) || !unambiguousNt(n2) || !unambiguousNt(n3) {
+			stats.Ambiguous++
+			stats.Resolved++
+		}
+	}
+	return
+}
+
+// isStartCodon returns true if all the possible codons given the 3 nucleotides
+// (IUPAC codes) are in starts
+func isStartCodon(n1, n2, n3 uint8, starts map[string]bool) bool {
+	codons := GenAllPossibleCodons(n1, n2, n3)
+	for _, codon := range codons {
+		if !starts[codon] {
+			return false
+		}
+	}
+	return len(codons) > 0
+}
+
+// unambiguousNt returns true if the given nucleotide is A, C, G, T or U
+func unambiguousNt(nt uint8) bool {
+	switch unicode.ToUpper(rune(nt)) {
+	case 'A', 'C', 'G', 'T', 'U':
+		return true
+	}
+	return false
+}
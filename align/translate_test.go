@@ -0,0 +1,113 @@
+package align
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_translateCodonStats(t *testing.T) {
+	code, _ := geneticCode(GENETIC_CODE_STANDARD)
+	tests := []struct {
+		codon     string
+		aaclasses bool
+		aa        uint8
+	}{
+		{"GGA", false, 'G'},
+		{"GGN", false, 'G'},
+		{"YTR", false, 'L'},
+		{"TAR", false, '*'},
+		{"RAY", false, 'X'},
+		{"RAY", true, 'B'},
+		{"SAR", true, 'Z'},
+		{"MTT", true, 'J'},
+		{"WTA", true, 'J'},
+		{"NNN", true, 'X'},
+		{"A-G", true, 'X'},
+		{"---", true, '-'},
+	}
+	for _, tt := range tests {
+		if aa := translateCodonStats(tt.codon[0], tt.codon[1], tt.codon[2], code, tt.aaclasses, nil); aa != tt.aa {
+			t.Errorf("Translation of %s (aaclasses=%v) should be %c, got %c", tt.codon, tt.aaclasses, tt.aa, aa)
+		}
+	}
+}
+
+func Test_seqbag_TranslateIUPAC(t *testing.T) {
+	sb := NewSeqBag(UNKNOWN)
+	sb.AddSequence("s1", "GGNYTRRAYSARMTTWTANNNTAR---", "")
+	sb.AddSequence("s2", "GGAATG---AAYCARATTATAACGTAA", "")
+	sb.AutoAlphabet()
+
+	stats, err := sb.TranslateIUPAC(0, GENETIC_CODE_STANDARD, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []TranslationStats{
+		{Name: "s1", Codons: 8, Ambiguous: 8, Resolved: 3, Classes: 4, Unresolved: 1},
+		{Name: "s2", Codons: 8, Ambiguous: 2, Resolved: 2, Classes: 0, Unresolved: 0},
+	}
+	if !reflect.DeepEqual(stats, exp) {
+		t.Errorf("Wrong translation stats, expected %v, got %v", exp, stats)
+	}
+	if s, _ := sb.GetSequence("s1"); s != "GLBZJJX*-" {
+		t.Errorf("Wrong translation of s1: %s", s)
+	}
+	if s, _ := sb.GetSequence("s2"); s != "GM-NQIIT*" {
+		t.Errorf("Wrong translation of s2: %s", s)
+	}
+}
+
+func Test_seqbag_TranslateIUPACAltStart(t *testing.T) {
+	bacterial, _ := GeneticCodeFromNCBI(11)
+	euplotid, _ := GeneticCodeFromNCBI(10)
+	seqs := func() SeqBag {
+		sb := NewSeqBag(NUCLEOTIDS)
+		sb.AddSequence("s1", "TTGAAATAA", "")
+		sb.AddSequence("s2", "GTGAAATTG", "")
+		sb.AddSequence("s3", "KTGAAATAA", "")
+		sb.AddSequence("s4", "CCCTTGTAA", "")
+		return sb
+	}
+	tests := []struct {
+		code     int
+		altstart bool
+		exp      []string
+	}{
+		{bacterial, false, []string{"LK*", "VKL", "XK*", "PL*"}},
+		{bacterial, true, []string{"MK*", "MKL", "MK*", "PL*"}},
+		// ATG is the only initiation codon of the Euplotid nuclear code
+		{euplotid, true, []string{"LK*", "VKL", "XK*", "PL*"}},
+	}
+	for _, tt := range tests {
+		sb := seqs()
+		stats, err := sb.TranslateIUPAC(0, tt.code, false, tt.altstart)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, exp := range tt.exp {
+			if s, _ := sb.GetSequenceById(i); s != exp {
+				t.Errorf("Wrong translation of s%d (code=%d, altstart=%v): expected %s, got %s", i+1, tt.code, tt.altstart, exp, s)
+			}
+		}
+		// KTG is either GTG or TTG: resolved only if both are start codons
+		if resolved := stats[2].Resolved == 1; resolved != (tt.code == bacterial && tt.altstart) {
+			t.Errorf("Wrong translation stats of s3 (code=%d, altstart=%v): %v", tt.code, tt.altstart, stats[2])
+		}
+	}
+}
+
+func Test_align_TranslateByReferenceIUPACAltStart(t *testing.T) {
+	bacterial, _ := GeneticCodeFromNCBI(11)
+	a := NewAlign(NUCLEOTIDS)
+	a.AddSequence("ref", "GTGAAA---TTGTAA", "")
+	a.AddSequence("s1", "TTGAAACCCTTGTAA", "")
+	a.AddSequence("s2", "---AAA---TTGTAA", "")
+	if _, err := a.TranslateByReferenceIUPAC(0, bacterial, "ref", false, true); err != nil {
+		t.Fatal(err)
+	}
+	for name, exp := range map[string]string{"ref": "MK-L*", "s1": "MKPL*", "s2": "-K-L*"} {
+		if s, _ := a.GetSequence(name); s != exp {
+			t.Errorf("Wrong translation of %s: expected %s, got %s", name, exp, s)
+		}
+	}
+}
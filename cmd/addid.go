@@ -3,6 +3,7 @@ package cmd
 import (
 	"github.com/evolbioinfo/goalign/align"
 	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/metadata"
 	"github.com/evolbioinfo/goalign/io/utils"
 	"github.com/spf13/cobra"
 )
@@ -18,9 +19,24 @@ var addidCmd = &cobra.Command{
 	Long: `This command adds an indentifier (string) to all sequences of an input alignment. 
 
 The string may be added to the left or to the right of each sequence name.
+
+If a metadata file is given with --metadata (tab separated, or comma separated
+if .csv, with a header line and one line per sequence), then the string is a
+template whose placeholders between braces are replaced by the values of the
+corresponding metadata columns, for example:
+
+goalign addid -i al.fa --metadata meta.tsv -n "{country}_{date}_" 
+
+Sequences that are not present in the metadata file are not renamed.
 `,
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		var f utils.StringWriterCloser
+		var md *metadata.Metadata
+
+		if md, err = readMetadata(); err != nil {
+			io.LogError(err)
+			return
+		}
 
 		if f, err = utils.OpenWriteFile(addIdOutput); err != nil {
 			io.LogError(err)
@@ -35,7 +51,10 @@ The string may be added to the left or to the right of each sequence name.
 				io.LogError(err)
 				return
 			}
-			seqs.AppendSeqIdentifier(addIdName, addIdRight)
+			if err = addIdentifier(seqs, md); err != nil {
+				io.LogError(err)
+				return
+			}
 			writeSequences(seqs, f)
 		} else {
 
@@ -45,7 +64,10 @@ The string may be added to the left or to the right of each sequence name.
 				return
 			}
 			for al := range aligns.Achan {
-				al.AppendSeqIdentifier(addIdName, addIdRight)
+				if err = addIdentifier(al, md); err != nil {
+					io.LogError(err)
+					return
+				}
 				writeAlign(al, f)
 			}
 
@@ -58,10 +80,30 @@ The string may be added to the left or to the right of each sequence name.
 	},
 }
 
+// addIdentifier adds the --name string to all sequence names. If metadata are given,
+// the string is rendered for each sequence using its metadata.
+func addIdentifier(sb align.SeqBag, md *metadata.Metadata) (err error) {
+	if md == nil {
+		sb.AppendSeqIdentifier(addIdName, addIdRight)
+		return
+	}
+	namemap := make(map[string]string)
+	template := addIdName + "{name}"
+	if addIdRight {
+		template = "{name}" + addIdName
+	}
+	if err = metadataRenameMap(md, template, sb, namemap); err != nil {
+		return
+	}
+	sb.Rename(namemap)
+	return
+}
+
 func init() {
 	RootCmd.AddCommand(addidCmd)
 	addidCmd.PersistentFlags().StringVarP(&addIdOutput, "out-align", "o", "stdout", "Renamed alignment output file")
 	addidCmd.PersistentFlags().StringVarP(&addIdName, "name", "n", "none", "String to add to sequence names")
 	addidCmd.PersistentFlags().BoolVarP(&addIdRight, "right", "r", false, "Adds the String on the right of sequence names (otherwise, adds to left)")
+	addMetadataFlags(addidCmd, false, false)
 	addidCmd.PersistentFlags().BoolVar(&unaligned, "unaligned", false, "Considers sequences as unaligned and format fasta (phylip, nexus,... options are ignored)")
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/evolbioinfo/goalign/align"
 	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/report"
 	"github.com/spf13/cobra"
 )
 
@@ -15,13 +16,29 @@ var allelesCmd = &cobra.Command{
 	Long:  `Prints the average number of alleles per sites of the alignment.`,
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		var aligns *align.AlignChannel
+		var rw *report.Writer
 
+		if rw, err = reportWriter("alleles"); err != nil {
+			io.LogError(err)
+			return
+		}
 		if aligns, err = readalign(infile); err != nil {
 			io.LogError(err)
 			return
 		}
+		nb := 0
 		for al := range aligns.Achan {
-			fmt.Println(al.AvgAllelesPerSite())
+			if rw == nil {
+				fmt.Println(al.AvgAllelesPerSite())
+			} else {
+				t := report.NewTable("avgalleles")
+				t.AddRow(al.AvgAllelesPerSite())
+				if err = rw.Write(nb, t); err != nil {
+					io.LogError(err)
+					return
+				}
+			}
+			nb++
 		}
 
 		if aligns.Err != nil {
@@ -3,6 +3,7 @@ package cmd
 import (
 	"github.com/evolbioinfo/goalign/align"
 	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/report"
 	"github.com/spf13/cobra"
 )
 
@@ -24,12 +25,18 @@ goalign stats char -i align.fasta
 `,
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		var aligns *align.AlignChannel
+		var rw *report.Writer
 
+		if rw, err = reportWriter("char"); err != nil {
+			io.LogError(err)
+			return
+		}
 		if aligns, err = readalign(infile); err != nil {
 			io.LogError(err)
 			return
 		}
 
+		nb := 0
 		for al := range aligns.Achan {
 			if aligns.Err != nil {
 				err = aligns.Err
@@ -37,12 +44,17 @@ goalign stats char -i align.fasta
 				return
 			}
 			if charstatpersites {
-				err = printSiteCharStats(al, charstatonly)
+				err = printSiteCharStats(al, charstatonly, rw, nb)
 			} else if charstatpersequences {
-				err = printSequenceCharStats(al, charstatonly)
+				err = printSequenceCharStats(al, charstatonly, rw, nb)
 			} else {
-				printCharStats(al, charstatonly)
+				err = printCharStats(al, charstatonly, rw, nb)
+			}
+			if err != nil {
+				io.LogError(err)
+				return
 			}
+			nb++
 		}
 		return
 	},
@@ -7,12 +7,18 @@ import (
 	"github.com/evolbioinfo/goalign/align"
 	"github.com/evolbioinfo/goalign/io"
 	"github.com/evolbioinfo/goalign/io/fasta"
+	"github.com/evolbioinfo/goalign/io/report"
 	"github.com/evolbioinfo/goalign/io/utils"
 	"github.com/spf13/cobra"
 )
 
 var codonAlignOutput string
 var nucleotideFasta string
+var codonAlignGeneticCode string
+var codonAlignDiagnostics string
+var codonAlignMaxMismatches int
+var codonAlignFixFrameshifts bool
+var codonAlignReverseTranslate bool
 
 // codonAlignCmd
 var codonAlignCmd = &cobra.Command{
@@ -29,6 +35,32 @@ nucleotide sequence where needed.
 
 Once gaps are added, if the nucleotide alignment length does not match 
 the protein alignment length * 3, returns an error.
+
+Additional stop codons at the end of nucleotide sequences are dropped. They
+are detected using the genetic code given with --genetic-code.
+
+If --diagnostics <file> is given, then each codon is checked against its
+residue, and the following differences are tolerated and reported in the
+given tab separated file:
+- terminal_stop: Terminal stop codon(s), removed;
+- trailing_nt: Additional nucleotides at the end of the sequence, removed;
+- partial_codon: Incomplete last codon, padded with N;
+- selenocysteine/pyrrolysine: U/O residue encoded by a stop codon;
+- alt_start: First M encoded by an alternative start codon;
+- mismatch/internal_stop/missing_codon: Codon not encoding its residue, stop
+  codon, or no more nucleotides (replaced by NNN). At most --max-mismatches
+  such mismatches per sequence are tolerated (-1: no limit), otherwise an
+  error is returned;
+- frameshift_insertion/frameshift_deletion: If --fix-frameshifts is given,
+  and shifting the frame by 1 or 2 nucleotides makes the next residues match,
+  additional nucleotides are removed, or missing nucleotides are replaced by N.
+X residues, B/Z/J and ambiguous codons match if at least one possibility is
+compatible. The diagnostic file has the columns: alignment, sequence, position
+(1-based position in the protein alignment), residue, codon, translation, type.
+
+If --reverse-translate is given, no nucleotide sequence is needed (-f is
+ignored): each residue is replaced by the degenerate IUPAC codon representing
+all its codons in the genetic code (ex: GGN for G, YTN for L, and NNN for X).
 `,
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		var aligns *align.AlignChannel
@@ -37,6 +69,14 @@ the protein alignment length * 3, returns an error.
 		var toclose goio.Closer
 		var ntseqs align.SeqBag
 		var codonAl align.Alignment
+		var geneticcode int
+		var issues []align.CodonAlignIssue
+		var rw *report.Writer
+
+		if geneticcode, err = parseGeneticCode(codonAlignGeneticCode); err != nil {
+			io.LogError(err)
+			return
+		}
 
 		// Read input aa alignment
 		if aligns, err = readalign(infile); err != nil {
@@ -44,6 +84,28 @@ the protein alignment length * 3, returns an error.
 			return
 		}
 
+		// Open output file
+		if f, err = utils.OpenWriteFile(codonAlignOutput); err != nil {
+			io.LogError(err)
+			return
+		}
+		defer utils.CloseWriteFile(f, codonAlignOutput)
+
+		if codonAlignReverseTranslate {
+			for al := range aligns.Achan {
+				if codonAl, err = al.ReverseTranslate(geneticcode); err != nil {
+					io.LogError(err)
+					return
+				}
+				writeAlign(codonAl, f)
+			}
+			if aligns.Err != nil {
+				err = aligns.Err
+				io.LogError(err)
+			}
+			return
+		}
+
 		// Read input fasta nt sequences
 		if toclose, ntseqsf, err = utils.GetReader(nucleotideFasta); err != nil {
 			io.LogError(err)
@@ -56,19 +118,37 @@ the protein alignment length * 3, returns an error.
 			return
 		}
 
-		// Open output file
-		if f, err = utils.OpenWriteFile(codonAlignOutput); err != nil {
-			io.LogError(err)
-			return
+		if codonAlignDiagnostics != "none" {
+			var fd utils.StringWriterCloser
+			if fd, err = utils.OpenWriteFile(codonAlignDiagnostics); err != nil {
+				io.LogError(err)
+				return
+			}
+			defer utils.CloseWriteFile(fd, codonAlignDiagnostics)
+			if rw, err = tableWriter(fd, "codonalign"); err != nil {
+				io.LogError(err)
+				return
+			}
 		}
-		defer utils.CloseWriteFile(f, codonAlignOutput)
 
+		nb := 0
 		for al := range aligns.Achan {
-			if codonAl, err = al.CodonAlign(ntseqs); err != nil {
+			if rw != nil {
+				codonAl, issues, err = al.CodonAlignDiagnostics(ntseqs, geneticcode, codonAlignMaxMismatches, codonAlignFixFrameshifts)
+				// Issues are written even if there are too many mismatches
+				if errw := writeCodonAlignIssues(rw, nb, issues); errw != nil {
+					io.LogError(errw)
+					return errw
+				}
+			} else {
+				codonAl, err = al.CodonAlign(ntseqs, geneticcode)
+			}
+			if err != nil {
 				io.LogError(err)
 				return
 			}
 			writeAlign(codonAl, f)
+			nb++
 		}
 
 		if aligns.Err != nil {
@@ -83,4 +163,29 @@ func init() {
 	RootCmd.AddCommand(codonAlignCmd)
 	codonAlignCmd.PersistentFlags().StringVarP(&codonAlignOutput, "output", "o", "stdout", "Output codon aligned file")
 	codonAlignCmd.PersistentFlags().StringVarP(&nucleotideFasta, "fasta", "f", "stdin", "Input nucleotide Fasta file to be codon aligned")
+	codonAlignCmd.PersistentFlags().StringVar(&codonAlignDiagnostics, "diagnostics", "none", "Checks the codons against the residues, tolerates small differences, and reports them in the given file")
+	codonAlignCmd.PersistentFlags().IntVar(&codonAlignMaxMismatches, "max-mismatches", 5, "Maximum number of mismatches per sequence (--diagnostics only, -1: no limit)")
+	codonAlignCmd.PersistentFlags().BoolVar(&codonAlignFixFrameshifts, "fix-frameshifts", false, "Fixes frameshifts by removing additional nucleotides or padding with N (--diagnostics only)")
+	codonAlignCmd.PersistentFlags().BoolVar(&codonAlignReverseTranslate, "reverse-translate", false, "Generates degenerate IUPAC codons from the protein alignment, without nucleotide sequences")
+	addGeneticCodeFlag(codonAlignCmd, &codonAlignGeneticCode)
+}
+
+// writeCodonAlignIssues writes the codon alignment diagnostics
+// of the alignment nb in the given report writer
+func writeCodonAlignIssues(rw *report.Writer, nb int, issues []align.CodonAlignIssue) (err error) {
+	t := report.NewTable("sequence", "position", "residue", "codon", "translation", "type")
+	for _, i := range issues {
+		var pos, res, tr interface{}
+		if i.Position >= 0 {
+			pos = i.Position + 1
+			res = i.Residue
+		}
+		if i.Translation != 0 {
+			tr = i.Translation
+		}
+		if err = t.AddRow(i.Name, pos, res, i.Codon, tr, i.Type); err != nil {
+			return
+		}
+	}
+	return rw.Write(nb, t)
 }
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/evolbioinfo/goalign/align"
+	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/metadata"
+	"github.com/evolbioinfo/goalign/io/report"
+	"github.com/evolbioinfo/goalign/io/utils"
+	"github.com/evolbioinfo/goalign/recomb"
+)
+
+var bootscanOutput string
+var bootscanQuery string
+var bootscanReferences string
+var bootscanModel string
+var bootscanWindowSize int
+var bootscanWindowStep int
+var bootscanReplicates int
+var bootscanBreakpoints string
+var bootscanPermutations int
+
+// computeBootscanCmd represents the compute bootscan command
+var computeBootscanCmd = &cobra.Command{
+	Use:   "bootscan",
+	Short: "Computes bootscan support of a query sequence along the alignment",
+	Long: `Computes bootscan support of a query sequence along the alignment.
+
+For each sliding window of the alignment (--window-size and --window-step),
+bootstrap replicates of the window are drawn (--replicates), and distances
+between the query (--query) and each reference are computed (--model, see
+goalign compute distance). For each reference, the bootscan support is the
+percentage of replicates in which the query clusters with the reference,
+i.e. in which the reference is the closest to the query (replicates in which
+several references are equally close are shared between them).
+
+References are all the other sequences, the sequences given with --references
+(comma separated), or groups of sequences defined by a tab separated file
+(--groups, sequence name<tab>group name), by a field of sequence names
+(--group-sep and --group-field), or by metadata columns (--metadata and
+--group-by). The distance between the query and a group is the minimum distance
+between the query and the sequences of the group.
+
+The output is a tab separated table (or json with --format json), with the
+columns: alignment (index of the input alignment), start and end (0-based,
+end excluded), reference, distance (on the original window), support (%).
+
+If --breakpoints <file> is given, then a recombination breakpoint is proposed
+for each pair of references with the maximum chi-square method (Maynard Smith
+1992): sites informative for the pair are the sites where the references
+(majority characters for groups) differ, and where the query has the character
+of one of them; the breakpoint maximizes the chi-square of the 2x2 table
+(before/after the breakpoint, query matching reference 1/reference 2). Its
+p-value is computed with --permutations permutations of the informative sites.
+The breakpoint file has the columns: alignment, reference1, reference2, sites
+(number of informative sites), position (0-based first site after the
+breakpoint, -1 if none), left1, left2, right1, right2 (number of sites matching
+reference 1/2 before/after the breakpoint), chi2, pvalue.
+
+Example:
+goalign compute bootscan -i al.fa --query recombinant --window-size 400 --window-step 40 --breakpoints bp.tsv
+`,
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		var aligns *align.AlignChannel
+		var f utils.StringWriterCloser
+		var rw, rwbp *report.Writer
+		var md *metadata.Metadata
+
+		if md, err = readMetadata(); err != nil {
+			io.LogError(err)
+			return
+		}
+		if f, err = utils.OpenWriteFile(bootscanOutput); err != nil {
+			io.LogError(err)
+			return
+		}
+		defer utils.CloseWriteFile(f, bootscanOutput)
+		if rw, err = tableWriter(f, "bootscan"); err != nil {
+			io.LogError(err)
+			return
+		}
+
+		if bootscanBreakpoints != "none" {
+			var fb utils.StringWriterCloser
+			if fb, err = utils.OpenWriteFile(bootscanBreakpoints); err != nil {
+				io.LogError(err)
+				return
+			}
+			defer utils.CloseWriteFile(fb, bootscanBreakpoints)
+			if rwbp, err = tableWriter(fb, "bootscan"); err != nil {
+				io.LogError(err)
+				return
+			}
+		}
+
+		if aligns, err = readalign(infile); err != nil {
+			io.LogError(err)
+			return
+		}
+		nb := 0
+		for al := range aligns.Achan {
+			var query int
+			var refs []recomb.Reference
+			var windows []recomb.BootscanWindow
+			var bps []recomb.Breakpoint
+
+			if query, refs, err = recombReferences(al, md, bootscanQuery, bootscanReferences); err != nil {
+				io.LogError(err)
+				return
+			}
+			if windows, err = recomb.Bootscan(al, query, refs, bootscanModel, bootscanWindowSize, bootscanWindowStep, bootscanReplicates, globalRand, rootcpus); err != nil {
+				io.LogError(err)
+				return
+			}
+			t := report.NewTable("start", "end", "reference", "distance", "support")
+			for _, w := range windows {
+				if err = t.AddRow(w.Start, w.End, w.Reference, w.Distance, w.Support); err != nil {
+					io.LogError(err)
+					return
+				}
+			}
+			if err = rw.Write(nb, t); err != nil {
+				io.LogError(err)
+				return
+			}
+
+			if rwbp != nil {
+				if bps, err = recomb.MaxChi(al, query, refs, bootscanPermutations, globalRand); err != nil {
+					io.LogError(err)
+					return
+				}
+				if err = writeBreakpoints(rwbp, nb, bps); err != nil {
+					io.LogError(err)
+					return
+				}
+			}
+			nb++
+		}
+		if aligns.Err != nil {
+			err = aligns.Err
+			io.LogError(err)
+		}
+		return
+	},
+}
+
+// recombReferences returns the index of the query sequence, and the references
+// defined by the group options, by the given comma separated list of sequence
+// names, or all the other sequences otherwise.
+func recombReferences(al align.Alignment, md *metadata.Metadata, queryname, references string) (query int, refs []recomb.Reference, err error) {
+	var groups map[string][]int
+	var keys []string
+
+	if queryname == "" {
+		err = fmt.Errorf("a query sequence must be given (--query)")
+		return
+	}
+	if query = al.GetSequenceIdByName(queryname); query < 0 {
+		err = fmt.Errorf("query sequence %s does not exist in the alignment", queryname)
+		return
+	}
+	if groups, keys, err = sequenceGroups(al, md); err != nil {
+		return
+	}
+	switch {
+	case groups != nil && references != "none":
+		err = fmt.Errorf("--references and group options are mutually exclusive")
+	case groups != nil:
+		for _, k := range keys {
+			var indices []int
+			for _, i := range groups[k] {
+				if i != query {
+					indices = append(indices, i)
+				}
+			}
+			if len(indices) > 0 {
+				refs = append(refs, recomb.Reference{Name: k, Indices: indices})
+			}
+		}
+	case references != "none":
+		for _, name := range strings.Split(references, ",") {
+			i := al.GetSequenceIdByName(name)
+			if i < 0 {
+				err = fmt.Errorf("reference sequence %s does not exist in the alignment", name)
+				return
+			}
+			refs = append(refs, recomb.Reference{Name: name, Indices: []int{i}})
+		}
+	default:
+		refs = recomb.SequenceReferences(al, query)
+	}
+	return
+}
+
+// writeBreakpoints writes the breakpoints of the alignment nb in the given report writer
+func writeBreakpoints(rw *report.Writer, nb int, bps []recomb.Breakpoint) (err error) {
+	t := report.NewTable("reference1", "reference2", "sites", "position", "left1", "left2", "right1", "right2", "chi2", "pvalue")
+	for _, b := range bps {
+		if err = t.AddRow(b.Reference1, b.Reference2, b.Sites, b.Position, b.Left1, b.Left2, b.Right1, b.Right2, b.ChiSquare, b.PValue); err != nil {
+			return
+		}
+	}
+	return rw.Write(nb, t)
+}
+
+func init() {
+	computeCmd.AddCommand(computeBootscanCmd)
+	computeBootscanCmd.PersistentFlags().StringVarP(&bootscanOutput, "output", "o", "stdout", "Output file")
+	computeBootscanCmd.PersistentFlags().StringVar(&bootscanQuery, "query", "", "Name of the query sequence")
+	computeBootscanCmd.PersistentFlags().StringVar(&bootscanReferences, "references", "none", "Comma separated list of reference sequences (none: all the other sequences)")
+	computeBootscanCmd.PersistentFlags().StringVarP(&bootscanModel, "model", "m", "k2p", "Model for distance computation")
+	computeBootscanCmd.PersistentFlags().IntVarP(&bootscanWindowSize, "window-size", "w", 200, "Window size")
+	computeBootscanCmd.PersistentFlags().IntVarP(&bootscanWindowStep, "window-step", "s", 20, "Window step")
+	computeBootscanCmd.PersistentFlags().IntVarP(&bootscanReplicates, "replicates", "b", 100, "Number of bootstrap replicates per window")
+	computeBootscanCmd.PersistentFlags().StringVar(&bootscanBreakpoints, "breakpoints", "none", "Output file of the breakpoints proposed by the maximum chi-square method")
+	computeBootscanCmd.PersistentFlags().IntVar(&bootscanPermutations, "permutations", 1000, "Number of permutations for the breakpoint p-values")
+	addGroupFlags(computeBootscanCmd)
+	addFormatFlag(computeBootscanCmd)
+}
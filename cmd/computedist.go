@@ -10,9 +10,11 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/evolbioinfo/goalign/align"
+	"github.com/evolbioinfo/goalign/distance"
 	"github.com/evolbioinfo/goalign/distance/dna"
 	"github.com/evolbioinfo/goalign/distance/protein"
 	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/distmatrix"
 	"github.com/evolbioinfo/goalign/io/utils"
 	pm "github.com/evolbioinfo/goalign/models/protein"
 
@@ -21,6 +23,7 @@ import (
 
 var computedistOutput string
 var computedistModel string
+var computedistModelFile string
 var computedistRemoveGaps bool
 var computedistAverage bool
 var computedistAlpha float64
@@ -28,6 +31,18 @@ var computedistCountGaps int
 var computedistRemoveAmbiguous bool
 var computedistRange1 string
 var computedistRange2 string
+var computedistOutputFormat string
+var computedistMaxDist float64
+var computedistQuery string
+var computedistKnn int
+
+// queryDistInit initializes the distance model on a reference alignment, and returns
+// the function computing the distances between a query sequence and all the references
+type queryDistInit func(ref align.Alignment) (distance.QueryDistFunc, error)
+
+// distMatrixFunc computes the distance matrix of an alignment, possibly
+// restricted to the given sequence ranges (-1 means no restriction)
+type distMatrixFunc func(al align.Alignment, range1Min, range1Max, range2Min, range2Max int) ([][]float64, error)
 
 // computedistCmd represents the computedist command
 var computedistCmd = &cobra.Command{
@@ -48,22 +63,57 @@ Nucleotides:
 - f81     : Felsenstein 81
 - f84     : Felsenstein 84
 - tn93    : Tamura and Nei 1993
+- t92     : Tamura 1992 (K2P with GC content of each pair of sequences)
+- logdet  : LogDet/paralinear (alias: paralinear)
+- ml-jc, ml-k2p, ml-f81, ml-f84, ml-tn93, ml-gtr: Maximum likelihood distances
+  under the given model (see below)
 Proteins:
 - DAYHOFF
 - JTT
 - MtRev 
 - LG
 - WAG
+- HIVb
+- AB
+- cpREV
+- rtREV
+- mtMAM
+- HIVw
+- FLU
+- Blosum62
+- VT
+- PMB
+
+Other protein models may be given in PAML format with --model-file (it overrides
+-m): the lower triangle of the exchangeability matrix (19 lines, line i having i
+values), followed by the 20 amino acid frequencies, amino acids being in the order
+A R N D C Q E G H I L K M F P S T W Y V (see the .dat files distributed with PAML).
 
 For nucleotides, differences are generally counted if nucleotides are incompatible.
 For example R and Y will give a difference; N and A will not give a difference.
 
-If distance is pdist (nucleotides), then giving the option --rm-ambiguous will not take into 
+If distance is pdist, t92 or logdet (nucleotides), then giving the option --rm-ambiguous will not take into 
 account ambiguous positions that are compatible in length normalization.
 For example if --rm-ambiguous is given, then R vs. Y will be taken into account
 because there is a difference. And N vs. A won't be taken into account in total length
 because we are not sure whether they are identical.
 
+t92 and logdet are robust to compositional heterogeneity between sequences.
+logdet does not assume stationary nucleotide frequencies. It is infinite (and
+replaced by 2 times the maximum distance, as other infinite distances) if the
+divergence matrix of two sequences is singular, for example if a nucleotide is
+absent from both sequences. With --alpha, logdet is computed with the gamma
+correction of Waddell and Steel (1997) applied on the symmetrized divergence
+matrix.
+
+ml-* models compute maximum likelihood distances. Model parameters (nucleotide
+frequencies and exchangeability rates) are estimated once from the whole
+alignment: rates are estimated from the divergence matrix of all pairs of
+sequences (GTR rates, from which kappa parameters of simpler models are
+derived). Then, the distance between two sequences is the branch length
+maximizing the likelihood of their divergence matrix, with gamma distributed
+rates if --alpha is given. Distances larger than 100 are considered infinite.
+
 For example:
 
 goalign compute distance -m k2p -i align.ph -p
@@ -77,12 +127,48 @@ will compute distance only between sequences [0 to 9] and sequences [10 to 19].
 Output matrix will be formatted the same way as usual, except that it will be made of 0 except for
 the comparisons 0 vs. 10; 0 .vs 11; ...; 9 vs. 19.
 
+Output format is given by --output-format:
+- phylip : square PHYLIP matrix (default)
+- lower  : lower triangular PHYLIP matrix
+- long   : one line per pair of sequences (seq1, seq2, distance), with only
+           the pairs having a distance <= --max-dist if given
+- npy    : NumPy array of float64 (rows and columns in the order of the alignment)
+- mega   : MEGA distance matrix (lower left)
+
+If --query is given, distances are computed between each sequence of the query file
+and each sequence of the input (reference) alignment, and the --knn nearest reference
+sequences of each query are written (columns query, reference, distance, rank), having
+a distance <= --max-dist if given. Query sequences must be aligned to the reference
+alignment. The model (sites removed with -r, frequencies, etc.) is initialized on the
+reference alignment only, and distances are computed one query at a time, so that the
+neighbors of a query do not depend on the other queries. For example:
+goalign compute distance -m k2p -i ref.fa --query query.fa --knn 5
+
 `,
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		var f utils.StringWriterCloser
 		var model dna.DistModel
-		var aligns *align.AlignChannel
+		var aligns, qaligns *align.AlignChannel
+		var queries align.Alignment
 		var protmodel int
+		var format int
+		var distfunc distMatrixFunc
+		var queryfunc queryDistInit
+
+		if format, err = distmatrix.ParseFormat(computedistOutputFormat); err != nil {
+			io.LogError(err)
+			return
+		}
+		if computedistMaxDist >= 0 && format != distmatrix.FORMAT_LONG && computedistQuery == "none" {
+			err = fmt.Errorf("--max-dist is only available with --output-format long or with --query")
+			io.LogError(err)
+			return
+		}
+		if computedistQuery != "none" && (computedistAverage || computedistRange1 != "" || computedistRange2 != "") {
+			err = fmt.Errorf("--query is not compatible with --average, --range1 and --range2")
+			io.LogError(err)
+			return
+		}
 
 		if f, err = utils.OpenWriteFile(computedistOutput); err != nil {
 			io.LogError(err)
@@ -95,31 +181,43 @@ the comparisons 0 vs. 10; 0 .vs 11; ...; 9 vs. 19.
 			return
 		}
 
+		if computedistQuery != "none" {
+			if qaligns, err = readalign(computedistQuery); err != nil {
+				io.LogError(err)
+				return
+			}
+			queries = <-qaligns.Achan
+			if qaligns.Err != nil {
+				err = qaligns.Err
+				io.LogError(err)
+				return
+			}
+			if queries == nil {
+				err = fmt.Errorf("no query sequence in %s", computedistQuery)
+				io.LogError(err)
+				return
+			}
+		}
+
 		// If prot model
-		if protmodel = pm.ModelStringToInt(computedistModel); protmodel != -1 {
-			var d *mat.Dense
-			m, _ := protein.NewProtDistModel(protmodel, true, cmd.Flags().Changed("alpha"), computedistAlpha, computedistRemoveGaps)
-			m.InitModel(nil, nil)
-			for align := range aligns.Achan {
-				if _, _, d, err = m.MLDist(align, nil); err != nil {
-					io.LogError(err)
+		if protmodel = pm.ModelStringToInt(computedistModel); protmodel != -1 || computedistModelFile != "none" {
+			var m *protein.ProtDistModel
+			if m, err = protDistModel(protmodel, computedistModelFile, cmd.Flags().Changed("alpha"), computedistAlpha, computedistRemoveGaps); err != nil {
+				io.LogError(err)
+				return
+			}
+			// Sequence ranges are not taken into account for proteins
+			distfunc = func(al align.Alignment, r1min, r1max, r2min, r2max int) (matrix [][]float64, err error) {
+				var d *mat.Dense
+				if _, _, d, err = m.MLDist(al, nil); err != nil {
 					return
 				}
-				if computedistAverage {
-					writeDistAverage(align, denseToSlice(d), f)
-				} else {
-					if err = writeDistMatrix(align, denseToSlice(d), f); err != nil {
-						io.LogError(err)
-						return
-					}
-				}
+				matrix = denseToSlice(d)
+				return
 			}
-
-			if aligns.Err != nil {
-				err = aligns.Err
-				io.LogError(err)
+			queryfunc = func(ref align.Alignment) (distance.QueryDistFunc, error) {
+				return m.QueryDistances(ref, nil)
 			}
-
 		} else {
 			switch computedistModel {
 			case "rawdist":
@@ -138,58 +236,79 @@ the comparisons 0 vs. 10; 0 .vs 11; ...; 9 vs. 19.
 					return
 				}
 				model = m
+			case "t92":
+				m := dna.NewT92Model(computedistRemoveGaps)
+				m.SetRemoveAmbiguous(computedistRemoveAmbiguous)
+				model = m
+			case "logdet", "paralinear":
+				m := dna.NewLogDetModel(computedistRemoveGaps)
+				m.SetRemoveAmbiguous(computedistRemoveAmbiguous)
+				model = m
 			default:
 				if model, err = dna.Model(computedistModel, computedistRemoveGaps); err != nil {
 					io.LogError(err)
 					return
 				}
 			}
+			distfunc = func(al align.Alignment, r1min, r1max, r2min, r2max int) (matrix [][]float64, err error) {
+				return dna.DistMatrix(al, nil, model, r1min, r1max, r2min, r2max, cmd.Flags().Changed("alpha"), computedistAlpha, rootcpus)
+			}
+			queryfunc = func(ref align.Alignment) (distance.QueryDistFunc, error) {
+				return dna.QueryDistances(ref, nil, model, cmd.Flags().Changed("alpha"), computedistAlpha, rootcpus)
+			}
+		}
 
-			var range1min, range1max, range2min, range2max int = -1, -1, -1, -1
+		var range1min, range1max, range2min, range2max int = -1, -1, -1, -1
 
-			if computedistRange1 != "" || computedistRange2 != "" {
-				r1 := strings.Split(computedistRange1, ":")
-				r2 := strings.Split(computedistRange2, ":")
-				if len(r1) != 2 || len(r2) != 2 {
-					return fmt.Errorf("sequence ranges are not well formed, should be min:max")
-				}
-				if range1min, err = strconv.Atoi(r1[0]); err != nil {
-					return fmt.Errorf("cannot convert range1 min to integer")
-				}
-				if range1max, err = strconv.Atoi(r1[1]); err != nil {
-					return fmt.Errorf("cannot convert range1 max to integer")
-				}
-				if range2min, err = strconv.Atoi(r2[0]); err != nil {
-					return fmt.Errorf("cannot convert range2 min to integer")
-				}
-				if range2max, err = strconv.Atoi(r2[1]); err != nil {
-					return fmt.Errorf("cannot convert range2 max to integer")
-				}
+		if computedistRange1 != "" || computedistRange2 != "" {
+			r1 := strings.Split(computedistRange1, ":")
+			r2 := strings.Split(computedistRange2, ":")
+			if len(r1) != 2 || len(r2) != 2 {
+				return fmt.Errorf("sequence ranges are not well formed, should be min:max")
+			}
+			if range1min, err = strconv.Atoi(r1[0]); err != nil {
+				return fmt.Errorf("cannot convert range1 min to integer")
+			}
+			if range1max, err = strconv.Atoi(r1[1]); err != nil {
+				return fmt.Errorf("cannot convert range1 max to integer")
 			}
+			if range2min, err = strconv.Atoi(r2[0]); err != nil {
+				return fmt.Errorf("cannot convert range2 min to integer")
+			}
+			if range2max, err = strconv.Atoi(r2[1]); err != nil {
+				return fmt.Errorf("cannot convert range2 max to integer")
+			}
+		}
 
-			for align := range aligns.Achan {
-				var distMatrix [][]float64
-				distMatrix, err = dna.DistMatrix(align, nil, model, range1min, range1max, range2min, range2max, cmd.Flags().Changed("alpha"), computedistAlpha, rootcpus)
-				if err != nil {
+		for align := range aligns.Achan {
+			if queries != nil {
+				if err = writeNearestNeighbors(align, queries, queryfunc, computedistKnn, computedistMaxDist, f); err != nil {
 					io.LogError(err)
 					return
 				}
-
-				if computedistAverage {
-					writeDistAverage(align, distMatrix, f)
-				} else {
-					if err = writeDistMatrix(align, distMatrix, f); err != nil {
-						io.LogError(err)
-						return
-					}
-				}
+				continue
 			}
 
-			if aligns.Err != nil {
-				err = aligns.Err
+			var distMatrix [][]float64
+			if distMatrix, err = distfunc(align, range1min, range1max, range2min, range2max); err != nil {
 				io.LogError(err)
+				return
+			}
+
+			if computedistAverage {
+				writeDistAverage(align, distMatrix, f)
+			} else {
+				if err = writeDistMatrixFormat(align, distMatrix, f, format, computedistMaxDist); err != nil {
+					io.LogError(err)
+					return
+				}
 			}
 		}
+
+		if aligns.Err != nil {
+			err = aligns.Err
+			io.LogError(err)
+		}
 		return
 	},
 }
@@ -198,27 +317,83 @@ func init() {
 	computeCmd.AddCommand(computedistCmd)
 	computedistCmd.PersistentFlags().StringVarP(&computedistOutput, "output", "o", "stdout", "Distance matrix output file")
 	computedistCmd.PersistentFlags().StringVarP(&computedistModel, "model", "m", "k2p", "Model for distance computation")
+	computedistCmd.PersistentFlags().StringVar(&computedistModelFile, "model-file", "none", "Protein model file in PAML format (exchangeabilities and frequencies), overrides -m")
 	computedistCmd.PersistentFlags().BoolVarP(&computedistRemoveGaps, "rm-gaps", "r", false, "Do not take into account positions containing >=1 gaps")
 	computedistCmd.PersistentFlags().IntVar(&computedistCountGaps, "gap-mut", 0, "Count gaps to nt as mutations: 0: inactivated, 1: only internal gaps, 2: all gaps. Only available for rawdist and pdist (nt)")
-	computedistCmd.PersistentFlags().BoolVar(&computedistRemoveAmbiguous, "rm-ambiguous", false, "if true, ambiguous positions are removed for the normalisation by the length in case of non different positions. Only available for pdist (nt)")
+	computedistCmd.PersistentFlags().BoolVar(&computedistRemoveAmbiguous, "rm-ambiguous", false, "if true, ambiguous positions are removed for the normalisation by the length in case of non different positions. Only available for pdist, t92 and logdet (nt)")
 	computedistCmd.PersistentFlags().BoolVarP(&computedistAverage, "average", "a", false, "Compute only the average distance between all pairs of sequences")
 	computedistCmd.PersistentFlags().Float64Var(&computedistAlpha, "alpha", 0.0, "Gamma alpha parameter, if not given : no gamma")
 	computedistCmd.PersistentFlags().StringVar(&computedistRange1, "range1", "", "If set, then will restrict distance computation to the given seq range compared to range 2 (0-based, ex --range1 0:100 means [0,100]), only for nucleotide models so far")
+	computedistCmd.PersistentFlags().StringVar(&computedistOutputFormat, "output-format", "phylip", "Distance matrix output format: phylip (square matrix), lower (lower triangular phylip matrix), long (one line per pair: seq1, seq2, distance), npy (NumPy float64 array) or mega")
+	computedistCmd.PersistentFlags().Float64Var(&computedistMaxDist, "max-dist", -1, "If >= 0, only pairs with a distance <= max-dist are written (only with --output-format long, or --query)")
+	computedistCmd.PersistentFlags().StringVar(&computedistQuery, "query", "none", "Query sequence file: computes distances between each query sequence and each sequence of the input (reference) alignment, and writes the nearest neighbors of each query (same format as the input alignment)")
+	computedistCmd.PersistentFlags().IntVar(&computedistKnn, "knn", 1, "Number of nearest neighbors to write for each query sequence (with --query), <=0 means all")
 	computedistCmd.PersistentFlags().StringVar(&computedistRange2, "range2", "", "If set, then will restrict distance computation to the given seq range compared to range 1 (0-based, ex --range2 0:100 means [0:100]), only for nucleotide models so far")
 }
 
+// protDistModel returns the protein distance model given by its code (see
+// models/protein.ModelStringToInt), or read from the given PAML format file
+// if file is not "none", and initializes it with model frequencies
+func protDistModel(model int, file string, usegamma bool, alpha float64, removegaps bool) (m *protein.ProtDistModel, err error) {
+	if file != "none" {
+		m, err = protein.NewProtDistModelFromFile(file, true, usegamma, alpha, removegaps)
+	} else {
+		m, err = protein.NewProtDistModel(model, true, usegamma, alpha, removegaps)
+	}
+	if err != nil {
+		return
+	}
+	err = m.InitModel(nil, nil)
+	return
+}
+
 func writeDistMatrix(al align.Alignment, matrix [][]float64, f utils.StringWriterCloser) (err error) {
-	f.WriteString(fmt.Sprintf("%d\n", len(matrix)))
-	for i := 0; i < len(matrix); i++ {
-		if name, ok := al.GetSequenceNameById(i); ok {
-			f.WriteString(name)
-		} else {
+	return writeDistMatrixFormat(al, matrix, f, distmatrix.FORMAT_PHYLIP, -1)
+}
+
+// writeDistMatrixFormat writes the distance matrix in the given format (see io/distmatrix),
+// with only the pairs having a distance <= maxdist if maxdist >= 0
+func writeDistMatrixFormat(al align.SeqBag, matrix [][]float64, f utils.StringWriterCloser, format int, maxdist float64) (err error) {
+	names := make([]string, len(matrix))
+	for i := range matrix {
+		var ok bool
+		if names[i], ok = al.GetSequenceNameById(i); !ok {
 			return fmt.Errorf("sequence %d does not exist in the alignment", i)
 		}
-		for j := 0; j < len(matrix); j++ {
-			f.WriteString(fmt.Sprintf("\t%.12f", matrix[i][j]))
+	}
+	return distmatrix.Write(f, names, matrix, format, maxdist)
+}
+
+// writeNearestNeighbors computes the distances between each query sequence and each
+// sequence of the reference alignment, and writes the knn nearest references of each
+// query (all references if knn <= 0), having a distance <= maxdist if maxdist >= 0.
+// The model is initialized on the reference alignment only, and distances are computed
+// one query at a time, so that the neighbors of a query do not depend on the other queries.
+// Output columns are: query, reference, distance, rank.
+func writeNearestNeighbors(ref, queries align.Alignment, queryfunc queryDistInit, knn int, maxdist float64, f utils.StringWriterCloser) (err error) {
+	var dists distance.QueryDistFunc
+	var neighbors [][]distance.Neighbor
+
+	if queries.Alphabet() != ref.Alphabet() {
+		return fmt.Errorf("query and reference sequences do not have the same alphabet")
+	}
+	if queries.Length() != ref.Length() {
+		return fmt.Errorf("query and reference sequences must be aligned together (query length: %d, reference length: %d)", queries.Length(), ref.Length())
+	}
+	if dists, err = queryfunc(ref); err != nil {
+		return
+	}
+	if neighbors, err = distance.NearestNeighbors(queries, dists, knn, maxdist); err != nil {
+		return
+	}
+
+	f.WriteString("query\treference\tdistance\trank\n")
+	for q, qneighbors := range neighbors {
+		qname, _ := queries.GetSequenceNameById(q)
+		for rank, n := range qneighbors {
+			rname, _ := ref.GetSequenceNameById(n.Reference)
+			f.WriteString(fmt.Sprintf("%s\t%s\t%.12f\t%d\n", qname, rname, n.Distance, rank+1))
 		}
-		f.WriteString("\n")
 	}
 	return
 }
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/evolbioinfo/goalign/align"
+	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/report"
+	"github.com/evolbioinfo/goalign/io/utils"
+)
+
+var dndsOutput string
+var dndsSitesOutput string
+var dndsMethod string
+var dndsGeneticCode string
+var dndsCorrection string
+var dndsMatrixValue string
+var dndsLong bool
+
+// computeDnDsCmd represents the compute dnds command
+var computeDnDsCmd = &cobra.Command{
+	Use:   "dnds",
+	Short: "Computes pairwise dN/dS from a codon alignment",
+	Long: `Computes pairwise dN/dS from a codon alignment.
+
+The input alignment must be a nucleotide alignment, in frame, whose length is
+a multiple of 3 (e.g. given by goalign codonalign). Codons containing gaps or
+ambiguous nucleotides, and stop codons, are ignored (pairwise deletion).
+
+For each pair of sequences, it computes the numbers of synonymous (S) and
+non-synonymous (N) sites, of synonymous (Sd) and non-synonymous (Nd) differences,
+their proportions pS=Sd/S and pN=Nd/N, and the synonymous (dS) and non-synonymous (dN)
+substitution rates, using one of the following methods (--method):
+- ng86: Nei & Gojobori (1986): synonymous and non-synonymous sites are counted for
+  each codon (changes to stop codons are not counted), and differences are averaged
+  over evolutionary pathways between codons (excluding pathways through stop codons).
+  With --correction jc (default), dS and dN are corrected with the Jukes-Cantor
+  formula;
+- lwl85: Li, Wu & Luo (1985): sites are classified as 0-fold, 2-fold, and 4-fold
+  degenerate, and differences as transitions or transversions. With --correction jc
+  (default), dS and dN are computed using the Kimura 2-parameters formulas of LWL85.
+With --correction none, dS=pS and dN=pN.
+
+Genetic code is given with --genetic-code: standard, mitoi (invertebrate
+mitochondrial), mitov (vertebrate mitochondrial), or an NCBI translation table
+number (see goalign translate).
+
+By default, the output is a matrix (same format as goalign compute distance) of
+dN/dS (or dN or dS with --matrix dn or --matrix ds). If --long is given, the
+output is a tab separated table (or json with --format json) with one line per
+pair of sequences and the columns: alignment, seq1, seq2, codons, S, N, Sd, Nd,
+pS, pN, dS, dN, dNdS.
+
+If --sites-output is given, per codon site statistics are written in the given
+file (tab separated, or json with --format json), with the columns: alignment,
+codon (0-based index of the codon), position (0-based position of its first
+nucleotide), ncodons (number of valid codons), S and N (average numbers of
+synonymous and non-synonymous sites of valid codons, ng86), Sd and Nd (average
+numbers of synonymous and non-synonymous differences between pairs of valid codons).
+
+If the input alignment contains several alignments, will process all of them.
+
+Example:
+goalign codonalign -i prot.fa -f nt.fa | goalign compute dnds --method ng86 --long
+`,
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		var aligns *align.AlignChannel
+		var f, fsites utils.StringWriterCloser
+		var rw, rwsites *report.Writer
+		var method, geneticcode int
+		var correction bool
+		var dnds [][]align.DnDs
+		var sites []align.DnDsSite
+
+		switch dndsMethod {
+		case "ng86":
+			method = align.DNDS_NG86
+		case "lwl85":
+			method = align.DNDS_LWL85
+		default:
+			err = fmt.Errorf("unknown dN/dS method: %s", dndsMethod)
+			io.LogError(err)
+			return
+		}
+
+		if geneticcode, err = parseGeneticCode(dndsGeneticCode); err != nil {
+			io.LogError(err)
+			return
+		}
+
+		switch dndsCorrection {
+		case "jc":
+			correction = true
+		case "none":
+			correction = false
+		default:
+			err = fmt.Errorf("unknown correction: %s (should be jc or none)", dndsCorrection)
+			io.LogError(err)
+			return
+		}
+
+		if dndsMatrixValue != "dnds" && dndsMatrixValue != "dn" && dndsMatrixValue != "ds" {
+			err = fmt.Errorf("unknown matrix value: %s (should be dnds, dn or ds)", dndsMatrixValue)
+			io.LogError(err)
+			return
+		}
+
+		if f, err = utils.OpenWriteFile(dndsOutput); err != nil {
+			io.LogError(err)
+			return
+		}
+		defer utils.CloseWriteFile(f, dndsOutput)
+
+		if dndsLong {
+			if rw, err = tableWriter(f, "dnds"); err != nil {
+				io.LogError(err)
+				return
+			}
+		}
+
+		if dndsSitesOutput != "none" {
+			if fsites, err = utils.OpenWriteFile(dndsSitesOutput); err != nil {
+				io.LogError(err)
+				return
+			}
+			defer utils.CloseWriteFile(fsites, dndsSitesOutput)
+			if rwsites, err = tableWriter(fsites, "dnds-sites"); err != nil {
+				io.LogError(err)
+				return
+			}
+		}
+
+		if aligns, err = readalign(infile); err != nil {
+			io.LogError(err)
+			return
+		}
+
+		nb := 0
+		for al := range aligns.Achan {
+			if dnds, err = align.PairwiseDnDs(al, method, geneticcode, correction); err != nil {
+				io.LogError(err)
+				return
+			}
+			if dndsLong {
+				err = rw.Write(nb, dndsTable(al, dnds))
+			} else {
+				err = writeDistMatrix(al, dndsMatrix(dnds, dndsMatrixValue), f)
+			}
+			if err != nil {
+				io.LogError(err)
+				return
+			}
+
+			if rwsites != nil {
+				if sites, err = align.SiteDnDs(al, geneticcode); err != nil {
+					io.LogError(err)
+					return
+				}
+				t := report.NewTable("codon", "position", "ncodons", "S", "N", "Sd", "Nd")
+				for i, s := range sites {
+					t.AddRow(i, 3*i, s.Codons, s.S, s.N, s.Sd, s.Nd)
+				}
+				if err = rwsites.Write(nb, t); err != nil {
+					io.LogError(err)
+					return
+				}
+			}
+			nb++
+		}
+
+		if aligns.Err != nil {
+			err = aligns.Err
+			io.LogError(err)
+		}
+		return
+	},
+}
+
+// dndsMatrix extracts the given value (dnds, dn or ds) from the dN/dS matrix.
+// Diagonal values are 0.
+func dndsMatrix(dnds [][]align.DnDs, value string) (matrix [][]float64) {
+	matrix = make([][]float64, len(dnds))
+	for i := range dnds {
+		matrix[i] = make([]float64, len(dnds))
+		for j := range dnds {
+			if i == j {
+				continue
+			}
+			switch value {
+			case "dn":
+				matrix[i][j] = dnds[i][j].DN
+			case "ds":
+				matrix[i][j] = dnds[i][j].DS
+			default:
+				matrix[i][j] = dnds[i][j].DnDsRatio()
+			}
+		}
+	}
+	return
+}
+
+// dndsTable returns the dN/dS statistics of each pair of sequences
+func dndsTable(al align.Alignment, dnds [][]align.DnDs) (t *report.Table) {
+	t = report.NewTable("seq1", "seq2", "codons", "S", "N", "Sd", "Nd", "pS", "pN", "dS", "dN", "dNdS")
+	for i := range dnds {
+		n1, _ := al.GetSequenceNameById(i)
+		for j := i + 1; j < len(dnds); j++ {
+			n2, _ := al.GetSequenceNameById(j)
+			d := dnds[i][j]
+			t.AddRow(n1, n2, d.Codons, d.S, d.N, d.Sd, d.Nd, d.PS, d.PN, d.DS, d.DN, d.DnDsRatio())
+		}
+	}
+	return
+}
+
+func init() {
+	computeCmd.AddCommand(computeDnDsCmd)
+	computeDnDsCmd.PersistentFlags().StringVarP(&dndsOutput, "output", "o", "stdout", "dN/dS matrix or table output file")
+	computeDnDsCmd.PersistentFlags().StringVar(&dndsSitesOutput, "sites-output", "none", "Per codon site statistics output file")
+	computeDnDsCmd.PersistentFlags().StringVarP(&dndsMethod, "method", "m", "ng86", "dN/dS method: ng86 (Nei & Gojobori 1986) or lwl85 (Li, Wu & Luo 1985)")
+	addGeneticCodeFlag(computeDnDsCmd, &dndsGeneticCode)
+	computeDnDsCmd.PersistentFlags().StringVar(&dndsCorrection, "correction", "jc", "Correction of multiple substitutions: jc (Jukes-Cantor for ng86, Kimura for lwl85) or none")
+	computeDnDsCmd.PersistentFlags().StringVar(&dndsMatrixValue, "matrix", "dnds", "Value of the output matrix: dnds, dn or ds (ignored with --long)")
+	computeDnDsCmd.PersistentFlags().BoolVar(&dndsLong, "long", false, "Outputs a table with one line per pair of sequences, instead of a matrix")
+	addFormatFlag(computeDnDsCmd)
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/evolbioinfo/goalign/align"
+	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/metadata"
+	"github.com/evolbioinfo/goalign/io/report"
+	"github.com/evolbioinfo/goalign/io/utils"
+	"github.com/evolbioinfo/goalign/popgen"
+)
+
+var dstatOutput string
+var dstatP1, dstatP2, dstatP3, dstatOutgroup string
+var dstatBlocks int
+
+// computeDstatCmd represents the compute dstat command
+var computeDstatCmd = &cobra.Command{
+	Use:   "dstat",
+	Short: "Computes ABBA-BABA D-statistics and f4-ratio for introgression testing",
+	Long: `Computes ABBA-BABA D-statistics and f4-ratio for introgression testing.
+
+Given populations P1, P2, P3 and an outgroup O with topology (((P1,P2),P3),O),
+it tests for introgression between P3 and P1 or P2 (Green et al. 2010, Durand et
+al. 2011). Each population is given either as a comma separated list of sequence
+names, or as a group name if groups are given (--groups, --group-sep/--group-field,
+or --group-by with --metadata).
+
+At each biallelic site (exactly two unambiguous characters over the four
+populations) having data in each population, the ancestral allele is the major
+allele of the outgroup, and p1, p2, p3 and p4 are the frequencies of the derived
+allele in each population (0 or 1 for single sequences), computed on sequences
+without missing data (gaps and ambiguous characters). The site contributes
+(1-p1)p2p3(1-p4) to ABBA, and p1(1-p2)p3(1-p4) to BABA.
+- Patterson's D is (ABBA-BABA)/(ABBA+BABA): a positive D means an excess of
+  shared derived alleles between P2 and P3, a negative D between P1 and P3;
+- The f4-ratio (Patterson et al. 2012) estimates the admixture proportion from
+  P3 into P2, by splitting P3 into two halves P3a and P3b (in the order of the
+  sequences in the alignment): S(P1,P2,P3a,O)/S(P1,P3b,P3a,O), S being ABBA-BABA.
+  It is NaN if P3 has only one sequence.
+
+Standard errors are computed with a delete-one block jackknife: the alignment is
+split into --blocks contiguous blocks of sites of equal length, and statistics
+are computed removing each block in turn. The p-value is the two-sided p-value
+of the Z-score of D (D/SE) under a normal distribution.
+
+The output is a tab separated table (or json with --format json), with the
+columns: alignment (index of the input alignment), p1, p2, p3, outgroup, sites
+(number of biallelic sites used), abba, baba, d, d_se, z, pvalue, f4ratio,
+f4ratio_se, blocks (number of non empty jackknife blocks).
+
+Example:
+goalign compute dstat -i al.fa --group-sep "_" --group-field 2 --p1 A --p2 B --p3 C --outgroup O
+`,
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		var aligns *align.AlignChannel
+		var f utils.StringWriterCloser
+		var rw *report.Writer
+		var md *metadata.Metadata
+
+		if md, err = readMetadata(); err != nil {
+			io.LogError(err)
+			return
+		}
+		if f, err = utils.OpenWriteFile(dstatOutput); err != nil {
+			io.LogError(err)
+			return
+		}
+		defer utils.CloseWriteFile(f, dstatOutput)
+		if rw, err = tableWriter(f, "dstat"); err != nil {
+			io.LogError(err)
+			return
+		}
+
+		if aligns, err = readalign(infile); err != nil {
+			io.LogError(err)
+			return
+		}
+		nb := 0
+		for al := range aligns.Achan {
+			var groups map[string][]int
+			var pops [4][]int
+			var ds popgen.DStatistics
+
+			if groups, _, err = sequenceGroups(al, md); err != nil {
+				io.LogError(err)
+				return
+			}
+			for i, p := range []string{dstatP1, dstatP2, dstatP3, dstatOutgroup} {
+				if pops[i], err = dstatPopulation(al, groups, p); err != nil {
+					io.LogError(err)
+					return
+				}
+			}
+			if ds, err = popgen.ComputeDStatistics(al, pops[0], pops[1], pops[2], pops[3], dstatBlocks); err != nil {
+				io.LogError(err)
+				return
+			}
+			t := report.NewTable("p1", "p2", "p3", "outgroup", "sites", "abba", "baba", "d", "d_se", "z", "pvalue", "f4ratio", "f4ratio_se", "blocks")
+			if err = t.AddRow(dstatP1, dstatP2, dstatP3, dstatOutgroup, ds.Sites, ds.ABBA, ds.BABA, ds.D, ds.DStdErr, ds.DZ, ds.DPValue, ds.F4Ratio, ds.F4RatioStdErr, ds.Blocks); err != nil {
+				io.LogError(err)
+				return
+			}
+			if err = rw.Write(nb, t); err != nil {
+				io.LogError(err)
+				return
+			}
+			nb++
+		}
+		if aligns.Err != nil {
+			err = aligns.Err
+			io.LogError(err)
+		}
+		return
+	},
+}
+
+// dstatPopulation returns the indices of the sequences of the given population:
+// the sequences of the group if groups are given, or the comma separated list
+// of sequence names otherwise
+func dstatPopulation(al align.Alignment, groups map[string][]int, pop string) (indices []int, err error) {
+	if pop == "none" {
+		err = fmt.Errorf("--p1, --p2, --p3 and --outgroup must be given")
+		return
+	}
+	if groups != nil {
+		var ok bool
+		if indices, ok = groups[pop]; !ok {
+			err = fmt.Errorf("group %s does not exist", pop)
+		}
+		return
+	}
+	for _, name := range strings.Split(pop, ",") {
+		i := al.GetSequenceIdByName(name)
+		if i < 0 {
+			err = fmt.Errorf("sequence %s does not exist in the alignment", name)
+			return
+		}
+		indices = append(indices, i)
+	}
+	return
+}
+
+func init() {
+	computeCmd.AddCommand(computeDstatCmd)
+	computeDstatCmd.PersistentFlags().StringVarP(&dstatOutput, "output", "o", "stdout", "Output file")
+	computeDstatCmd.PersistentFlags().StringVar(&dstatP1, "p1", "none", "Population P1: group name, or comma separated sequence names")
+	computeDstatCmd.PersistentFlags().StringVar(&dstatP2, "p2", "none", "Population P2: group name, or comma separated sequence names")
+	computeDstatCmd.PersistentFlags().StringVar(&dstatP3, "p3", "none", "Population P3: group name, or comma separated sequence names")
+	computeDstatCmd.PersistentFlags().StringVar(&dstatOutgroup, "outgroup", "none", "Outgroup population: group name, or comma separated sequence names")
+	computeDstatCmd.PersistentFlags().IntVar(&dstatBlocks, "blocks", 20, "Number of contiguous site blocks for the jackknife")
+	addFormatFlag(computeDstatCmd)
+	addGroupFlags(computeDstatCmd)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/evolbioinfo/goalign/align"
 	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/report"
 	"github.com/spf13/cobra"
 )
 
@@ -41,21 +42,34 @@ and it will not be taken into account in the average.
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		var aligns *align.AlignChannel
 		var e float64
+		var rw *report.Writer
+		var t *report.Table
 
+		if rw, err = reportWriter("entropy"); err != nil {
+			io.LogError(err)
+			return
+		}
 		if aligns, err = readalign(infile); err != nil {
 			io.LogError(err)
 			return
 		}
 
 		nb := 0
-		if entropyAverage {
-			fmt.Println("Alignment\tAvgEntropy")
-		} else {
-			fmt.Println("Alignment\tSite\tEntropy")
+		if rw == nil {
+			if entropyAverage {
+				fmt.Println("Alignment\tAvgEntropy")
+			} else {
+				fmt.Println("Alignment\tSite\tEntropy")
+			}
 		}
 		for align := range aligns.Achan {
 			avg := 0.0
 			total := 0
+			if entropyAverage {
+				t = report.NewTable("avgentropy")
+			} else {
+				t = report.NewTable("site", "entropy")
+			}
 			for i := 0; i < align.Length(); i++ {
 				if e, err = align.Entropy(i, entropyRemoveGaps); err != nil {
 					io.LogError(err)
@@ -66,14 +80,24 @@ and it will not be taken into account in the average.
 							avg += e
 							total++
 						}
+					} else if rw != nil {
+						t.AddRow(i, e)
 					} else {
 						fmt.Printf("%d\t%d\t%.3f\n", nb, i, e)
 					}
 				}
 			}
-			if entropyAverage {
+			if entropyAverage && rw != nil {
+				t.AddRow(avg / float64(total))
+			} else if entropyAverage {
 				fmt.Printf("%d\t%.3f\n", nb, avg/float64(total))
 			}
+			if rw != nil {
+				if err = rw.Write(nb, t); err != nil {
+					io.LogError(err)
+					return
+				}
+			}
 			nb++
 		}
 
@@ -87,6 +111,7 @@ and it will not be taken into account in the average.
 
 func init() {
 	computeCmd.AddCommand(entropyCmd)
+	addFormatFlag(entropyCmd)
 	entropyCmd.PersistentFlags().BoolVarP(&entropyAverage, "average", "a", false, "Compute only the average entropy of input alignment")
 	entropyCmd.PersistentFlags().BoolVarP(&entropyRemoveGaps, "remove-gaps", "g", false, "If true, then do not take into account gaps in the computation")
 }
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/evolbioinfo/goalign/align"
+	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/metadata"
+	"github.com/evolbioinfo/goalign/io/report"
+	"github.com/evolbioinfo/goalign/io/utils"
+	"github.com/evolbioinfo/goalign/popgen"
+)
+
+var fstOutput string
+var fstMaxMissing float64
+
+// computeFstCmd represents the compute fst command
+var computeFstCmd = &cobra.Command{
+	Use:   "fst",
+	Short: "Computes differentiation statistics between groups of sequences",
+	Long: `Computes differentiation statistics between groups of sequences.
+
+Groups of sequences are defined by a tab separated file (--groups, with sequence 
+names in the first column and group names in the second one), by a field of 
+sequence names (--group-sep and --group-field), or by metadata columns 
+(--metadata and --group-by). In the latter case, sequences may be filtered
+with --where. Sequences that are not assigned to any group are ignored.
+
+For each pair of groups, it computes:
+- n1, n2: Number of sequences in each group;
+- sites: Number of analyzed sites;
+- pi1, pi2: Nucleotide diversity within each group;
+- dxy: Average number of differences between sequences of the two groups;
+- da: Net divergence: dxy - (pi1+pi2)/2;
+- fst: Hudson's Fst: da/dxy (Hudson et al. 1992, ratio of averages over sites 
+  as recommended by Bhatia et al. 2013);
+- fixed: Number of fixed differences (sites monomorphic in both groups, with
+  different characters);
+- shared: Number of shared polymorphisms (sites polymorphic in both groups);
+- private1, private2: Number of private polymorphisms (sites polymorphic in 
+  one group only).
+
+Only unambiguous characters are considered, gaps and ambiguous characters being
+missing data (see goalign compute popgen): sites having, in any group, a proportion
+of missing data > --max-missing are not analyzed.
+
+Statistics are computed on the whole alignment, on each partition defined in the 
+file given with --partition, or on sliding windows with --window-size and 
+--window-step.
+
+The output is a tab separated table (or json with --format json), whose first
+columns are: alignment (index of the input alignment), group1, group2, region
+(partition name, "window" or "all"), start and end (0-based, end excluded,
+empty for partitions).
+
+Example:
+goalign compute fst -i al.fa --groups groups.tsv --window-size 1000 --window-step 500
+`,
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		var aligns *align.AlignChannel
+		var f utils.StringWriterCloser
+		var rw *report.Writer
+		var md *metadata.Metadata
+		var t *report.Table
+
+		if md, err = readMetadata(); err != nil {
+			io.LogError(err)
+			return
+		}
+		if f, err = utils.OpenWriteFile(fstOutput); err != nil {
+			io.LogError(err)
+			return
+		}
+		defer utils.CloseWriteFile(f, fstOutput)
+
+		if rw, err = tableWriter(f, "fst"); err != nil {
+			io.LogError(err)
+			return
+		}
+
+		if aligns, err = readalign(infile); err != nil {
+			io.LogError(err)
+			return
+		}
+		nb := 0
+		for al := range aligns.Achan {
+			if t, err = fstStats(al, md); err != nil {
+				io.LogError(err)
+				return
+			}
+			if err = rw.Write(nb, t); err != nil {
+				io.LogError(err)
+				return
+			}
+			nb++
+		}
+		if aligns.Err != nil {
+			err = aligns.Err
+			io.LogError(err)
+		}
+		return
+	},
+}
+
+// fstStats computes differentiation statistics between each pair
+// of groups, on each region of the alignment
+func fstStats(al align.Alignment, md *metadata.Metadata) (t *report.Table, err error) {
+	var groups map[string][]int
+	var keys []string
+	var regions []alignRegion
+	var d popgen.Differentiation
+
+	if groups, keys, err = sequenceGroups(al, md); err != nil {
+		return
+	}
+	if len(keys) < 2 {
+		err = errors.New("at least 2 groups of sequences must be defined (--groups, --group-sep or --group-by)")
+		return
+	}
+	if regions, err = alignRegions(al); err != nil {
+		return
+	}
+
+	t = report.NewTable("group1", "group2", "region", "start", "end", "n1", "n2", "sites",
+		"pi1", "pi2", "dxy", "da", "fst", "fixed", "shared", "private1", "private2")
+	for i, g1 := range keys {
+		for _, g2 := range keys[i+1:] {
+			for _, r := range regions {
+				if d, err = popgen.ComputeDifferentiation(r.al, groups[g1], groups[g2], fstMaxMissing); err != nil {
+					return
+				}
+				if err = t.AddRow(g1, g2, r.name, r.start, r.end, d.NbSeqs1, d.NbSeqs2, d.Sites,
+					d.Pi1, d.Pi2, d.Dxy, d.Da, d.Fst, d.Fixed, d.Shared, d.Private1, d.Private2); err != nil {
+					return
+				}
+			}
+		}
+	}
+	return
+}
+
+func init() {
+	computeCmd.AddCommand(computeFstCmd)
+	computeFstCmd.PersistentFlags().StringVarP(&fstOutput, "output", "o", "stdout", "Output file")
+	computeFstCmd.PersistentFlags().Float64Var(&fstMaxMissing, "max-missing", 0.0, "Maximum proportion of missing data (gaps, ambiguities) in each group at a site for it to be analyzed")
+	addRegionFlags(computeFstCmd)
+	addGroupFlags(computeFstCmd)
+	addFormatFlag(computeFstCmd)
+}
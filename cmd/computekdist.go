@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/evolbioinfo/goalign/align"
+	"github.com/evolbioinfo/goalign/distance/kmer"
+	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/distmatrix"
+	"github.com/evolbioinfo/goalign/io/utils"
+)
+
+var kdistOutput string
+var kdistMethod string
+var kdistK int
+var kdistSketchSize int
+var kdistCanonical bool
+var kdistOutputFormat string
+var kdistMaxDist float64
+
+// computeKDistCmd represents the compute kdist command
+var computeKDistCmd = &cobra.Command{
+	Use:   "kdist",
+	Short: "Computes alignment-free k-mer distances between sequences",
+	Long: `Computes alignment-free k-mer distances between sequences.
+
+Sequences may be unaligned (--unaligned option). Gaps are removed from sequences
+before k-mer extraction, and k-mers containing characters other than A, C, G, T/U
+(nucleotides) or the 20 standard amino acids (proteins) are ignored.
+
+Available distances (-m):
+- jaccard  : 1 - |A inter B|/|A union B|, A and B being the sets of k-mers of the
+             two sequences
+- mash     : Mash distance (Ondov et al. 2016): -1/k ln(2j/(1+j)), j being the Jaccard
+             index estimated from bottom MinHash sketches of size --sketch-size.
+             It is 1 if the sketches do not share any k-mer (default)
+- spectrum : 1 - cosine similarity of the k-mer count vectors of the two sequences
+
+Distances involving a sequence without any k-mer (e.g. shorter than k) are NaN.
+
+The k-mer size (--kmer-size) is 21 for nucleotides and 9 for proteins by default.
+With --canonical (nucleotides only), a k-mer and its reverse complement are considered
+identical, which makes distances independent of sequence orientation.
+
+Output formats are the same as goalign compute distance (--output-format phylip, lower,
+long, npy or mega, and --max-dist with long format). The resulting matrix may for example
+be given to a distance based tree inference tool to build a guide tree for alignment.
+
+K-mer profiles and distances are computed in parallel (-t).
+
+For example:
+goalign compute kdist -i seqs.fa --unaligned -m mash --kmer-size 15 -t 4
+`,
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		var f utils.StringWriterCloser
+		var method, format int
+
+		if method, err = kmer.DistanceFromString(kdistMethod); err != nil {
+			io.LogError(err)
+			return
+		}
+		if format, err = distmatrix.ParseFormat(kdistOutputFormat); err != nil {
+			io.LogError(err)
+			return
+		}
+		if kdistMaxDist >= 0 && format != distmatrix.FORMAT_LONG {
+			err = fmt.Errorf("--max-dist is only available with --output-format long")
+			io.LogError(err)
+			return
+		}
+
+		if f, err = utils.OpenWriteFile(kdistOutput); err != nil {
+			io.LogError(err)
+			return
+		}
+		defer utils.CloseWriteFile(f, kdistOutput)
+
+		kdist := func(sb align.SeqBag) (err error) {
+			var matrix [][]float64
+			k := kdistK
+			if !cmd.Flags().Changed("kmer-size") && sb.Alphabet() == align.AMINOACIDS {
+				k = kmer.DEFAULT_K_AA
+			}
+			if matrix, err = kmer.DistMatrix(sb, method, k, kdistSketchSize, kdistCanonical, rootcpus); err != nil {
+				return
+			}
+			return writeDistMatrixFormat(sb, matrix, f, format, kdistMaxDist)
+		}
+
+		if unaligned {
+			var seqs align.SeqBag
+
+			if seqs, err = readsequences(infile); err != nil {
+				io.LogError(err)
+				return
+			}
+			if err = kdist(seqs); err != nil {
+				io.LogError(err)
+				return
+			}
+		} else {
+			var aligns *align.AlignChannel
+
+			if aligns, err = readalign(infile); err != nil {
+				io.LogError(err)
+				return
+			}
+			for al := range aligns.Achan {
+				if err = kdist(al); err != nil {
+					io.LogError(err)
+					return
+				}
+			}
+
+			if aligns.Err != nil {
+				err = aligns.Err
+				io.LogError(err)
+			}
+		}
+		return
+	},
+}
+
+func init() {
+	computeCmd.AddCommand(computeKDistCmd)
+	computeKDistCmd.PersistentFlags().StringVarP(&kdistOutput, "output", "o", "stdout", "Distance matrix output file")
+	computeKDistCmd.PersistentFlags().BoolVar(&unaligned, "unaligned", false, "Considers sequences as unaligned and format fasta (phylip, nexus,... options are ignored)")
+	computeKDistCmd.PersistentFlags().StringVarP(&kdistMethod, "method", "m", "mash", "K-mer distance: jaccard, mash or spectrum")
+	computeKDistCmd.PersistentFlags().IntVar(&kdistK, "kmer-size", kmer.DEFAULT_K_NT, "K-mer size (default 9 for proteins)")
+	computeKDistCmd.PersistentFlags().IntVarP(&kdistSketchSize, "sketch-size", "s", 1000, "MinHash sketch size (only for mash)")
+	computeKDistCmd.PersistentFlags().BoolVar(&kdistCanonical, "canonical", false, "Consider a k-mer and its reverse complement identical (nucleotides only)")
+	computeKDistCmd.PersistentFlags().StringVar(&kdistOutputFormat, "output-format", "phylip", "Distance matrix output format: phylip (square matrix), lower (lower triangular phylip matrix), long (one line per pair: seq1, seq2, distance), npy (NumPy float64 array) or mega")
+	computeKDistCmd.PersistentFlags().Float64Var(&kdistMaxDist, "max-dist", -1, "If >= 0, only pairs with a distance <= max-dist are written (only with --output-format long)")
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/evolbioinfo/goalign/align"
+	"github.com/evolbioinfo/goalign/draw"
+	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/report"
+	"github.com/evolbioinfo/goalign/io/utils"
+	"github.com/evolbioinfo/goalign/popgen"
+)
+
+var ldOutput string
+var ldMaxDist int
+var ldRefSequence string
+var ldImage string
+var ldImageCellSize int
+
+// Maximum number of biallelic sites drawn in the heatmap: the heatmap is
+// a dense matrix of sites x sites cells, whatever --max-dist
+const ldImageMaxSites = 2000
+
+// computeLDCmd represents the compute ld command
+var computeLDCmd = &cobra.Command{
+	Use:   "ld",
+	Short: "Computes linkage disequilibrium between biallelic sites",
+	Long: `Computes linkage disequilibrium between biallelic sites.
+
+Biallelic sites are sites having exactly two different unambiguous characters.
+For each pair of biallelic sites (or each pair of sites at most --max-dist
+positions apart), it computes:
+- n: Number of sequences without missing data at both sites;
+- d: Coefficient of linkage disequilibrium: p(A1B1) - p(A1)p(B1), A1 and B1
+  being the major alleles of the two sites;
+- dprime: D' = D/Dmax (Lewontin 1964);
+- r2: Squared correlation between the two sites: D^2/(p(A1)p(A2)p(B1)p(B2)).
+
+Missing data (gaps and ambiguous characters) are handled by pairwise deletion:
+for each pair of sites, only the sequences having an unambiguous character at
+both sites are considered. If one of the sites is monomorphic on these sequences,
+D is 0 and D' and r2 are NaN.
+
+If --ref-sequence is given, site positions and distances are given in the
+coordinates of the reference sequence (0-based, without gaps), and sites where
+the reference has a gap are ignored.
+
+The output is a long tab separated table (or json with --format json), with the
+columns: alignment (index of the input alignment), site1, site2 (0-based
+alignment sites), refpos1, refpos2 (only with --ref-sequence), distance, n,
+alleles1, alleles2 (major/minor alleles), d, dprime, r2.
+
+If --image <file.png> is given, a heatmap of the biallelic sites is drawn:
+r2 above the diagonal and |D'| below the diagonal, from white (0) to red (1),
+NaN in grey, and pairs of sites that are not compared (--max-dist) in white.
+Each pair of sites is a square of --image-cell-size pixels. The heatmap is a
+dense square whatever --max-dist, so it is drawn only for alignments having at
+most 2000 biallelic sites: above this limit, the command fails (after writing
+the table), and the alignment should first be restricted to a region (see
+goalign subseq).
+
+Example:
+goalign compute ld -i al.fa --ref-sequence ref --max-dist 1000 --image ld.png
+`,
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		var aligns *align.AlignChannel
+		var f utils.StringWriterCloser
+		var rw *report.Writer
+
+		if f, err = utils.OpenWriteFile(ldOutput); err != nil {
+			io.LogError(err)
+			return
+		}
+		defer utils.CloseWriteFile(f, ldOutput)
+		if rw, err = tableWriter(f, "ld"); err != nil {
+			io.LogError(err)
+			return
+		}
+
+		if aligns, err = readalign(infile); err != nil {
+			io.LogError(err)
+			return
+		}
+		nb := 0
+		for al := range aligns.Achan {
+			var positions []int
+			var ld []popgen.LD
+
+			if positions, err = refSequencePositions(al, ldRefSequence); err != nil {
+				io.LogError(err)
+				return
+			}
+			if ld, err = popgen.ComputeLD(al, positions, ldMaxDist); err != nil {
+				io.LogError(err)
+				return
+			}
+			if err = rw.Write(nb, ldTable(ld, positions)); err != nil {
+				io.LogError(err)
+				return
+			}
+			if ldImage != "none" {
+				fname := ldImage
+				// Add an index to the image file name
+				// if there are several alignments
+				if nb > 0 {
+					ext := filepath.Ext(fname)
+					fname = fmt.Sprintf("%s_%d%s", fname[0:len(fname)-len(ext)], nb, ext)
+				}
+				if err = drawLDHeatmap(al, positions, ld, fname); err != nil {
+					io.LogError(err)
+					return
+				}
+			}
+			nb++
+		}
+		if aligns.Err != nil {
+			err = aligns.Err
+			io.LogError(err)
+		}
+		return
+	},
+}
+
+// refSequencePositions returns the position of each alignment site on the given
+// reference sequence (0-based, without gaps), -1 if the reference has a gap.
+// Returns nil if refseq is "none".
+func refSequencePositions(al align.Alignment, refseq string) (positions []int, err error) {
+	var ref []uint8
+	var ok bool
+
+	if refseq == "none" {
+		return
+	}
+	if ref, ok = al.GetSequenceChar(refseq); !ok {
+		err = fmt.Errorf("reference sequence %s does not exist in the alignment", refseq)
+		return
+	}
+	positions = make([]int, len(ref))
+	pos := 0
+	for i, c := range ref {
+		positions[i] = -1
+		if c != align.GAP {
+			positions[i] = pos
+			pos++
+		}
+	}
+	return
+}
+
+// ldTable returns the long table of linkage disequilibrium between pairs of sites
+func ldTable(ld []popgen.LD, positions []int) (t *report.Table) {
+	columns := []string{"site1", "site2"}
+	if positions != nil {
+		columns = append(columns, "refpos1", "refpos2")
+	}
+	columns = append(columns, "distance", "n", "alleles1", "alleles2", "d", "dprime", "r2")
+	t = report.NewTable(columns...)
+	for _, l := range ld {
+		row := []interface{}{l.Site1, l.Site2}
+		dist := l.Site2 - l.Site1
+		if positions != nil {
+			row = append(row, positions[l.Site1], positions[l.Site2])
+			dist = positions[l.Site2] - positions[l.Site1]
+		}
+		row = append(row, dist, l.N,
+			fmt.Sprintf("%c/%c", l.Alleles1[0], l.Alleles1[1]),
+			fmt.Sprintf("%c/%c", l.Alleles2[0], l.Alleles2[1]),
+			l.D, l.DPrime, l.R2)
+		t.AddRow(row...)
+	}
+	return
+}
+
+// drawLDHeatmap draws the heatmap of linkage disequilibrium between
+// biallelic sites: r2 above the diagonal, |D'| below the diagonal.
+// Returns an error if there are more than ldImageMaxSites biallelic sites.
+func drawLDHeatmap(al align.Alignment, positions []int, ld []popgen.LD, fname string) (err error) {
+	var sites []int
+	var f utils.StringWriterCloser
+
+	if sites, _, err = popgen.BiallelicSites(al, positions); err != nil {
+		return
+	}
+	if len(sites) > ldImageMaxSites {
+		err = fmt.Errorf("too many biallelic sites to draw the heatmap: %d (maximum %d)", len(sites), ldImageMaxSites)
+		return
+	}
+	if ldImageCellSize <= 0 {
+		err = fmt.Errorf("heatmap cell size must be > 0: %d", ldImageCellSize)
+		return
+	}
+	index := make(map[int]int)
+	values := make([][]float64, len(sites))
+	for i, s := range sites {
+		index[s] = i
+		values[i] = make([]float64, len(sites))
+		for j := range values[i] {
+			values[i][j] = -1
+		}
+		values[i][i] = 1
+	}
+	for _, l := range ld {
+		i, j := index[l.Site1], index[l.Site2]
+		values[i][j] = l.R2
+		values[j][i] = math.Abs(l.DPrime)
+	}
+
+	if f, err = utils.OpenWriteFile(fname); err != nil {
+		return
+	}
+	defer utils.CloseWriteFile(f, fname)
+	w := bufio.NewWriter(f)
+	if err = draw.NewPngHeatmapLayout(w, ldImageCellSize).DrawHeatmap(values); err != nil {
+		return
+	}
+	return w.Flush()
+}
+
+func init() {
+	computeCmd.AddCommand(computeLDCmd)
+	computeLDCmd.PersistentFlags().StringVarP(&ldOutput, "output", "o", "stdout", "Output file")
+	computeLDCmd.PersistentFlags().IntVar(&ldMaxDist, "max-dist", 0, "Maximum distance between the sites of a pair (0: all pairs)")
+	computeLDCmd.PersistentFlags().StringVar(&ldRefSequence, "ref-sequence", "none", "Name of the reference sequence giving site coordinates")
+	computeLDCmd.PersistentFlags().StringVar(&ldImage, "image", "none", "Linkage disequilibrium heatmap png output file (at most 2000 biallelic sites)")
+	computeLDCmd.PersistentFlags().IntVar(&ldImageCellSize, "image-cell-size", 4, "Size in pixels of each pair of sites in the heatmap")
+	addFormatFlag(computeLDCmd)
+}
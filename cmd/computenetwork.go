@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/evolbioinfo/goalign/align"
+	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/metadata"
+	"github.com/evolbioinfo/goalign/io/utils"
+	"github.com/evolbioinfo/goalign/network"
+)
+
+var networkOutput string
+var networkMethod string
+var networkEpsilon int
+var networkLimit int
+var networkJSON bool
+
+// computeNetworkCmd represents the compute network command
+var computeNetworkCmd = &cobra.Command{
+	Use:   "network",
+	Short: "Builds a haplotype network",
+	Long: `Builds a haplotype network.
+
+Identical sequences are first collapsed into haplotypes (as with goalign dedup),
+after masking the sites where at least one sequence has a gap or an ambiguous
+character. Haplotypes are named after their first sequence.
+
+The network is built using one of the following methods (--method):
+- msn: Minimum spanning network (Bandelt et al. 1999): union of all minimum
+  spanning trees. With --epsilon > 0, an edge of length d between two haplotypes
+  is added if they are not connected by edges shorter than d-epsilon;
+- mjn: Median-joining network (Bandelt et al. 1999): median vectors (inferred
+  haplotypes, named mv1, mv2, ...) of triplets of haplotypes connected in the
+  minimum spanning network are added iteratively, and obsolete median vectors
+  (less than 3 edges) are removed. --epsilon is used both for minimum spanning
+  networks and for the connection cost of median vectors;
+- tcs: TCS-like statistical parsimony network (Templeton et al. 1992): pairs of
+  haplotypes are connected by increasing number of differences, through
+  intermediate haplotypes (named int1, int2, ...), each edge being a single
+  mutational step. Haplotypes of the same network are connected again if they are
+  further apart in the network than their number of differences (loops).
+  By default, the connection limit (maximum number of differences between
+  connected haplotypes) is the largest number of differences whose probability
+  of parsimony (Templeton et al. 1992) is >= 95%, given the number of unmasked
+  sites. --connection-limit overrides it (0: no limit).
+
+The network is written in GraphML format, or in Cytoscape JSON format with
+--json, so that it can be laid out in Cytoscape or similar viewers. Nodes have
+the attributes: name, frequency (number of sequences), inferred (true for median
+vectors and intermediates), sequences (names of the sequences), and the number of
+sequences of each group if groups are given (--groups, --group-sep/--group-field
+or --group-by with --metadata). Edges have a weight attribute (number of
+differences).
+
+Example:
+goalign compute network -i al.fa --method mjn --group-sep "_" --group-field 2 -o net.graphml
+`,
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		var aligns *align.AlignChannel
+		var f utils.StringWriterCloser
+		var md *metadata.Metadata
+
+		if networkMethod != "msn" && networkMethod != "mjn" && networkMethod != "tcs" {
+			err = fmt.Errorf("unknown network method: %s", networkMethod)
+			io.LogError(err)
+			return
+		}
+		if md, err = readMetadata(); err != nil {
+			io.LogError(err)
+			return
+		}
+		if f, err = utils.OpenWriteFile(networkOutput); err != nil {
+			io.LogError(err)
+			return
+		}
+		defer utils.CloseWriteFile(f, networkOutput)
+
+		if aligns, err = readalign(infile); err != nil {
+			io.LogError(err)
+			return
+		}
+		for al := range aligns.Achan {
+			var n *network.Network
+			var groups map[string][]int
+			var keys []string
+
+			switch networkMethod {
+			case "msn":
+				n, err = network.MinimumSpanningNetwork(al, networkEpsilon)
+			case "mjn":
+				n, err = network.MedianJoiningNetwork(al, networkEpsilon)
+			default:
+				n, err = network.TCSNetwork(al, networkLimit)
+			}
+			if err != nil {
+				io.LogError(err)
+				return
+			}
+			if groups, keys, err = sequenceGroups(al, md); err != nil {
+				io.LogError(err)
+				return
+			}
+			if groups != nil {
+				n.SetGroups(groups, keys)
+			}
+			if networkJSON {
+				var out []byte
+				if out, err = n.JSON(seqNames(al)); err != nil {
+					io.LogError(err)
+					return
+				}
+				f.WriteString(string(out) + "\n")
+			} else {
+				f.WriteString(n.GraphML(seqNames(al)))
+			}
+		}
+		if aligns.Err != nil {
+			err = aligns.Err
+			io.LogError(err)
+		}
+		return
+	},
+}
+
+func init() {
+	computeCmd.AddCommand(computeNetworkCmd)
+	computeNetworkCmd.PersistentFlags().StringVarP(&networkOutput, "output", "o", "stdout", "Network output file")
+	computeNetworkCmd.PersistentFlags().StringVarP(&networkMethod, "method", "m", "mjn", "Network method: msn (minimum spanning), mjn (median-joining), or tcs (statistical parsimony)")
+	computeNetworkCmd.PersistentFlags().IntVar(&networkEpsilon, "epsilon", 0, "Epsilon parameter of msn and mjn methods")
+	computeNetworkCmd.PersistentFlags().IntVar(&networkLimit, "connection-limit", -1, "Maximum number of differences between connected haplotypes for the tcs method (-1: 95% parsimony limit, 0: no limit)")
+	computeNetworkCmd.PersistentFlags().BoolVar(&networkJSON, "json", false, "Writes the network in Cytoscape JSON format instead of GraphML")
+	addGroupFlags(computeNetworkCmd)
+}
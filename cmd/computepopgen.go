@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/evolbioinfo/goalign/align"
+	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/metadata"
+	"github.com/evolbioinfo/goalign/io/report"
+	"github.com/evolbioinfo/goalign/io/utils"
+	"github.com/evolbioinfo/goalign/popgen"
+)
+
+var popgenOutput string
+var popgenMaxMissing float64
+
+// computePopgenCmd represents the compute popgen command
+var computePopgenCmd = &cobra.Command{
+	Use:   "popgen",
+	Short: "Computes population genetics summary statistics",
+	Long: `Computes population genetics summary statistics.
+
+It computes, for each input alignment, the following statistics:
+- nseqs: Number of sequences;
+- samplesize: Sample size used in neutrality tests;
+- sites: Number of analyzed sites;
+- S: Number of segregating sites;
+- eta: Total number of mutations (>S if some sites have more than 2 alleles);
+- singletons: Number of singleton mutations;
+- pi: Nucleotide diversity (average number of pairwise differences), and 
+  pisite (pi/sites);
+- thetaw: Watterson's theta, and thetawsite (thetaw/sites);
+- tajimad: Tajima's D (Tajima, 1989);
+- fulid, fulif: Fu & Li's D* and F* (without outgroup, Fu & Li 1993, Simonsen et al. 1995);
+- nhap: Number of haplotypes;
+- hd: Haplotype diversity (Nei, 1987).
+
+Only unambiguous characters (A, C, G, T for nucleotides, 20 standard amino acids 
+for proteins) are considered, gaps and ambiguous characters are missing data:
+- Sites having a proportion of missing data > --max-missing are not analyzed 
+  (default 0: only sites without missing data are analyzed);
+- At each analyzed site, statistics are computed on non missing characters 
+  (pi and thetaw are sums of per-site values);
+- Neutrality tests are computed with a sample size equal to the average number 
+  of non missing characters per analyzed site;
+- Haplotypes are defined on analyzed sites without missing data.
+Undefined statistics (ex: Tajima's D without segregating sites) are NaN (tsv)
+or null (json).
+
+Statistics may be computed on:
+- Each partition defined in the file given with --partition;
+- Sliding windows, with --window-size and --window-step;
+- Each group of sequences, defined by a tab separated file (--groups, with 
+  sequence names in the first column and group names in the second one), by a
+  field of sequence names (--group-sep and --group-field), or by metadata columns
+  (--metadata and --group-by). In the latter case, sequences may be filtered
+  with --where.
+
+The output is a tab separated table (or json with --format json), whose first
+columns are: alignment (index of the input alignment), group (if groups are given),
+region (partition name, "window" or "all"), start and end (0-based, end excluded,
+empty for partitions).
+
+Example:
+goalign compute popgen -i al.fa --window-size 1000 --window-step 500 --group-sep '_' --group-field 2
+`,
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		var aligns *align.AlignChannel
+		var f utils.StringWriterCloser
+		var rw *report.Writer
+		var md *metadata.Metadata
+		var t *report.Table
+
+		if md, err = readMetadata(); err != nil {
+			io.LogError(err)
+			return
+		}
+		if f, err = utils.OpenWriteFile(popgenOutput); err != nil {
+			io.LogError(err)
+			return
+		}
+		defer utils.CloseWriteFile(f, popgenOutput)
+
+		if rw, err = tableWriter(f, "popgen"); err != nil {
+			io.LogError(err)
+			return
+		}
+
+		if aligns, err = readalign(infile); err != nil {
+			io.LogError(err)
+			return
+		}
+		nb := 0
+		for al := range aligns.Achan {
+			if t, err = popgenStats(al, md); err != nil {
+				io.LogError(err)
+				return
+			}
+			if err = rw.Write(nb, t); err != nil {
+				io.LogError(err)
+				return
+			}
+			nb++
+		}
+		if aligns.Err != nil {
+			err = aligns.Err
+			io.LogError(err)
+		}
+		return
+	},
+}
+
+// popgenStats computes population genetics statistics on each group
+// and region of the alignment
+func popgenStats(al align.Alignment, md *metadata.Metadata) (t *report.Table, err error) {
+	var groups map[string][]int
+	var keys []string
+	var regions []alignRegion
+	var d popgen.Diversity
+
+	if groups, keys, err = sequenceGroups(al, md); err != nil {
+		return
+	}
+	if regions, err = alignRegions(al); err != nil {
+		return
+	}
+
+	columns := []string{"region", "start", "end", "nseqs", "samplesize", "sites", "S", "eta", "singletons",
+		"pi", "pisite", "thetaw", "thetawsite", "tajimad", "fulid", "fulif", "nhap", "hd"}
+	if groups == nil {
+		keys = []string{""}
+	} else {
+		columns = append([]string{"group"}, columns...)
+	}
+	t = report.NewTable(columns...)
+
+	for _, g := range keys {
+		for _, r := range regions {
+			if d, err = popgen.ComputeDiversity(r.al, groups[g], popgenMaxMissing); err != nil {
+				return
+			}
+			row := []interface{}{r.name, r.start, r.end, d.NbSeqs, d.SampleSize, d.Sites, d.Segregating, d.Mutations, d.Singletons,
+				d.Pi, d.Pi / float64(d.Sites), d.ThetaW, d.ThetaW / float64(d.Sites),
+				d.TajimaD, d.FuLiDStar, d.FuLiFStar, d.Haplotypes, d.Hd}
+			if groups != nil {
+				row = append([]interface{}{g}, row...)
+			}
+			if err = t.AddRow(row...); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+func init() {
+	computeCmd.AddCommand(computePopgenCmd)
+	computePopgenCmd.PersistentFlags().StringVarP(&popgenOutput, "output", "o", "stdout", "Output file")
+	computePopgenCmd.PersistentFlags().Float64Var(&popgenMaxMissing, "max-missing", 0.0, "Maximum proportion of missing data (gaps, ambiguities) at a site for it to be analyzed")
+	addRegionFlags(computePopgenCmd)
+	addGroupFlags(computePopgenCmd)
+	addFormatFlag(computePopgenCmd)
+}
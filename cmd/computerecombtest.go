@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/evolbioinfo/goalign/align"
+	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/report"
+	"github.com/evolbioinfo/goalign/io/utils"
+	"github.com/evolbioinfo/goalign/recomb"
+)
+
+var recombTestOutput string
+var recombTestWindow int
+var recombTestPermutations int
+var recombTest3Seq string
+
+// computeRecombTestCmd represents the compute recomb-test command
+var computeRecombTestCmd = &cobra.Command{
+	Use:   "recomb-test",
+	Short: "Tests for recombination in the alignment (PHI and 3SEQ-like tests)",
+	Long: `Tests for recombination in the alignment (PHI and 3SEQ-like tests).
+
+It computes the Pairwise Homoplasy Index test (PHI, Bruen et al. 2006): the PHI
+statistic is the mean refined incompatibility between pairs of informative
+sites (sites with at least two characters occurring at least twice each) that
+are at most --window informative sites apart. The refined incompatibility of
+two sites is the minimum number of homoplasies needed to explain them (1 for
+two biallelic sites showing the 4 gametes, 0 for compatible sites). Without
+recombination, the order of the sites does not matter: the p-value is the
+proportion of --permutations permutations of the informative sites giving a
+PHI lower than or equal to the observed one.
+
+The output is a tab separated table (or json with --format json), with the
+columns: alignment (index of the input alignment), sites (number of
+informative sites), window, phi, mean and sd (mean and standard deviation of
+PHI over permutations), pvalue. PHI and p-value are NaN with less than 3
+informative sites.
+
+If --3seq <file> is given, then a 3SEQ-like triplet test (Boni et al. 2007) is
+also computed: for each child sequence and each ordered pair of parents, a
+random walk along the sites where the parents differ and the child matches one
+of them goes up (child matches parent 1) or down (child matches parent 2). The
+statistic is the maximum descent of the walk, and its exact p-value is the
+probability of a maximum descent at least as large under random orderings.
+For each child, the most significant triplet is reported in the given file,
+with the columns: alignment, child, parent1, parent2, up, down, descent, start,
+end (alignment positions, 0-based, of the first and last sites of the descent,
+i.e. putative breakpoints), pvalue, corrected (Dunn-Sidak correction for the
+number of tested triplets), triplets (number of tested triplets).
+
+Only unambiguous characters are considered for compatibilities and triplets,
+gaps and ambiguous characters being missing data.
+
+Example:
+goalign compute recomb-test -i al.fa --3seq triplets.tsv
+`,
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		var aligns *align.AlignChannel
+		var f utils.StringWriterCloser
+		var rw, rw3 *report.Writer
+
+		if f, err = utils.OpenWriteFile(recombTestOutput); err != nil {
+			io.LogError(err)
+			return
+		}
+		defer utils.CloseWriteFile(f, recombTestOutput)
+		if rw, err = tableWriter(f, "recomb-test"); err != nil {
+			io.LogError(err)
+			return
+		}
+
+		if recombTest3Seq != "none" {
+			var f3 utils.StringWriterCloser
+			if f3, err = utils.OpenWriteFile(recombTest3Seq); err != nil {
+				io.LogError(err)
+				return
+			}
+			defer utils.CloseWriteFile(f3, recombTest3Seq)
+			if rw3, err = tableWriter(f3, "recomb-test"); err != nil {
+				io.LogError(err)
+				return
+			}
+		}
+
+		if aligns, err = readalign(infile); err != nil {
+			io.LogError(err)
+			return
+		}
+		nb := 0
+		for al := range aligns.Achan {
+			var phi recomb.PhiTest
+			var triplets []recomb.Triplet
+
+			if phi, err = recomb.Phi(al, recombTestWindow, recombTestPermutations, globalRand); err != nil {
+				io.LogError(err)
+				return
+			}
+			t := report.NewTable("sites", "window", "phi", "mean", "sd", "pvalue")
+			if err = t.AddRow(phi.Sites, phi.Window, phi.Phi, phi.Mean, phi.Stddev, phi.PValue); err != nil {
+				io.LogError(err)
+				return
+			}
+			if err = rw.Write(nb, t); err != nil {
+				io.LogError(err)
+				return
+			}
+
+			if rw3 != nil {
+				if triplets, err = recomb.ThreeSeq(al, nil); err != nil {
+					io.LogError(err)
+					return
+				}
+				t3 := report.NewTable("child", "parent1", "parent2", "up", "down", "descent", "start", "end", "pvalue", "corrected", "triplets")
+				for _, tr := range triplets {
+					if err = t3.AddRow(tr.Child, tr.Parent1, tr.Parent2, tr.Up, tr.Down, tr.Descent, tr.Start, tr.End, tr.PValue, tr.CorrectedPValue, tr.Triplets); err != nil {
+						io.LogError(err)
+						return
+					}
+				}
+				if err = rw3.Write(nb, t3); err != nil {
+					io.LogError(err)
+					return
+				}
+			}
+			nb++
+		}
+		if aligns.Err != nil {
+			err = aligns.Err
+			io.LogError(err)
+		}
+		return
+	},
+}
+
+func init() {
+	computeCmd.AddCommand(computeRecombTestCmd)
+	computeRecombTestCmd.PersistentFlags().StringVarP(&recombTestOutput, "output", "o", "stdout", "PHI test output file")
+	computeRecombTestCmd.PersistentFlags().IntVar(&recombTestWindow, "window", 100, "PHI window size, in number of informative sites")
+	computeRecombTestCmd.PersistentFlags().IntVar(&recombTestPermutations, "permutations", 1000, "Number of permutations for the PHI p-value")
+	computeRecombTestCmd.PersistentFlags().StringVar(&recombTest3Seq, "3seq", "none", "3SEQ-like triplet test output file")
+	addFormatFlag(computeRecombTestCmd)
+}
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"image/color"
+
+	"github.com/spf13/cobra"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/font"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+
+	"github.com/evolbioinfo/goalign/align"
+	"github.com/evolbioinfo/goalign/distance/dna"
+	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/report"
+	"github.com/evolbioinfo/goalign/io/utils"
+)
+
+var saturationOutput string
+var saturationPairsOutput string
+var saturationModel string
+var saturationRemoveGaps bool
+var saturationIsscSym float64
+var saturationIsscAsym float64
+var saturationOutImageFile string
+var saturationOutImageWidth int
+var saturationOutImageHeight int
+
+// computeSaturationCmd represents the compute saturation command
+var computeSaturationCmd = &cobra.Command{
+	Use:   "saturation",
+	Short: "Computes substitution saturation statistics of nucleotide alignments",
+	Long: `Computes substitution saturation statistics of nucleotide alignments.
+
+1) Xia's index of substitution saturation (Xia et al. 2003):
+For each site, the entropy H = -sum(p log2 p) of its unambiguous nucleotides
+is computed (gaps and ambiguous nucleotides are considered missing), and
+compared to HFSS, its expected value under full substitution saturation, i.e.
+if the nucleotides of the sequences having data at this site were drawn from
+the nucleotide frequencies of the alignment. Iss = mean(H)/mean(HFSS), and its
+standard error is sd(H)/sqrt(L)/mean(HFSS), L being the number of sites having
+at least 2 unambiguous nucleotides. The closer Iss is to 1, the more saturated
+the alignment.
+
+The critical values Iss.c at which the phylogenetic signal is lost depend on
+the number of sequences, the length of the alignment, and the tree topology.
+As in DAMBE, they are interpolated from the values obtained by simulations
+(Xia et al. 2003, Xia & Lemey 2009) for 4, 8, 16 and 32 sequences and 100 to
+5000 sites (L), for a symmetrical and for an asymmetrical topology. Values
+outside these ranges are brought back to the closest bound. They can be
+overridden with --iss-c-sym and --iss-c-asym. Iss is compared to both Iss.c
+with a two-tailed t-test with L-1 degrees of freedom: if Iss is significantly
+lower than Iss.c, the sequences have experienced little saturation.
+
+The output (-o) is a tab separated table (or json with --format json) with the
+columns: alignment (index of the input alignment), nseqs, sites (L), h, hfss,
+iss, se, iss_c_sym, t_sym, df_sym, pvalue_sym, iss_c_asym, t_asym, df_asym,
+pvalue_asym.
+
+2) Transitions and transversions vs. distance:
+If --pairs is given, the number of transitions and transversions between each
+pair of sequences, and their corrected distance under the model given by
+--model (k2p or tn93) are written to the given file, with the columns:
+alignment, seq1, seq2, sites, transitions, transversions, distance.
+If --image is given, they are plotted against the distance in the given png
+file. Transitions that do not increase linearly with the distance are a sign
+of saturation.
+
+If the third codon positions are saturated, they may be removed with goalign
+subsites --reverse, giving the positions 2, 5, 8, ... (0-based) on the command
+line or in a site file (--sitefile).
+
+Example:
+goalign compute saturation -i al.fa --pairs pairs.txt --image saturation.png
+`,
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		var aligns *align.AlignChannel
+		var f, pf utils.StringWriterCloser
+		var rw, prw *report.Writer
+
+		if f, err = utils.OpenWriteFile(saturationOutput); err != nil {
+			io.LogError(err)
+			return
+		}
+		defer utils.CloseWriteFile(f, saturationOutput)
+		if rw, err = tableWriter(f, "saturation"); err != nil {
+			io.LogError(err)
+			return
+		}
+		if saturationPairsOutput != "none" {
+			if pf, err = utils.OpenWriteFile(saturationPairsOutput); err != nil {
+				io.LogError(err)
+				return
+			}
+			defer utils.CloseWriteFile(pf, saturationPairsOutput)
+			if prw, err = tableWriter(pf, "saturation pairs"); err != nil {
+				io.LogError(err)
+				return
+			}
+		}
+
+		if aligns, err = readalign(infile); err != nil {
+			io.LogError(err)
+			return
+		}
+		nb := 0
+		for al := range aligns.Achan {
+			var x dna.XiaTest
+			var t *report.Table
+			var pairs []dna.SaturationPair
+
+			if x, err = dna.XiaSaturation(al); err != nil {
+				io.LogError(err)
+				return
+			}
+			if t, err = saturationTable(x); err != nil {
+				io.LogError(err)
+				return
+			}
+			if err = rw.Write(nb, t); err != nil {
+				io.LogError(err)
+				return
+			}
+
+			if saturationPairsOutput != "none" || saturationOutImageFile != "none" {
+				if pairs, err = dna.SaturationPairs(al, saturationModel, saturationRemoveGaps); err != nil {
+					io.LogError(err)
+					return
+				}
+			}
+			if saturationPairsOutput != "none" {
+				pt := report.NewTable("seq1", "seq2", "sites", "transitions", "transversions", "distance")
+				for _, p := range pairs {
+					n1, _ := al.GetSequenceNameById(p.Seq1)
+					n2, _ := al.GetSequenceNameById(p.Seq2)
+					if err = pt.AddRow(n1, n2, p.Sites, p.Transitions, p.Transversions, p.Distance); err != nil {
+						io.LogError(err)
+						return
+					}
+				}
+				if err = prw.Write(nb, pt); err != nil {
+					io.LogError(err)
+					return
+				}
+			}
+			// Only the first alignment is plotted
+			if saturationOutImageFile != "none" && nb == 0 {
+				if err = drawSaturationPlot(pairs); err != nil {
+					io.LogError(err)
+					return
+				}
+			}
+			nb++
+		}
+		if aligns.Err != nil {
+			err = aligns.Err
+			io.LogError(err)
+		}
+		return
+	},
+}
+
+// saturationTable returns the table of Xia's test, with the t-tests against
+// the critical values for symmetrical and asymmetrical topologies (computed,
+// or given on the command line)
+func saturationTable(x dna.XiaTest) (t *report.Table, err error) {
+	columns := []string{"nseqs", "sites", "h", "hfss", "iss", "se"}
+	row := []interface{}{x.NbSequences, x.Sites, x.H, x.HFSS, x.Iss, x.StdErr}
+	sym, asym := x.IssC()
+	if saturationIsscSym >= 0 {
+		sym = saturationIsscSym
+	}
+	if saturationIsscAsym >= 0 {
+		asym = saturationIsscAsym
+	}
+	for _, c := range []struct {
+		name string
+		issc float64
+	}{{"sym", sym}, {"asym", asym}} {
+		tt, df, pvalue := x.TTest(c.issc)
+		columns = append(columns, "iss_c_"+c.name, "t_"+c.name, "df_"+c.name, "pvalue_"+c.name)
+		row = append(row, c.issc, tt, df, pvalue)
+	}
+	t = report.NewTable(columns...)
+	err = t.AddRow(row...)
+	return
+}
+
+// drawSaturationPlot draws the numbers of transitions and transversions of
+// each pair of sequences against their distance
+func drawSaturationPlot(pairs []dna.SaturationPair) (err error) {
+	var s *plotter.Scatter
+
+	p := plot.New()
+	p.Title.Text = "Saturation"
+	p.X.Label.Text = "Distance (" + saturationModel + ")"
+	p.Y.Label.Text = "Number of substitutions"
+
+	ts := make(plotter.XYs, len(pairs))
+	tv := make(plotter.XYs, len(pairs))
+	for i, pair := range pairs {
+		ts[i] = plotter.XY{X: pair.Distance, Y: pair.Transitions}
+		tv[i] = plotter.XY{X: pair.Distance, Y: pair.Transversions}
+	}
+	for _, serie := range []struct {
+		name  string
+		xys   plotter.XYs
+		color color.Color
+		shape draw.GlyphDrawer
+	}{
+		{"Transitions", ts, color.RGBA{R: 31, G: 120, B: 180, A: 255}, draw.CircleGlyph{}},
+		{"Transversions", tv, color.RGBA{R: 227, G: 26, B: 28, A: 255}, draw.TriangleGlyph{}},
+	} {
+		if s, err = plotter.NewScatter(serie.xys); err != nil {
+			return
+		}
+		s.Shape = serie.shape
+		s.Radius = 2
+		s.Color = serie.color
+		p.Add(s)
+		p.Legend.Add(serie.name, s)
+	}
+	p.Legend.Top = true
+	p.Legend.Left = true
+
+	err = p.Save(font.Length(saturationOutImageWidth)*vg.Inch, font.Length(saturationOutImageHeight)*vg.Inch, saturationOutImageFile)
+	return
+}
+
+func init() {
+	computeCmd.AddCommand(computeSaturationCmd)
+	computeSaturationCmd.PersistentFlags().StringVarP(&saturationOutput, "output", "o", "stdout", "Xia's test output file")
+	computeSaturationCmd.PersistentFlags().StringVar(&saturationPairsOutput, "pairs", "none", "Pairwise transitions/transversions vs. distance output file")
+	computeSaturationCmd.PersistentFlags().StringVarP(&saturationModel, "model", "m", "tn93", "Model for pairwise distances (k2p or tn93)")
+	computeSaturationCmd.PersistentFlags().BoolVar(&saturationRemoveGaps, "remove-gaps", false, "Do not use sites having at least one gap for pairwise distances")
+	computeSaturationCmd.PersistentFlags().Float64Var(&saturationIsscSym, "iss-c-sym", -1, "Critical value of Iss for a symmetrical topology (<0: interpolated from Xia et al. 2003)")
+	computeSaturationCmd.PersistentFlags().Float64Var(&saturationIsscAsym, "iss-c-asym", -1, "Critical value of Iss for an asymmetrical topology (<0: interpolated from Xia et al. 2003)")
+	computeSaturationCmd.PersistentFlags().StringVar(&saturationOutImageFile, "image", "none", "Transitions/transversions vs. distance plot image output file (png)")
+	computeSaturationCmd.PersistentFlags().IntVar(&saturationOutImageWidth, "image-width", 4, "Plot image output width")
+	computeSaturationCmd.PersistentFlags().IntVar(&saturationOutImageHeight, "image-height", 4, "Plot image output height")
+	addFormatFlag(computeSaturationCmd)
+}
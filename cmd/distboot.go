@@ -18,6 +18,7 @@ var distbootOutput string
 var distbootnb int
 var distbootAlpha float64
 var distbootmodel string
+var distbootModelFile string
 var distbootcontinuous bool = false
 var distbootRemoveGaps bool
 var distbootFrac float64
@@ -38,12 +39,29 @@ Available Distances:
 - f81  : Felsenstein 81
 - f84  : Felsenstein 84
 - tn93 : Tamura and Nei 1993
+- t92  : Tamura 1992
+- logdet : LogDet/paralinear
+- ml-jc, ml-k2p, ml-f81, ml-f84, ml-tn93, ml-gtr: Maximum likelihood distances
+  (model parameters are estimated on each replicate)
 Proteins:
 - DAYHOFF
 - JTT
 - MtRev 
 - LG
 - WAG
+- HIVb
+- AB
+- cpREV
+- rtREV
+- mtMAM
+- HIVw
+- FLU
+- Blosum62
+- VT
+- PMB
+
+Other protein models may be given in PAML format with --model-file (it overrides
+-m, see goalign compute distance).
 
 For example:
 
@@ -84,9 +102,11 @@ original alignment.
 			io.LogError(err)
 			return
 		}
-		if protmodelI = pm.ModelStringToInt(distbootmodel); protmodelI != -1 {
-			protmodel, _ = protein.NewProtDistModel(protmodelI, true, cmd.Flags().Changed("alpha"), distbootAlpha, distbootRemoveGaps)
-			protmodel.InitModel(nil, nil)
+		if protmodelI = pm.ModelStringToInt(distbootmodel); protmodelI != -1 || distbootModelFile != "none" {
+			if protmodel, err = protDistModel(protmodelI, distbootModelFile, cmd.Flags().Changed("alpha"), distbootAlpha, distbootRemoveGaps); err != nil {
+				io.LogError(err)
+				return
+			}
 			for i := 0; i < distbootnb; i++ {
 				if distbootcontinuous {
 					weights = dna.BuildWeightsDirichlet(align)
@@ -136,6 +156,7 @@ func init() {
 	buildCmd.AddCommand(distbootCmd)
 	distbootCmd.PersistentFlags().StringVarP(&distbootOutput, "output", "o", "stdout", "Distance matrices output file")
 	distbootCmd.PersistentFlags().StringVarP(&distbootmodel, "model", "m", "k2p", "Model for distance computation")
+	distbootCmd.PersistentFlags().StringVar(&distbootModelFile, "model-file", "none", "Protein model file in PAML format (exchangeabilities and frequencies), overrides -m")
 	distbootCmd.PersistentFlags().IntVarP(&distbootnb, "nboot", "n", 1, "Number of bootstrap replicates to build")
 	distbootCmd.PersistentFlags().Float64VarP(&distbootFrac, "frac", "f", 1.0, "Fraction of sites to sample (if < 1.0: Partial bootstrap as in phylip seqboot)")
 	//distbootCmd.PersistentFlags().BoolVarP(&distbootcontinuous, "continuous", "c", false, "Bootstraps are done by weighting alignment with continuous weights (dirichlet)")
@@ -80,6 +80,7 @@ var extractCmd = &cobra.Command{
 	- If --translate 0: Standard genetic code
 	- If --translate 1: Vertebrate mitochondrial genetic code
 	- If --translate 2: Invertebrate mitochondrial genetic code
+	- If --translate >= 3: NCBI genetic code having this number (ex: 4, 11, 25, see goalign translate)
 	
 	If --ref-seq is given, and --translate>=0 is given, be careful about the behavior! It will extract the 
 	nucleotide sequences corresponding to start/stop coordinates on the reference sequence, and will translate
@@ -228,7 +229,7 @@ var extractCmd = &cobra.Command{
 func init() {
 	RootCmd.AddCommand(extractCmd)
 	extractCmd.PersistentFlags().StringVar(&extractrefseq, "ref-seq", "none", "Reference sequence on which coordinates are given")
-	extractCmd.PersistentFlags().IntVar(&extracttranslate, "translate", -1, "Wether the extracted sequence will be translated (only if input alignment is nucleotide). <0: No translation, 0: Std code, 1: Vertebrate mito, 2: Invertebrate mito")
+	extractCmd.PersistentFlags().IntVar(&extracttranslate, "translate", -1, "Wether the extracted sequence will be translated (only if input alignment is nucleotide). <0: No translation, 0: Std code, 1: Vertebrate mito, 2: Invertebrate mito, >=3: NCBI genetic code number")
 	extractCmd.PersistentFlags().StringVarP(&extractoutput, "output", "o", ".", "Output folder")
 	extractCmd.PersistentFlags().StringVar(&extractcoordfile, "coordinates", "none", "File with all coordinates of the sequences to extract")
 	extractCmd.PersistentFlags().BoolVar(&extractgff, "gff", false, "Wether the coordinate file specified with --coordinates is in gff format")
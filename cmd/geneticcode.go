@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"github.com/evolbioinfo/goalign/align"
+	"github.com/spf13/cobra"
+)
+
+// addGeneticCodeFlag adds the --genetic-code option to the given command
+func addGeneticCodeFlag(c *cobra.Command, geneticcode *string) {
+	c.PersistentFlags().StringVar(geneticcode, "genetic-code", "standard", "Genetic Code: standard, mitoi (invertebrate mitochondrial), mitov (vertebrate mitochondrial), or NCBI translation table number (1-6, 9-16, 21-33)")
+}
+
+// parseGeneticCode returns the genetic code given with --genetic-code
+func parseGeneticCode(geneticcode string) (code int, err error) {
+	return align.GeneticCodeFromString(geneticcode)
+}
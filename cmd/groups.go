@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/evolbioinfo/goalign/align"
+	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/metadata"
+	"github.com/spf13/cobra"
+)
+
+// Options shared by all commands computing statistics on groups of sequences
+var groupSep string
+var groupField int
+var groupBy string
+var groupFile string
+
+// addGroupFlags adds the options defining groups of sequences, either from
+// a file (--groups), from sequence names (--group-sep and --group-field) or
+// from metadata columns (--group-by, with the metadata options).
+func addGroupFlags(c *cobra.Command) {
+	c.PersistentFlags().StringVar(&groupFile, "groups", "none", "Tab separated file giving the group of each sequence (sequence name<tab>group name)")
+	c.PersistentFlags().StringVar(&groupSep, "group-sep", "none", "Separator splitting sequence names into fields, one of which defines the group (see --group-field)")
+	c.PersistentFlags().IntVar(&groupField, "group-field", 1, "Field of sequence names defining the group (1-based, with --group-sep)")
+	c.PersistentFlags().StringVar(&groupBy, "group-by", "none", "Comma separated list of metadata columns defining the groups (with --metadata)")
+	addMetadataFlags(c, true, false)
+}
+
+// sequenceGroups returns the indices of the sequences of the alignment in each
+// group defined by --groups, --group-sep/--group-field or --group-by, and the
+// sorted group names. Returns nil groups if no group option is given.
+//
+// Sequences that can not be assigned to a group (missing from the group file or
+// from metadata, or not satisfying --where) are ignored, and a warning is printed.
+func sequenceGroups(al align.Alignment, md *metadata.Metadata) (groups map[string][]int, keys []string, err error) {
+	var names []string
+	var selected map[string]bool
+	var missing int
+
+	nopts := 0
+	for _, o := range []string{groupFile, groupSep, groupBy} {
+		if o != "none" {
+			nopts++
+		}
+	}
+	if nopts > 1 {
+		err = errors.New("--groups, --group-sep and --group-by are mutually exclusive")
+		return
+	}
+	if nopts == 0 {
+		return
+	}
+
+	names = seqNames(al)
+	groups = make(map[string][]int)
+	if groupFile != "none" {
+		var namegroup map[string]string
+		if namegroup, err = readMapFile(groupFile, false); err != nil {
+			return
+		}
+		for i, name := range names {
+			if g, ok := namegroup[name]; ok {
+				groups[g] = append(groups[g], i)
+			} else {
+				missing++
+			}
+		}
+	} else if groupSep != "none" {
+		var fields []string
+		if groupField < 1 {
+			err = fmt.Errorf("group field must be >= 1: %d", groupField)
+			return
+		}
+		for i, name := range names {
+			if fields, err = align.NameFields(name, groupSep, nil); err != nil {
+				return
+			}
+			if len(fields) < groupField {
+				err = fmt.Errorf("sequence name %s has less than %d fields", name, groupField)
+				return
+			}
+			groups[fields[groupField-1]] = append(groups[fields[groupField-1]], i)
+		}
+	} else {
+		var namegroups map[string][]string
+		var notgrouped []string
+		if md == nil {
+			err = errors.New("--group-by needs a metadata file (--metadata)")
+			return
+		}
+		if selected, err = metadataFilter(md, al); err != nil {
+			return
+		}
+		kept := make([]string, 0, len(names))
+		for _, name := range names {
+			if selected == nil || selected[name] {
+				kept = append(kept, name)
+			}
+		}
+		if namegroups, _, notgrouped, err = md.Groups(kept, splitColumns(groupBy)); err != nil {
+			return
+		}
+		missing = len(notgrouped)
+		for g, gnames := range namegroups {
+			for _, name := range gnames {
+				groups[g] = append(groups[g], al.GetSequenceIdByName(name))
+			}
+			sort.Ints(groups[g])
+		}
+	}
+	if missing > 0 {
+		io.PrintMessage(fmt.Sprintf("%d sequences can not be assigned to a group, they are ignored", missing))
+	}
+
+	keys = make([]string, 0, len(groups))
+	for g := range groups {
+		keys = append(keys, g)
+	}
+	sort.Strings(keys)
+	return
+}
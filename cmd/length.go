@@ -5,6 +5,7 @@ import (
 
 	"github.com/evolbioinfo/goalign/align"
 	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/report"
 	"github.com/spf13/cobra"
 )
 
@@ -28,6 +29,12 @@ goalign stats length -i align.fasta
 
 `,
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		var rw *report.Writer
+
+		if rw, err = reportWriter("length"); err != nil {
+			io.LogError(err)
+			return
+		}
 		if unaligned {
 			var seqs align.SeqBag
 
@@ -36,10 +43,21 @@ goalign stats length -i align.fasta
 				return
 			}
 
+			t := report.NewTable("sequence", "length")
 			seqs.IterateChar(func(name string, sequence []uint8) bool {
-				fmt.Println(name, "\t", len(sequence))
+				if rw == nil {
+					fmt.Println(name, "\t", len(sequence))
+				} else {
+					t.AddRow(name, len(sequence))
+				}
 				return false
 			})
+			if rw != nil {
+				if err = rw.Write(0, t); err != nil {
+					io.LogError(err)
+					return
+				}
+			}
 		} else {
 			var aligns *align.AlignChannel
 
@@ -48,8 +66,19 @@ goalign stats length -i align.fasta
 				return
 			}
 
+			nb := 0
 			for al := range aligns.Achan {
-				fmt.Println(al.Length())
+				if rw == nil {
+					fmt.Println(al.Length())
+				} else {
+					t := report.NewTable("length")
+					t.AddRow(al.Length())
+					if err = rw.Write(nb, t); err != nil {
+						io.LogError(err)
+						return
+					}
+				}
+				nb++
 			}
 
 			if aligns.Err != nil {
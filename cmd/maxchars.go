@@ -3,6 +3,7 @@ package cmd
 import (
 	"github.com/evolbioinfo/goalign/align"
 	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/report"
 	"github.com/spf13/cobra"
 )
 
@@ -28,8 +29,13 @@ goalign stats maxchar -i align.fasta
 `,
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		var aligns *align.AlignChannel
+		var rw *report.Writer
 		maxCharIgnoreGaps = maxCharIgnoreGaps || maxCharExcludeGaps
 
+		if rw, err = reportWriter("maxchar"); err != nil {
+			io.LogError(err)
+			return
+		}
 		if aligns, err = readalign(infile); err != nil {
 			io.LogError(err)
 			return
@@ -41,7 +47,9 @@ goalign stats maxchar -i align.fasta
 			io.LogError(err)
 			return
 		}
-		printMaxCharStats(al, maxCharIgnoreGaps, maxCharIgnoreNs)
+		if err = printMaxCharStats(al, maxCharIgnoreGaps, maxCharIgnoreNs, rw, 0); err != nil {
+			io.LogError(err)
+		}
 
 		return
 	},
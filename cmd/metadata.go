@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/evolbioinfo/goalign/align"
+	"github.com/evolbioinfo/goalign/gutils"
+	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/metadata"
+	"github.com/evolbioinfo/goalign/io/utils"
+	"github.com/spf13/cobra"
+)
+
+// Options shared by all commands using a metadata file
+var metadataFile string
+var metadataSep string
+var metadataNameColumn string
+var metadataDateColumn string
+var metadataWhere string
+var metadataOutput string
+
+// addMetadataFlags adds the metadata options to the given command.
+// If where is true, it also adds the --where filtering option, and
+// if out is true, the --metadata-out option.
+func addMetadataFlags(c *cobra.Command, where, out bool) {
+	c.PersistentFlags().StringVar(&metadataFile, "metadata", "none", "Metadata file (tab separated, or comma separated if .csv), with a header line")
+	c.PersistentFlags().StringVar(&metadataNameColumn, "name-column", "", "Metadata column giving sequence names (default: first column)")
+	c.PersistentFlags().StringVar(&metadataSep, "metadata-sep", "", "Metadata field separator (default: tab, or comma if the file extension is .csv)")
+	c.PersistentFlags().StringVar(&metadataDateColumn, "date-column", "date", "Metadata column from which year, month, week and day are derived")
+	if where {
+		c.PersistentFlags().StringVar(&metadataWhere, "where", "none", "Keeps only sequences whose metadata satisfy the given expression (ex: \"country=='FR' && date>='2024-01-01'\")")
+	}
+	if out {
+		c.PersistentFlags().StringVar(&metadataOutput, "metadata-out", "none", "Writes metadata of output sequences in the given file")
+	}
+}
+
+// readMetadata reads the metadata file given with --metadata.
+// Returns a nil metadata if no metadata file is given.
+func readMetadata() (md *metadata.Metadata, err error) {
+	if metadataFile == "none" {
+		if metadataWhere != "none" {
+			err = fmt.Errorf("--where needs a metadata file (--metadata)")
+		}
+		return
+	}
+	if md, err = metadata.FromFile(metadataFile, metadataSep, metadataNameColumn); err != nil {
+		return
+	}
+	md.SetDateColumn(metadataDateColumn)
+	return
+}
+
+// metadataFilter returns the set of sequence names satisfying the --where
+// expression. Returns a nil map if no expression is given.
+// Sequences that are not present in the metadata do not satisfy the
+// expression, and a warning is printed.
+func metadataFilter(md *metadata.Metadata, sb align.SeqBag) (selected map[string]bool, err error) {
+	var expr *metadata.Expression
+	var kept, missing []string
+
+	if md == nil || metadataWhere == "none" {
+		return
+	}
+	if expr, err = metadata.CompileExpression(metadataWhere); err != nil {
+		return
+	}
+	if kept, missing, err = expr.Filter(md, seqNames(sb)); err != nil {
+		return
+	}
+	if len(missing) > 0 {
+		io.PrintMessage(fmt.Sprintf("%d sequences are not present in the metadata, they are filtered out", len(missing)))
+	}
+	selected = make(map[string]bool)
+	for _, n := range kept {
+		selected[n] = true
+	}
+	return
+}
+
+// metadataRenameMap computes the new name of each sequence of the seqbag by
+// rendering the given template with its metadata, and adds it to the namemap.
+// Placeholders refer to metadata columns, {name} being the current name (if
+// there is no such column).
+// Sequences that are not present in the metadata are not renamed, and a warning
+// is printed.
+func metadataRenameMap(md *metadata.Metadata, template string, sb align.SeqBag, namemap map[string]string) (err error) {
+	var t *gutils.Template
+	var newname string
+	var missing int = 0
+
+	if t, err = gutils.ParseTemplate(template); err != nil {
+		return
+	}
+	for _, k := range t.Keys() {
+		if k != "name" && !md.HasColumn(k) {
+			err = fmt.Errorf("template column %s does not exist in the metadata", k)
+			return
+		}
+	}
+	for _, name := range seqNames(sb) {
+		if !md.Has(name) {
+			missing++
+			continue
+		}
+		if newname, err = t.Render(func(key string) (string, error) { return md.Lookup(name, key) }); err != nil {
+			return
+		}
+		namemap[name] = newname
+	}
+	if missing > 0 {
+		io.PrintMessage(fmt.Sprintf("%d sequences are not present in the metadata, they are not renamed", missing))
+	}
+	return
+}
+
+// writeMetadata writes the metadata of the given sequences in the file given by --metadata-out
+func writeMetadata(md *metadata.Metadata, names []string) (err error) {
+	var f utils.StringWriterCloser
+
+	if md == nil || metadataOutput == "none" {
+		return
+	}
+	if f, err = utils.OpenWriteFile(metadataOutput); err != nil {
+		return
+	}
+	defer utils.CloseWriteFile(f, metadataOutput)
+	err = md.Write(f, names)
+	return
+}
+
+// seqNames returns the names of the sequences of the seqbag, in order
+func seqNames(sb align.SeqBag) (names []string) {
+	names = make([]string, 0, sb.NbSequences())
+	sb.Iterate(func(name string, sequence string) bool {
+		names = append(names, name)
+		return false
+	})
+	return
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/evolbioinfo/goalign/align"
 	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/report"
 	"github.com/spf13/cobra"
 )
 
@@ -24,7 +25,12 @@ goalign stats nalign -i align.ph -p
 `,
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		var aligns *align.AlignChannel
+		var rw *report.Writer
 
+		if rw, err = reportWriter("nalign"); err != nil {
+			io.LogError(err)
+			return
+		}
 		if aligns, err = readalign(infile); err != nil {
 			io.LogError(err)
 			return
@@ -34,7 +40,16 @@ goalign stats nalign -i align.ph -p
 		for range aligns.Achan {
 			naligns++
 		}
-		fmt.Println(naligns)
+		if rw == nil {
+			fmt.Println(naligns)
+		} else {
+			t := report.NewTable("nalign")
+			t.AddRow(naligns)
+			if err = rw.Write(0, t); err != nil {
+				io.LogError(err)
+				return
+			}
+		}
 
 		if aligns.Err != nil {
 			err = aligns.Err
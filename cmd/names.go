@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// namesCmd represents the names command
+var namesCmd = &cobra.Command{
+	Use:   "names",
+	Short: "Extracts information from sequence names",
+	Long: `Extracts information from sequence names. For example:
+
+Extracting the fields of sequence names separated by '|' into a tab separated file:
+goalign names fields -i align.fa --split '|' > fields.tsv
+
+`,
+}
+
+func init() {
+	RootCmd.AddCommand(namesCmd)
+}
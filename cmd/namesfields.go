@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/evolbioinfo/goalign/align"
+	"github.com/evolbioinfo/goalign/gutils"
+	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/utils"
+	"github.com/spf13/cobra"
+)
+
+var namesFieldsOutput string
+var namesFieldsSplit string
+var namesFieldsRegexp string
+var namesFieldsColumns string
+var namesFieldsDates string
+
+// namesFieldsCmd represents the names fields command
+var namesFieldsCmd = &cobra.Command{
+	Use:   "fields",
+	Short: "Extracts fields of sequence names into a tab separated file",
+	Long: `Extracts fields of sequence names into a tab separated file.
+
+Sequence names are split into fields using the separator given with --split,
+or using the groups captured by the regexp given with --regexp. For example,
+with --split '|', the name "hCoV-19/France/XYZ/2021|EPI_ISL_123|2021-03-01"
+gives the fields "hCoV-19/France/XYZ/2021", "EPI_ISL_123" and "2021-03-01".
+
+The output file is tab separated, with a header line, and one line per
+sequence: the first column is the sequence name, and the following columns are
+the fields. Columns are named field1, field2, etc., or using the comma separated
+list of names given with --columns. The output file may be used as a metadata
+file (--metadata) in other commands, for example:
+
+goalign names fields -i align.fa --split '|' --columns strain,id,date > meta.tsv
+goalign subset -i align.fa --metadata meta.tsv --where "date>='2021-03-01'"
+
+Dates in the fields given with --date-fields (comma separated list of field
+numbers, 1-based) are normalized in ISO 8601 format, keeping their precision
+(ex: 2021/3/1 => 2021-03-01, 01/03/2021 => 2021-03-01, Mar-2021 => 2021-03).
+Dates that can not be parsed are left unchanged, and a warning is printed.
+
+If a name does not match the regexp, an error is returned.
+
+If the input alignment contains several alignments, will process all of them,
+names present in several alignments being written only once.
+`,
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		var f utils.StringWriterCloser
+		var r *regexp.Regexp
+		var columns []string
+		var datefields []int
+		var rows [][]string = make([][]string, 0)
+		var done map[string]bool = make(map[string]bool)
+		var nfields, baddates int = 0, 0
+
+		if !cmd.Flags().Changed("split") && !cmd.Flags().Changed("regexp") {
+			err = errors.New("--split or --regexp must be given")
+			io.LogError(err)
+			return
+		}
+		if cmd.Flags().Changed("regexp") {
+			if r, err = regexp.Compile(namesFieldsRegexp); err != nil {
+				io.LogError(err)
+				return
+			}
+		}
+		columns = splitColumns(namesFieldsColumns)
+		for _, d := range splitColumns(namesFieldsDates) {
+			var i int
+			if i, err = strconv.Atoi(d); err != nil || i < 1 {
+				err = fmt.Errorf("date field %s is not a valid field number", d)
+				io.LogError(err)
+				return
+			}
+			datefields = append(datefields, i)
+		}
+
+		addrows := func(sb align.SeqBag) (err error) {
+			var fields []string
+			for _, name := range seqNames(sb) {
+				if done[name] {
+					continue
+				}
+				done[name] = true
+				if fields, err = align.NameFields(name, namesFieldsSplit, r); err != nil {
+					return
+				}
+				for _, d := range datefields {
+					if d <= len(fields) && fields[d-1] != "" {
+						if norm, errdate := gutils.NormalizeDate(fields[d-1]); errdate == nil {
+							fields[d-1] = norm
+						} else {
+							baddates++
+						}
+					}
+				}
+				nfields = max(nfields, len(fields))
+				rows = append(rows, append([]string{name}, fields...))
+			}
+			return
+		}
+
+		if unaligned {
+			var seqs align.SeqBag
+			if seqs, err = readsequences(infile); err != nil {
+				io.LogError(err)
+				return
+			}
+			if err = addrows(seqs); err != nil {
+				io.LogError(err)
+				return
+			}
+		} else {
+			var aligns *align.AlignChannel
+			if aligns, err = readalign(infile); err != nil {
+				io.LogError(err)
+				return
+			}
+			for al := range aligns.Achan {
+				if err = addrows(al); err != nil {
+					io.LogError(err)
+					return
+				}
+			}
+			if aligns.Err != nil {
+				err = aligns.Err
+				io.LogError(err)
+				return
+			}
+		}
+
+		if len(columns) > nfields {
+			err = fmt.Errorf("%d column names are given while names have at most %d fields", len(columns), nfields)
+			io.LogError(err)
+			return
+		}
+		if baddates > 0 {
+			io.PrintMessage(fmt.Sprintf("%d dates could not be normalized, they are left unchanged", baddates))
+		}
+
+		if f, err = utils.OpenWriteFile(namesFieldsOutput); err != nil {
+			io.LogError(err)
+			return
+		}
+		defer utils.CloseWriteFile(f, namesFieldsOutput)
+
+		header := []string{"name"}
+		for i := 0; i < nfields; i++ {
+			if i < len(columns) {
+				header = append(header, columns[i])
+			} else {
+				header = append(header, fmt.Sprintf("field%d", i+1))
+			}
+		}
+		f.WriteString(strings.Join(header, "\t") + "\n")
+		for _, row := range rows {
+			// Names having less fields are completed with empty values
+			for len(row) < nfields+1 {
+				row = append(row, "")
+			}
+			f.WriteString(strings.Join(row, "\t") + "\n")
+		}
+		return
+	},
+}
+
+func init() {
+	namesCmd.AddCommand(namesFieldsCmd)
+	namesFieldsCmd.PersistentFlags().StringVarP(&namesFieldsOutput, "output", "o", "stdout", "Output tab separated file")
+	namesFieldsCmd.PersistentFlags().StringVar(&namesFieldsSplit, "split", "", "Splits sequence names with the given separator")
+	namesFieldsCmd.PersistentFlags().StringVarP(&namesFieldsRegexp, "regexp", "e", "", "Extracts the groups captured by the given regexp (priority over --split)")
+	namesFieldsCmd.PersistentFlags().StringVar(&namesFieldsColumns, "columns", "", "Comma separated list of column names (default: field1, field2, ...)")
+	namesFieldsCmd.PersistentFlags().StringVar(&namesFieldsDates, "date-fields", "", "Comma separated list of fields (1-based) containing dates to normalize")
+	namesFieldsCmd.PersistentFlags().BoolVar(&unaligned, "unaligned", false, "Considers sequences as unaligned and format fasta (phylip, nexus,... options are ignored)")
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/evolbioinfo/goalign/align"
 	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/report"
 	"github.com/spf13/cobra"
 )
 
@@ -23,7 +24,12 @@ goalign stats nseq -i align.phylip -p
 goalign stats nseq -i align.fasta
 `,
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		var rw *report.Writer
 
+		if rw, err = reportWriter("nseq"); err != nil {
+			io.LogError(err)
+			return
+		}
 		if unaligned {
 			var seqs align.SeqBag
 
@@ -31,7 +37,10 @@ goalign stats nseq -i align.fasta
 				io.LogError(err)
 				return
 			}
-			fmt.Println(seqs.NbSequences())
+			if err = printNbSequences(rw, 0, seqs); err != nil {
+				io.LogError(err)
+				return
+			}
 		} else {
 			var aligns *align.AlignChannel
 
@@ -40,8 +49,13 @@ goalign stats nseq -i align.fasta
 				return
 			}
 
+			nb := 0
 			for al := range aligns.Achan {
-				fmt.Println(al.NbSequences())
+				if err = printNbSequences(rw, nb, al); err != nil {
+					io.LogError(err)
+					return
+				}
+				nb++
 			}
 
 			if aligns.Err != nil {
@@ -53,6 +67,16 @@ goalign stats nseq -i align.fasta
 	},
 }
 
+func printNbSequences(rw *report.Writer, nb int, sb align.SeqBag) (err error) {
+	if rw == nil {
+		fmt.Println(sb.NbSequences())
+		return
+	}
+	t := report.NewTable("nseqs")
+	t.AddRow(sb.NbSequences())
+	return rw.Write(nb, t)
+}
+
 func init() {
 	nseqCmd.PersistentFlags().BoolVar(&unaligned, "unaligned", false, "Considers sequences as unaligned and format fasta (phylip, nexus,... options are ignored)")
 	statsCmd.AddCommand(nseqCmd)
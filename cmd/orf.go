@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/evolbioinfo/goalign/align"
 	"github.com/evolbioinfo/goalign/io"
 	"github.com/evolbioinfo/goalign/io/utils"
@@ -9,6 +11,16 @@ import (
 
 var orfOutput string
 var orfreverse bool
+var orfGeneticCode string
+var orfAltStart bool
+var orfAll bool
+var orfMinLen int
+var orfStopToStop bool
+var orfNested bool
+var orfPartial bool
+var orfAaOutput string
+var orfGffOutput string
+var orfBedOutput string
 
 // translateCmd represents the addid command
 var orfCmd = &cobra.Command{
@@ -19,13 +31,43 @@ var orfCmd = &cobra.Command{
 If input sequences are not nucleotidic, then returns an error.
 If input sequences are aligned (contain '-'), then they are unaligned first.
 
+Stop codons are given by the genetic code (--genetic-code). Start codons are
+ATG, or all the initiation codons of the genetic code if --alt-start is given
+(ex: TTG, CTG and ATG for the standard code, and TTG, CTG, ATT, ATC, ATA, ATG
+and GTG for the bacterial code, 11).
+
 Output is in fasta format.
+
+If --all is given, then all the ORFs of at least --min-len nucleotides (including
+the stop codon) are reported, in the 3 frames of the forward strand, and in the
+3 frames of the reverse strand if --reverse is given (6 frames), similar to NCBI
+ORFfinder. In this mode:
+- --stop-to-stop: ORFs go from a stop codon (or the start of the sequence) to
+  the next stop codon, whatever the start codon;
+- --nested: For each stop codon, reports also the ORFs starting at the start
+  codons following the first one (by default only the longest ORF is reported);
+- --partial: Reports also the ORFs without stop codon at the end of the sequences;
+- -o: Output nucleotide sequences of the ORFs (on their strand, with stop codon);
+- --aa-output: Output protein sequences of the ORFs (without stop codon, first
+  codon translated as M if it is a start codon);
+- --gff: Output ORF coordinates in GFF3 format (1-based);
+- --bed: Output ORF coordinates in BED format (0-based, end excluded, score 0).
+
+ORFs are named <sequence>_orf<i>, and the fasta comments give their coordinates
+on the forward strand (1-based), strand and frame. Ex:
+	goalign orf -i seqs.fa --all --reverse --min-len 300 -o orfs.fa --aa-output orfs_aa.fa --gff orfs.gff
 `,
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		var f utils.StringWriterCloser
 		var reforf align.SeqBag
 		var inseqs align.SeqBag
 		var orf align.Sequence
+		var geneticcode int
+
+		if geneticcode, err = parseGeneticCode(orfGeneticCode); err != nil {
+			io.LogError(err)
+			return
+		}
 
 		if f, err = utils.OpenWriteFile(orfOutput); err != nil {
 			io.LogError(err)
@@ -40,7 +82,12 @@ Output is in fasta format.
 
 		inseqs = inseqs.Unalign()
 
-		if orf, err = inseqs.LongestORF(orfreverse); err != nil {
+		if orfAll {
+			err = findAllORFs(inseqs, geneticcode, f)
+			return
+		}
+
+		if orf, err = inseqs.LongestORF(orfreverse, geneticcode, orfAltStart); err != nil {
 			io.LogError(err)
 			return
 		}
@@ -57,4 +104,112 @@ func init() {
 	RootCmd.AddCommand(orfCmd)
 	orfCmd.PersistentFlags().StringVarP(&orfOutput, "output", "o", "stdout", "ORF Output Fasta File")
 	orfCmd.PersistentFlags().BoolVar(&orfreverse, "reverse", false, "Search for the longest ORF ALSO in the reverse strand")
+	orfCmd.PersistentFlags().BoolVar(&orfAltStart, "alt-start", false, "Considers all the initiation codons of the genetic code as start codons (default: only ATG)")
+	orfCmd.PersistentFlags().BoolVar(&orfAll, "all", false, "Reports all the ORFs longer than --min-len instead of the longest one")
+	orfCmd.PersistentFlags().IntVar(&orfMinLen, "min-len", 75, "Minimum ORF length in nucleotides, including stop codon (--all only)")
+	orfCmd.PersistentFlags().BoolVar(&orfStopToStop, "stop-to-stop", false, "ORFs go from stop to stop, whatever the start codon (--all only)")
+	orfCmd.PersistentFlags().BoolVar(&orfNested, "nested", false, "Reports also nested ORFs starting at following start codons (--all only)")
+	orfCmd.PersistentFlags().BoolVar(&orfPartial, "partial", false, "Reports also ORFs without stop codon at the end of sequences (--all only)")
+	orfCmd.PersistentFlags().StringVar(&orfAaOutput, "aa-output", "none", "ORF protein output Fasta File (--all only)")
+	orfCmd.PersistentFlags().StringVar(&orfGffOutput, "gff", "none", "ORF GFF3 output file (--all only)")
+	orfCmd.PersistentFlags().StringVar(&orfBedOutput, "bed", "none", "ORF BED output file (--all only)")
+	addGeneticCodeFlag(orfCmd, &orfGeneticCode)
+}
+
+// findAllORFs searches all the ORFs of the input sequences, and writes
+// them in the nt output file, and in the aa, gff and bed files if given
+func findAllORFs(inseqs align.SeqBag, geneticcode int, ntout utils.StringWriterCloser) (err error) {
+	var orfs []align.ORF
+	var startmode = align.ORF_START_ATG
+	var ntseqs, aaseqs align.SeqBag
+	var aaout, gffout, bedout utils.StringWriterCloser
+
+	if orfAltStart {
+		startmode = align.ORF_START_ALT
+	}
+	if orfStopToStop {
+		startmode = align.ORF_START_ANY
+	}
+
+	if orfs, err = inseqs.FindORFs(geneticcode, startmode, orfMinLen, orfreverse, orfNested, orfPartial); err != nil {
+		io.LogError(err)
+		return
+	}
+
+	ntseqs = align.NewSeqBag(align.NUCLEOTIDS)
+	aaseqs = align.NewSeqBag(align.AMINOACIDS)
+	names := make([]string, len(orfs))
+	count := make(map[string]int)
+	for i, o := range orfs {
+		count[o.Name]++
+		names[i] = fmt.Sprintf("%s_orf%d", o.Name, count[o.Name])
+		strand := "+"
+		if o.Strand < 0 {
+			strand = "-"
+		}
+		comment := fmt.Sprintf("%s:%d-%d strand=%s frame=%d length=%d", o.Name, o.Start+1, o.End, strand, o.Frame, o.Length())
+		if o.Partial {
+			comment += " partial"
+		}
+		if o.Nested {
+			comment += " nested"
+		}
+		if err = ntseqs.AddSequenceChar(names[i], o.Nt, comment); err != nil {
+			io.LogError(err)
+			return
+		}
+		if err = aaseqs.AddSequenceChar(names[i], o.Aa, comment); err != nil {
+			io.LogError(err)
+			return
+		}
+	}
+	writeSequences(ntseqs, ntout)
+
+	if orfAaOutput != "none" {
+		if aaout, err = utils.OpenWriteFile(orfAaOutput); err != nil {
+			io.LogError(err)
+			return
+		}
+		defer utils.CloseWriteFile(aaout, orfAaOutput)
+		writeSequences(aaseqs, aaout)
+	}
+
+	if orfGffOutput != "none" {
+		if gffout, err = utils.OpenWriteFile(orfGffOutput); err != nil {
+			io.LogError(err)
+			return
+		}
+		defer utils.CloseWriteFile(gffout, orfGffOutput)
+		gffout.WriteString("##gff-version 3\n")
+		for i, o := range orfs {
+			strand := "+"
+			if o.Strand < 0 {
+				strand = "-"
+			}
+			attr := fmt.Sprintf("ID=%s;frame=%d;start_codon=%s", names[i], o.Frame, o.StartCodon)
+			if o.Partial {
+				attr += ";partial=true"
+			}
+			if o.Nested {
+				attr += ";nested=true"
+			}
+			gffout.WriteString(fmt.Sprintf("%s\tgoalign\tORF\t%d\t%d\t.\t%s\t0\t%s\n", o.Name, o.Start+1, o.End, strand, attr))
+		}
+	}
+
+	if orfBedOutput != "none" {
+		if bedout, err = utils.OpenWriteFile(orfBedOutput); err != nil {
+			io.LogError(err)
+			return
+		}
+		defer utils.CloseWriteFile(bedout, orfBedOutput)
+		for i, o := range orfs {
+			strand := "+"
+			if o.Strand < 0 {
+				strand = "-"
+			}
+			bedout.WriteString(fmt.Sprintf("%s\t%d\t%d\t%s\t0\t%s\n", o.Name, o.Start, o.End, names[i], strand))
+		}
+	}
+	return
 }
@@ -19,6 +19,7 @@ var lencutoff float64
 var matchcutoff float64
 var phasereverse bool
 var phasecutend bool
+var phaseAltStart bool
 
 // translateCmd represents the addid command
 var phaseCmd = &cobra.Command{
@@ -29,7 +30,8 @@ var phaseCmd = &cobra.Command{
 This command "phases" input sequences on the basis on either a set of input sequences, or the longest detected orf.
 To do so, it will:
 
-1. Search for the longest ORF in the dataset if no reference orf(s) is(are) given;
+1. Search for the longest ORF in the dataset if no reference orf(s) is(are) given
+   (starting with ATG, or any initiation codon of the genetic code if --alt-start is given);
 2. Translate the given ORF(s) in aminoacids;
 3. For each sequence of the dataset: translate it in the 3 phases (or 6 if --reverse is given),
    align it with all the translated orfs, and take the phase and the reference orf giving the best alignment;
@@ -74,6 +76,10 @@ Output file is an unaligned set of sequences in fasta.
 		}
 		defer utils.CloseWriteFile(aaf, phaseAAOutput)
 
+		if geneticcode, err = parseGeneticCode(phaseGeneticCode); err != nil {
+			return
+		}
+
 		if unaligned {
 			if inseqs, err = readsequences(infile); err != nil {
 				io.LogError(err)
@@ -101,7 +107,7 @@ Output file is an unaligned set of sequences in fasta.
 			}
 		} else {
 			// We detect the orf
-			if orf, err = inseqs.LongestORF(phasereverse); err != nil {
+			if orf, err = inseqs.LongestORF(phasereverse, geneticcode, phaseAltStart); err != nil {
 				io.LogError(err)
 				return
 			}
@@ -111,18 +117,6 @@ Output file is an unaligned set of sequences in fasta.
 			reforf.AutoAlphabet()
 		}
 
-		switch phaseGeneticCode {
-		case "standard":
-			geneticcode = align.GENETIC_CODE_STANDARD
-		case "mitov":
-			geneticcode = align.GENETIC_CODE_VETEBRATE_MITO
-		case "mitoi":
-			geneticcode = align.GENETIC_CODE_INVETEBRATE_MITO
-		default:
-			err = fmt.Errorf("unknown genetic code : %s", phaseGeneticCode)
-			return
-		}
-
 		phaser := align.NewPhaser()
 		phaser.SetLenCutoff(lencutoff)
 		phaser.SetMatchCutoff(matchcutoff)
@@ -130,6 +124,7 @@ Output file is an unaligned set of sequences in fasta.
 		phaser.SetCutEnd(phasecutend)
 		phaser.SetCpus(rootcpus)
 		phaser.SetTranslate(true, geneticcode)
+		phaser.SetAltStart(phaseAltStart)
 		phaser.SetGapOpen(gapopen)
 		phaser.SetGapExtend(gapextend)
 
@@ -177,7 +172,7 @@ Output file is an unaligned set of sequences in fasta.
 func init() {
 	RootCmd.AddCommand(phaseCmd)
 	phaseCmd.PersistentFlags().StringVarP(&phaseOutput, "output", "o", "stdout", "Output \"phased\" FASTA file")
-	phaseCmd.PersistentFlags().StringVar(&phaseGeneticCode, "genetic-code", "standard", "Genetic Code: standard, mitoi (invertebrate mitochondrial) or mitov (vertebrate mitochondrial)")
+	addGeneticCodeFlag(phaseCmd, &phaseGeneticCode)
 	phaseCmd.PersistentFlags().StringVar(&phaseAAOutput, "aa-output", "none", "Output Met \"phased\" aa FASTA file")
 	phaseCmd.PersistentFlags().StringVarP(&phaseLogOutput, "log", "l", "none", "Output log: positions of the considered Start for each sequence")
 	phaseCmd.PersistentFlags().Float64Var(&lencutoff, "len-cutoff", -1.0, "Length cutoff, over orf length, to consider sequence hits (-1==No cutoff)")
@@ -188,6 +183,7 @@ func init() {
 	phaseCmd.PersistentFlags().Float64Var(&gapextend, "gap-extend", -0.5, "Score for extending a gap ")
 	phaseCmd.PersistentFlags().BoolVar(&unaligned, "unaligned", false, "Considers sequences as unaligned and only format fasta is accepted (phylip, nexus,... options are ignored)")
 	phaseCmd.PersistentFlags().BoolVar(&phasereverse, "reverse", false, "Search ALSO in the reverse strand (in addition to the forward strand)")
+	phaseCmd.PersistentFlags().BoolVar(&phaseAltStart, "alt-start", false, "Considers all the initiation codons of the genetic code as start codons of the longest ORF (default: only ATG)")
 	phaseCmd.PersistentFlags().BoolVar(&phasecutend, "cut-end", false, "Iftrue, then also remove the end of sequences that do not align with orf")
 	phaseCmd.PersistentFlags().StringVar(&orfsequence, "ref-orf", "none", "Reference ORF to phase against (if none is given, then will try to get the longest orf in the input data)")
 }
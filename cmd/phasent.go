@@ -22,7 +22,8 @@ Unlike goalign phase, it does not take into account translation of input sequenc
 This command "phases" input sequences on the basis on either a set of input sequences, or the longest detected orf.
 To do so, it will:
 
-1. Search for the longest ORF in the dataset if no reference orf(s) is(are) given;
+1. Search for the longest ORF in the dataset if no reference orf(s) is(are) given
+   (starting with ATG, or any initiation codon of the genetic code if --alt-start is given);
 2. For each sequence of the dataset: will take the sequence in forward and revcomp (if --reverse is given),
    align it with all ref orfs, and take the phase (fwd or revcomp) and the reference orf giving the best alignment;
    If no phase gives a good alignment in any reference orf (cutoffs given by --len-cutoff and --match-cutoff),
@@ -78,6 +79,10 @@ Output file is an unaligned set of sequences in fasta.
 		}
 		defer utils.CloseWriteFile(logf, phaseLogOutput)
 
+		if geneticcode, err = parseGeneticCode(phaseGeneticCode); err != nil {
+			return
+		}
+
 		if unaligned {
 			if inseqs, err = readsequences(infile); err != nil {
 				io.LogError(err)
@@ -105,7 +110,7 @@ Output file is an unaligned set of sequences in fasta.
 			}
 		} else {
 			// We detect the orf
-			if orf, err = inseqs.LongestORF(phasereverse); err != nil {
+			if orf, err = inseqs.LongestORF(phasereverse, geneticcode, phaseAltStart); err != nil {
 				io.LogError(err)
 				return
 			}
@@ -115,18 +120,6 @@ Output file is an unaligned set of sequences in fasta.
 			reforf.AutoAlphabet()
 		}
 
-		switch phaseGeneticCode {
-		case "standard":
-			geneticcode = align.GENETIC_CODE_STANDARD
-		case "mitov":
-			geneticcode = align.GENETIC_CODE_VETEBRATE_MITO
-		case "mitoi":
-			geneticcode = align.GENETIC_CODE_INVETEBRATE_MITO
-		default:
-			err = fmt.Errorf("unknown genetic code : %s", phaseGeneticCode)
-			return
-		}
-
 		phaser := align.NewPhaser()
 		phaser.SetLenCutoff(lencutoff)
 		phaser.SetMatchCutoff(matchcutoff)
@@ -134,6 +127,7 @@ Output file is an unaligned set of sequences in fasta.
 		phaser.SetCutEnd(phasecutend)
 		phaser.SetCpus(rootcpus)
 		phaser.SetTranslate(false, geneticcode)
+		phaser.SetAltStart(phaseAltStart)
 		phaser.SetGapOpen(gapopen)
 		phaser.SetGapExtend(gapextend)
 
@@ -200,7 +194,7 @@ func init() {
 	RootCmd.AddCommand(phasentCmd)
 	phasentCmd.PersistentFlags().StringVarP(&phaseOutput, "output", "o", "stdout", "Output ATG \"phased\" FASTA file")
 	phasentCmd.PersistentFlags().StringVar(&phaseCodonOutput, "nt-output", "none", "Output ATG \"phased\" FASTA file + first nts not in ref phase removed (nt corresponding to aa-output sequence)")
-	phasentCmd.PersistentFlags().StringVar(&phaseGeneticCode, "genetic-code", "standard", "Genetic Code: standard, mitoi (invertebrate mitochondrial) or mitov (vertebrate mitochondrial)")
+	addGeneticCodeFlag(phasentCmd, &phaseGeneticCode)
 	phasentCmd.PersistentFlags().StringVar(&phaseAAOutput, "aa-output", "none", "Output translated sequences FASTA file")
 	phasentCmd.PersistentFlags().StringVarP(&phaseLogOutput, "log", "l", "none", "Output log: positions of the considered ATG for each sequence")
 	phasentCmd.PersistentFlags().Float64Var(&lencutoff, "len-cutoff", -1.0, "Length cutoff, over orf length, to consider sequence hits (-1==No cutoff)")
@@ -211,6 +205,7 @@ func init() {
 	phasentCmd.PersistentFlags().Float64Var(&gapextend, "gap-extend", -0.5, "Score for extending a gap ")
 	phasentCmd.PersistentFlags().BoolVar(&unaligned, "unaligned", false, "Considers sequences as unaligned and only format fasta is accepted (phylip, nexus,... options are ignored)")
 	phasentCmd.PersistentFlags().BoolVar(&phasereverse, "reverse", false, "Search ALSO in the reverse strand (in addition to the forward strand)")
+	phasentCmd.PersistentFlags().BoolVar(&phaseAltStart, "alt-start", false, "Considers all the initiation codons of the genetic code as start codons of the longest ORF (default: only ATG)")
 	phasentCmd.PersistentFlags().BoolVar(&phasecutend, "cut-end", false, "If true, then also remove the end of sequences that do not align with orf")
 	phasentCmd.PersistentFlags().StringVar(&orfsequence, "ref-orf", "none", "Reference ORF to phase against (if none is given, then will try to get the longest orf in the input data)")
 }
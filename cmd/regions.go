@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/evolbioinfo/goalign/align"
+	"github.com/spf13/cobra"
+)
+
+// Options shared by all commands computing statistics on regions of alignments
+var regionPartition string
+var regionWindowSize int
+var regionWindowStep int
+
+// alignRegion is a set of sites of an alignment on which statistics are computed
+type alignRegion struct {
+	name       string
+	start, end interface{} // First (0-based) and last (excluded) site, nil for partitions
+	al         align.Alignment
+}
+
+// addRegionFlags adds the options defining the regions on which statistics are
+// computed: partitions (--partition) or sliding windows (--window-size and --window-step)
+func addRegionFlags(c *cobra.Command) {
+	c.PersistentFlags().StringVar(&regionPartition, "partition", "none", "File containing definition of the partitions: statistics are computed on each partition")
+	c.PersistentFlags().IntVar(&regionWindowSize, "window-size", 0, "If > 0, statistics are computed on sliding windows of the given size")
+	c.PersistentFlags().IntVar(&regionWindowStep, "window-step", 0, "Step between sliding windows (default: window size)")
+}
+
+// alignRegions returns the regions of the alignment defined by --partition or
+// --window-size/--window-step. If none is given, the region is the whole alignment,
+// named "all".
+func alignRegions(al align.Alignment) (regions []alignRegion, err error) {
+	switch {
+	case regionPartition != "none" && regionWindowSize > 0:
+		err = errors.New("--partition and --window-size are mutually exclusive")
+	case regionPartition != "none":
+		var ps *align.PartitionSet
+		var parts []align.Alignment
+		if ps, err = parsePartition(regionPartition, al.Length()); err != nil {
+			return
+		}
+		if parts, err = al.Split(ps); err != nil {
+			return
+		}
+		for i, p := range parts {
+			regions = append(regions, alignRegion{name: ps.PartitionName(i), al: p})
+		}
+	case regionWindowSize > 0:
+		var sub align.Alignment
+		step := regionWindowStep
+		if step <= 0 {
+			step = regionWindowSize
+		}
+		for start := 0; start+regionWindowSize <= al.Length(); start += step {
+			if sub, err = al.SubAlign(start, regionWindowSize); err != nil {
+				return
+			}
+			regions = append(regions, alignRegion{name: "window", start: start, end: start + regionWindowSize, al: sub})
+		}
+	default:
+		regions = []alignRegion{{name: "all", start: 0, end: al.Length(), al: al}}
+	}
+	return
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/evolbioinfo/goalign/align"
 	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/metadata"
 	"github.com/evolbioinfo/goalign/io/utils"
 	"github.com/spf13/cobra"
 )
@@ -15,6 +16,8 @@ var renameCleanNames bool
 var renameOutput string
 var renameRegexp string
 var renameReplace string
+var renameTemplate string
+var renameSplit string
 
 // renameCmd represents the rename command
 var renameCmd = &cobra.Command{
@@ -45,21 +48,71 @@ Different options:
    And mapping between old and new names is written in 
    the file potentially given with --map-file
 
+4) --template is given along with --metadata:
+   Sequences are renamed using the given template, whose placeholders
+   between braces are replaced by the values of the corresponding columns
+   of the metadata file (tab separated, or comma separated if .csv, with
+   a header line, the column giving sequence names being the first one,
+   or the one given with --name-column). {name} is the current sequence
+   name. For example:
+   goalign rename -i al.fa --metadata meta.tsv --template "{lineage}|{name}|{date}" -m map.txt
+   Sequences that are not in the metadata file are not renamed.
+   And mapping between old and new names is written in 
+   the file potentially given with --map-file
+
+5) --template is given along with --split or --regexp:
+   Sequence names are split into fields using the separator given
+   with --split (or the groups captured by --regexp), and sequences
+   are renamed using the given template, whose placeholders refer to
+   these fields:
+     - {1}, {2}, ...: first, second, ... field;
+     - {-1}, {-2}, ...: last, second to last, ... field;
+     - {0} or {name}: the whole current name.
+   For example:
+   goalign rename -i al.fa --split '|' --template '{3}_{1}' -m map.txt
+   renames "hCoV-19/France/XYZ/2021|EPI_ISL_123|2021-03-01" into 
+   "2021-03-01_hCoV-19/France/XYZ/2021".
+   An error is returned if a field does not exist in a name.
+   And mapping between old and new names is written in 
+   the file potentially given with --map-file, so that the renaming
+   can be reverted with --map-file and --revert.
+
+In templates (4 and 5), a placeholder may be followed by a modifier:
+   - {3:date}: normalizes the date in ISO 8601 format, keeping its precision
+     (ex: 2021/3/1 => 2021-03-01, 01/03/2021 => 2021-03-01, Mar-2021 => 2021-03);
+   - {1:upper}, {1:lower}: converts the field to upper or lower case.
+
 In any case, option --unalign option will rename unaligned fasta files
 while ignoring formatting options (phylip, etc.).
 
 
 `,
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
-		var setregex, setreplace bool
+		var setregex, setreplace, settemplate, setsplit bool
 		var f utils.StringWriterCloser
 		var namemap map[string]string
+		var md *metadata.Metadata
 
 		setregex = cmd.Flags().Changed("regexp")
 		setreplace = cmd.Flags().Changed("replace")
+		settemplate = cmd.Flags().Changed("template")
+		setsplit = cmd.Flags().Changed("split")
 
-		if setregex && !setreplace {
-			err = errors.New("--replace must be given with --regexp")
+		if setregex && !setreplace && !settemplate {
+			err = errors.New("--replace or --template must be given with --regexp")
+			return
+		}
+		if setsplit && !settemplate {
+			err = errors.New("--template must be given with --split")
+			return
+		}
+
+		if md, err = readMetadata(); err != nil {
+			io.LogError(err)
+			return
+		}
+		if settemplate && md == nil && !setsplit && !setregex {
+			err = errors.New("--template must be given with --metadata, --split or --regexp")
 			return
 		}
 
@@ -70,7 +123,7 @@ while ignoring formatting options (phylip, etc.).
 		defer utils.CloseWriteFile(f, renameOutput)
 
 		// Read Map File
-		if !setregex && !renameCleanNames {
+		if !setregex && !renameCleanNames && !settemplate {
 			if renameMap == "none" {
 				err = errors.New("map file is not given")
 				return
@@ -90,15 +143,9 @@ while ignoring formatting options (phylip, etc.).
 				io.LogError(err)
 				return
 			}
-			if renameCleanNames {
-				seqs.CleanNames(namemap)
-			} else if setregex {
-				if err = seqs.RenameRegexp(renameRegexp, renameReplace, namemap); err != nil {
-					io.LogError(err)
-					return
-				}
-			} else {
-				seqs.Rename(namemap)
+			if err = renameSeqBag(seqs, md, namemap, setregex, settemplate); err != nil {
+				io.LogError(err)
+				return
 			}
 			writeSequences(seqs, f)
 		} else {
@@ -108,15 +155,9 @@ while ignoring formatting options (phylip, etc.).
 				return
 			}
 			for al := range aligns.Achan {
-				if renameCleanNames {
-					al.CleanNames(namemap)
-				} else if setregex {
-					if err = al.RenameRegexp(renameRegexp, renameReplace, namemap); err != nil {
-						io.LogError(err)
-						return
-					}
-				} else {
-					al.Rename(namemap)
+				if err = renameSeqBag(al, md, namemap, setregex, settemplate); err != nil {
+					io.LogError(err)
+					return
 				}
 				writeAlign(al, f)
 			}
@@ -126,7 +167,7 @@ while ignoring formatting options (phylip, etc.).
 			}
 		}
 
-		if (setregex || renameCleanNames) && renameMap != "None" {
+		if (setregex || renameCleanNames || settemplate) && renameMap != "none" {
 			writeNameMap(namemap, renameMap)
 		}
 
@@ -134,6 +175,31 @@ while ignoring formatting options (phylip, etc.).
 	},
 }
 
+// renameSeqBag renames the sequences of the seqbag according to the
+// given options, and adds the mapping between old and new names to namemap
+func renameSeqBag(sb align.SeqBag, md *metadata.Metadata, namemap map[string]string, setregex, settemplate bool) (err error) {
+	switch {
+	case renameCleanNames:
+		sb.CleanNames(namemap)
+	case settemplate && md != nil:
+		if err = metadataRenameMap(md, renameTemplate, sb, namemap); err != nil {
+			return
+		}
+		sb.Rename(namemap)
+	case settemplate:
+		regex := ""
+		if setregex {
+			regex = renameRegexp
+		}
+		err = sb.RenameTemplate(renameSplit, regex, renameTemplate, namemap)
+	case setregex:
+		err = sb.RenameRegexp(renameRegexp, renameReplace, namemap)
+	default:
+		sb.Rename(namemap)
+	}
+	return
+}
+
 func init() {
 	RootCmd.AddCommand(renameCmd)
 
@@ -142,6 +208,9 @@ func init() {
 	renameCmd.PersistentFlags().StringVarP(&renameOutput, "output", "o", "stdout", "renamed alignment output file")
 	renameCmd.PersistentFlags().StringVarP(&renameRegexp, "regexp", "e", "none", "rename alignment using given regexp")
 	renameCmd.PersistentFlags().StringVarP(&renameReplace, "replace", "b", "none", "replaces regexp matching strings by this string")
+	renameCmd.PersistentFlags().StringVar(&renameTemplate, "template", "none", "renames sequences using the given template, and metadata columns (ex: \"{lineage}|{name}|{date}\", with --metadata) or name fields (ex: \"{3:date}_{1}\", with --split or --regexp)")
+	renameCmd.PersistentFlags().StringVar(&renameSplit, "split", "", "splits sequence names with the given separator into fields usable in --template")
+	addMetadataFlags(renameCmd, false, false)
 	renameCmd.PersistentFlags().BoolVar(&renameCleanNames, "clean-names", false, "Replaces special characters (tabs, spaces, newick characters) with '-' from input sequence names before writing output alignment")
 	renameCmd.PersistentFlags().BoolVar(&unaligned, "unaligned", false, "Considers sequences as unaligned and format fasta (phylip, nexus,... options are ignored)")
 }
@@ -31,16 +31,7 @@ var replaceStopCmd = &cobra.Command{
 			return
 		}
 
-		switch replaceGeneticCode {
-		case "standard":
-			geneticcode = align.GENETIC_CODE_STANDARD
-		case "mitov":
-			geneticcode = align.GENETIC_CODE_VETEBRATE_MITO
-		case "mitoi":
-			geneticcode = align.GENETIC_CODE_INVETEBRATE_MITO
-
-		default:
-			err = fmt.Errorf("unknown genetic code : %s", replaceGeneticCode)
+		if geneticcode, err = parseGeneticCode(replaceGeneticCode); err != nil {
 			return
 		}
 
@@ -87,6 +78,6 @@ func init() {
 	replaceCmd.AddCommand(replaceStopCmd)
 
 	replaceStopCmd.PersistentFlags().IntVar(&replacePhase, "phase", 0, "Phase in which replace STOP codons")
-	replaceStopCmd.PersistentFlags().StringVar(&replaceGeneticCode, "genetic-code", "standard", "Genetic Code: standard, mitoi (invertebrate mitochondrial) or mitov (vertebrate mitochondrial)")
+	addGeneticCodeFlag(replaceStopCmd, &replaceGeneticCode)
 
 }
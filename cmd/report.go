@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	goio "io"
+	"os"
+
+	"github.com/evolbioinfo/goalign/io/report"
+	"github.com/spf13/cobra"
+)
+
+// Output format of reporting commands (text, tsv or json)
+var reportFormat string
+
+// addFormatFlag adds the --format option to the given reporting command
+func addFormatFlag(c *cobra.Command) {
+	c.PersistentFlags().StringVar(&reportFormat, "format", "text", "Output format: text (historical format of the command), tsv (with an alignment column), or json (one document per line and per input alignment)")
+}
+
+// reportWriter returns a writer of tables on stdout, in the format given
+// with --format. Returns a nil writer if the format is text.
+func reportWriter(command string) (rw *report.Writer, err error) {
+	var format int
+
+	if format, err = report.FormatFromString(reportFormat); err != nil {
+		return
+	}
+	if format == report.FORMAT_TEXT {
+		return
+	}
+	rw, err = report.NewWriter(os.Stdout, format, command)
+	return
+}
+
+// tableWriter returns a writer of tables on w, in the format given
+// with --format, for commands whose text format is tsv.
+func tableWriter(w goio.StringWriter, command string) (rw *report.Writer, err error) {
+	var format int
+
+	if format, err = report.FormatFromString(reportFormat); err != nil {
+		return
+	}
+	if format == report.FORMAT_TEXT {
+		format = report.FORMAT_TSV
+	}
+	rw, err = report.NewWriter(w, format, command)
+	return
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"github.com/evolbioinfo/goalign/align"
 	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/metadata"
 	"github.com/evolbioinfo/goalign/io/utils"
 	"github.com/spf13/cobra"
 )
@@ -31,9 +32,25 @@ For each input alignment
 It is advised to manipulate phylip alignments, in order to be able to
 divide the output file with 'goalign divide' for example.
 
+If --where is given with a metadata file (--metadata), sequences are sampled
+only among those whose metadata satisfy the given expression (see goalign subset),
+for example:
+
+goalign sample seqs -i al.fa -n 10 --metadata meta.tsv --where "country=='FR'"
+
+If --metadata-out is given, metadata of the sampled sequences are written in
+the given file.
 `,
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		var f utils.StringWriterCloser
+		var md *metadata.Metadata
+		var selected map[string]bool
+		var sampled []string = make([]string, 0)
+
+		if md, err = readMetadata(); err != nil {
+			io.LogError(err)
+			return
+		}
 
 		if f, err = utils.OpenWriteFile(sampleseqOutput); err != nil {
 			io.LogError(err)
@@ -49,12 +66,27 @@ divide the output file with 'goalign divide' for example.
 				io.LogError(err)
 				return
 			}
+			if selected, err = metadataFilter(md, seqs); err != nil {
+				io.LogError(err)
+				return
+			}
+			if selected != nil {
+				filtered := align.NewSeqBag(seqs.Alphabet())
+				seqs.IterateAll(func(name string, sequence []uint8, comment string) bool {
+					if selected[name] {
+						filtered.AddSequenceChar(name, sequence, comment)
+					}
+					return false
+				})
+				seqs = filtered
+			}
 			for i := 0; i < sampleseqNbSamples; i++ {
 				if sample, err = seqs.SampleSeqBag(sampleseqSize, globalRand); err != nil {
 					io.LogError(err)
 					return
 				}
 				writeSequences(sample, f)
+				sampled = append(sampled, seqNames(sample)...)
 			}
 		} else {
 			var aligns *align.AlignChannel
@@ -66,20 +98,39 @@ divide the output file with 'goalign divide' for example.
 			}
 
 			for al := range aligns.Achan {
+				if selected, err = metadataFilter(md, al); err != nil {
+					io.LogError(err)
+					return
+				}
+				if selected != nil {
+					filtered := align.NewAlign(al.Alphabet())
+					al.IterateAll(func(name string, sequence []uint8, comment string) bool {
+						if selected[name] {
+							filtered.AddSequenceChar(name, sequence, comment)
+						}
+						return false
+					})
+					al = filtered
+				}
 				for i := 0; i < sampleseqNbSamples; i++ {
 					if sample, err = al.Sample(sampleseqSize, globalRand); err != nil {
 						io.LogError(err)
 						return
 					}
 					writeAlign(sample, f)
+					sampled = append(sampled, seqNames(sample)...)
 				}
 			}
 
 			if aligns.Err != nil {
 				err = aligns.Err
 				io.LogError(err)
+				return
 			}
 		}
+		if err = writeMetadata(md, sampled); err != nil {
+			io.LogError(err)
+		}
 		return
 	},
 }
@@ -89,5 +140,6 @@ func init() {
 	sampleseqCmd.PersistentFlags().BoolVar(&unaligned, "unaligned", false, "Considers sequences as unaligned and format fasta (phylip, nexus,... options are ignored)")
 	sampleseqCmd.PersistentFlags().IntVarP(&sampleseqSize, "nb-seq", "n", 1, "Number of sequences to sample from the alignment")
 	sampleseqCmd.PersistentFlags().IntVarP(&sampleseqNbSamples, "nb-samples", "s", 1, "Number of samples to generate")
+	addMetadataFlags(sampleseqCmd, true, true)
 	sampleseqCmd.PersistentFlags().StringVarP(&sampleseqOutput, "output", "o", "stdout", "Sampled alignment output file")
 }
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/evolbioinfo/goalign/align"
+	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/metadata"
+	"github.com/evolbioinfo/goalign/io/utils"
+	"github.com/spf13/cobra"
+)
+
+var stratifiedOutput string
+var stratifiedGroupBy string
+var stratifiedMaxPerGroup int
+var stratifiedWeightColumn string
+
+// stratifiedCmd represents the sample stratified command
+var stratifiedCmd = &cobra.Command{
+	Use:   "stratified",
+	Short: "Samples sequences within groups defined by a metadata file",
+	Long: `Samples sequences within groups defined by a metadata file.
+
+The metadata file is a tabulated file (or comma separated if its extension
+is .csv, or if --metadata-sep , is given) with a header line, and one line per sequence.
+The column giving sequence names is the first one, or the one given with
+--name-column.
+
+Sequences are grouped according to the values of the columns given with
+--group-by (comma separated), and at most --max-per-group sequences are
+sampled in each group.
+
+Columns year, month, week and day may be used in --group-by even if they are
+not present in the metadata file: they are derived from the date column
+(given by --date-column). For example:
+
+goalign sample stratified -i align.fa --metadata meta.tsv --group-by country,month --max-per-group 10
+
+By default, sequences are sampled uniformly in each group. If --weight-column
+is given, then sequences are sampled without replacement with probabilities
+proportional to the (numerical) values of this column.
+
+Sequences that are not present in the metadata file (or whose date is not
+precise enough to derive the date columns) are not sampled, and a warning
+is printed.
+
+If --where is given, only sequences whose metadata satisfy the given expression
+are sampled (see goalign subset). If --metadata-out is given, metadata of the
+sampled sequences are written in the given file.
+
+The sample is reproducible when --seed is given.
+
+If the input alignment contains several alignments, will process all of them.
+`,
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		var f utils.StringWriterCloser
+		var md *metadata.Metadata
+		var columns []string
+		var sampled []string = make([]string, 0)
+
+		if metadataFile == "none" {
+			err = fmt.Errorf("metadata file must be given with --metadata")
+			io.LogError(err)
+			return
+		}
+		if columns = splitColumns(stratifiedGroupBy); len(columns) == 0 {
+			err = fmt.Errorf("grouping columns must be given with --group-by")
+			io.LogError(err)
+			return
+		}
+
+		if md, err = readMetadata(); err != nil {
+			io.LogError(err)
+			return
+		}
+
+		if f, err = utils.OpenWriteFile(stratifiedOutput); err != nil {
+			io.LogError(err)
+			return
+		}
+		defer utils.CloseWriteFile(f, stratifiedOutput)
+
+		if unaligned {
+			var seqs, sample align.SeqBag
+			var groups map[string][]string
+			var weights map[string]float64
+
+			if seqs, err = readsequences(infile); err != nil {
+				io.LogError(err)
+				return
+			}
+			if groups, weights, err = stratifiedGroups(seqs, md, columns); err != nil {
+				io.LogError(err)
+				return
+			}
+			if sample, err = seqs.StratifiedSampleSeqBag(groups, stratifiedMaxPerGroup, weights, globalRand); err != nil {
+				io.LogError(err)
+				return
+			}
+			writeSequences(sample, f)
+			sampled = append(sampled, seqNames(sample)...)
+		} else {
+			var aligns *align.AlignChannel
+			var sample align.Alignment
+			var groups map[string][]string
+			var weights map[string]float64
+
+			if aligns, err = readalign(infile); err != nil {
+				io.LogError(err)
+				return
+			}
+			for al := range aligns.Achan {
+				if groups, weights, err = stratifiedGroups(al, md, columns); err != nil {
+					io.LogError(err)
+					return
+				}
+				if sample, err = al.StratifiedSample(groups, stratifiedMaxPerGroup, weights, globalRand); err != nil {
+					io.LogError(err)
+					return
+				}
+				writeAlign(sample, f)
+				sampled = append(sampled, seqNames(sample)...)
+			}
+			if aligns.Err != nil {
+				err = aligns.Err
+				io.LogError(err)
+				return
+			}
+		}
+		if err = writeMetadata(md, sampled); err != nil {
+			io.LogError(err)
+		}
+		return
+	},
+}
+
+// stratifiedGroups builds the groups of sequences of the seqbag according
+// to the given metadata columns, and the sampling weights if --weight-column
+// is given (nil otherwise)
+func stratifiedGroups(sb align.SeqBag, md *metadata.Metadata, columns []string) (groups map[string][]string, weights map[string]float64, err error) {
+	var missing []string
+	var names []string = make([]string, 0, sb.NbSequences())
+	var selected map[string]bool
+
+	if selected, err = metadataFilter(md, sb); err != nil {
+		return
+	}
+	for _, name := range seqNames(sb) {
+		if selected == nil || selected[name] {
+			names = append(names, name)
+		}
+	}
+
+	if groups, _, missing, err = md.Groups(names, columns); err != nil {
+		return
+	}
+	if len(missing) > 0 {
+		io.PrintMessage(fmt.Sprintf("%d sequences are not present in the metadata or can not be grouped, they will not be sampled", len(missing)))
+	}
+
+	if stratifiedWeightColumn != "none" {
+		var v string
+		var w float64
+		weights = make(map[string]float64)
+		for _, group := range groups {
+			for _, name := range group {
+				if v, err = md.Value(name, stratifiedWeightColumn); err != nil {
+					return
+				}
+				if w, err = strconv.ParseFloat(v, 64); err != nil {
+					err = fmt.Errorf("weight of sequence %s is not a number: %s", name, v)
+					return
+				}
+				weights[name] = w
+			}
+		}
+	}
+	return
+}
+
+// splitColumns splits a comma separated list of column names
+func splitColumns(list string) (columns []string) {
+	columns = make([]string, 0)
+	for _, c := range strings.Split(list, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			columns = append(columns, c)
+		}
+	}
+	return
+}
+
+func init() {
+	sampleCmd.AddCommand(stratifiedCmd)
+	stratifiedCmd.PersistentFlags().BoolVar(&unaligned, "unaligned", false, "Considers sequences as unaligned and format fasta (phylip, nexus,... options are ignored)")
+	addMetadataFlags(stratifiedCmd, true, true)
+	stratifiedCmd.PersistentFlags().StringVar(&stratifiedGroupBy, "group-by", "", "Comma separated list of metadata columns defining the groups")
+	stratifiedCmd.PersistentFlags().IntVarP(&stratifiedMaxPerGroup, "max-per-group", "n", 1, "Maximum number of sequences to sample per group")
+	stratifiedCmd.PersistentFlags().StringVar(&stratifiedWeightColumn, "weight-column", "none", "Metadata column giving sampling weights (default: uniform sampling)")
+	stratifiedCmd.PersistentFlags().StringVarP(&stratifiedOutput, "output", "o", "stdout", "Sampled alignment output file")
+}
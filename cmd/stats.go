@@ -8,6 +8,7 @@ import (
 	"github.com/evolbioinfo/goalign/align"
 	"github.com/evolbioinfo/goalign/io"
 	"github.com/evolbioinfo/goalign/io/countprofile"
+	"github.com/evolbioinfo/goalign/io/report"
 	"github.com/spf13/cobra"
 )
 
@@ -52,6 +53,14 @@ If --per-sequences is given, then it will print the following stats, for each se
 Note that --count-profile takes a tab separated file such as given by the command 
 goalign stats char --per-sites
 
+All stats commands accept --format:
+- text (default): historical output of each command;
+- tsv: tab separated values with a header line, the first column (alignment)
+  giving the index of the input alignment;
+- json: one JSON document per line and per input alignment, ex:
+  {"command":"gaps","alignment":0,"columns":["sequence","gaps"],"rows":[{"sequence":"A","gaps":2}]}
+In tsv and json formats, all input alignments are processed.
+
 site  A C G T
 0 nA  nC  nG  nT
 1...
@@ -61,18 +70,31 @@ n...
 `,
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		var aligns *align.AlignChannel
+		var rw *report.Writer
 
+		if rw, err = reportWriter("stats"); err != nil {
+			io.LogError(err)
+			return
+		}
 		if aligns, err = readalign(infile); err != nil {
 			io.LogError(err)
 			return
 		}
+		nb := 0
 		for al := range aligns.Achan {
-			if !statpersequences {
+			if !statpersequences && rw != nil {
+				t := report.NewTable("length", "nseqs", "avgalleles", "variablesites", "alphabet")
+				t.AddRow(al.Length(), al.NbSequences(), al.AvgAllelesPerSite(), al.NbVariableSites(), al.AlphabetStr())
+				if err = rw.Write(nb, t); err != nil {
+					io.LogError(err)
+					return
+				}
+			} else if !statpersequences {
 				fmt.Fprintf(os.Stdout, "length\t%d\n", al.Length())
 				fmt.Fprintf(os.Stdout, "nseqs\t%d\n", al.NbSequences())
 				fmt.Fprintf(os.Stdout, "avgalleles\t%.4f\n", al.AvgAllelesPerSite())
 				fmt.Fprintf(os.Stdout, "variable sites\t%d\n", al.NbVariableSites())
-				printCharStats(al, "*")
+				printCharStats(al, "*", nil, nb)
 				fmt.Fprintf(os.Stdout, "alphabet\t%s\n", al.AlphabetStr())
 			} else {
 				var refseq align.Sequence
@@ -103,8 +125,12 @@ n...
 					}
 					refseq = align.NewSequence("ref", []uint8(s), "")
 				}
-				err = printAllSequenceStats(al, refseq, profile)
+				if err = printAllSequenceStats(al, refseq, profile, rw, nb); err != nil {
+					io.LogError(err)
+					return
+				}
 			}
+			nb++
 		}
 
 		if aligns.Err != nil {
@@ -115,7 +141,11 @@ n...
 	},
 }
 
-func printCharStats(align align.Alignment, only string) {
+// Prints the number and frequency of each character of the alignment
+// (or only of the given character).
+// If rw is nil, prints it in text format, otherwise, writes the table of
+// alignment nb with rw.
+func printCharStats(align align.Alignment, only string, rw *report.Writer, nb int) (err error) {
 	charmap := align.CharStats()
 
 	// We add the only character we want to output
@@ -135,14 +165,28 @@ func printCharStats(align align.Alignment, only string) {
 	}
 	sort.Strings(keys)
 
+	if rw != nil {
+		t := report.NewTable("char", "nb", "freq")
+		for _, k := range keys {
+			nbchar := charmap[uint8(k[0])]
+			t.AddRow(k, nbchar, float64(nbchar)/float64(total))
+		}
+		return rw.Write(nb, t)
+	}
+
 	fmt.Fprintf(os.Stdout, "char\tnb\tfreq\n")
 	for _, k := range keys {
 		nb := charmap[uint8(k[0])]
 		fmt.Fprintf(os.Stdout, "%s\t%d\t%f\n", k, nb, float64(nb)/float64(total))
 	}
+	return
 }
 
-func printSiteCharStats(al align.Alignment, only string) (err error) {
+// Prints the number of occurences of each character (or only of the
+// given character) at each site of the alignment.
+// If rw is nil, prints it in text format (one column per character), otherwise,
+// writes the table of alignment nb with rw, with one row per site and character.
+func printSiteCharStats(al align.Alignment, only string, rw *report.Writer, nb int) (err error) {
 	var profile *align.CountProfile
 	var ok bool
 	var indexonly int
@@ -153,6 +197,26 @@ func printSiteCharStats(al align.Alignment, only string) (err error) {
 		err = fmt.Errorf("character should have length 1: %s", only)
 	}
 
+	if rw != nil {
+		t := report.NewTable("site", "char", "nb")
+		for site := 0; site < al.Length(); site++ {
+			if only != "*" {
+				if indexonly, ok = profile.NameIndex(onlyr[0]); !ok {
+					t.AddRow(site, onlyr[0], 0)
+					continue
+				}
+			}
+			for index := 0; index < profile.NbCharacters(); index++ {
+				r, _ := profile.NameAt(index)
+				if only == "*" || index == indexonly {
+					count, _ := profile.CountAt(index, site)
+					t.AddRow(site, r, count)
+				}
+			}
+		}
+		return rw.Write(nb, t)
+	}
+
 	fmt.Fprintf(os.Stdout, "site")
 	if only == "*" {
 		indexonly = -1
@@ -185,7 +249,11 @@ func printSiteCharStats(al align.Alignment, only string) (err error) {
 	return
 }
 
-func printSequenceCharStats(sb align.SeqBag, only string) (err error) {
+// Prints the number of occurences of each character (or only of the
+// given character) in each sequence.
+// If rw is nil, prints it in text format (one column per character), otherwise,
+// writes the table of alignment nb with rw, with one row per sequence and character.
+func printSequenceCharStats(sb align.SeqBag, only string, rw *report.Writer, nb int) (err error) {
 	var sequencemap map[uint8]int
 
 	charmap := sb.CharStats()
@@ -202,6 +270,23 @@ func printSequenceCharStats(sb align.SeqBag, only string) (err error) {
 		keys = append(keys, string(k))
 	}
 	sort.Strings(keys)
+
+	if rw != nil {
+		t := report.NewTable("sequence", "char", "nb")
+		for i := 0; i < sb.NbSequences(); i++ {
+			if sequencemap, err = sb.CharStatsSeq(i); err != nil {
+				return
+			}
+			name, _ := sb.GetSequenceNameById(i)
+			for _, k := range keys {
+				if only == "*" || k == only {
+					t.AddRow(name, k, sequencemap[uint8(k[0])])
+				}
+			}
+		}
+		return rw.Write(nb, t)
+	}
+
 	fmt.Fprintf(os.Stdout, "seq")
 	for _, v := range keys {
 		if only == "*" || v == only {
@@ -226,7 +311,10 @@ func printSequenceCharStats(sb align.SeqBag, only string) (err error) {
 	return
 }
 
-func printAllSequenceStats(al align.Alignment, refSequence align.Sequence, countProfile *align.CountProfile) (err error) {
+// Prints statistics of each sequence of the alignment (see stats --per-sequences).
+// If rw is nil, prints it in text format, otherwise, writes the table of
+// alignment nb with rw.
+func printAllSequenceStats(al align.Alignment, refSequence align.Sequence, countProfile *align.CountProfile, rw *report.Writer, nb int) (err error) {
 	var sequencemap map[uint8]int
 
 	var numnewgaps []int // new gaps that are not found in the profile
@@ -240,8 +328,8 @@ func printAllSequenceStats(al align.Alignment, refSequence align.Sequence, count
 
 	var nummutations int
 	var gaps int
-	var name string
 	var uniquechars []uint8 = al.UniqueCharacters()
+	var columns []string
 
 	if numgapsuniques, numnewgaps, numgapsboth, err = al.NumGapsUniquePerSequence(countProfile); err != nil {
 		return
@@ -250,83 +338,78 @@ func printAllSequenceStats(al align.Alignment, refSequence align.Sequence, count
 		return
 	}
 
-	fmt.Fprintf(os.Stdout, "sequence")
-	fmt.Fprintf(os.Stdout, "\tgaps")
-	fmt.Fprintf(os.Stdout, "\tgapsstart")
-	fmt.Fprintf(os.Stdout, "\tgapsend")
-	fmt.Fprintf(os.Stdout, "\tgapsuniques")
+	columns = []string{"sequence", "gaps", "gapsstart", "gapsend", "gapsuniques"}
 	if countProfile != nil {
-		fmt.Fprintf(os.Stdout, "\tgapsnew")
-		fmt.Fprintf(os.Stdout, "\tgapsboth")
+		columns = append(columns, "gapsnew", "gapsboth")
 	}
-	fmt.Fprintf(os.Stdout, "\tgapsopenning")
-	fmt.Fprintf(os.Stdout, "\tmutuniques")
+	columns = append(columns, "gapsopenning", "mutuniques")
 	if countProfile != nil {
-		fmt.Fprintf(os.Stdout, "\tmutsnew")
-		fmt.Fprintf(os.Stdout, "\tmutsboth")
+		columns = append(columns, "mutsnew", "mutsboth")
 	}
 	if refSequence != nil {
-		fmt.Fprintf(os.Stdout, "\tmutref")
+		columns = append(columns, "mutref")
 	}
-	fmt.Fprintf(os.Stdout, "\tlength")
+	columns = append(columns, "length")
 	for _, v := range uniquechars {
-		fmt.Fprintf(os.Stdout, "\t%c", v)
+		columns = append(columns, string(v))
 	}
+	t := report.NewTable(columns...)
 
-	fmt.Fprintf(os.Stdout, "\n")
 	for i, s := range al.Sequences() {
 		if sequencemap, err = al.CharStatsSeq(i); err != nil {
 			return
 		}
-		name = s.Name()
 		gaps = s.NumGaps()
-		fmt.Printf("%s", name)
-		fmt.Printf("\t%d", gaps)
-		fmt.Printf("\t%d", s.NumGapsFromStart())
-		fmt.Printf("\t%d", s.NumGapsFromEnd())
-		fmt.Printf("\t%d", numgapsuniques[i])
+		row := []interface{}{s.Name(), gaps, s.NumGapsFromStart(), s.NumGapsFromEnd(), numgapsuniques[i]}
 		if countProfile != nil {
-			fmt.Printf("\t%d", numnewgaps[i])
-			fmt.Printf("\t%d", numgapsboth[i])
+			row = append(row, numnewgaps[i], numgapsboth[i])
 		}
-		fmt.Printf("\t%d", s.NumGapsOpenning())
-		fmt.Printf("\t%d", nummutuniques[i])
+		row = append(row, s.NumGapsOpenning(), nummutuniques[i])
 		if countProfile != nil {
-			fmt.Printf("\t%d", numnewmuts[i])
-			fmt.Printf("\t%d", nummutsboth[i])
+			row = append(row, numnewmuts[i], nummutsboth[i])
 		}
 		if refSequence != nil {
 			if nummutations, err = s.NumMutationsComparedToReferenceSequence(al.Alphabet(), refSequence); err != nil {
-				io.LogError(err)
 				return
 			}
-			fmt.Printf("\t%d", nummutations)
+			row = append(row, nummutations)
 		}
-		fmt.Printf("\t%d", s.Length()-gaps)
+		row = append(row, s.Length()-gaps)
 		for _, k := range uniquechars {
-			nb := sequencemap[k]
-			fmt.Printf("\t%d", nb)
+			row = append(row, sequencemap[k])
+		}
+		if err = t.AddRow(row...); err != nil {
+			return
 		}
-		fmt.Printf("\n")
 	}
 
-	return
+	if rw != nil {
+		return rw.Write(nb, t)
+	}
+	return t.WriteTabular(os.Stdout, true)
 }
 
 // Prints the Character with the most frequency
 // for each site of the alignment
-func printMaxCharStats(align align.Alignment, ignoreGaps, ignoreNs bool) {
+// If rw is nil, prints it in text format, otherwise, writes the table of
+// alignment nb with rw.
+func printMaxCharStats(align align.Alignment, ignoreGaps, ignoreNs bool, rw *report.Writer, nb int) (err error) {
 	maxchars, occur, _ := align.MaxCharStats(ignoreGaps, ignoreNs)
 
-	fmt.Fprintf(os.Stdout, "site\tchar\tnb\n")
+	t := report.NewTable("site", "char", "nb")
 	for i, c := range maxchars {
-		fmt.Fprintf(os.Stdout, "%d\t%c\t%d\n", i, c, occur[i])
+		t.AddRow(i, c, occur[i])
+	}
+	if rw != nil {
+		return rw.Write(nb, t)
 	}
+	return t.WriteTabular(os.Stdout, true)
 }
 
 func init() {
 	RootCmd.AddCommand(statsCmd)
 	statsCmd.PersistentFlags().BoolVar(&statpersequences, "per-sequences", false, "Prints  statistics per alignment sequences")
 	statsCmd.PersistentFlags().StringVar(&statrefsequence, "ref-sequence", "none", "Reference sequence to compare each sequence with (only with --per-sequences")
+	addFormatFlag(statsCmd)
 	statsCmd.PersistentFlags().StringVar(&statcountprofile, "count-profile", "none", "A profile to compare the alignment with, and to compute statistics faster (only with --per-sequences)")
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/evolbioinfo/goalign/align"
 	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/report"
 	"github.com/spf13/cobra"
 )
 
@@ -18,13 +19,18 @@ var alphabetCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		var aligns *align.AlignChannel
 		var seqs align.SeqBag
+		var rw *report.Writer
 
+		if rw, err = reportWriter("alphabet"); err != nil {
+			io.LogError(err)
+			return
+		}
 		if unaligned {
 			if seqs, err = readsequences(infile); err != nil {
 				io.LogError(err)
 				return
 			}
-			fmt.Println(seqs.AlphabetStr())
+			err = printAlphabet(rw, seqs)
 		} else {
 
 			if aligns, err = readalign(infile); err != nil {
@@ -38,13 +44,26 @@ var alphabetCmd = &cobra.Command{
 					io.LogError(err)
 					return
 				}
-				fmt.Println(al.AlphabetStr())
+				err = printAlphabet(rw, al)
 			}
 		}
 		return
 	},
 }
 
+func printAlphabet(rw *report.Writer, sb align.SeqBag) (err error) {
+	if rw == nil {
+		fmt.Println(sb.AlphabetStr())
+		return
+	}
+	t := report.NewTable("alphabet")
+	t.AddRow(sb.AlphabetStr())
+	if err = rw.Write(0, t); err != nil {
+		io.LogError(err)
+	}
+	return
+}
+
 func init() {
 	alphabetCmd.PersistentFlags().BoolVar(&unaligned, "unaligned", false, "Considers sequences as unaligned and format fasta (phylip, nexus,... options are ignored)")
 	statsCmd.AddCommand(alphabetCmd)
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/evolbioinfo/goalign/align"
+	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/report"
+	"github.com/spf13/cobra"
+)
+
+var statCodonsGeneticCode string
+var statCodonsReference string
+var statCodonsUsage bool
+
+// statCodonsCmd represents the stats codons command
+var statCodonsCmd = &cobra.Command{
+	Use:   "codons",
+	Short: "Prints codon usage statistics of nucleotide coding sequences",
+	Long: `Prints codon usage statistics of nucleotide coding sequences.
+
+Sequences are read in frame, from their first position. Codons containing gaps
+or ambiguous nucleotides are ignored, as well as the last incomplete codon.
+
+By default, it prints a table with one row per sequence, plus a last row
+(sequence "all") for all the sequences together, and the following columns:
+1. sequence: Name of the sequence;
+2. codons: Number of counted codons;
+3. gc: GC content;
+4. gc1, gc2, gc3: GC content at first, second, and third codon positions;
+5. gc3s: GC content at synonymous third positions (codons of amino acids encoded
+   by a single codon and stop codons are excluded);
+6. enc: Effective number of codons (Wright 1990);
+7. cai: Codon adaptation index (Sharp & Li 1987), only if --reference is given.
+
+If --usage is given, it prints instead the codon usage table, with one row per
+sequence (plus "all") and per codon, and the following columns: sequence, codon,
+aa (amino acid, '*' for stops), count, rscu (relative synonymous codon usage).
+
+--reference gives a reference codon usage table (e.g. of highly expressed genes),
+used to compute CAI. It is a tab separated file with 2 columns: codon and count
+(or frequency), for example:
+AAA	24.4
+AAC	19.1
+...
+
+Genetic code is given with --genetic-code: standard, mitoi (invertebrate
+mitochondrial), mitov (vertebrate mitochondrial), or an NCBI translation table
+number (see goalign translate).
+
+The output is tab separated by default (--format text or tsv), with a first column
+(alignment) giving the index of the input alignment, or may be in json (see goalign stats).
+
+If the input alignment contains several alignments, will process all of them.
+`,
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		var aligns *align.AlignChannel
+		var seqs align.SeqBag
+		var rw *report.Writer
+		var t *report.Table
+		var geneticcode int
+		var ref *align.CodonUsage
+
+		if geneticcode, err = parseGeneticCode(statCodonsGeneticCode); err != nil {
+			io.LogError(err)
+			return
+		}
+
+		if statCodonsReference != "none" {
+			if ref, err = readCodonUsage(statCodonsReference, geneticcode); err != nil {
+				io.LogError(err)
+				return
+			}
+		}
+
+		if rw, err = tableWriter(os.Stdout, "codons"); err != nil {
+			io.LogError(err)
+			return
+		}
+
+		if unaligned {
+			if seqs, err = readsequences(infile); err != nil {
+				io.LogError(err)
+				return
+			}
+			if t, err = codonStats(seqs, geneticcode, ref, statCodonsUsage); err != nil {
+				io.LogError(err)
+				return
+			}
+			if err = rw.Write(0, t); err != nil {
+				io.LogError(err)
+			}
+			return
+		}
+
+		if aligns, err = readalign(infile); err != nil {
+			io.LogError(err)
+			return
+		}
+		nb := 0
+		for al := range aligns.Achan {
+			if t, err = codonStats(al, geneticcode, ref, statCodonsUsage); err != nil {
+				io.LogError(err)
+				return
+			}
+			if err = rw.Write(nb, t); err != nil {
+				io.LogError(err)
+				return
+			}
+			nb++
+		}
+		if aligns.Err != nil {
+			err = aligns.Err
+			io.LogError(err)
+		}
+		return
+	},
+}
+
+// codonStats computes the codon usage of each sequence and of all sequences,
+// and returns either the summary statistics table or the codon usage table (usage=true).
+func codonStats(sb align.SeqBag, geneticcode int, ref *align.CodonUsage, usage bool) (t *report.Table, err error) {
+	var all, cu *align.CodonUsage
+
+	if sb.Alphabet() != align.NUCLEOTIDS {
+		err = fmt.Errorf("codon usage statistics need nucleotide sequences")
+		return
+	}
+	if all, err = align.NewCodonUsage(geneticcode); err != nil {
+		return
+	}
+
+	if usage {
+		t = report.NewTable("sequence", "codon", "aa", "count", "rscu")
+	} else if ref != nil {
+		t = report.NewTable("sequence", "codons", "gc", "gc1", "gc2", "gc3", "gc3s", "enc", "cai")
+	} else {
+		t = report.NewTable("sequence", "codons", "gc", "gc1", "gc2", "gc3", "gc3s", "enc")
+	}
+
+	addRows := func(name string, cu *align.CodonUsage) {
+		if usage {
+			rscu := cu.RSCU()
+			for c := 0; c < 64; c++ {
+				t.AddRow(name, align.CodonString(c), cu.AminoAcid(c), cu.Count(c), rscu[c])
+			}
+			return
+		}
+		row := []interface{}{name, cu.NbCodons(), cu.GC(-1), cu.GC(0), cu.GC(1), cu.GC(2), cu.GC3s(), cu.ENC()}
+		if ref != nil {
+			row = append(row, cu.CAI(ref))
+		}
+		t.AddRow(row...)
+	}
+
+	sb.IterateChar(func(name string, sequence []uint8) bool {
+		if cu, err = align.NewCodonUsage(geneticcode); err != nil {
+			return true
+		}
+		cu.AddSequence(sequence)
+		all.Add(cu)
+		addRows(name, cu)
+		return false
+	})
+	if err != nil {
+		return
+	}
+	addRows("all", all)
+	return
+}
+
+// readCodonUsage reads a codon usage table from a tab separated file
+// with 2 columns: codon and count (or frequency).
+func readCodonUsage(file string, geneticcode int) (cu *align.CodonUsage, err error) {
+	var counts map[string]string
+	var count float64
+
+	if counts, err = readMapFile(file, false); err != nil {
+		return
+	}
+	if cu, err = align.NewCodonUsage(geneticcode); err != nil {
+		return
+	}
+	for codon, c := range counts {
+		if count, err = strconv.ParseFloat(c, 64); err != nil {
+			err = fmt.Errorf("wrong codon count in reference codon usage file for %s: %s", codon, c)
+			return
+		}
+		if err = cu.AddCount(codon, count); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func init() {
+	addGeneticCodeFlag(statCodonsCmd, &statCodonsGeneticCode)
+	statCodonsCmd.PersistentFlags().StringVar(&statCodonsReference, "reference", "none", "Reference codon usage table file (codon<TAB>count), used to compute CAI")
+	statCodonsCmd.PersistentFlags().BoolVar(&statCodonsUsage, "usage", false, "Prints the codon usage table (counts and RSCU of each codon) instead of summary statistics")
+	statCodonsCmd.PersistentFlags().BoolVar(&unaligned, "unaligned", false, "Considers sequences as unaligned and format fasta (phylip, nexus,... options are ignored)")
+	statsCmd.AddCommand(statCodonsCmd)
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/evolbioinfo/goalign/align"
+	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/report"
+	"github.com/spf13/cobra"
+)
+
+var statCompositionAlpha float64
+var statCompositionBootstrap int
+
+// statCompositionCmd represents the stats composition command
+var statCompositionCmd = &cobra.Command{
+	Use:   "composition",
+	Short: "Tests the homogeneity of character composition across sequences",
+	Long: `Tests the homogeneity of character composition across sequences.
+
+Tree inference usually assumes stationary composition. As in IQ-TREE and PAUP*
+composition tests, for each sequence, a chi-square statistic compares its
+character counts to the counts expected given the character frequencies of all
+the sequences together. Only unambiguous characters are counted (gaps and
+ambiguous characters are ignored). The degrees of freedom are the number of
+observed characters minus 1. A sequence fails the test if its p-value is < --alpha.
+
+The last row ("all") gives the global test, i.e. the chi-square test of the
+contingency table sequences x characters, with (nseqs-1)*(nchars-1) degrees of
+freedom.
+
+As sites are not independent, chi-square p-values may be too liberal. If
+--bootstrap n is given, p-values are also computed under a parametric bootstrap
+null: n replicates are simulated by drawing the same number of characters for
+each sequence from the global frequencies, and the bootstrap p-value is the
+proportion of replicates giving a chi-square statistic >= the observed one. In
+this case, failing sequences are defined using bootstrap p-values.
+
+It prints a table with one row per sequence, plus a last row (sequence "all"),
+and the following columns:
+1. sequence: Name of the sequence;
+2. length: Number of unambiguous characters;
+3. One column per character of the alphabet, giving its frequency;
+4. chi2, df, pvalue: Chi-square statistic, degrees of freedom, and p-value;
+5. bootpvalue: Bootstrap p-value (only with --bootstrap);
+6. failed: true if the p-value is < --alpha;
+7. For nucleotides only:
+   - gc: GC content;
+   - gcskew: (G-C)/(G+C);
+   - atskew: (A-T)/(A+T);
+   - A1, C1, G1, T1, A2, ..., T3: Frequencies of the nucleotides at each codon
+     position, i.e. position in the sequence (in the alignment for aligned
+     sequences) modulo 3.
+
+The output is tab separated by default (--format text or tsv), with a first column
+(alignment) giving the index of the input alignment, or may be in json (see goalign stats).
+
+If the input alignment contains several alignments, will process all of them.
+`,
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		var aligns *align.AlignChannel
+		var seqs align.SeqBag
+		var rw *report.Writer
+		var t *report.Table
+
+		if rw, err = tableWriter(os.Stdout, "composition"); err != nil {
+			io.LogError(err)
+			return
+		}
+
+		if unaligned {
+			if seqs, err = readsequences(infile); err != nil {
+				io.LogError(err)
+				return
+			}
+			if t, err = compositionStats(seqs); err != nil {
+				io.LogError(err)
+				return
+			}
+			if err = rw.Write(0, t); err != nil {
+				io.LogError(err)
+			}
+			return
+		}
+
+		if aligns, err = readalign(infile); err != nil {
+			io.LogError(err)
+			return
+		}
+		nb := 0
+		for al := range aligns.Achan {
+			if t, err = compositionStats(al); err != nil {
+				io.LogError(err)
+				return
+			}
+			if err = rw.Write(nb, t); err != nil {
+				io.LogError(err)
+				return
+			}
+			nb++
+		}
+		if aligns.Err != nil {
+			err = aligns.Err
+			io.LogError(err)
+		}
+		return
+	},
+}
+
+// compositionStats tests the composition homogeneity of the sequences, and returns
+// the table of composition statistics of each sequence and of all sequences
+func compositionStats(sb align.SeqBag) (t *report.Table, err error) {
+	var tests []align.CompositionTest
+	var all align.CompositionTest
+
+	if tests, all, err = sb.CompositionTest(statCompositionBootstrap, globalRand); err != nil {
+		return
+	}
+
+	chars := sb.AlphabetCharacters()
+	nt := sb.Alphabet() == align.NUCLEOTIDS
+	columns := []string{"sequence", "length"}
+	for _, c := range chars {
+		columns = append(columns, string(c))
+	}
+	columns = append(columns, "chi2", "df", "pvalue")
+	if statCompositionBootstrap > 0 {
+		columns = append(columns, "bootpvalue")
+	}
+	columns = append(columns, "failed")
+	if nt {
+		columns = append(columns, "gc", "gcskew", "atskew")
+		for pos := 1; pos <= 3; pos++ {
+			for _, c := range chars {
+				columns = append(columns, fmt.Sprintf("%c%d", c, pos))
+			}
+		}
+	}
+	t = report.NewTable(columns...)
+
+	// Counts of each nucleotide at each codon position
+	poscounts := make([][3][]int, len(tests)+1)
+	if nt {
+		for i := range poscounts {
+			for pos := 0; pos < 3; pos++ {
+				poscounts[i][pos] = make([]int, len(chars))
+			}
+		}
+		i := 0
+		sb.IterateChar(func(name string, sequence []uint8) bool {
+			for site, c := range sequence {
+				if idx := sb.AlphabetCharToIndex(c); idx >= 0 {
+					poscounts[i][site%3][idx]++
+					poscounts[len(tests)][site%3][idx]++
+				}
+			}
+			i++
+			return false
+		})
+	}
+
+	for i, test := range append(tests, all) {
+		row := []interface{}{test.Name, test.Total}
+		for _, f := range test.Frequencies() {
+			row = append(row, f)
+		}
+		row = append(row, test.ChiSquare, test.DF, test.PValue)
+		pvalue := test.PValue
+		if statCompositionBootstrap > 0 {
+			row = append(row, test.BootPValue)
+			pvalue = test.BootPValue
+		}
+		row = append(row, pvalue < statCompositionAlpha)
+		if nt {
+			a, c, g, tt := float64(test.Counts[0]), float64(test.Counts[1]), float64(test.Counts[2]), float64(test.Counts[3])
+			row = append(row, (g+c)/float64(test.Total), skew(g, c), skew(a, tt))
+			for pos := 0; pos < 3; pos++ {
+				total := 0
+				for _, n := range poscounts[i][pos] {
+					total += n
+				}
+				for _, n := range poscounts[i][pos] {
+					row = append(row, float64(n)/float64(total))
+				}
+			}
+		}
+		if err = t.AddRow(row...); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// skew returns (x-y)/(x+y), NaN if x+y is 0
+func skew(x, y float64) float64 {
+	if x+y == 0 {
+		return math.NaN()
+	}
+	return (x - y) / (x + y)
+}
+
+func init() {
+	statCompositionCmd.PersistentFlags().Float64Var(&statCompositionAlpha, "alpha", 0.05, "Significance level to flag failing sequences")
+	statCompositionCmd.PersistentFlags().IntVar(&statCompositionBootstrap, "bootstrap", 0, "Number of parametric bootstrap replicates to compute p-values (0: no bootstrap)")
+	statCompositionCmd.PersistentFlags().BoolVar(&unaligned, "unaligned", false, "Considers sequences as unaligned and format fasta (phylip, nexus,... options are ignored)")
+	statsCmd.AddCommand(statCompositionCmd)
+}
@@ -1,11 +1,12 @@
 package cmd
 
 import (
-	"fmt"
+	"os"
 
 	"github.com/evolbioinfo/goalign/align"
 	"github.com/evolbioinfo/goalign/io"
 	"github.com/evolbioinfo/goalign/io/countprofile"
+	"github.com/evolbioinfo/goalign/io/report"
 	"github.com/spf13/cobra"
 )
 
@@ -47,15 +48,14 @@ var statGapsCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		var aligns *align.AlignChannel
 		var profile *align.CountProfile
+		var rw *report.Writer
+		var t *report.Table
 
-		if aligns, err = readalign(infile); err != nil {
+		if rw, err = reportWriter("gaps"); err != nil {
 			io.LogError(err)
 			return
 		}
-
-		al := <-aligns.Achan
-		if aligns.Err != nil {
-			err = aligns.Err
+		if aligns, err = readalign(infile); err != nil {
 			io.LogError(err)
 			return
 		}
@@ -67,39 +67,77 @@ var statGapsCmd = &cobra.Command{
 			}
 		}
 
-		var numnewgaps []int     // new gaps that are not found in the profile
-		var numgapsuniques []int // gaps that are unique in the given alignment
-		var numgapsboth []int    // gaps that are unique in the given alignment and not found in the profile
-
-		if statGapsUnique {
-			if numgapsuniques, numnewgaps, numgapsboth, err = al.NumGapsUniquePerSequence(profile); err != nil {
+		nb := 0
+		for al := range aligns.Achan {
+			if t, err = gapStats(al, profile); err != nil {
 				io.LogError(err)
 				return
 			}
-		}
-
-		for i, s := range al.Sequences() {
-			if statGapsFromStart {
-				fmt.Printf("%s\t%d\n", s.Name(), s.NumGapsFromStart())
-			} else if statGapsFromEnd {
-				fmt.Printf("%s\t%d\n", s.Name(), s.NumGapsFromEnd())
-			} else if statGapsUnique {
-				fmt.Printf("%s\t%d", s.Name(), numgapsuniques[i])
-				if statGapsProfile != "none" {
-					fmt.Printf("\t%d\t%d", numnewgaps[i], numgapsboth[i])
-				}
-				fmt.Printf("\n")
-			} else if statGapsOpenning {
-				fmt.Printf("%s\t%d\n", s.Name(), s.NumGapsOpenning())
-			} else {
-				fmt.Printf("%s\t%d\n", s.Name(), s.NumGaps())
+			// In text format, only the first alignment is processed
+			if rw == nil {
+				err = t.WriteTabular(os.Stdout, false)
+				return
+			}
+			if err = rw.Write(nb, t); err != nil {
+				io.LogError(err)
+				return
 			}
+			nb++
+		}
+		if aligns.Err != nil {
+			err = aligns.Err
+			io.LogError(err)
 		}
-
 		return
 	},
 }
 
+// gapStats computes the gap statistic given by the options for each sequence of the alignment
+func gapStats(al align.Alignment, profile *align.CountProfile) (t *report.Table, err error) {
+	var numnewgaps []int     // new gaps that are not found in the profile
+	var numgapsuniques []int // gaps that are unique in the given alignment
+	var numgapsboth []int    // gaps that are unique in the given alignment and not found in the profile
+
+	if statGapsUnique {
+		if numgapsuniques, numnewgaps, numgapsboth, err = al.NumGapsUniquePerSequence(profile); err != nil {
+			return
+		}
+	}
+
+	switch {
+	case statGapsFromStart:
+		t = report.NewTable("sequence", "gapsstart")
+	case statGapsFromEnd:
+		t = report.NewTable("sequence", "gapsend")
+	case statGapsUnique && profile != nil:
+		t = report.NewTable("sequence", "gapsuniques", "gapsnew", "gapsboth")
+	case statGapsUnique:
+		t = report.NewTable("sequence", "gapsuniques")
+	case statGapsOpenning:
+		t = report.NewTable("sequence", "gapsopenning")
+	default:
+		t = report.NewTable("sequence", "gaps")
+	}
+
+	for i, s := range al.Sequences() {
+		switch {
+		case statGapsFromStart:
+			t.AddRow(s.Name(), s.NumGapsFromStart())
+		case statGapsFromEnd:
+			t.AddRow(s.Name(), s.NumGapsFromEnd())
+		case statGapsUnique && profile != nil:
+			t.AddRow(s.Name(), numgapsuniques[i], numnewgaps[i], numgapsboth[i])
+		case statGapsUnique:
+			t.AddRow(s.Name(), numgapsuniques[i])
+		case statGapsOpenning:
+			t.AddRow(s.Name(), s.NumGapsOpenning())
+		default:
+			t.AddRow(s.Name(), s.NumGaps())
+		}
+	}
+	return
+}
+
 func init() {
 	statGapsCmd.PersistentFlags().BoolVar(&statGapsFromStart, "from-start", false, "Count gaps in each sequence from start of sequences (until a non gap character is encountered)")
 	statGapsCmd.PersistentFlags().BoolVar(&statGapsFromEnd, "from-end", false, "Count gaps in each sequence from end of sequences (until a non gap character is encountered)")
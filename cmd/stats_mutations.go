@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/evolbioinfo/goalign/align"
 	"github.com/evolbioinfo/goalign/io"
 	"github.com/evolbioinfo/goalign/io/countprofile"
+	"github.com/evolbioinfo/goalign/io/report"
 	"github.com/spf13/cobra"
 )
 
@@ -46,15 +48,19 @@ var statMutationsCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		var aligns *align.AlignChannel
 		var profile *align.CountProfile
+		var rw *report.Writer
+		var t *report.Table
 
-		if aligns, err = readalign(infile); err != nil {
+		if rw, err = reportWriter("mutations"); err != nil {
 			io.LogError(err)
 			return
 		}
-
-		al := <-aligns.Achan
-		if aligns.Err != nil {
-			err = aligns.Err
+		if statMutationsRef == "none" && !statMutationsUnique {
+			err = fmt.Errorf("mutations should be counted by comparing to a reference sequnce with --ref-sequence")
+			io.LogError(err)
+			return
+		}
+		if aligns, err = readalign(infile); err != nil {
 			io.LogError(err)
 			return
 		}
@@ -66,55 +72,93 @@ var statMutationsCmd = &cobra.Command{
 			}
 		}
 
-		var nummutations []int
-		var numnewmuts []int  // new mutations that are not found in the profile
-		var nummutsboth []int // mutations that are unique in the given alignment and not found in the profile
-		var num int
-
-		if statMutationsRef != "none" {
-			var s string
-			var sb align.SeqBag
-			var ok bool
-			// We try to get the sequence from its name in the alignment
-			if s, ok = al.GetSequence(statMutationsRef); !ok {
-				//Else we open the potential file
-				if sb, err = readsequences(statMutationsRef); err != nil {
-					io.LogError(err)
-					return
-				}
-				if sb.NbSequences() < 1 {
-					err = fmt.Errorf("the reference sequence file does not contain any sequence")
-					io.LogError(err)
-					return
-				}
-				s, _ = sb.GetSequenceById(0)
+		nb := 0
+		for al := range aligns.Achan {
+			if t, err = mutationStats(al, profile); err != nil {
+				io.LogError(err)
+				return
 			}
-			for _, s2 := range al.Sequences() {
-				if num, err = s2.NumMutationsComparedToReferenceSequence(al.Alphabet(), align.NewSequence("ref", []uint8(s), "")); err != nil {
-					io.LogError(err)
-					return
-				}
-				fmt.Printf("%s\t%d\n", s2.Name(), num)
+			// In text format, only the first alignment is processed
+			if rw == nil {
+				err = t.WriteTabular(os.Stdout, false)
+				return
 			}
-
-		} else if statMutationsUnique {
-			nummutations, numnewmuts, nummutsboth, err = al.NumMutationsUniquePerSequence(profile)
-			for i, s := range al.Sequences() {
-				fmt.Printf("%s\t%d", s.Name(), nummutations[i])
-				if profile != nil {
-					fmt.Printf("\t%d", numnewmuts[i])
-					fmt.Printf("\t%d", nummutsboth[i])
-				}
-				fmt.Printf("\n")
+			if err = rw.Write(nb, t); err != nil {
+				io.LogError(err)
+				return
 			}
-		} else {
-			err = fmt.Errorf("mutations should be counted by comparing to a reference sequnce with --ref-sequence")
+			nb++
+		}
+		if aligns.Err != nil {
+			err = aligns.Err
 			io.LogError(err)
+		}
+		return
+	},
+}
+
+// mutationStats counts mutations of each sequence of the alignment compared to the
+// reference sequence, or unique mutations if --unique is given
+func mutationStats(al align.Alignment, profile *align.CountProfile) (t *report.Table, err error) {
+	var nummutations []int
+	var numnewmuts []int  // new mutations that are not found in the profile
+	var nummutsboth []int // mutations that are unique in the given alignment and not found in the profile
+	var num int
+	var ref align.Sequence
+
+	if statMutationsRef != "none" {
+		if ref, err = mutationsReference(al); err != nil {
 			return
 		}
+		t = report.NewTable("sequence", "mutref")
+		for _, s2 := range al.Sequences() {
+			if num, err = s2.NumMutationsComparedToReferenceSequence(al.Alphabet(), ref); err != nil {
+				return
+			}
+			t.AddRow(s2.Name(), num)
+		}
+		return
+	}
 
+	if nummutations, numnewmuts, nummutsboth, err = al.NumMutationsUniquePerSequence(profile); err != nil {
 		return
-	},
+	}
+	if profile != nil {
+		t = report.NewTable("sequence", "mutuniques", "mutsnew", "mutsboth")
+	} else {
+		t = report.NewTable("sequence", "mutuniques")
+	}
+	for i, s := range al.Sequences() {
+		if profile != nil {
+			t.AddRow(s.Name(), nummutations[i], numnewmuts[i], nummutsboth[i])
+		} else {
+			t.AddRow(s.Name(), nummutations[i])
+		}
+	}
+	return
+}
+
+// mutationsReference returns the reference sequence given with --ref-sequence:
+// the sequence having that name in the alignment, or else the first sequence of
+// the file having that name
+func mutationsReference(al align.Alignment) (ref align.Sequence, err error) {
+	var s string
+	var sb align.SeqBag
+	var ok bool
+
+	if s, ok = al.GetSequence(statMutationsRef); !ok {
+		//Else we open the potential file
+		if sb, err = readsequences(statMutationsRef); err != nil {
+			return
+		}
+		if sb.NbSequences() < 1 {
+			err = fmt.Errorf("the reference sequence file does not contain any sequence")
+			return
+		}
+		s, _ = sb.GetSequenceById(0)
+	}
+	ref = align.NewSequence("ref", []uint8(s), "")
+	return
 }
 
 func init() {
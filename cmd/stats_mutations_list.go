@@ -5,11 +5,13 @@ import (
 
 	"github.com/evolbioinfo/goalign/align"
 	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/report"
 	"github.com/spf13/cobra"
 )
 
 var statMutationsListAA bool
 var statMutationsListCodon bool
+var statMutationsListGeneticCode string
 
 // charCmd represents the char command
 var statMutationsListCmd = &cobra.Command{
@@ -24,7 +26,8 @@ var statMutationsListCmd = &cobra.Command{
 	- --aa : takes reference nucleotides codon by codon of the reference sequence to list mutations (alihgn) and translates each codon. In case of 
 			 insertion or a deletion in the target sequence: if %3!=0 (without gaps): it may be a frameshift, indicated by a '/'. It is better to use
 			 this option rather than translating the alignment and then listing mutations in aa, because the insertions/deletions may not be 
-			 appropriately listed if the gap is inside a reference codon for example.
+			 appropriately listed if the gap is inside a reference codon for example. Codons are translated using the
+			 genetic code given with --genetic-code.
 
 	- --codon : takes reference nucleotides codon by codon (like --aa) of the reference sequence to list mutations and does not translate them (unlike --aa).
 
@@ -33,58 +36,71 @@ var statMutationsListCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		var aligns *align.AlignChannel
 		var mutations []align.Mutation
+		var rw *report.Writer
+		var ref align.Sequence
+		var geneticcode int
 
-		if aligns, err = readalign(infile); err != nil {
+		if geneticcode, err = parseGeneticCode(statMutationsListGeneticCode); err != nil {
 			io.LogError(err)
 			return
 		}
-
-		al := <-aligns.Achan
-		if aligns.Err != nil {
-			err = aligns.Err
+		if rw, err = reportWriter("mutations list"); err != nil {
 			io.LogError(err)
 			return
 		}
-
 		if statMutationsRef == "none" {
 			err = fmt.Errorf("mutations should be counted by comparing to a reference sequnce with --ref-sequence")
 			io.LogError(err)
 			return
 		}
+		if aligns, err = readalign(infile); err != nil {
+			io.LogError(err)
+			return
+		}
 
-		var s string
-		var sb align.SeqBag
-		var ok bool
-		// We try to get the sequence from its name in the alignment
-		if s, ok = al.GetSequence(statMutationsRef); !ok {
-			//Else we open the potential file
-			if sb, err = readsequences(statMutationsRef); err != nil {
+		nb := 0
+		for al := range aligns.Achan {
+			if ref, err = mutationsReference(al); err != nil {
 				io.LogError(err)
 				return
 			}
-			if sb.NbSequences() < 1 {
-				err = fmt.Errorf("the reference sequence file does not contain any sequence")
+			t := report.NewTable("sequence", "ref", "pos", "alt")
+			for _, s2 := range al.Sequences() {
+				if s2.Name() != statMutationsRef {
+					if mutations, err = s2.ListMutationsComparedToReferenceSequence(al.Alphabet(), ref, statMutationsListCodon || statMutationsListAA, statMutationsListAA, geneticcode); err != nil {
+						io.LogError(err)
+						return
+					}
+					if rw != nil {
+						for _, m := range mutations {
+							t.AddRow(s2.Name(), string(m.Ref), m.Pos, string(m.Alt))
+						}
+						continue
+					}
+					fmt.Printf("%s", s2.Name())
+					for i, m := range mutations {
+						if i == 0 {
+							fmt.Printf("\t%s%d%s", string(m.Ref), m.Pos, string(m.Alt))
+						} else {
+							fmt.Printf(",%s%d%s", string(m.Ref), m.Pos, string(m.Alt))
+						}
+					}
+					fmt.Printf("\n")
+				}
+			}
+			// In text format, only the first alignment is processed
+			if rw == nil {
+				return
+			}
+			if err = rw.Write(nb, t); err != nil {
 				io.LogError(err)
 				return
 			}
-			s, _ = sb.GetSequenceById(0)
+			nb++
 		}
-		for _, s2 := range al.Sequences() {
-			if s2.Name() != statMutationsRef {
-				if mutations, err = s2.ListMutationsComparedToReferenceSequence(al.Alphabet(), align.NewSequence("ref", []uint8(s), ""), statMutationsListCodon || statMutationsListAA, statMutationsListAA); err != nil {
-					io.LogError(err)
-					return
-				}
-				fmt.Printf("%s", s2.Name())
-				for i, m := range mutations {
-					if i == 0 {
-						fmt.Printf("\t%s%d%s", string(m.Ref), m.Pos, string(m.Alt))
-					} else {
-						fmt.Printf(",%s%d%s", string(m.Ref), m.Pos, string(m.Alt))
-					}
-				}
-				fmt.Printf("\n")
-			}
+		if aligns.Err != nil {
+			err = aligns.Err
+			io.LogError(err)
 		}
 		return
 	},
@@ -93,5 +109,6 @@ var statMutationsListCmd = &cobra.Command{
 func init() {
 	statMutationsListCmd.PersistentFlags().BoolVar(&statMutationsListAA, "aa", false, "Take the reference sequence condon by codon, and translate ")
 	statMutationsListCmd.PersistentFlags().BoolVar(&statMutationsListCodon, "codon", false, "Take the reference sequence condon by codon, and do not translate (mutually exclusive with --aa)")
+	addGeneticCodeFlag(statMutationsListCmd, &statMutationsListGeneticCode)
 	statMutationsCmd.AddCommand(statMutationsListCmd)
 }
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/evolbioinfo/goalign/align"
+	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/report"
+	"github.com/spf13/cobra"
+)
+
+var statSitesRef string
+
+// statSitesCmd represents the stats sites command
+var statSitesCmd = &cobra.Command{
+	Use:   "sites",
+	Short: "Prints statistics of each alignment site",
+	Long: `Prints statistics of each alignment site.
+
+It prints a table with one row per alignment site, and the following columns:
+1. site: Index of the site (0-based);
+2. refpos: Position of the site on the reference sequence, without gaps
+   (0-based, only if --ref-sequence is given, empty if the reference has a gap);
+3. nseqs: Number of sequences;
+4. One column per character of the alignment: Number of occurences of the
+   character at the site (case insensitive);
+5. majority: Most frequent character (in case of ties, the first one in ASCII
+   order, gaps being first: '-' < 'A' < 'C' < ...);
+6. majoritynb: Number of occurences of the most frequent character;
+7. gapfreq: Fraction of gaps;
+8. entropy: Entropy of the site (see goalign compute entropy);
+9. conservation: Conservation of the site: identical, conserved, semiconserved
+   or notconserved (see goalign draw biojs);
+10. variable: true if the site has at least two different characters (gaps, '*' 
+    and '.' are not considered);
+11. informative: true if the site contains at least two characters that occur 
+    at least twice each (gaps, N and X are not considered).
+
+--ref-sequence gives the name of a sequence of the alignment.
+
+The output is tab separated by default (--format text or tsv), with a first column
+(alignment) giving the index of the input alignment, or may be in json (see goalign stats).
+
+If the input alignment contains several alignments, will process all of them.
+`,
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		var aligns *align.AlignChannel
+		var rw *report.Writer
+		var t *report.Table
+
+		if rw, err = tableWriter(os.Stdout, "sites"); err != nil {
+			io.LogError(err)
+			return
+		}
+		if aligns, err = readalign(infile); err != nil {
+			io.LogError(err)
+			return
+		}
+
+		nb := 0
+		for al := range aligns.Achan {
+			if t, err = siteStats(al, statSitesRef); err != nil {
+				io.LogError(err)
+				return
+			}
+			if err = rw.Write(nb, t); err != nil {
+				io.LogError(err)
+				return
+			}
+			nb++
+		}
+		if aligns.Err != nil {
+			err = aligns.Err
+			io.LogError(err)
+		}
+		return
+	},
+}
+
+// siteStats computes the statistics of each site of the alignment.
+// If refseq is not "none", gives the position of each site on the
+// given reference sequence.
+func siteStats(al align.Alignment, refseq string) (t *report.Table, err error) {
+	var refpos []interface{}
+	var chars []uint8
+	var counts map[uint8]int
+	var conservation int
+	var entropy float64
+	var variable, informative map[int]bool
+
+	if refseq != "none" {
+		var ref []uint8
+		var ok bool
+		if ref, ok = al.GetSequenceChar(refseq); !ok {
+			err = fmt.Errorf("reference sequence %s does not exist in the alignment", refseq)
+			return
+		}
+		refpos = make([]interface{}, al.Length())
+		pos := 0
+		for i, c := range ref {
+			if c != align.GAP {
+				refpos[i] = pos
+				pos++
+			}
+		}
+	}
+
+	// All characters of the alignment, case insensitive
+	charset := make(map[uint8]bool)
+	for _, c := range al.UniqueCharacters() {
+		charset[uint8(toUpper(c))] = true
+	}
+	for c := range charset {
+		chars = append(chars, c)
+	}
+	sort.Slice(chars, func(i, j int) bool { return chars[i] < chars[j] })
+
+	columns := []string{"site"}
+	if refpos != nil {
+		columns = append(columns, "refpos")
+	}
+	columns = append(columns, "nseqs")
+	for _, c := range chars {
+		columns = append(columns, string(c))
+	}
+	columns = append(columns, "majority", "majoritynb", "gapfreq", "entropy", "conservation", "variable", "informative")
+	t = report.NewTable(columns...)
+
+	variable = sitesSet(al.VariableSites())
+	informative = sitesSet(al.InformativeSites())
+	majority, occur, _ := al.MaxCharStats(false, false)
+
+	for site := 0; site < al.Length(); site++ {
+		if counts, err = al.CharStatsSite(site); err != nil {
+			return
+		}
+		if entropy, err = al.Entropy(site, false); err != nil {
+			return
+		}
+		if conservation, err = al.SiteConservation(site); err != nil {
+			return
+		}
+		row := []interface{}{site}
+		if refpos != nil {
+			row = append(row, refpos[site])
+		}
+		row = append(row, al.NbSequences())
+		for _, c := range chars {
+			row = append(row, counts[c])
+		}
+		row = append(row, majority[site], occur[site],
+			float64(counts[align.GAP])/float64(al.NbSequences()),
+			entropy, conservationString(conservation),
+			variable[site], informative[site])
+		if err = t.AddRow(row...); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func sitesSet(sites []int) (set map[int]bool) {
+	set = make(map[int]bool, len(sites))
+	for _, s := range sites {
+		set[s] = true
+	}
+	return
+}
+
+func toUpper(c uint8) uint8 {
+	if c >= 'a' && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
+
+func conservationString(conservation int) string {
+	switch conservation {
+	case align.POSITION_IDENTICAL:
+		return "identical"
+	case align.POSITION_CONSERVED:
+		return "conserved"
+	case align.POSITION_SEMI_CONSERVED:
+		return "semiconserved"
+	default:
+		return "notconserved"
+	}
+}
+
+func init() {
+	statSitesCmd.PersistentFlags().StringVar(&statSitesRef, "ref-sequence", "none", "Name of the reference sequence giving site coordinates")
+	statsCmd.AddCommand(statSitesCmd)
+}
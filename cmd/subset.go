@@ -10,6 +10,7 @@ import (
 
 	"github.com/evolbioinfo/goalign/align"
 	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/metadata"
 	"github.com/evolbioinfo/goalign/io/utils"
 	"github.com/spf13/cobra"
 )
@@ -47,6 +48,27 @@ The output format is the same than input format.
 
 If -f is given, it does not take into account sequence names 
 given in the comand line.
+
+Sequences may also be selected using their metadata, given in a tab separated
+file (or comma separated if .csv) with --metadata, with a header line and one line
+per sequence (the column giving sequence names is the first one, or the one given
+with --name-column). The expression given with --where is evaluated on the
+metadata of each sequence, for example:
+
+goalign subset -i al.fa --metadata meta.tsv --where "country=='FR' && date>='2024-01-01'"
+
+Expressions compare columns with values (quoted strings or numbers) using
+==, !=, <, <=, >, >= or =~ (regular expression match), combined with &&, || and !
+and parentheses. Values are compared numerically if both are numbers, and as
+strings otherwise (which works for ISO dates). Columns year, month, week and day
+are derived from the date column (--date-column) if they do not exist.
+Sequences absent from the metadata do not satisfy the expression.
+
+If names are also given, sequences must both be given and satisfy the expression.
+--revert removes the selected sequences instead.
+
+If --metadata-out is given, metadata of the output sequences are written in
+the given file.
 `,
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		var subset map[string]int
@@ -56,6 +78,14 @@ given in the comand line.
 		var r *regexp.Regexp
 		var indexlist []int
 		var regexps []*regexp.Regexp
+		var md *metadata.Metadata
+		var selected map[string]bool
+		var kept []string = make([]string, 0)
+
+		if md, err = readMetadata(); err != nil {
+			io.LogError(err)
+			return
+		}
 
 		// If input file
 		if namefile != "stdin" {
@@ -105,13 +135,17 @@ given in the comand line.
 				io.LogError(err)
 				return
 			}
+			if selected, err = metadataFilter(md, seqs); err != nil {
+				io.LogError(err)
+				return
+			}
 			var filtered align.SeqBag = nil
 			var i int = 0
 			seqs.Iterate(func(name string, sequence string) bool {
 				if filtered == nil {
 					filtered = align.NewSeqBag(seqs.Alphabet())
 				}
-				ok := matchSeqName(name, i, subset, regexps, regexmatch, indexlist, indices)
+				ok := matchSeqNameMetadata(name, i, subset, regexps, regexmatch, indexlist, indices, selected)
 				if !revert && ok {
 					filtered.AddSequence(name, sequence, "")
 				} else if revert && !ok {
@@ -121,6 +155,7 @@ given in the comand line.
 				return false
 			})
 			writeSequences(filtered, f)
+			kept = append(kept, seqNames(filtered)...)
 		} else {
 			if aligns, err = readalign(infile); err != nil {
 				io.LogError(err)
@@ -129,11 +164,15 @@ given in the comand line.
 			for al := range aligns.Achan {
 				var filtered align.Alignment = nil
 				var i int = 0
+				if selected, err = metadataFilter(md, al); err != nil {
+					io.LogError(err)
+					return
+				}
 				al.Iterate(func(name string, sequence string) bool {
 					if filtered == nil {
 						filtered = align.NewAlign(al.Alphabet())
 					}
-					ok := matchSeqName(name, i, subset, regexps, regexmatch, indexlist, indices)
+					ok := matchSeqNameMetadata(name, i, subset, regexps, regexmatch, indexlist, indices, selected)
 					if !revert && ok {
 						filtered.AddSequence(name, sequence, "")
 					} else if revert && !ok {
@@ -147,12 +186,17 @@ given in the comand line.
 					filtered.RemoveGapSites(1.0, false)
 				}
 				writeAlign(filtered, f)
+				kept = append(kept, seqNames(filtered)...)
 			}
 			if aligns.Err != nil {
 				err = aligns.Err
 				io.LogError(err)
+				return
 			}
 		}
+		if err = writeMetadata(md, kept); err != nil {
+			io.LogError(err)
+		}
 		return
 	},
 }
@@ -215,6 +259,20 @@ func matchSeqName(name string, index int, subset map[string]int, regexps []*rege
 	return ok
 }
 
+// Returns true if the name matches the given names (see matchSeqName) and
+// is in the selected set of sequences (from metadata).
+// If selected is nil, then only names are considered.
+// If no names are given, then only selected sequences are considered.
+func matchSeqNameMetadata(name string, index int, subset map[string]int, regexps []*regexp.Regexp, regexp bool, indexlist []int, indices bool, selected map[string]bool) bool {
+	if selected == nil {
+		return matchSeqName(name, index, subset, regexps, regexp, indexlist, indices)
+	}
+	if len(subset) == 0 {
+		return selected[name]
+	}
+	return selected[name] && matchSeqName(name, index, subset, regexps, regexp, indexlist, indices)
+}
+
 func init() {
 	RootCmd.AddCommand(subsetCmd)
 	subsetCmd.PersistentFlags().StringVarP(&namefile, "name-file", "f", "stdin", "File containing names of sequences to keep")
@@ -223,5 +281,6 @@ func init() {
 	subsetCmd.PersistentFlags().BoolVarP(&revert, "revert", "r", false, "If true, will remove given sequences instead of keeping only them")
 	subsetCmd.PersistentFlags().BoolVar(&indices, "indices", false, "If true, extracts given sequence indices instead of sequence names (0-based)")
 	subsetCmd.PersistentFlags().BoolVar(&unaligned, "unaligned", false, "Considers input sequences as unaligned and fasta format (phylip, nexus,... options are ignored)")
+	addMetadataFlags(subsetCmd, true, true)
 	subsetCmd.PersistentFlags().BoolVar(&subsetrmgaps, "remove-gaps", false, "If true, then remove gap only sites of the sub-alignment (only when --unaligned is not given)")
 }
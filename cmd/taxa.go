@@ -5,6 +5,7 @@ import (
 
 	"github.com/evolbioinfo/goalign/align"
 	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/report"
 	"github.com/spf13/cobra"
 )
 
@@ -23,7 +24,12 @@ goalign stats taxa -i align.phylip -p
 goalign stats taxa -i align.fasta
 `,
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		var rw *report.Writer
 
+		if rw, err = reportWriter("taxa"); err != nil {
+			io.LogError(err)
+			return
+		}
 		if unaligned {
 			var seqs align.SeqBag
 
@@ -31,12 +37,7 @@ goalign stats taxa -i align.fasta
 				io.LogError(err)
 				return
 			}
-			i := 0
-			seqs.Iterate(func(name string, sequence string) bool {
-				fmt.Printf("%d\t%s\n", i, name)
-				i++
-				return false
-			})
+			err = printTaxa(rw, seqs)
 		} else {
 			var aligns *align.AlignChannel
 
@@ -52,17 +53,32 @@ goalign stats taxa -i align.fasta
 				return
 			}
 
-			i := 0
-			al.Iterate(func(name string, sequence string) bool {
-				fmt.Printf("%d\t%s\n", i, name)
-				i++
-				return false
-			})
+			err = printTaxa(rw, al)
 		}
 		return
 	},
 }
 
+func printTaxa(rw *report.Writer, sb align.SeqBag) (err error) {
+	t := report.NewTable("index", "sequence")
+	i := 0
+	sb.Iterate(func(name string, sequence string) bool {
+		if rw == nil {
+			fmt.Printf("%d\t%s\n", i, name)
+		} else {
+			t.AddRow(i, name)
+		}
+		i++
+		return false
+	})
+	if rw != nil {
+		if err = rw.Write(0, t); err != nil {
+			io.LogError(err)
+		}
+	}
+	return
+}
+
 func init() {
 	taxaCmd.PersistentFlags().BoolVar(&unaligned, "unaligned", false, "Considers sequences as unaligned and format fasta (phylip, nexus,... options are ignored)")
 	statsCmd.AddCommand(taxaCmd)
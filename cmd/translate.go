@@ -1,10 +1,9 @@
 package cmd
 
 import (
-	"fmt"
-
 	"github.com/evolbioinfo/goalign/align"
 	"github.com/evolbioinfo/goalign/io"
+	"github.com/evolbioinfo/goalign/io/report"
 	"github.com/evolbioinfo/goalign/io/utils"
 	"github.com/spf13/cobra"
 )
@@ -13,6 +12,9 @@ var translatePhase int
 var translateOutput string
 var translateGeneticCode string
 var translaterefseq string
+var translateAAClasses bool
+var translateAltStart bool
+var translateReport string
 
 // translateCmd represents the addid command
 var translateCmd = &cobra.Command{
@@ -31,10 +33,55 @@ _<phase>. At the end, 3x times more sequences will be present in the
 file.
 
 It is possible to specify alternative genetic code with --genetic-code 
-(mitoi, mitov, or standard).
+(mitoi, mitov, or standard), or any NCBI translation table by its number
+(https://www.ncbi.nlm.nih.gov/Taxonomy/Utils/wprintgc.cgi):
+1: Standard (standard),
+2: Vertebrate Mitochondrial (mitov),
+3: Yeast Mitochondrial,
+4: Mold, Protozoan, and Coelenterate Mitochondrial and Mycoplasma/Spiroplasma,
+5: Invertebrate Mitochondrial (mitoi),
+6: Ciliate, Dasycladacean and Hexamita Nuclear,
+9: Echinoderm and Flatworm Mitochondrial,
+10: Euplotid Nuclear,
+11: Bacterial, Archaeal and Plant Plastid,
+12: Alternative Yeast Nuclear,
+13: Ascidian Mitochondrial,
+14: Alternative Flatworm Mitochondrial,
+15: Blepharisma Macronuclear,
+16: Chlorophycean Mitochondrial,
+21: Trematode Mitochondrial,
+22: Scenedesmus obliquus Mitochondrial,
+23: Thraustochytrium Mitochondrial,
+24: Rhabdopleuridae Mitochondrial,
+25: Candidate Division SR1 and Gracilibacteria,
+26: Pachysolen tannophilus Nuclear,
+27: Karyorelict Nuclear,
+28: Condylostoma Nuclear,
+29: Mesodinium Nuclear,
+30: Peritrich Nuclear,
+31: Blastocrithidia Nuclear,
+32: Balanophoraceae Plastid,
+33: Cephalodiscidae Mitochondrial.
+The same genetic codes are available in goalign orf, phase, phasent, codonalign,
+replace stops, stats mutations list, stats codons and compute dnds.
 
 IUPAC codes are taken into account for the translation. If a codon containing 
 IUPAC code is ambiguous for translation, then a X is added in place of the aminoacid.
+Ambiguous codons whose possible codons all give the same amino acid are translated
+into this amino acid (ex: GGN=G, YTR=L). If --aa-ambiguity is given, ambiguous codons
+giving either D or N, E or Q, I or L are translated into B, Z and J respectively 
+(ex: RAY=B, SAR=Z, MTT=J).
+
+If --alt-start is given, the first codon of each sequence (after --phase) is translated
+into M if it is an initiation codon of the genetic code, as in goalign orf --alt-start
+(ex: TTG or GTG with --genetic-code 11, which are otherwise translated into L and V).
+
+If --ambiguity-report is given, a tab separated file is written, with one line per
+translated sequence, and the columns: alignment (index of the input alignment),
+sequence, codons (number of translated codons, without gap codons), ambiguous (number
+of codons containing ambiguous nucleotides or gaps), resolved (number of ambiguous
+codons translated into a single amino acid), aaclasses (number of ambiguous codons
+translated into B, Z or J), unresolved (number of ambiguous codons translated into X).
 
 If --ref-seq is given, be careful about the behavior! As with goalign extract, it will will translate
 the alignment with the following process: The alignment will be translated codon by
@@ -73,6 +120,8 @@ translation of the unaligned sequences.
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		var f utils.StringWriterCloser
 		var geneticcode int
+		var stats []align.TranslationStats
+		var rw *report.Writer
 
 		if f, err = utils.OpenWriteFile(translateOutput); err != nil {
 			io.LogError(err)
@@ -80,19 +129,23 @@ translation of the unaligned sequences.
 		}
 		defer utils.CloseWriteFile(f, translateOutput)
 
-		switch translateGeneticCode {
-		case "standard":
-			geneticcode = align.GENETIC_CODE_STANDARD
-		case "mitov":
-			geneticcode = align.GENETIC_CODE_VETEBRATE_MITO
-		case "mitoi":
-			geneticcode = align.GENETIC_CODE_INVETEBRATE_MITO
-
-		default:
-			err = fmt.Errorf("unknown genetic code : %s", translateGeneticCode)
+		if geneticcode, err = parseGeneticCode(translateGeneticCode); err != nil {
 			return
 		}
 
+		if translateReport != "none" {
+			var fr utils.StringWriterCloser
+			if fr, err = utils.OpenWriteFile(translateReport); err != nil {
+				io.LogError(err)
+				return
+			}
+			defer utils.CloseWriteFile(fr, translateReport)
+			if rw, err = tableWriter(fr, "translate"); err != nil {
+				io.LogError(err)
+				return
+			}
+		}
+
 		if unaligned {
 			var seqs align.SeqBag
 
@@ -100,11 +153,15 @@ translation of the unaligned sequences.
 				io.LogError(err)
 				return
 			}
-			if err = seqs.Translate(translatePhase, geneticcode); err != nil {
+			if stats, err = seqs.TranslateIUPAC(translatePhase, geneticcode, translateAAClasses, translateAltStart); err != nil {
 				io.LogError(err)
 				return
 			}
 			writeSequences(seqs, f)
+			if err = writeTranslationStats(rw, 0, stats); err != nil {
+				io.LogError(err)
+				return
+			}
 		} else {
 			var aligns *align.AlignChannel
 			var al align.Alignment
@@ -113,19 +170,25 @@ translation of the unaligned sequences.
 				io.LogError(err)
 				return
 			}
+			nb := 0
 			for al = range aligns.Achan {
 				if translaterefseq != "" {
-					if err = al.TranslateByReference(translatePhase, geneticcode, translaterefseq); err != nil {
+					if stats, err = al.TranslateByReferenceIUPAC(translatePhase, geneticcode, translaterefseq, translateAAClasses, translateAltStart); err != nil {
 						io.LogError(err)
 						return
 					}
 				} else {
-					if err = al.Translate(translatePhase, geneticcode); err != nil {
+					if stats, err = al.TranslateIUPAC(translatePhase, geneticcode, translateAAClasses, translateAltStart); err != nil {
 						io.LogError(err)
 						return
 					}
 				}
 				writeAlign(al, f)
+				if err = writeTranslationStats(rw, nb, stats); err != nil {
+					io.LogError(err)
+					return
+				}
+				nb++
 			}
 
 			if aligns.Err != nil {
@@ -140,9 +203,25 @@ translation of the unaligned sequences.
 
 func init() {
 	RootCmd.AddCommand(translateCmd)
-	translateCmd.PersistentFlags().StringVar(&translateGeneticCode, "genetic-code", "standard", "Genetic Code: standard, mitoi (invertebrate mitochondrial) or mitov (vertebrate mitochondrial)")
+	addGeneticCodeFlag(translateCmd, &translateGeneticCode)
 	translateCmd.PersistentFlags().StringVarP(&translateOutput, "output", "o", "stdout", "Output translated alignment file")
 	translateCmd.PersistentFlags().StringVar(&translaterefseq, "ref-seq", "", "Reference sequence on which coordinates are given (ignored if --unaligned)")
+	translateCmd.PersistentFlags().BoolVar(&translateAAClasses, "aa-ambiguity", false, "Translates ambiguous codons giving D or N, E or Q, I or L into B, Z and J respectively (instead of X)")
+	translateCmd.PersistentFlags().BoolVar(&translateAltStart, "alt-start", false, "Translates the first codon into M if it is an initiation codon of the genetic code (default: only ATG)")
+	translateCmd.PersistentFlags().StringVar(&translateReport, "ambiguity-report", "none", "Output file of the numbers of ambiguous codons of each sequence, and how they were translated")
 	translateCmd.PersistentFlags().IntVar(&translatePhase, "phase", 0, "Number of characters to drop from the start of the alignment (if -1: Translate in the 3 phases, from positions 0, 1, and 2)")
 	translateCmd.PersistentFlags().BoolVar(&unaligned, "unaligned", false, "Considers sequences as unaligned and format fasta (phylip, nexus,... options are ignored)")
 }
+
+// writeTranslationStats writes the numbers of ambiguous codons of each
+// translated sequence, if rw is not nil
+func writeTranslationStats(rw *report.Writer, nb int, stats []align.TranslationStats) (err error) {
+	if rw == nil {
+		return
+	}
+	t := report.NewTable("sequence", "codons", "ambiguous", "resolved", "aaclasses", "unresolved")
+	for _, s := range stats {
+		t.AddRow(s.Name, s.Codons, s.Ambiguous, s.Resolved, s.Classes, s.Unresolved)
+	}
+	return rw.Write(nb, t)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"strings"
 	"sync"
 
 	"github.com/evolbioinfo/goalign/align"
@@ -75,6 +76,12 @@ func Model(modelType string, removegaps bool) (model DistModel, err error) {
 		model = NewTN93Model(removegaps)
 	case "f84":
 		model = NewF84Model(removegaps)
+	case "t92":
+		model = NewT92Model(removegaps)
+	case "logdet", "paralinear":
+		model = NewLogDetModel(removegaps)
+	case "ml-jc", "ml-k2p", "ml-f81", "ml-f84", "ml-tn93", "ml-gtr":
+		model, err = NewMLModel(strings.TrimPrefix(modelType, "ml-"), removegaps)
 	default:
 		err = errors.New("This model is not implemented : " + modelType)
 	}
@@ -228,6 +235,85 @@ func DistMatrix(al align.Alignment, weights []float64, model DistModel, range1Mi
 	return
 }
 
+// QueryDistances initializes the model on the reference alignment only (selected sites,
+// frequencies, etc.), and returns a function computing the distances between a query
+// sequence, aligned to the reference, and all the reference sequences, using cpus threads.
+// As in DistMatrix, distances that cannot be computed (infinite, negative, or > NT_DIST_OVER)
+// are replaced by twice the maximum distance of the query, so that the distances of a
+// query do not depend on the other queries.
+func QueryDistances(ref align.Alignment, weights []float64, model DistModel, gamma bool, alpha float64, cpus int) (dists func(query []uint8) ([]float64, error), err error) {
+	var refseqs [][]uint8
+
+	if ref.Alphabet() != align.NUCLEOTIDS {
+		err = errors.New("The alignment is not nucleotidic")
+		return
+	}
+	if err = model.InitModel(ref, weights, gamma, alpha); err != nil {
+		return
+	}
+	if cpus <= 0 {
+		cpus = 1
+	}
+	refseqs = make([][]uint8, ref.NbSequences())
+	for i := range refseqs {
+		if refseqs[i], err = model.Sequence(i); err != nil {
+			return
+		}
+	}
+
+	dists = func(query []uint8) (row []float64, err error) {
+		if len(query) != ref.Length() {
+			err = fmt.Errorf("query sequence length (%d) is different from reference length (%d)", len(query), ref.Length())
+			return
+		}
+		qseq := make([]uint8, len(query))
+		for l, r := range query {
+			if qseq[l], err = align.Nt2IndexIUPAC(r); err != nil {
+				return
+			}
+		}
+		row = make([]float64, len(refseqs))
+		errs := make([]error, cpus)
+		var wg sync.WaitGroup
+		for c := range cpus {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := c; i < len(refseqs); i += cpus {
+					if row[i], errs[c] = model.Distance(qseq, refseqs[i], weights); errs[c] != nil {
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		for _, e := range errs {
+			if e != nil {
+				return nil, e
+			}
+		}
+
+		max := 0.0
+		for _, d := range row {
+			if !uncomputable(d) && d > max {
+				max = d
+			}
+		}
+		for i, d := range row {
+			if uncomputable(d) {
+				row[i] = 2 * max
+			}
+		}
+		return
+	}
+	return
+}
+
+// uncomputable returns true if the distance is infinite, negative, or > NT_DIST_OVER
+func uncomputable(d float64) bool {
+	return d < 0 || d == math.Inf(1) || d > NT_DIST_OVER
+}
+
 /* Returns true if it is a transition, false otherwise */
 func isTransition(n1 uint8, n2 uint8) bool {
 	return ((n1 == align.NT_A && n2 == align.NT_G) || (n1 == align.NT_G && n2 == align.NT_A) ||
@@ -187,3 +187,121 @@ func Test_countDiffsWithInternalGaps(t *testing.T) {
 		})
 	}
 }
+
+func TestT92Model(t *testing.T) {
+	al := align.NewAlign(align.NUCLEOTIDS)
+	al.AddSequence("s1", "ACGTACGTACGTACGTNN", "")
+	al.AddSequence("s2", "ACGTACGTGCGTACTTAC", "")
+
+	t92 := NewT92Model(false)
+	k2p := NewK2PModel(false)
+	for _, gamma := range []bool{false, true} {
+		t92.InitModel(al, nil, gamma, 1.5)
+		k2p.InitModel(al, nil, gamma, 1.5)
+		seq1, _ := t92.Sequence(0)
+		seq2, _ := t92.Sequence(1)
+		// Both GC contents are 0.5 on compared sites: T92 is K2P
+		dt92, err := t92.Distance(seq1, seq2, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dk2p, _ := k2p.Distance(seq1, seq2, nil)
+		if math.Abs(dt92-dk2p) > 1e-12 {
+			t.Errorf("T92 (gamma=%v): got %v, want %v", gamma, dt92, dk2p)
+		}
+	}
+
+	// Removing ambiguous positions: 1 transition and 1 transversion over 16 sites
+	t92.InitModel(al, nil, false, 0)
+	t92.SetRemoveAmbiguous(true)
+	seq1, _ := t92.Sequence(0)
+	seq2, _ := t92.Sequence(1)
+	d, _ := t92.Distance(seq1, seq2, nil)
+	exp := -.5*math.Log(1.-2./16.-1./16.) - .25*math.Log(1.-2./16.)
+	if math.Abs(d-exp) > 1e-12 {
+		t.Errorf("T92 (rm-ambiguous): got %v, want %v", d, exp)
+	}
+}
+
+func TestLogDetModel(t *testing.T) {
+	// All ordered pairs of different nucleotides once, and each identical pair 3 times:
+	// divergence matrix of a JC model with p=0.5
+	s1 := "AAACCCGGGTTT" + "AAACCCGGGTTT"
+	s2 := "CGTAGTACTACG" + "AAACCCGGGTTT"
+	al := align.NewAlign(align.NUCLEOTIDS)
+	al.AddSequence("s1", s1, "")
+	al.AddSequence("s2", s2, "")
+
+	m := NewLogDetModel(false)
+	m.InitModel(al, nil, false, 0)
+	seq1, _ := m.Sequence(0)
+	seq2, _ := m.Sequence(1)
+	d, err := m.Distance(seq1, seq2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := .75 * math.Log(3.); math.Abs(d-exp) > 1e-9 {
+		t.Errorf("LogDet: got %v, want %v", d, exp)
+	}
+
+	alpha := 2.0
+	m.InitModel(al, nil, true, alpha)
+	if d, err = m.Distance(seq1, seq2, nil); err != nil {
+		t.Fatal(err)
+	}
+	if exp := .75 * alpha * (math.Pow(3., 1./alpha) - 1.); math.Abs(d-exp) > 1e-9 {
+		t.Errorf("LogDet gamma: got %v, want %v", d, exp)
+	}
+
+	// Identical sequences
+	if d, _ = m.Distance(seq1, seq1, nil); math.Abs(d) > 1e-9 {
+		t.Errorf("LogDet identical sequences: got %v, want 0", d)
+	}
+}
+
+func TestMLModel(t *testing.T) {
+	// Divergence matrix of a JC model with p=0.5 (see TestLogDetModel)
+	al := align.NewAlign(align.NUCLEOTIDS)
+	al.AddSequence("s1", "AAACCCGGGTTTAAACCCGGGTTT", "")
+	al.AddSequence("s2", "CGTAGTACTACGAAACCCGGGTTT", "")
+
+	for _, model := range []string{"jc", "k2p", "f81", "f84", "tn93", "gtr"} {
+		for _, alpha := range []float64{0, 1.5} {
+			m, err := NewMLModel(model, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = m.InitModel(al, nil, alpha > 0, alpha); err != nil {
+				t.Fatal(err)
+			}
+			// All rates are equal
+			for _, r := range m.Rates() {
+				if math.Abs(r-1.) > 1e-6 {
+					t.Errorf("ML %s: rates %v, want all 1", model, m.Rates())
+					break
+				}
+			}
+			seq1, _ := m.Sequence(0)
+			seq2, _ := m.Sequence(1)
+			d, err := m.Distance(seq1, seq2, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// ML JC distance
+			exp := .75 * math.Log(3.)
+			if alpha > 0 {
+				exp = .75 * alpha * (math.Pow(3., 1./alpha) - 1.)
+			}
+			if math.Abs(d-exp) > 1e-6 {
+				t.Errorf("ML %s (alpha=%v): got %v, want %v", model, alpha, d, exp)
+			}
+			if d, _ = m.Distance(seq1, seq1, nil); d != 0 {
+				t.Errorf("ML %s identical sequences: got %v, want 0", model, d)
+			}
+		}
+	}
+
+	if _, err := NewMLModel("hky", false); err == nil {
+		t.Errorf("ML hky: expected an error")
+	}
+}
@@ -0,0 +1,170 @@
+package dna
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+// LogDetModel is the LogDet/paralinear distance (Lockhart et al. 1994, Lake 1994),
+// which does not assume stationary nucleotide frequencies, and is therefore robust to
+// compositional heterogeneity between sequences.
+type LogDetModel struct {
+	numSites      float64 // Number of selected sites (no gaps)
+	selectedSites []bool  // true for selected sites
+	removegaps    bool    // If true, we will remove posision with >=1 gaps
+	// if true, we remove ambiguous positions for the normalisation by the length
+	// (see PDistModel)
+	removeAmbiguous bool
+	gamma           bool
+	alpha           float64
+	sequenceCodes   [][]uint8 // Sequences converted into int codes
+}
+
+func NewLogDetModel(removegaps bool) *LogDetModel {
+	return &LogDetModel{
+		0,
+		nil,
+		removegaps,
+		false,
+		false,
+		0.,
+		nil,
+	}
+}
+
+// SetRemoveAmbiguous sets removeAmbiguous model variable
+// if true, ambiguous positions are removed for the normalisation by the length
+// for example:
+// N vs. A : position not taken into account in length (can not decide wether there is a difference)
+// R vs. Y : position taken into account in length (we know there is a difference)
+func (m *LogDetModel) SetRemoveAmbiguous(removeAmbiguous bool) {
+	m.removeAmbiguous = removeAmbiguous
+}
+
+/*
+computes the paralinear distance between 2 sequences:
+d = -1/4 [ln det F - 1/2 (ln det Px + ln det Py)]
+with F the 4x4 divergence matrix (frequencies of pairs of nucleotides), and Px and Py
+the diagonal matrices of the nucleotide frequencies of each sequence.
+
+With gamma, the divergence matrix is symmetrized (F+F')/2, with P the mean nucleotide
+frequencies, and the log of each eigenvalue l of P^-1/2 F P^-1/2 is replaced by
+alpha(1-l^(-1/alpha)) (Waddell and Steel 1997): d = 1/4 sum alpha(l^(-1/alpha)-1).
+*/
+func (m *LogDetModel) Distance(seq1 []uint8, seq2 []uint8, weights []float64) (dist float64, err error) {
+	var f *mat.Dense
+
+	if f, err = divergenceMatrix(seq1, seq2, m.selectedSites, weights, m.removeAmbiguous); err != nil {
+		return
+	}
+	px := make([]float64, 4)
+	py := make([]float64, 4)
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			px[i] += f.At(i, j)
+			py[j] += f.At(i, j)
+		}
+	}
+
+	if m.gamma {
+		sym := mat.NewSymDense(4, nil)
+		for i := 0; i < 4; i++ {
+			for j := i; j < 4; j++ {
+				pi := (px[i] + py[i]) / 2.
+				pj := (px[j] + py[j]) / 2.
+				sym.SetSym(i, j, (f.At(i, j)+f.At(j, i))/2./math.Sqrt(pi*pj))
+			}
+		}
+		var eig mat.EigenSym
+		if ok := eig.Factorize(sym, false); !ok {
+			err = fmt.Errorf("cannot compute eigen values of the divergence matrix")
+			return
+		}
+		for _, l := range eig.Values(nil) {
+			if l <= 0 {
+				return math.Inf(1), nil
+			}
+			dist += m.alpha * (math.Pow(l, -1./m.alpha) - 1.)
+		}
+		dist /= 4.
+		return
+	}
+
+	logdet, sign := mat.LogDet(f)
+	if sign <= 0 {
+		return math.Inf(1), nil
+	}
+	logpx, logpy := 0., 0.
+	for i := 0; i < 4; i++ {
+		logpx += math.Log(px[i])
+		logpy += math.Log(py[i])
+	}
+	dist = -.25 * (logdet - .5*(logpx+logpy))
+	return
+}
+
+func (m *LogDetModel) InitModel(al align.Alignment, weights []float64, gamma bool, alpha float64) (err error) {
+	m.gamma = gamma
+	m.alpha = alpha
+	m.numSites, m.selectedSites = selectedSites(al, weights, m.removegaps)
+	m.sequenceCodes, err = alignmentToCodes(al)
+	return
+}
+
+// Sequence returns the ith sequence of the alignment
+// encoded in int
+func (m *LogDetModel) Sequence(i int) (seq []uint8, err error) {
+	if i < 0 || i >= len(m.sequenceCodes) {
+		err = fmt.Errorf("This sequence does not exist: %d", i)
+		return
+	}
+	seq = m.sequenceCodes[i]
+	return
+}
+
+/*
+Computes the (weighted) divergence matrix between 2 sequences: F[i][j] is the
+frequency of sites having nucleotide i in seq1 and nucleotide j in seq2
+(A=0, C=1, G=2, T=3). Ambiguous nucleotides are split between their possible
+nucleotides. If removeAmbiguous is true, positions with ambiguous nucleotides
+are not taken into account, unless nucleotides are incompatible (see countDiffs).
+*/
+func divergenceMatrix(seq1 []uint8, seq2 []uint8, selectedSites []bool, weights []float64, removeAmbiguous bool) (f *mat.Dense, err error) {
+	var id1, id2 []uint8
+
+	f = mat.NewDense(4, 4, nil)
+	total := 0.0
+	for pos := range seq1 {
+		w := 1.0
+		if weights != nil {
+			w = weights[pos]
+		}
+		if !selectedSites[pos] || !isNuc(seq1[pos]) || !isNuc(seq2[pos]) {
+			continue
+		}
+		if removeAmbiguous && (isAmbiguous(seq1[pos]) || isAmbiguous(seq2[pos])) && seq1[pos]&seq2[pos] != 0 {
+			continue
+		}
+		if id1, err = align.PossibleNtIUPAC(seq1[pos]); err != nil {
+			return
+		}
+		if id2, err = align.PossibleNtIUPAC(seq2[pos]); err != nil {
+			return
+		}
+		nb := float64(len(id1) * len(id2))
+		for _, i1 := range id1 {
+			for _, i2 := range id2 {
+				f.Set(ntByteToId[i1], ntByteToId[i2], f.At(ntByteToId[i1], ntByteToId[i2])+w/nb)
+			}
+		}
+		total += w
+	}
+	if total > 0 {
+		f.Scale(1./total, f)
+	}
+	return
+}
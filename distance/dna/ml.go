@@ -0,0 +1,375 @@
+package dna
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+
+	"github.com/evolbioinfo/goalign/align"
+	mdna "github.com/evolbioinfo/goalign/models/dna"
+)
+
+const (
+	ML_BL_MIN = 1.e-08
+	ML_BL_MAX = 100.0
+	// Minimum exchangeability rate relative to the mean transversion rate
+	ML_RATE_MIN = 1.e-4
+)
+
+// MLModel computes maximum likelihood distances under a substitution model
+// of models/dna (jc, k2p, f81, f84, tn93 or gtr), with optional gamma.
+//
+// Model parameters (nucleotide frequencies and exchangeability rates) are estimated
+// once from the whole alignment, in InitModel, then the distance between each pair of
+// sequences is the branch length maximizing the likelihood of their divergence matrix.
+type MLModel struct {
+	model         string    // Name of the models/dna model
+	pi            []float64 // Nucleotide frequencies
+	rates         []float64 // Estimated exchangeability rates: AC, AG, AT, CG, CT, GT
+	val           []float64 // Eigen values of the normalized rate matrix
+	left, right   *mat.Dense
+	numSites      float64 // Number of selected sites (no gaps)
+	selectedSites []bool  // true for selected sites
+	removegaps    bool    // If true, we will remove posision with >=1 gaps
+	gamma         bool
+	alpha         float64
+	sequenceCodes [][]uint8 // Sequences converted into int codes
+}
+
+// NewMLModel returns a new ML distance model, model being the name of a
+// models/dna model: jc, k2p, f81, f84, tn93 or gtr
+func NewMLModel(model string, removegaps bool) (m *MLModel, err error) {
+	model = strings.ToLower(model)
+	switch model {
+	case "jc", "k2p", "f81", "f84", "tn93", "gtr":
+	default:
+		err = fmt.Errorf("this ML distance model is not implemented: %s", model)
+		return
+	}
+	m = &MLModel{
+		model:      model,
+		removegaps: removegaps,
+	}
+	return
+}
+
+// Rates returns the exchangeability rates estimated from the alignment
+// (AC, AG, AT, CG, CT, GT), normalized so that GT is 1
+func (m *MLModel) Rates() []float64 {
+	return m.rates
+}
+
+// Frequencies returns the nucleotide frequencies estimated from the alignment (A, C, G, T)
+func (m *MLModel) Frequencies() []float64 {
+	return m.pi
+}
+
+/* computes ML distance between 2 sequences */
+func (m *MLModel) Distance(seq1 []uint8, seq2 []uint8, weights []float64) (dist float64, err error) {
+	var f *mat.Dense
+
+	if f, err = divergenceMatrix(seq1, seq2, m.selectedSites, weights, false); err != nil {
+		return
+	}
+	if mat.Sum(f) == 0 {
+		return math.NaN(), nil
+	}
+	diffs := 0.0
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			if i != j {
+				diffs += f.At(i, j)
+			}
+		}
+	}
+	if diffs == 0 {
+		return 0, nil
+	}
+
+	// Minimization of -lnL on log(distance)
+	x := brentMinimize(func(x float64) float64 {
+		return -m.lnL(f, math.Exp(x))
+	}, math.Log(ML_BL_MIN), math.Log(ML_BL_MAX), 1.e-8)
+	dist = math.Exp(x)
+	if dist > ML_BL_MAX*0.99 {
+		dist = math.Inf(1)
+	}
+	return
+}
+
+// lnL returns the log likelihood of the divergence matrix f given the distance d
+// (up to a constant: pi_i terms are ignored)
+func (m *MLModel) lnL(f *mat.Dense, d float64) (lnl float64) {
+	var expt [4]float64
+	for k := 0; k < 4; k++ {
+		if m.gamma {
+			expt[k] = math.Pow(m.alpha/(m.alpha-m.val[k]*d), m.alpha)
+		} else {
+			expt[k] = math.Exp(m.val[k] * d)
+		}
+	}
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			if f.At(i, j) == 0 {
+				continue
+			}
+			p := 0.0
+			for k := 0; k < 4; k++ {
+				p += m.right.At(i, k) * expt[k] * m.left.At(k, j)
+			}
+			if p < mdna.DBL_MIN {
+				p = mdna.DBL_MIN
+			}
+			lnl += f.At(i, j) * math.Log(p)
+		}
+	}
+	return
+}
+
+func (m *MLModel) InitModel(al align.Alignment, weights []float64, gamma bool, alpha float64) (err error) {
+	var model mdna.DNAModel
+
+	m.gamma = gamma
+	m.alpha = alpha
+	if gamma && alpha <= 0 {
+		err = fmt.Errorf("gamma alpha parameter must be > 0: %f", alpha)
+		return
+	}
+	m.numSites, m.selectedSites = selectedSites(al, weights, m.removegaps)
+	if m.sequenceCodes, err = alignmentToCodes(al); err != nil {
+		return
+	}
+	if m.pi, err = probaNt(m.sequenceCodes, m.selectedSites, weights); err != nil {
+		return
+	}
+	sum := 0.0
+	for _, p := range m.pi {
+		sum += p
+	}
+	for i := range m.pi {
+		m.pi[i] /= sum
+	}
+	if m.rates, err = m.estimateRates(weights); err != nil {
+		return
+	}
+
+	// Rates relative to the mean transversion rate
+	r := m.rates
+	tv := (r[0] + r[2] + r[3] + r[5]) / 4.
+	piA, piC, piG, piT := m.pi[0], m.pi[1], m.pi[2], m.pi[3]
+	switch m.model {
+	case "jc":
+		jc := mdna.NewJCModel()
+		err = jc.InitModel()
+		model = jc
+	case "k2p":
+		k2p := mdna.NewK2PModel()
+		k2p.InitModel((r[1] + r[4]) / 2. / tv)
+		model = k2p
+	case "f81":
+		f81 := mdna.NewF81Model()
+		err = f81.InitModel(piA, piC, piG, piT)
+		model = f81
+	case "f84":
+		// Transition rates are (1+kappa/piR) and (1+kappa/piY) times the transversion rate
+		kappa := ((piA+piG)*(r[1]/tv-1.) + (piC+piT)*(r[4]/tv-1.)) / 2.
+		f84 := mdna.NewF84Model()
+		f84.InitModel(math.Max(0, kappa), piA, piC, piG, piT)
+		model = f84
+	case "tn93":
+		tn93 := mdna.NewTN93Model()
+		err = tn93.InitModel(r[1]/tv, r[4]/tv, piA, piC, piG, piT)
+		model = tn93
+	case "gtr":
+		gtr := mdna.NewGTRModel()
+		err = gtr.InitModel(r[0], r[1], r[2], r[3], r[4], r[5], piA, piC, piG, piT)
+		model = gtr
+	}
+	if err != nil {
+		return
+	}
+	m.val, m.left, m.right, err = model.Eigens()
+	return
+}
+
+/*
+Estimates GTR exchangeability rates from the divergence matrix F of all pairs of
+sequences (symmetrized, and normalized): with P = Pi^-1 F, Qt = log(P) (computed
+with the eigen decomposition of the symmetric matrix Pi^-1/2 F Pi^-1/2), and
+rate(i,j) = Qt(i,j)/pi(j). With gamma, log(l) is replaced by alpha(1-l^(-1/alpha)).
+If rates can not be estimated (saturated alignment), all rates are 1.
+Rates are returned in the order AC, AG, AT, CG, CT, GT, and normalized so that GT is 1.
+*/
+func (m *MLModel) estimateRates(weights []float64) (rates []float64, err error) {
+	var fpair *mat.Dense
+	var eig mat.EigenSym
+
+	rates = []float64{1., 1., 1., 1., 1., 1.}
+	f := mat.NewDense(4, 4, nil)
+	for i := 0; i < len(m.sequenceCodes); i++ {
+		for j := i + 1; j < len(m.sequenceCodes); j++ {
+			if fpair, err = divergenceMatrix(m.sequenceCodes[i], m.sequenceCodes[j], m.selectedSites, weights, false); err != nil {
+				return
+			}
+			f.Add(f, fpair)
+		}
+	}
+	total := mat.Sum(f)
+	if total == 0 {
+		return
+	}
+
+	// Symmetrized divergence matrix and its nucleotide frequencies
+	pi := make([]float64, 4)
+	s := mat.NewSymDense(4, nil)
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			pi[i] += (f.At(i, j) + f.At(j, i)) / 2. / total
+			if j >= i {
+				s.SetSym(i, j, (f.At(i, j)+f.At(j, i))/2./total)
+			}
+		}
+	}
+	for i := 0; i < 4; i++ {
+		if pi[i] <= 0 {
+			return
+		}
+		for j := i; j < 4; j++ {
+			s.SetSym(i, j, s.At(i, j)/math.Sqrt(pi[i]*pi[j]))
+		}
+	}
+	if ok := eig.Factorize(s, true); !ok {
+		return
+	}
+	vals := eig.Values(nil)
+	var u mat.Dense
+	eig.VectorsTo(&u)
+	for k, l := range vals {
+		if l <= 0 {
+			return
+		}
+		if m.gamma {
+			vals[k] = m.alpha * (1. - math.Pow(l, -1./m.alpha))
+		} else {
+			vals[k] = math.Log(l)
+		}
+	}
+
+	// rate(i,j) = Qt(i,j)/pi(j), with Qt = Pi^-1/2 U log(L) U' Pi^1/2
+	k := 0
+	for i := 0; i < 4; i++ {
+		for j := i + 1; j < 4; j++ {
+			qt := 0.0
+			for e := 0; e < 4; e++ {
+				qt += u.At(i, e) * vals[e] * u.At(j, e)
+			}
+			qt *= math.Sqrt(pi[j]/pi[i]) / pi[j]
+			rates[k] = qt
+			k++
+		}
+	}
+	tv := (rates[0] + rates[2] + rates[3] + rates[5]) / 4.
+	if tv <= 0 {
+		rates = []float64{1., 1., 1., 1., 1., 1.}
+		return
+	}
+	for i := range rates {
+		rates[i] = math.Max(rates[i], ML_RATE_MIN*tv)
+	}
+	gt := rates[5]
+	for i := range rates {
+		rates[i] /= gt
+	}
+	return
+}
+
+// Sequence returns the ith sequence of the alignment
+// encoded in int
+func (m *MLModel) Sequence(i int) (seq []uint8, err error) {
+	if i < 0 || i >= len(m.sequenceCodes) {
+		err = fmt.Errorf("This sequence does not exist: %d", i)
+		return
+	}
+	seq = m.sequenceCodes[i]
+	return
+}
+
+// brentMinimize returns the x minimizing f on [a,b], using Brent's
+// method (golden section search and parabolic interpolation)
+func brentMinimize(f func(float64) float64, a, b, tol float64) float64 {
+	const cgold = 0.3819660
+	const zeps = 1.e-10
+	var d, e float64
+
+	x := a + cgold*(b-a)
+	w, v := x, x
+	fx := f(x)
+	fw, fv := fx, fx
+	for iter := 0; iter < 1000; iter++ {
+		xm := 0.5 * (a + b)
+		tol1 := tol*math.Abs(x) + zeps
+		tol2 := 2. * tol1
+		if math.Abs(x-xm) <= tol2-0.5*(b-a) {
+			break
+		}
+		if math.Abs(e) > tol1 {
+			r := (x - w) * (fx - fv)
+			q := (x - v) * (fx - fw)
+			p := (x-v)*q - (x-w)*r
+			q = 2. * (q - r)
+			if q > 0. {
+				p = -p
+			}
+			q = math.Abs(q)
+			etemp := e
+			e = d
+			if math.Abs(p) >= math.Abs(0.5*q*etemp) || p <= q*(a-x) || p >= q*(b-x) {
+				if x >= xm {
+					e = a - x
+				} else {
+					e = b - x
+				}
+				d = cgold * e
+			} else {
+				d = p / q
+				if u := x + d; u-a < tol2 || b-u < tol2 {
+					d = math.Copysign(tol1, xm-x)
+				}
+			}
+		} else {
+			if x >= xm {
+				e = a - x
+			} else {
+				e = b - x
+			}
+			d = cgold * e
+		}
+		u := x + math.Copysign(math.Max(math.Abs(d), tol1), d)
+		fu := f(u)
+		if fu <= fx {
+			if u >= x {
+				a = x
+			} else {
+				b = x
+			}
+			v, w, x = w, x, u
+			fv, fw, fx = fw, fx, fu
+		} else {
+			if u < x {
+				a = u
+			} else {
+				b = u
+			}
+			if fu <= fw || w == x {
+				v, w = w, u
+				fv, fw = fw, fu
+			} else if fu <= fv || v == x || v == w {
+				v = u
+				fv = fu
+			}
+		}
+	}
+	return x
+}
@@ -0,0 +1,238 @@
+package dna
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/stat/distuv"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+// XiaTest gives Xia's index of substitution saturation of an alignment
+type XiaTest struct {
+	NbSequences int     // Number of sequences
+	Sites       int     // Number of sites with at least 2 unambiguous nucleotides
+	H           float64 // Mean site entropy (log2)
+	HFSS        float64 // Mean expected site entropy under full substitution saturation
+	Iss         float64 // Index of substitution saturation: H/HFSS
+	StdErr      float64 // Standard error of Iss
+}
+
+// SaturationPair gives the number of transitions and transversions between two
+// sequences, and their corrected distance
+type SaturationPair struct {
+	Seq1, Seq2    int     // Indices of the sequences
+	Sites         float64 // Number of compared sites
+	Transitions   float64 // Number of transitions
+	Transversions float64 // Number of transversions
+	Distance      float64 // Corrected distance
+}
+
+// XiaSaturation computes Xia's index of substitution saturation (Xia et al. 2003).
+//
+// For each site, the entropy H = -sum(p log2 p) of its unambiguous nucleotides is
+// computed (gaps and ambiguous nucleotides are considered missing), and compared to
+// HFSS, its expected value under full substitution saturation, i.e. when the nucleotides
+// of the n sequences having data at this site are drawn from the nucleotide frequencies
+// of the alignment: HFSS = -sum_j sum_k Binom(k;n,pi_j) (k/n) log2(k/n).
+// Iss = mean(H)/mean(HFSS). Its standard error is sd(H)/sqrt(L)/mean(HFSS), L being
+// the number of sites with at least 2 unambiguous nucleotides.
+func XiaSaturation(al align.Alignment) (x XiaTest, err error) {
+	var codes [][]uint8
+
+	if al.Alphabet() != align.NUCLEOTIDS {
+		err = fmt.Errorf("alignment must be nucleotidic")
+		return
+	}
+	if codes, err = alignmentToCodes(al); err != nil {
+		return
+	}
+
+	// Counts of unambiguous nucleotides at each site, and nucleotide frequencies
+	pi := make([]float64, 4)
+	total := 0.0
+	counts := make([][4]int, al.Length())
+	for l := range counts {
+		for _, seq := range codes {
+			if isNucStrict(seq[l]) {
+				counts[l][ntByteToId[seq[l]]]++
+				pi[ntByteToId[seq[l]]]++
+				total++
+			}
+		}
+	}
+	for i := range pi {
+		pi[i] /= total
+	}
+
+	x.NbSequences = al.NbSequences()
+	hfss := make(map[int]float64)
+	var sumh, sumh2, sumhfss float64
+	for _, c := range counts {
+		n := c[0] + c[1] + c[2] + c[3]
+		if n < 2 {
+			continue
+		}
+		h := 0.0
+		for _, nc := range c {
+			if nc > 0 {
+				p := float64(nc) / float64(n)
+				h -= p * math.Log2(p)
+			}
+		}
+		if _, ok := hfss[n]; !ok {
+			hfss[n] = fullSaturationEntropy(pi, n)
+		}
+		x.Sites++
+		sumh += h
+		sumh2 += h * h
+		sumhfss += hfss[n]
+	}
+	if x.Sites == 0 {
+		x.H, x.HFSS, x.Iss, x.StdErr = math.NaN(), math.NaN(), math.NaN(), math.NaN()
+		return
+	}
+	l := float64(x.Sites)
+	x.H = sumh / l
+	x.HFSS = sumhfss / l
+	x.Iss = x.H / x.HFSS
+	x.StdErr = math.NaN()
+	if x.Sites > 1 {
+		sd := math.Sqrt(math.Max(0, (sumh2-l*x.H*x.H)/(l-1)))
+		x.StdErr = sd / math.Sqrt(l) / x.HFSS
+	}
+	return
+}
+
+// Numbers of OTUs and sequence lengths of the Iss.c tables
+var issCNotus = []float64{4, 8, 16, 32}
+var issCLengths = []float64{100, 300, 500, 1000, 2000, 5000}
+
+// Critical values Iss.c for symmetrical and asymmetrical topologies, by number of
+// OTUs (rows) and sequence length (columns), as used by DAMBE
+// (Xia et al. 2003, Xia & Lemey 2009)
+var issCSym = [][]float64{
+	{0.78, 0.82, 0.84, 0.85, 0.86, 0.87},
+	{0.76, 0.80, 0.82, 0.84, 0.85, 0.86},
+	{0.74, 0.78, 0.80, 0.82, 0.83, 0.84},
+	{0.71, 0.76, 0.78, 0.80, 0.81, 0.82},
+}
+var issCAsym = [][]float64{
+	{0.74, 0.78, 0.80, 0.82, 0.83, 0.84},
+	{0.65, 0.70, 0.72, 0.75, 0.76, 0.77},
+	{0.54, 0.59, 0.62, 0.65, 0.66, 0.68},
+	{0.40, 0.45, 0.48, 0.51, 0.53, 0.55},
+}
+
+// IssC returns the critical value Iss.c of Xia's test for a symmetrical (or
+// asymmetrical) topology of notu OTUs, and sequences of the given length, as
+// DAMBE does: values simulated by Xia et al. (2003) and Xia & Lemey (2009) for
+// 4, 8, 16 and 32 OTUs and lengths from 100 to 5000 sites are interpolated
+// linearly in log2(notu) and in length. Numbers of OTUs and lengths outside
+// these ranges are brought back to the closest bound.
+func IssC(notu, length int, symmetrical bool) float64 {
+	table := issCAsym
+	if symmetrical {
+		table = issCSym
+	}
+	i, fi := interpolationIndex(issCNotus, float64(notu), true)
+	j, fj := interpolationIndex(issCLengths, float64(length), false)
+	row := func(i int) float64 {
+		return table[i][j] + fj*(table[i][j+1]-table[i][j])
+	}
+	return row(i) + fi*(row(i+1)-row(i))
+}
+
+// IssC returns the critical values Iss.c of the test for symmetrical and
+// asymmetrical topologies, given its number of sequences and of sites
+func (x XiaTest) IssC() (sym, asym float64) {
+	return IssC(x.NbSequences, x.Sites, true), IssC(x.NbSequences, x.Sites, false)
+}
+
+// interpolationIndex returns the index i of the interval [grid[i],grid[i+1]]
+// containing v (brought back to the grid bounds), and the relative position
+// of v in this interval (on a log2 scale if log is true)
+func interpolationIndex(grid []float64, v float64, log bool) (i int, f float64) {
+	v = math.Max(grid[0], math.Min(grid[len(grid)-1], v))
+	for i < len(grid)-2 && v > grid[i+1] {
+		i++
+	}
+	if log {
+		f = (math.Log2(v) - math.Log2(grid[i])) / (math.Log2(grid[i+1]) - math.Log2(grid[i]))
+	} else {
+		f = (v - grid[i]) / (grid[i+1] - grid[i])
+	}
+	return
+}
+
+// TTest tests whether Iss is significantly different from the critical value issc
+// (Iss.c, Xia et al. 2003), with a two-tailed t-test with Sites-1 degrees of freedom.
+// If Iss is significantly lower than Iss.c, sequences have experienced little saturation.
+func (x XiaTest) TTest(issc float64) (t float64, df int, pvalue float64) {
+	df = x.Sites - 1
+	t = (issc - x.Iss) / x.StdErr
+	pvalue = math.NaN()
+	if df > 0 && !math.IsNaN(t) {
+		pvalue = 2 * distuv.StudentsT{Mu: 0, Sigma: 1, Nu: float64(df)}.Survival(math.Abs(t))
+	}
+	return
+}
+
+// fullSaturationEntropy returns the expected entropy (log2) of a site of n
+// nucleotides drawn from the nucleotide frequencies pi
+func fullSaturationEntropy(pi []float64, n int) (h float64) {
+	for _, p := range pi {
+		if p <= 0 {
+			continue
+		}
+		b := distuv.Binomial{N: float64(n), P: p}
+		for k := 1; k <= n; k++ {
+			q := float64(k) / float64(n)
+			h -= b.Prob(float64(k)) * q * math.Log2(q)
+		}
+	}
+	return
+}
+
+// SaturationPairs computes, for each pair of sequences of the alignment, the number
+// of transitions and transversions (see countMutations), and their corrected distance
+// under the given model (k2p or tn93). If removegaps is true, sites with at least one
+// gap are removed.
+func SaturationPairs(al align.Alignment, model string, removegaps bool) (pairs []SaturationPair, err error) {
+	var m DistModel
+	var seq1, seq2 []uint8
+
+	if al.Alphabet() != align.NUCLEOTIDS {
+		err = fmt.Errorf("alignment must be nucleotidic")
+		return
+	}
+	if model != "k2p" && model != "tn93" {
+		err = fmt.Errorf("saturation model must be k2p or tn93: %s", model)
+		return
+	}
+	if m, err = Model(model, removegaps); err != nil {
+		return
+	}
+	if err = m.InitModel(al, nil, false, 0); err != nil {
+		return
+	}
+	_, sites := selectedSites(al, nil, removegaps)
+	for i := 0; i < al.NbSequences(); i++ {
+		if seq1, err = m.Sequence(i); err != nil {
+			return
+		}
+		for j := i + 1; j < al.NbSequences(); j++ {
+			if seq2, err = m.Sequence(j); err != nil {
+				return
+			}
+			p := SaturationPair{Seq1: i, Seq2: j}
+			p.Transitions, p.Transversions, _, _, p.Sites = countMutations(seq1, seq2, sites, nil)
+			if p.Distance, err = m.Distance(seq1, seq2, nil); err != nil {
+				return
+			}
+			pairs = append(pairs, p)
+		}
+	}
+	return
+}
@@ -0,0 +1,79 @@
+package dna
+
+import (
+	"math"
+	"testing"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+func Test_fullSaturationEntropy(t *testing.T) {
+	pi := []float64{0.1, 0.2, 0.3, 0.4}
+	// Two nucleotides: entropy is 1 if they differ, 0 otherwise
+	exp := 1.0 - (0.01 + 0.04 + 0.09 + 0.16)
+	if h := fullSaturationEntropy(pi, 2); math.Abs(h-exp) > 1e-9 {
+		t.Errorf("fullSaturationEntropy(2) = %v, want %v", h, exp)
+	}
+	// Equal frequencies, many nucleotides: close to log2(4)
+	if h := fullSaturationEntropy([]float64{0.25, 0.25, 0.25, 0.25}, 1000); math.Abs(h-2.0) > 0.01 {
+		t.Errorf("fullSaturationEntropy(1000) = %v, want ~2", h)
+	}
+}
+
+func TestXiaSaturation(t *testing.T) {
+	al := align.NewAlign(align.NUCLEOTIDS)
+	al.AddSequence("s1", "AACG-", "")
+	al.AddSequence("s2", "AACAT", "")
+	al.AddSequence("s3", "ACGTN", "")
+	al.AddSequence("s4", "ACTC-", "")
+
+	x, err := XiaSaturation(al)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.NbSequences != 4 || x.Sites != 4 {
+		t.Errorf("XiaSaturation: nseqs=%d sites=%d, want 4 4", x.NbSequences, x.Sites)
+	}
+	// Site entropies: 0, 1, 1.5, 2 (last site has 1 nucleotide only: ignored)
+	if math.Abs(x.H-4.5/4.0) > 1e-9 {
+		t.Errorf("XiaSaturation: H=%v, want %v", x.H, 4.5/4.0)
+	}
+	// A=7/17, C=5/17, G=2/17, T=3/17
+	hfss := fullSaturationEntropy([]float64{7.0 / 17.0, 5.0 / 17.0, 2.0 / 17.0, 3.0 / 17.0}, 4)
+	if math.Abs(x.HFSS-hfss) > 1e-9 || math.Abs(x.Iss-x.H/hfss) > 1e-9 {
+		t.Errorf("XiaSaturation: HFSS=%v Iss=%v, want %v %v", x.HFSS, x.Iss, hfss, x.H/hfss)
+	}
+	sd := math.Sqrt(((0-x.H)*(0-x.H) + (1-x.H)*(1-x.H) + (1.5-x.H)*(1.5-x.H) + (2-x.H)*(2-x.H)) / 3.0)
+	if math.Abs(x.StdErr-sd/2.0/hfss) > 1e-9 {
+		t.Errorf("XiaSaturation: SE=%v, want %v", x.StdErr, sd/2.0/hfss)
+	}
+	if _, df, _ := x.TTest(0.8); df != 3 {
+		t.Errorf("TTest: df=%d, want 3", df)
+	}
+}
+
+func TestIssC(t *testing.T) {
+	for _, c := range []struct {
+		notu, length int
+		sym          bool
+		exp          float64
+	}{
+		// Table values
+		{4, 1000, true, 0.85},
+		{32, 1000, false, 0.51},
+		// Interpolation between 8 and 16 OTUs (log2 scale), and 500 and 1000 sites
+		{12, 750, true, (0.82+0.84)/2.*(1-math.Log2(1.5)) + (0.80+0.82)/2.*math.Log2(1.5)},
+		// Bounds
+		{3, 50, true, 0.78},
+		{100, 10000, false, 0.55},
+	} {
+		if v := IssC(c.notu, c.length, c.sym); math.Abs(v-c.exp) > 1e-9 {
+			t.Errorf("IssC(%d, %d, %t) = %v, want %v", c.notu, c.length, c.sym, v, c.exp)
+		}
+	}
+
+	x := XiaTest{NbSequences: 16, Sites: 2000}
+	if sym, asym := x.IssC(); sym != 0.83 || asym != 0.66 {
+		t.Errorf("XiaTest.IssC() = %v %v, want 0.83 0.66", sym, asym)
+	}
+}
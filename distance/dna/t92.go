@@ -0,0 +1,146 @@
+package dna
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+// T92Model is the Tamura (1992) model: K2P with unequal GC contents.
+// GC contents are computed on each pair of sequences, which makes the
+// distance robust to GC content heterogeneity between sequences.
+type T92Model struct {
+	numSites      float64 // Number of selected sites (no gaps)
+	selectedSites []bool  // true for selected sites
+	removegaps    bool    // If true, we will remove posision with >=1 gaps
+	// if true, we remove ambiguous positions for the normalisation by the length
+	// (see PDistModel)
+	removeAmbiguous bool
+	gamma           bool
+	alpha           float64
+	sequenceCodes   [][]uint8 // Sequences converted into int codes
+}
+
+func NewT92Model(removegaps bool) *T92Model {
+	return &T92Model{
+		0,
+		nil,
+		removegaps,
+		false,
+		false,
+		0.,
+		nil,
+	}
+}
+
+// SetRemoveAmbiguous sets removeAmbiguous model variable
+// if true, ambiguous positions are removed for the normalisation by the length
+// for example:
+// N vs. A : position not taken into account in length (can not decide wether there is a difference)
+// R vs. Y : position taken into account in length (we know there is a difference)
+func (m *T92Model) SetRemoveAmbiguous(removeAmbiguous bool) {
+	m.removeAmbiguous = removeAmbiguous
+}
+
+/*
+computes T92 distance between 2 sequences:
+d = -h ln(1 - P/h - Q) - 1/2 (1-h) ln(1 - 2Q)
+with h = gc1 + gc2 - 2 gc1 gc2, gc1 and gc2 being the GC contents of the sequences
+*/
+func (m *T92Model) Distance(seq1 []uint8, seq2 []uint8, weights []float64) (dist float64, err error) {
+	var trS, trV, gc1, gc2, total float64
+
+	if trS, trV, gc1, gc2, total, err = countT92(seq1, seq2, m.selectedSites, weights, m.removeAmbiguous); err != nil {
+		return
+	}
+	trS, trV = trS/total, trV/total
+	h := gc1 + gc2 - 2*gc1*gc2
+
+	if m.gamma {
+		dist = m.alpha * (h*(math.Pow(1.-trS/h-trV, -1./m.alpha)-1.) + .5*(1.-h)*(math.Pow(1.-2.*trV, -1./m.alpha)-1.))
+	} else {
+		dist = -h*math.Log(1.-trS/h-trV) - .5*(1.-h)*math.Log(1.-2.*trV)
+	}
+	return
+}
+
+func (m *T92Model) InitModel(al align.Alignment, weights []float64, gamma bool, alpha float64) (err error) {
+	m.gamma = gamma
+	m.alpha = alpha
+	m.numSites, m.selectedSites = selectedSites(al, weights, m.removegaps)
+	m.sequenceCodes, err = alignmentToCodes(al)
+	return
+}
+
+// Sequence returns the ith sequence of the alignment
+// encoded in int
+func (m *T92Model) Sequence(i int) (seq []uint8, err error) {
+	if i < 0 || i >= len(m.sequenceCodes) {
+		err = fmt.Errorf("This sequence does not exist: %d", i)
+		return
+	}
+	seq = m.sequenceCodes[i]
+	return
+}
+
+/*
+Counts the (weighted) number of transitions and transversions between the 2 sequences
+(as countMutations), and the GC content of each sequence on the compared sites
+(ambiguous nucleotides being split between their possible nucleotides).
+If removeAmbiguous is true, positions with ambiguous nucleotides are not compared
+unless they give a transversion (see countDiffs).
+*/
+func countT92(seq1 []uint8, seq2 []uint8, selectedSites []bool, weights []float64, removeAmbiguous bool) (transitions, transversions, gc1, gc2, total float64, err error) {
+	var g1, g2 float64
+	for i := 0; i < len(seq1); i++ {
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+		if !isNuc(seq1[i]) || !isNuc(seq2[i]) || !selectedSites[i] {
+			continue
+		}
+		diff := false
+		if seq1[i] != seq2[i] {
+			if isTransversion(seq1[i], seq2[i]) {
+				transversions += w
+				diff = true
+			} else if isTransition(seq1[i], seq2[i]) {
+				transitions += w
+				diff = true
+			}
+		}
+		if !diff && removeAmbiguous && (isAmbiguous(seq1[i]) || isAmbiguous(seq2[i])) {
+			continue
+		}
+		if g1, err = gcFraction(seq1[i]); err != nil {
+			return
+		}
+		if g2, err = gcFraction(seq2[i]); err != nil {
+			return
+		}
+		gc1 += w * g1
+		gc2 += w * g2
+		total += w
+	}
+	gc1 /= total
+	gc2 /= total
+	return
+}
+
+// gcFraction returns the fraction of the possible nucleotides
+// of the given nucleotide code that are G or C
+func gcFraction(nt uint8) (gc float64, err error) {
+	var nts []uint8
+	if nts, err = align.PossibleNtIUPAC(nt); err != nil {
+		return
+	}
+	for _, n := range nts {
+		if n == align.NT_G || n == align.NT_C {
+			gc++
+		}
+	}
+	gc /= float64(len(nts))
+	return
+}
@@ -0,0 +1,323 @@
+// Package kmer computes alignment-free distances between sequences,
+// based on their k-mer content.
+package kmer
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+const (
+	DIST_JACCARD  = iota // 1 - Jaccard index of k-mer sets
+	DIST_MASH            // Mash distance, estimated from MinHash sketches
+	DIST_SPECTRUM        // 1 - cosine similarity of k-mer count vectors
+)
+
+// Default k-mer sizes (as in Mash)
+const (
+	DEFAULT_K_NT = 21
+	DEFAULT_K_AA = 9
+)
+
+// DistanceFromString returns the distance code corresponding to
+// the given name: jaccard, mash or spectrum
+func DistanceFromString(dist string) (code int, err error) {
+	switch strings.ToLower(dist) {
+	case "jaccard":
+		code = DIST_JACCARD
+	case "mash":
+		code = DIST_MASH
+	case "spectrum":
+		code = DIST_SPECTRUM
+	default:
+		err = fmt.Errorf("unknown k-mer distance: %s", dist)
+	}
+	return
+}
+
+// profile stores the k-mer content of a sequence:
+// counts of distinct k-mers (jaccard and spectrum),
+// or sorted bottom sketch hashes (mash)
+type profile struct {
+	counts map[string]int
+	sketch []uint64
+}
+
+type seqpair struct {
+	i, j int
+}
+
+/*
+DistMatrix computes the k-mer distance dist (DIST_JACCARD, DIST_MASH or DIST_SPECTRUM)
+between all pairs of sequences of sb, using k-mers of size k.
+
+Gaps are removed from sequences before k-mer extraction, and k-mers containing characters
+other than A, C, G, T/U (nucleotides) or the 20 standard amino acids are ignored. If canonical
+is true (nucleotides only), a k-mer and its reverse complement are considered identical.
+
+  - jaccard  : 1 - |A inter B|/|A union B|, A and B being the sets of k-mers of the two sequences
+  - mash     : -1/k ln(2j/(1+j)), j being the Jaccard index estimated from bottom MinHash
+    sketches of size sketchSize (Ondov et al. 2016). It is 1 if no k-mer is shared
+  - spectrum : 1 - cosine similarity of the k-mer count vectors of the two sequences
+
+Distances involving a sequence without any k-mer are NaN.
+Profiles and distances are computed using cpus threads.
+*/
+func DistMatrix(sb align.SeqBag, dist int, k int, sketchSize int, canonical bool, cpus int) (outmatrix [][]float64, err error) {
+	var valid [256]bool
+	var distfunc func(p1, p2 *profile) float64
+	var nucleotides bool
+
+	if k <= 0 {
+		err = fmt.Errorf("k-mer size must be > 0: %d", k)
+		return
+	}
+	if cpus <= 0 {
+		cpus = 1
+	}
+	switch sb.Alphabet() {
+	case align.NUCLEOTIDS:
+		nucleotides = true
+		for _, c := range "ACGT" {
+			valid[c] = true
+		}
+	case align.AMINOACIDS:
+		if canonical {
+			err = fmt.Errorf("canonical k-mers are only available for nucleotide sequences")
+			return
+		}
+		for _, c := range "ACDEFGHIKLMNPQRSTVWY" {
+			valid[c] = true
+		}
+	default:
+		err = fmt.Errorf("unknown sequence alphabet")
+		return
+	}
+	switch dist {
+	case DIST_JACCARD:
+		distfunc = jaccardDist
+	case DIST_MASH:
+		if sketchSize <= 0 {
+			err = fmt.Errorf("sketch size must be > 0: %d", sketchSize)
+			return
+		}
+		distfunc = func(p1, p2 *profile) float64 {
+			return mashDist(p1, p2, k, sketchSize)
+		}
+	case DIST_SPECTRUM:
+		distfunc = spectrumDist
+	default:
+		err = fmt.Errorf("unknown k-mer distance: %d", dist)
+		return
+	}
+
+	n := sb.NbSequences()
+	profiles := make([]*profile, n)
+	outmatrix = make([][]float64, n)
+	for i := 0; i < n; i++ {
+		outmatrix[i] = make([]float64, n)
+	}
+
+	// K-mer profiles of all sequences
+	idxchan := make(chan int, 100)
+	go func() {
+		defer close(idxchan)
+		for i := 0; i < n; i++ {
+			idxchan <- i
+		}
+	}()
+	var wg sync.WaitGroup
+	for range cpus {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idxchan {
+				seq, _ := sb.GetSequenceCharById(i)
+				counts := kmerCounts(seq, k, &valid, nucleotides, canonical)
+				if dist == DIST_MASH {
+					profiles[i] = &profile{sketch: bottomSketch(counts, sketchSize)}
+				} else {
+					profiles[i] = &profile{counts: counts}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Distances between all pairs
+	pairchan := make(chan seqpair, 100)
+	go func() {
+		defer close(pairchan)
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				pairchan <- seqpair{i, j}
+			}
+		}
+	}()
+	for range cpus {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range pairchan {
+				d := distfunc(profiles[p.i], profiles[p.j])
+				outmatrix[p.i][p.j] = d
+				outmatrix[p.j][p.i] = d
+			}
+		}()
+	}
+	wg.Wait()
+
+	return
+}
+
+// kmerCounts returns the number of occurences of each k-mer of the sequence
+// (in upper case, without gaps, and U replaced by T if nucleotides is true),
+// k-mers containing non valid characters being ignored
+func kmerCounts(seq []uint8, k int, valid *[256]bool, nucleotides, canonical bool) (counts map[string]int) {
+	counts = make(map[string]int)
+	clean := make([]uint8, 0, len(seq))
+	for _, c := range seq {
+		if c == align.GAP || c == align.POINT {
+			continue
+		}
+		if c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		// U is uracil for nucleotides, but selenocysteine for amino acids
+		if nucleotides && c == 'U' {
+			c = 'T'
+		}
+		clean = append(clean, c)
+	}
+
+	// Start of the current run of valid characters
+	start := 0
+	for i, c := range clean {
+		if !valid[c] {
+			start = i + 1
+			continue
+		}
+		if i-start+1 < k {
+			continue
+		}
+		kmer := clean[i-k+1 : i+1]
+		if canonical {
+			kmer = canonicalKmer(kmer)
+		}
+		counts[string(kmer)]++
+	}
+	return
+}
+
+// canonicalKmer returns the lexicographically smallest of the k-mer
+// and of its reverse complement (k-mer made of A, C, G and T only)
+func canonicalKmer(kmer []uint8) []uint8 {
+	rc := make([]uint8, len(kmer))
+	for i, c := range kmer {
+		var comp uint8
+		switch c {
+		case 'A':
+			comp = 'T'
+		case 'C':
+			comp = 'G'
+		case 'G':
+			comp = 'C'
+		case 'T':
+			comp = 'A'
+		}
+		rc[len(kmer)-1-i] = comp
+	}
+	if string(rc) < string(kmer) {
+		return rc
+	}
+	return kmer
+}
+
+// bottomSketch returns the sketchSize smallest hashes (FNV-1a 64 bits)
+// of the distinct k-mers, in increasing order
+func bottomSketch(counts map[string]int, sketchSize int) (sketch []uint64) {
+	sketch = make([]uint64, 0, len(counts))
+	h := fnv.New64a()
+	for kmer := range counts {
+		h.Reset()
+		h.Write([]byte(kmer))
+		sketch = append(sketch, h.Sum64())
+	}
+	sort.Slice(sketch, func(i, j int) bool { return sketch[i] < sketch[j] })
+	if len(sketch) > sketchSize {
+		sketch = sketch[:sketchSize]
+	}
+	return
+}
+
+// jaccardDist returns 1 - the Jaccard index of the k-mer sets
+func jaccardDist(p1, p2 *profile) float64 {
+	if len(p1.counts) == 0 || len(p2.counts) == 0 {
+		return math.NaN()
+	}
+	small, large := p1.counts, p2.counts
+	if len(small) > len(large) {
+		small, large = large, small
+	}
+	inter := 0
+	for kmer := range small {
+		if _, ok := large[kmer]; ok {
+			inter++
+		}
+	}
+	return 1. - float64(inter)/float64(len(p1.counts)+len(p2.counts)-inter)
+}
+
+// mashDist returns the Mash distance between the two sketches: the Jaccard index is
+// estimated on the sketchSize smallest hashes of the union of the two sketches
+func mashDist(p1, p2 *profile, k, sketchSize int) float64 {
+	s1, s2 := p1.sketch, p2.sketch
+	if len(s1) == 0 || len(s2) == 0 {
+		return math.NaN()
+	}
+	i, j, common, total := 0, 0, 0, 0
+	for total < sketchSize && (i < len(s1) || j < len(s2)) {
+		if j >= len(s2) || (i < len(s1) && s1[i] < s2[j]) {
+			i++
+		} else if i >= len(s1) || s2[j] < s1[i] {
+			j++
+		} else {
+			common++
+			i++
+			j++
+		}
+		total++
+	}
+	if common == 0 {
+		return 1.
+	}
+	if common == total {
+		return 0.
+	}
+	jac := float64(common) / float64(total)
+	return math.Min(1., -1./float64(k)*math.Log(2.*jac/(1.+jac)))
+}
+
+// spectrumDist returns 1 - the cosine similarity of the k-mer count vectors
+func spectrumDist(p1, p2 *profile) float64 {
+	if len(p1.counts) == 0 || len(p2.counts) == 0 {
+		return math.NaN()
+	}
+	dot, n1, n2 := 0., 0., 0.
+	for kmer, c1 := range p1.counts {
+		n1 += float64(c1 * c1)
+		if c2, ok := p2.counts[kmer]; ok {
+			dot += float64(c1 * c2)
+		}
+	}
+	for _, c2 := range p2.counts {
+		n2 += float64(c2 * c2)
+	}
+	return math.Max(0., 1.-dot/math.Sqrt(n1*n2))
+}
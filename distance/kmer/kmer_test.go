@@ -0,0 +1,134 @@
+package kmer
+
+import (
+	"math"
+	"testing"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+func testSeqBag(t *testing.T, alphabet int, seqs ...string) align.SeqBag {
+	sb := align.NewSeqBag(alphabet)
+	for i, s := range seqs {
+		if err := sb.AddSequence(string(rune('a'+i)), s, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return sb
+}
+
+func Test_kmerCounts(t *testing.T) {
+	var valid [256]bool
+	for _, c := range "ACGT" {
+		valid[c] = true
+	}
+	// Gaps are removed, N breaks k-mers, U is T
+	counts := kmerCounts([]uint8("ac-gtNacgU"), 3, &valid, true, false)
+	exp := map[string]int{"ACG": 2, "CGT": 2}
+	if len(counts) != len(exp) {
+		t.Fatalf("k-mer counts should be %v but are %v", exp, counts)
+	}
+	for k, c := range exp {
+		if counts[k] != c {
+			t.Errorf("count of %s should be %d but is %d", k, c, counts[k])
+		}
+	}
+
+	// ACG and CGT are reverse complements
+	counts = kmerCounts([]uint8("ACGT"), 3, &valid, true, true)
+	if len(counts) != 1 || counts["ACG"] != 2 {
+		t.Errorf("canonical k-mer counts should be map[ACG:2] but are %v", counts)
+	}
+
+	// For amino acids, U (selenocysteine) is not T and breaks k-mers
+	valid = [256]bool{}
+	for _, c := range "ACDEFGHIKLMNPQRSTVWY" {
+		valid[c] = true
+	}
+	counts = kmerCounts([]uint8("MKTUMKT"), 3, &valid, false, false)
+	if len(counts) != 1 || counts["MKT"] != 2 {
+		t.Errorf("protein k-mer counts should be map[MKT:2] but are %v", counts)
+	}
+}
+
+func TestDistMatrixJaccard(t *testing.T) {
+	// k-mers: {ACG, CGT, GTA}, {ACG, CGT, GTT}, {TTT}
+	sb := testSeqBag(t, align.NUCLEOTIDS, "ACGTA", "ACGTT", "TTT")
+	d, err := DistMatrix(sb, DIST_JACCARD, 3, 0, false, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := [][]float64{{0, 0.5, 1}, {0.5, 0, 1}, {1, 1, 0}}
+	for i := range exp {
+		for j := range exp[i] {
+			if math.Abs(d[i][j]-exp[i][j]) > 1e-12 {
+				t.Errorf("jaccard distance [%d][%d] should be %f but is %f", i, j, exp[i][j], d[i][j])
+			}
+		}
+	}
+}
+
+func TestDistMatrixSpectrum(t *testing.T) {
+	// 2-mers: {AC:2, CA:1}, {AC:1, CG:1}
+	sb := testSeqBag(t, align.NUCLEOTIDS, "ACAC", "ACG", "A")
+	d, err := DistMatrix(sb, DIST_SPECTRUM, 2, 0, false, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := 1. - 2./(math.Sqrt(5)*math.Sqrt(2))
+	if math.Abs(d[0][1]-exp) > 1e-12 {
+		t.Errorf("spectrum distance should be %f but is %f", exp, d[0][1])
+	}
+	if !math.IsNaN(d[0][2]) {
+		t.Errorf("spectrum distance with a sequence without k-mer should be NaN but is %f", d[0][2])
+	}
+}
+
+func TestDistMatrixMash(t *testing.T) {
+	s1 := "ACGTTGCATGCATGCCGATCGATCGGATCCTAGCTAGCATCGACTGACTAGCTAGCTAGCATCG"
+	s2 := s1[:32] + "T" + s1[33:]
+	sb := testSeqBag(t, align.NUCLEOTIDS, s1, s2, s1)
+	k := 5
+
+	// With a sketch larger than the number of k-mers, the Jaccard index is exact
+	jac, err := DistMatrix(sb, DIST_JACCARD, k, 0, false, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mash, err := DistMatrix(sb, DIST_MASH, k, 1000, false, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := 1. - jac[0][1]
+	exp := -1. / float64(k) * math.Log(2.*j/(1.+j))
+	if math.Abs(mash[0][1]-exp) > 1e-12 {
+		t.Errorf("mash distance should be %f but is %f", exp, mash[0][1])
+	}
+	if mash[0][2] != 0 {
+		t.Errorf("mash distance between identical sequences should be 0 but is %f", mash[0][2])
+	}
+
+	// Smaller sketches give an estimate
+	if mash, err = DistMatrix(sb, DIST_MASH, k, 20, false, 1); err != nil {
+		t.Fatal(err)
+	}
+	if mash[0][1] <= 0 || mash[0][1] > 1 {
+		t.Errorf("mash distance estimate should be in ]0,1] but is %f", mash[0][1])
+	}
+}
+
+func TestDistMatrixErrors(t *testing.T) {
+	sb := testSeqBag(t, align.AMINOACIDS, "MKLV", "MKLW")
+	if _, err := DistMatrix(sb, DIST_JACCARD, 2, 0, true, 1); err == nil {
+		t.Error("canonical k-mers with proteins should give an error")
+	}
+	if _, err := DistMatrix(sb, DIST_JACCARD, 0, 0, false, 1); err == nil {
+		t.Error("k=0 should give an error")
+	}
+	if _, err := DistMatrix(sb, DIST_MASH, 2, 0, false, 1); err == nil {
+		t.Error("sketch size 0 should give an error")
+	}
+	if _, err := DistanceFromString("euclid"); err == nil {
+		t.Error("unknown distance should give an error")
+	}
+}
@@ -0,0 +1,90 @@
+package distance
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+// Neighbor is a reference sequence close to a query sequence
+type Neighbor struct {
+	Reference int     // Index of the reference sequence
+	Distance  float64 // Distance between the query and the reference
+}
+
+// QueryDistFunc computes the distances between a query sequence and all the
+// reference sequences (see dna.QueryDistances and protein.ProtDistModel.QueryDistances)
+type QueryDistFunc func(query []uint8) ([]float64, error)
+
+// NearestNeighbors returns, for each query sequence, its k nearest reference sequences
+// (all references if k <= 0) having a distance <= maxdist (if maxdist >= 0), by increasing
+// distance, NaN distances being last, and ties being broken by reference index.
+//
+// Distances are computed one query at a time with dists, and only the k best references
+// are kept for each query, so that the whole query x reference matrix is never stored.
+func NearestNeighbors(queries align.SeqBag, dists QueryDistFunc, k int, maxdist float64) (neighbors [][]Neighbor, err error) {
+	var row []float64
+
+	neighbors = make([][]Neighbor, queries.NbSequences())
+	for q := range neighbors {
+		seq, _ := queries.GetSequenceCharById(q)
+		if row, err = dists(seq); err != nil {
+			return
+		}
+		neighbors[q] = kBest(row, k, maxdist)
+	}
+	return
+}
+
+// kBest returns the k smallest distances of the row (all if k <= 0) that are
+// <= maxdist (if maxdist >= 0), in increasing order, using a bounded max-heap
+func kBest(row []float64, k int, maxdist float64) (best []Neighbor) {
+	h := &neighborHeap{}
+	for r, d := range row {
+		if maxdist >= 0 && !(d <= maxdist) {
+			continue
+		}
+		n := Neighbor{r, d}
+		if k <= 0 || h.Len() < k {
+			heap.Push(h, n)
+		} else if closer(n, (*h)[0]) {
+			(*h)[0] = n
+			heap.Fix(h, 0)
+		}
+	}
+	best = []Neighbor(*h)
+	sort.Slice(best, func(i, j int) bool { return closer(best[i], best[j]) })
+	return
+}
+
+// closer returns true if n1 is closer than n2: smaller distance, NaN being the
+// largest distance, or same distance and smaller reference index
+func closer(n1, n2 Neighbor) bool {
+	nan1, nan2 := math.IsNaN(n1.Distance), math.IsNaN(n2.Distance)
+	switch {
+	case nan1 != nan2:
+		return nan2
+	case !nan1 && n1.Distance != n2.Distance:
+		return n1.Distance < n2.Distance
+	default:
+		return n1.Reference < n2.Reference
+	}
+}
+
+// neighborHeap is a max-heap of neighbors: the root is the farthest neighbor
+type neighborHeap []Neighbor
+
+func (h neighborHeap) Len() int           { return len(h) }
+func (h neighborHeap) Less(i, j int) bool { return closer(h[j], h[i]) }
+func (h neighborHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *neighborHeap) Push(x interface{}) { *h = append(*h, x.(Neighbor)) }
+
+func (h *neighborHeap) Pop() interface{} {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
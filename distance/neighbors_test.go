@@ -0,0 +1,65 @@
+package distance
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/evolbioinfo/goalign/align"
+	"github.com/evolbioinfo/goalign/distance/dna"
+)
+
+func Test_kBest(t *testing.T) {
+	row := []float64{0.3, math.NaN(), 0.1, 0.2, 0.1, 0.5}
+
+	exp := []Neighbor{{2, 0.1}, {4, 0.1}, {3, 0.2}}
+	if best := kBest(row, 3, -1); !reflect.DeepEqual(best, exp) {
+		t.Errorf("kBest(3) = %v, want %v", best, exp)
+	}
+	if best := kBest(row, 0, -1); len(best) != 6 || best[4].Reference != 5 || !math.IsNaN(best[5].Distance) {
+		t.Errorf("kBest(0) = %v, NaN distance should be last", best)
+	}
+	exp = []Neighbor{{2, 0.1}, {4, 0.1}, {3, 0.2}, {0, 0.3}}
+	if best := kBest(row, 10, 0.3); !reflect.DeepEqual(best, exp) {
+		t.Errorf("kBest(10, 0.3) = %v, want %v", best, exp)
+	}
+}
+
+func TestNearestNeighborsIndependentQueries(t *testing.T) {
+	ref := align.NewAlign(align.NUCLEOTIDS)
+	ref.AddSequence("r1", "ACGTACGTACGTACGTACGT", "")
+	ref.AddSequence("r2", "ACGTACGTACGAACGTACGT", "")
+	ref.AddSequence("r3", "ACCTACGTTCGAACGTACTT", "")
+	ref.AddSequence("r4", "TCCTAGGTTCGAACGAACTT", "")
+
+	q1 := "ACGTACGTACGAACGTACTT"
+	one := align.NewAlign(align.NUCLEOTIDS)
+	one.AddSequence("q1", q1, "")
+	// Other queries with gaps and biased composition: they would change
+	// the sites and the frequencies used if the model were initialized on them
+	many := align.NewAlign(align.NUCLEOTIDS)
+	many.AddSequence("q2", "AAAAAAAAAA----------", "")
+	many.AddSequence("q1", q1, "")
+	many.AddSequence("q3", "GGGGGGGGGGGGGGGGGG--", "")
+
+	neighbors := func(queries align.Alignment) [][]Neighbor {
+		dists, err := dna.QueryDistances(ref, nil, dna.NewTN93Model(true), false, 0, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := NearestNeighbors(queries, dists, 2, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	alone := neighbors(one)[0]
+	together := neighbors(many)[1]
+	if !reflect.DeepEqual(alone, together) {
+		t.Errorf("Neighbors of q1 depend on other queries: %v vs. %v", alone, together)
+	}
+	if alone[0].Reference != 1 || alone[1].Reference != 0 {
+		t.Errorf("Wrong nearest neighbors of q1: %v", alone)
+	}
+}
@@ -22,10 +22,7 @@ const (
 var DBL_EPSILON float64 = math.Nextafter(1, 2) - 1
 
 func (model *ProtDistModel) MLDist(a align.Alignment, weights []float64) (p, q, dist *mat.Dense, err error) {
-	var j, k, l int
-	var state0, state1 int
-	var len float64
-	var init, sum float64
+	var j, k int
 	var warn bool
 	var d_max float64
 	var Fs *mat.Dense
@@ -56,60 +53,11 @@ func (model *ProtDistModel) MLDist(a align.Alignment, weights []float64) (p, q,
 		seq1, _ := a.GetSequenceCharById(j)
 		for k = j + 1; k < a.NbSequences(); k++ { // begin for k->n_otu
 			seq2, _ := a.GetSequenceCharById(k)
-			pair := seqpairdist{j, k, seq1, seq2, nil, nil}
-
-			checkAmbiguities(&pair, 1)
-			// If sequences are different (avoid ambiguities), compute distance
-			// Else distance = 0
-			if check2SequencesDiff(&pair) {
-				//Hide_Ambiguities(pair)
-				init = dist.At(j, k)
-
-				if (init == PROT_DIST_MAX) || (init < .0) {
-					init = 0.1
-				}
-				d_max = init
-				Fs.Apply(func(i, j int, v float64) float64 { return .0 }, Fs)
-				len = 0.0
-
-				for l = 0; l < a.Length(); l++ {
-					if selected[l] {
-						w := weights[l]
-						if pair.seq1Ambigu[l] || pair.seq2Ambigu[l] {
-							w = 0.0
-						}
-						state0 = a.AlphabetCharToIndex(pair.seq1[l])
-						state1 = a.AlphabetCharToIndex(pair.seq2[l])
-						if (state0 > -1) && (state1 > -1) {
-							Fs.Set(state0, state1, Fs.At(state0, state1)+w)
-							len += w
-						}
-					}
-				}
-
-				if len > .0 {
-					Fs.Apply(func(i, j int, v float64) float64 { return (v / len) }, Fs)
-				}
-
-				sum = mat.Sum(Fs)
-
-				if sum < .001 {
-					d_max = -1.
-				} else if (sum > 1.-.001) && (sum < 1.+.001) {
-					d_max = model.opt_Dist_F(d_max, Fs)
-				} else {
-					return nil, nil, nil, fmt.Errorf("Invalid value when computing distance. sum = %f.", sum)
-				}
-
-				if d_max >= PROT_DIST_MAX {
-					warn = true
-					d_max = PROT_DIST_MAX
-				}
-			} else {
-				// Do not correct for dist < BL_MIN,
-				// otherwise Fill_Missing_Dist will not be called
-				d_max = 0.
+			var w bool
+			if d_max, w, err = model.pairDist(a, seq1, seq2, weights, selected, dist.At(j, k), Fs); err != nil {
+				return nil, nil, nil, err
 			}
+			warn = warn || w
 			dist.Set(j, k, d_max)
 			dist.Set(k, j, d_max)
 		} // end for k->n_otu
@@ -121,6 +69,111 @@ func (model *ProtDistModel) MLDist(a align.Alignment, weights []float64) (p, q,
 	return
 }
 
+// pairDist computes the ML distance between two sequences of the alignment a (or aligned to a),
+// init being the starting distance (JC69), and Fs a matrix used to store the divergence matrix.
+// warn is true if the distance is >= PROT_DIST_MAX.
+func (model *ProtDistModel) pairDist(a align.Alignment, seq1, seq2 []uint8, weights []float64, selected []bool, init float64, Fs *mat.Dense) (d_max float64, warn bool, err error) {
+	var len, sum float64
+	var state0, state1 int
+
+	pair := seqpairdist{0, 0, seq1, seq2, nil, nil}
+	checkAmbiguities(&pair, 1)
+	// If sequences are different (avoid ambiguities), compute distance
+	// Else distance = 0
+	if !check2SequencesDiff(&pair) {
+		// Do not correct for dist < BL_MIN,
+		// otherwise Fill_Missing_Dist will not be called
+		return 0., false, nil
+	}
+	//Hide_Ambiguities(pair)
+	if (init == PROT_DIST_MAX) || (init < .0) {
+		init = 0.1
+	}
+	d_max = init
+	Fs.Apply(func(i, j int, v float64) float64 { return .0 }, Fs)
+	len = 0.0
+
+	for l := 0; l < a.Length(); l++ {
+		if selected[l] {
+			w := weights[l]
+			if pair.seq1Ambigu[l] || pair.seq2Ambigu[l] {
+				w = 0.0
+			}
+			state0 = a.AlphabetCharToIndex(pair.seq1[l])
+			state1 = a.AlphabetCharToIndex(pair.seq2[l])
+			if (state0 > -1) && (state1 > -1) {
+				Fs.Set(state0, state1, Fs.At(state0, state1)+w)
+				len += w
+			}
+		}
+	}
+
+	if len > .0 {
+		Fs.Apply(func(i, j int, v float64) float64 { return (v / len) }, Fs)
+	}
+
+	sum = mat.Sum(Fs)
+
+	if sum < .001 {
+		d_max = -1.
+	} else if (sum > 1.-.001) && (sum < 1.+.001) {
+		d_max = model.opt_Dist_F(d_max, Fs)
+	} else {
+		return 0, false, fmt.Errorf("Invalid value when computing distance. sum = %f.", sum)
+	}
+
+	if d_max >= PROT_DIST_MAX {
+		warn = true
+		d_max = PROT_DIST_MAX
+	}
+	return
+}
+
+// QueryDistances returns a function computing the ML distances between a query sequence,
+// aligned to the reference alignment ref, and all the reference sequences. Sites are
+// selected (see removegaps) on the reference alignment only, so that the distances of
+// a query do not depend on the other queries. The model must have been initialized
+// with model frequencies, or on the reference alignment (see InitModel).
+func (model *ProtDistModel) QueryDistances(ref align.Alignment, weights []float64) (dists func(query []uint8) ([]float64, error), err error) {
+	var selected []bool
+
+	if ref.Alphabet() != align.AMINOACIDS {
+		err = fmt.Errorf("Cannot compute protein distance with this alignment: Wrong alphabet")
+		return
+	}
+	if weights == nil {
+		weights = make([]float64, ref.Length())
+		for i := range weights {
+			weights[i] = 1.
+		}
+	}
+	_, selected = selectedSites(ref, weights, model.removegaps)
+	Fs := mat.NewDense(model.Ns(), model.Ns(), nil)
+
+	dists = func(query []uint8) (row []float64, err error) {
+		var warn bool
+		if len(query) != ref.Length() {
+			err = fmt.Errorf("query sequence length (%d) is different from reference length (%d)", len(query), ref.Length())
+			return
+		}
+		row = make([]float64, ref.NbSequences())
+		for i := range row {
+			var w bool
+			seq, _ := ref.GetSequenceCharById(i)
+			_, init := model.jc69PairDist(query, seq, weights, selected)
+			if row[i], w, err = model.pairDist(ref, query, seq, weights, selected, init, Fs); err != nil {
+				return nil, err
+			}
+			warn = warn || w
+		}
+		if warn {
+			io.PrintMessage(fmt.Sprintf("At least one query distance exceeds %.2f.", PROT_DIST_MAX))
+		}
+		return
+	}
+	return
+}
+
 func (model *ProtDistModel) lk_Dist(F *mat.Dense, dist float64) float64 {
 	var i, j int
 	var len, lnL float64
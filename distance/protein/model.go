@@ -37,6 +37,22 @@ func NewProtDistModel(model int, modelfreqs bool, usegamma bool, alpha float64,
 	}, nil
 }
 
+// Initialize a new protein model from a PAML format file giving the exchangeability
+// matrix and the amino acid frequencies (see protein.ReadPAMLMats)
+func NewProtDistModelFromFile(file string, modelfreqs bool, usegamma bool, alpha float64, removegaps bool) (*ProtDistModel, error) {
+	m, err := protein.NewProtModelFromFile(file, usegamma, alpha)
+	if err != nil {
+		return nil, err
+	}
+	return &ProtDistModel{
+		m,
+		modelfreqs,
+		removegaps,
+		nil,
+		1,
+	}, nil
+}
+
 func (model *ProtDistModel) InitModel(a align.Alignment, weights []float64) (err error) {
 	var pi []float64
 
@@ -66,61 +82,62 @@ func (model *ProtDistModel) InitModel(a align.Alignment, weights []float64) (err
 
 // Basic JC69 Protein Distance Matrix
 func (model *ProtDistModel) JC69Dist(a align.Alignment, weights []float64, selected []bool) (p *mat.Dense, q *mat.Dense, dist *mat.Dense) {
-	var site, i, j, k int
-	var len *mat.Dense
-	ns := model.Ns()
+	var i, j int
 
-	len = mat.NewDense(a.NbSequences(), a.NbSequences(), nil)
 	p = mat.NewDense(a.NbSequences(), a.NbSequences(), nil)
 	q = mat.NewDense(a.NbSequences(), a.NbSequences(), nil)
 	dist = mat.NewDense(a.NbSequences(), a.NbSequences(), nil)
 
-	for site = 0; site < a.Length(); site += model.stepsize {
-		if selected[site] {
-			for j = 0; j < a.NbSequences()-1; j++ {
-				s1, _ := a.GetSequenceCharById(j)
-				for k = j + 1; k < a.NbSequences(); k++ {
-					s2, _ := a.GetSequenceCharById(k)
-					if (!isAmbigu(s1[site])) && (!isAmbigu(s2[site])) {
-						len.Set(j, k, len.At(j, k)+weights[site])
-						len.Set(k, j, weights[site])
-						for n, c1 := range s1[site : site+model.stepsize] {
-							if c1 != s2[site+n] {
-								p.Set(j, k, p.At(j, k)+weights[site])
-								break
-							}
-						}
-					}
-				}
-			}
+	for i = 0; i < a.NbSequences()-1; i++ {
+		s1, _ := a.GetSequenceCharById(i)
+		for j = i + 1; j < a.NbSequences(); j++ {
+			s2, _ := a.GetSequenceCharById(j)
+			pij, dij := model.jc69PairDist(s1, s2, weights, selected)
+			p.Set(i, j, pij)
+			p.Set(j, i, pij)
+			dist.Set(i, j, dij)
+			dist.Set(j, i, dij)
 		}
 	}
 
-	for i = 0; i < a.NbSequences()-1; i++ {
-		for j = i + 1; j < a.NbSequences(); j++ {
-			if len.At(i, j) > 0 {
-				p.Set(i, j, p.At(i, j)/len.At(i, j))
-			} else {
-				p.Set(i, j, 1.)
-			}
+	return p, q, dist
+}
 
-			p.Set(j, i, p.At(i, j))
+// jc69PairDist returns the proportion of differences p and the JC69 distance
+// between two aligned sequences
+func (model *ProtDistModel) jc69PairDist(s1, s2 []uint8, weights []float64, selected []bool) (p, dist float64) {
+	var length float64
+	ns := model.Ns()
 
-			if (1. - float64(ns)/float64(ns-1.)*p.At(i, j)) < .0 {
-				dist.Set(i, j, PROT_DIST_MAX)
-			} else {
-				dist.Set(i, j, -float64(ns-1.)/float64(ns)*math.Log(1.-float64(ns)/float64(ns-1.)*p.At(i, j)))
-			}
-			if dist.At(i, j) > PROT_DIST_MAX {
-				dist.Set(i, j, PROT_DIST_MAX)
+	for site := 0; site < len(s1); site += model.stepsize {
+		if selected[site] {
+			if (!isAmbigu(s1[site])) && (!isAmbigu(s2[site])) {
+				length += weights[site]
+				for n, c1 := range s1[site : site+model.stepsize] {
+					if c1 != s2[site+n] {
+						p += weights[site]
+						break
+					}
+				}
 			}
-			dist.Set(j, i, dist.At(i, j))
 		}
 	}
 
-	len = nil
+	if length > 0 {
+		p = p / length
+	} else {
+		p = 1.
+	}
 
-	return p, q, dist
+	if (1. - float64(ns)/float64(ns-1.)*p) < .0 {
+		dist = PROT_DIST_MAX
+	} else {
+		dist = -float64(ns-1.) / float64(ns) * math.Log(1.-float64(ns)/float64(ns-1.)*p)
+	}
+	if dist > PROT_DIST_MAX {
+		dist = PROT_DIST_MAX
+	}
+	return
 }
 
 func (model *ProtDistModel) Ns() int {
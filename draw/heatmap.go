@@ -0,0 +1,71 @@
+package draw
+
+import (
+	"bufio"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+)
+
+// HeatmapLayout draws a matrix of values in [0,1]
+type HeatmapLayout interface {
+	DrawHeatmap(values [][]float64) error
+}
+
+type pngHeatmapLayout struct {
+	writer   *bufio.Writer
+	cellsize int
+}
+
+// NewPngHeatmapLayout returns a layout drawing heatmaps in png format,
+// each cell of the matrix being a square of cellsize x cellsize pixels.
+//
+// Values are drawn from white (0) to red (1), NaN values in light grey,
+// and negative values (ex: not computed) in white.
+func NewPngHeatmapLayout(writer *bufio.Writer, cellsize int) HeatmapLayout {
+	if cellsize < 1 {
+		cellsize = 1
+	}
+	return &pngHeatmapLayout{writer, cellsize}
+}
+
+func (layout *pngHeatmapLayout) DrawHeatmap(values [][]float64) (err error) {
+	height := len(values)
+	width := 0
+	for _, row := range values {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width*layout.cellsize, height*layout.cellsize))
+	for i, row := range values {
+		for j := 0; j < width; j++ {
+			c := color.RGBA{255, 255, 255, 255}
+			if j < len(row) {
+				c = heatmapColor(row[j])
+			}
+			for x := j * layout.cellsize; x < (j+1)*layout.cellsize; x++ {
+				for y := i * layout.cellsize; y < (i+1)*layout.cellsize; y++ {
+					img.Set(x, y, c)
+				}
+			}
+		}
+	}
+	return png.Encode(layout.writer, img)
+}
+
+// heatmapColor returns the color of the given value, from white (0) to red (1)
+func heatmapColor(v float64) color.RGBA {
+	switch {
+	case math.IsNaN(v):
+		return color.RGBA{210, 210, 210, 255}
+	case v < 0:
+		return color.RGBA{255, 255, 255, 255}
+	case v > 1:
+		v = 1
+	}
+	gb := uint8(math.Round(255 * (1 - v)))
+	return color.RGBA{255, gb, gb, 255}
+}
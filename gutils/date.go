@@ -0,0 +1,77 @@
+package gutils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	DATE_PRECISION_YEAR  = 0 // Only the year is known (ex: 2021)
+	DATE_PRECISION_MONTH = 1 // Year and month are known (ex: 2021-03)
+	DATE_PRECISION_DAY   = 2 // Full date (ex: 2021-03-01)
+)
+
+// Accepted date layouts, with their precision
+var dateLayouts = []struct {
+	layout    string
+	precision int
+}{
+	{"2006-01-02", DATE_PRECISION_DAY},
+	{"2006/01/02", DATE_PRECISION_DAY},
+	{"2006.01.02", DATE_PRECISION_DAY},
+	{"20060102", DATE_PRECISION_DAY},
+	{"02/01/2006", DATE_PRECISION_DAY},
+	{"2006-1-2", DATE_PRECISION_DAY},
+	{"2006/1/2", DATE_PRECISION_DAY},
+	{"2-Jan-2006", DATE_PRECISION_DAY},
+	{"2 Jan 2006", DATE_PRECISION_DAY},
+	{"2006-01", DATE_PRECISION_MONTH},
+	{"2006/01", DATE_PRECISION_MONTH},
+	{"2006-1", DATE_PRECISION_MONTH},
+	{"Jan-2006", DATE_PRECISION_MONTH},
+	{"2006", DATE_PRECISION_YEAR},
+}
+
+// ParseDate parses the given date string, trying several usual layouts
+// (2021-03-01, 2021/03/01, 20210301, 01/03/2021, 2021-03, 2021, etc.).
+//
+// It returns the parsed date and its precision (DATE_PRECISION_YEAR,
+// DATE_PRECISION_MONTH or DATE_PRECISION_DAY). Missing month and day
+// are set to 1.
+func ParseDate(date string) (t time.Time, precision int, err error) {
+	date = strings.TrimSpace(date)
+	for _, l := range dateLayouts {
+		if t, err = time.Parse(l.layout, date); err == nil {
+			precision = l.precision
+			return
+		}
+	}
+	err = fmt.Errorf("cannot parse date: %s", date)
+	return
+}
+
+// NormalizeDate converts the given date string into ISO 8601 format,
+// keeping its precision: 2021-03-01, 2021-03 or 2021.
+func NormalizeDate(date string) (norm string, err error) {
+	var t time.Time
+	var precision int
+
+	if t, precision, err = ParseDate(date); err != nil {
+		return
+	}
+	norm = FormatDate(t, precision)
+	return
+}
+
+// FormatDate writes the given date in ISO 8601 format, with the given precision
+func FormatDate(t time.Time, precision int) string {
+	switch precision {
+	case DATE_PRECISION_YEAR:
+		return t.Format("2006")
+	case DATE_PRECISION_MONTH:
+		return t.Format("2006-01")
+	default:
+		return t.Format("2006-01-02")
+	}
+}
@@ -0,0 +1,118 @@
+package gutils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Template is a string containing placeholders between braces, for example
+// "{lineage}|{name}|{date}". Literal braces are written "{{" and "}}".
+//
+// A placeholder may be followed by a modifier, applied to its value:
+//   - {key:date}: normalizes the date in ISO 8601 format (see NormalizeDate);
+//   - {key:upper}: converts the value to upper case;
+//   - {key:lower}: converts the value to lower case.
+type Template struct {
+	parts []templatePart
+	keys  []string
+}
+
+type templatePart struct {
+	text        string // Literal text if not placeholder, key otherwise
+	modifier    string // Modifier of the placeholder ("" if none)
+	placeholder bool
+}
+
+var templateModifiers = []string{"date", "upper", "lower"}
+
+// ParseTemplate parses the given template string
+func ParseTemplate(template string) (t *Template, err error) {
+	var b strings.Builder
+	var r []rune = []rune(template)
+
+	t = &Template{
+		parts: make([]templatePart, 0),
+		keys:  make([]string, 0),
+	}
+	for i := 0; i < len(r); i++ {
+		switch {
+		case r[i] == '{' && i+1 < len(r) && r[i+1] == '{':
+			b.WriteRune('{')
+			i++
+		case r[i] == '}' && i+1 < len(r) && r[i+1] == '}':
+			b.WriteRune('}')
+			i++
+		case r[i] == '{':
+			end := i + 1
+			for end < len(r) && r[end] != '}' {
+				end++
+			}
+			if end >= len(r) {
+				err = fmt.Errorf("unclosed placeholder at position %d in template %s", i, template)
+				return
+			}
+			key, modifier, _ := strings.Cut(string(r[i+1:end]), ":")
+			key, modifier = strings.TrimSpace(key), strings.TrimSpace(modifier)
+			if key == "" {
+				err = fmt.Errorf("empty placeholder at position %d in template %s", i, template)
+				return
+			}
+			if modifier != "" && !Contains(templateModifiers, modifier) {
+				err = fmt.Errorf("unknown modifier %s at position %d in template %s", modifier, i, template)
+				return
+			}
+			if b.Len() > 0 {
+				t.parts = append(t.parts, templatePart{b.String(), "", false})
+				b.Reset()
+			}
+			t.parts = append(t.parts, templatePart{key, modifier, true})
+			t.keys = append(t.keys, key)
+			i = end
+		case r[i] == '}':
+			err = fmt.Errorf("unexpected '}' at position %d in template %s", i, template)
+			return
+		default:
+			b.WriteRune(r[i])
+		}
+	}
+	if b.Len() > 0 {
+		t.parts = append(t.parts, templatePart{b.String(), "", false})
+	}
+	return
+}
+
+// Keys returns the placeholder keys of the template (without modifiers), in order of appearance
+func (t *Template) Keys() []string {
+	return t.keys
+}
+
+// Render replaces each placeholder of the template with the value
+// returned by the lookup function for its key, after applying its
+// modifier. Returns an error if a date can not be normalized.
+func (t *Template) Render(lookup func(key string) (string, error)) (out string, err error) {
+	var b strings.Builder
+	var v string
+
+	for _, p := range t.parts {
+		if p.placeholder {
+			if v, err = lookup(p.text); err != nil {
+				return
+			}
+			switch p.modifier {
+			case "date":
+				if v, err = NormalizeDate(v); err != nil {
+					return
+				}
+			case "upper":
+				v = strings.ToUpper(v)
+			case "lower":
+				v = strings.ToLower(v)
+			}
+			b.WriteString(v)
+		} else {
+			b.WriteString(p.text)
+		}
+	}
+	out = b.String()
+	return
+}
@@ -0,0 +1,168 @@
+// Package distmatrix writes distance matrices in different formats
+package distmatrix
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+const (
+	FORMAT_PHYLIP = iota // Square PHYLIP matrix
+	FORMAT_LOWER         // Lower triangular PHYLIP matrix
+	FORMAT_LONG          // One line per pair of sequences: seq1, seq2, distance
+	FORMAT_NPY           // NumPy binary array (float64)
+	FORMAT_MEGA          // MEGA distance matrix (lower left)
+)
+
+// ParseFormat returns the format code corresponding to the given name:
+// phylip, lower, long, npy or mega
+func ParseFormat(format string) (code int, err error) {
+	switch strings.ToLower(format) {
+	case "phylip":
+		code = FORMAT_PHYLIP
+	case "lower":
+		code = FORMAT_LOWER
+	case "long":
+		code = FORMAT_LONG
+	case "npy":
+		code = FORMAT_NPY
+	case "mega":
+		code = FORMAT_MEGA
+	default:
+		err = fmt.Errorf("unknown distance matrix format: %s", format)
+	}
+	return
+}
+
+// Write writes the distance matrix in the given format, names being the names of
+// the rows/columns of the matrix. If maxdist >= 0, only pairs with a distance <= maxdist
+// are written, which is only possible with FORMAT_LONG.
+func Write(w io.Writer, names []string, matrix [][]float64, format int, maxdist float64) (err error) {
+	if len(names) != len(matrix) {
+		return fmt.Errorf("number of names (%d) different from distance matrix size (%d)", len(names), len(matrix))
+	}
+	if maxdist >= 0 && format != FORMAT_LONG {
+		return fmt.Errorf("a maximum distance can only be given with long format")
+	}
+	switch format {
+	case FORMAT_PHYLIP:
+		err = writePhylip(w, names, matrix, false)
+	case FORMAT_LOWER:
+		err = writePhylip(w, names, matrix, true)
+	case FORMAT_LONG:
+		err = writeLong(w, names, matrix, maxdist)
+	case FORMAT_NPY:
+		err = writeNpy(w, matrix)
+	case FORMAT_MEGA:
+		err = writeMega(w, names, matrix)
+	default:
+		err = fmt.Errorf("unknown distance matrix format: %d", format)
+	}
+	return
+}
+
+// writePhylip writes the square (or lower triangular) PHYLIP matrix
+func writePhylip(w io.Writer, names []string, matrix [][]float64, lower bool) (err error) {
+	if _, err = fmt.Fprintf(w, "%d\n", len(matrix)); err != nil {
+		return
+	}
+	for i := range matrix {
+		n := len(matrix)
+		if lower {
+			n = i
+		}
+		line := make([]string, 0, n+1)
+		line = append(line, names[i])
+		for j := 0; j < n; j++ {
+			line = append(line, fmt.Sprintf("%.12f", matrix[i][j]))
+		}
+		if _, err = io.WriteString(w, strings.Join(line, "\t")+"\n"); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// writeLong writes one line per pair of sequences (i<j), with distance <= maxdist if maxdist >= 0
+func writeLong(w io.Writer, names []string, matrix [][]float64, maxdist float64) (err error) {
+	if _, err = io.WriteString(w, "seq1\tseq2\tdistance\n"); err != nil {
+		return
+	}
+	for i := range matrix {
+		for j := i + 1; j < len(matrix); j++ {
+			if maxdist >= 0 && !(matrix[i][j] <= maxdist) {
+				continue
+			}
+			if _, err = fmt.Fprintf(w, "%s\t%s\t%.12f\n", names[i], names[j], matrix[i][j]); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// writeNpy writes the matrix as a NumPy array (.npy format version 1.0),
+// of little endian float64, in C order
+func writeNpy(w io.Writer, matrix [][]float64) (err error) {
+	n := len(matrix)
+	header := fmt.Sprintf("{'descr': '<f8', 'fortran_order': False, 'shape': (%d, %d), }", n, n)
+	// magic (6) + version (2) + header length (2) + header + \n must be a multiple of 64
+	total := 10 + len(header) + 1
+	if pad := total % 64; pad != 0 {
+		header += strings.Repeat(" ", 64-pad)
+	}
+	header += "\n"
+
+	if _, err = w.Write([]byte("\x93NUMPY\x01\x00")); err != nil {
+		return
+	}
+	if err = binary.Write(w, binary.LittleEndian, uint16(len(header))); err != nil {
+		return
+	}
+	if _, err = io.WriteString(w, header); err != nil {
+		return
+	}
+	buf := make([]byte, 8*n)
+	for i := range matrix {
+		for j := 0; j < n; j++ {
+			binary.LittleEndian.PutUint64(buf[8*j:], math.Float64bits(matrix[i][j]))
+		}
+		if _, err = w.Write(buf); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// writeMega writes the matrix in MEGA format (lower left matrix)
+func writeMega(w io.Writer, names []string, matrix [][]float64) (err error) {
+	if _, err = fmt.Fprintf(w, "#mega\n!Title: Distance matrix;\n!Format DataType=Distance DataFormat=LowerLeft NTaxa=%d;\n\n", len(matrix)); err != nil {
+		return
+	}
+	for i, name := range names {
+		if _, err = fmt.Fprintf(w, "[%d] #%s\n", i+1, megaName(name)); err != nil {
+			return
+		}
+	}
+	if _, err = io.WriteString(w, "\n"); err != nil {
+		return
+	}
+	for i := range matrix {
+		line := []string{fmt.Sprintf("[%d]", i+1)}
+		for j := 0; j < i; j++ {
+			line = append(line, fmt.Sprintf("%.12f", matrix[i][j]))
+		}
+		if _, err = io.WriteString(w, strings.Join(line, " ")+"\n"); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// megaName replaces spaces by underscores in taxon names, as MEGA does not allow them
+func megaName(name string) string {
+	return strings.ReplaceAll(name, " ", "_")
+}
@@ -0,0 +1,122 @@
+package distmatrix
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+)
+
+var testNames = []string{"s1", "s2", "s3"}
+var testMatrix = [][]float64{
+	{0, 0.1, 0.5},
+	{0.1, 0, 0.25},
+	{0.5, 0.25, 0},
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, code := range map[string]int{"phylip": FORMAT_PHYLIP, "Lower": FORMAT_LOWER, "long": FORMAT_LONG, "npy": FORMAT_NPY, "MEGA": FORMAT_MEGA} {
+		c, err := ParseFormat(name)
+		if err != nil {
+			t.Error(err)
+		}
+		if c != code {
+			t.Errorf("Format code of %s should be %d but is %d", name, code, c)
+		}
+	}
+	if _, err := ParseFormat("csv"); err == nil {
+		t.Error("Unknown format should give an error")
+	}
+}
+
+func TestWritePhylip(t *testing.T) {
+	var b strings.Builder
+	if err := Write(&b, testNames, testMatrix, FORMAT_PHYLIP, -1); err != nil {
+		t.Fatal(err)
+	}
+	expected := "3\n" +
+		"s1\t0.000000000000\t0.100000000000\t0.500000000000\n" +
+		"s2\t0.100000000000\t0.000000000000\t0.250000000000\n" +
+		"s3\t0.500000000000\t0.250000000000\t0.000000000000\n"
+	if b.String() != expected {
+		t.Errorf("Phylip output should be:\n%s\nbut is:\n%s", expected, b.String())
+	}
+}
+
+func TestWriteLower(t *testing.T) {
+	var b strings.Builder
+	if err := Write(&b, testNames, testMatrix, FORMAT_LOWER, -1); err != nil {
+		t.Fatal(err)
+	}
+	expected := "3\n" +
+		"s1\n" +
+		"s2\t0.100000000000\n" +
+		"s3\t0.500000000000\t0.250000000000\n"
+	if b.String() != expected {
+		t.Errorf("Lower output should be:\n%s\nbut is:\n%s", expected, b.String())
+	}
+}
+
+func TestWriteLong(t *testing.T) {
+	var b strings.Builder
+	if err := Write(&b, testNames, testMatrix, FORMAT_LONG, 0.25); err != nil {
+		t.Fatal(err)
+	}
+	expected := "seq1\tseq2\tdistance\n" +
+		"s1\ts2\t0.100000000000\n" +
+		"s2\ts3\t0.250000000000\n"
+	if b.String() != expected {
+		t.Errorf("Long output should be:\n%s\nbut is:\n%s", expected, b.String())
+	}
+
+	if err := Write(&b, testNames, testMatrix, FORMAT_PHYLIP, 0.25); err == nil {
+		t.Error("A maximum distance with phylip format should give an error")
+	}
+}
+
+func TestWriteMega(t *testing.T) {
+	var b strings.Builder
+	if err := Write(&b, []string{"s 1", "s2", "s3"}, testMatrix, FORMAT_MEGA, -1); err != nil {
+		t.Fatal(err)
+	}
+	expected := "#mega\n!Title: Distance matrix;\n!Format DataType=Distance DataFormat=LowerLeft NTaxa=3;\n\n" +
+		"[1] #s_1\n[2] #s2\n[3] #s3\n\n" +
+		"[1]\n" +
+		"[2] 0.100000000000\n" +
+		"[3] 0.500000000000 0.250000000000\n"
+	if b.String() != expected {
+		t.Errorf("Mega output should be:\n%s\nbut is:\n%s", expected, b.String())
+	}
+}
+
+func TestWriteNpy(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, testNames, testMatrix, FORMAT_NPY, -1); err != nil {
+		t.Fatal(err)
+	}
+	out := b.Bytes()
+	if string(out[:8]) != "\x93NUMPY\x01\x00" {
+		t.Fatalf("Wrong npy magic string: %q", out[:8])
+	}
+	hlen := int(binary.LittleEndian.Uint16(out[8:10]))
+	if (10+hlen)%64 != 0 {
+		t.Errorf("npy header should be aligned on 64 bytes: %d", 10+hlen)
+	}
+	header := string(out[10 : 10+hlen])
+	if !strings.HasPrefix(header, "{'descr': '<f8', 'fortran_order': False, 'shape': (3, 3), }") || !strings.HasSuffix(header, "\n") {
+		t.Errorf("Wrong npy header: %q", header)
+	}
+	data := out[10+hlen:]
+	if len(data) != 9*8 {
+		t.Fatalf("npy data should have %d bytes, but has %d", 9*8, len(data))
+	}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			v := math.Float64frombits(binary.LittleEndian.Uint64(data[8*(i*3+j):]))
+			if v != testMatrix[i][j] {
+				t.Errorf("npy value [%d][%d] should be %f but is %f", i, j, testMatrix[i][j], v)
+			}
+		}
+	}
+}
@@ -0,0 +1,410 @@
+package metadata
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expression is a boolean expression evaluated on the metadata of a sequence, for example:
+//
+//	country=='FR' && date>='2024-01-01'
+//	(lineage=~'^B\.1\.1\.7' || lineage=='Q.1') && !(country=='UK')
+//
+// Operands are:
+//   - column names (letters, digits, '_' and '.', or any string between backquotes,
+//     ex: `collection date`). The special name "name" refers to the sequence name if
+//     there is no such column. Derived date columns (year, month, week, day) are allowed;
+//   - string literals, between single or double quotes;
+//   - numbers.
+//
+// Comparison operators are ==, !=, <, <=, >, >= and =~ (regular expression match).
+// If both operands are numbers, they are compared numerically, otherwise, they are
+// compared as strings (which works for ISO 8601 dates).
+// Comparisons may be combined with &&, ||, ! and parentheses.
+type Expression struct {
+	expr string
+	root exprNode
+}
+
+type exprNode interface {
+	eval(m *Metadata, name string) (bool, error)
+}
+
+type exprOperand struct {
+	column  string // If not literal
+	value   string // If literal
+	literal bool
+}
+
+type exprCompare struct {
+	op          string
+	left, right exprOperand
+	regex       *regexp.Regexp // If op is =~ and right is a literal
+}
+
+type exprAnd struct{ left, right exprNode }
+type exprOr struct{ left, right exprNode }
+type exprNot struct{ node exprNode }
+
+type exprToken struct {
+	kind  int // One of exprToken*
+	value string
+	pos   int
+}
+
+const (
+	exprTokenIdent = iota
+	exprTokenString
+	exprTokenNumber
+	exprTokenOp
+	exprTokenAnd
+	exprTokenOr
+	exprTokenNot
+	exprTokenOpen
+	exprTokenClose
+	exprTokenEnd
+)
+
+// CompileExpression parses the given expression
+func CompileExpression(expr string) (e *Expression, err error) {
+	var tokens []exprToken
+	var p *exprParser
+
+	if tokens, err = tokenizeExpression(expr); err != nil {
+		return
+	}
+	p = &exprParser{tokens: tokens}
+	e = &Expression{expr: expr}
+	if e.root, err = p.parseOr(); err != nil {
+		return
+	}
+	if p.peek().kind != exprTokenEnd {
+		err = fmt.Errorf("unexpected token '%s' at position %d in expression %s", p.peek().value, p.peek().pos, expr)
+	}
+	return
+}
+
+// String returns the original expression
+func (e *Expression) String() string {
+	return e.expr
+}
+
+// Eval evaluates the expression on the metadata of the given sequence.
+// Returns an error if the sequence is not present in the metadata, if a
+// column does not exist, or if a regular expression is malformed.
+func (e *Expression) Eval(m *Metadata, name string) (ok bool, err error) {
+	if !m.Has(name) {
+		err = fmt.Errorf("sequence %s is not present in the metadata", name)
+		return
+	}
+	return e.root.eval(m, name)
+}
+
+// Filter returns the names satisfying the expression, in the given order.
+// Names that are not present in the metadata are returned in missing.
+func (e *Expression) Filter(m *Metadata, names []string) (kept []string, missing []string, err error) {
+	var ok bool
+
+	kept = make([]string, 0, len(names))
+	missing = make([]string, 0)
+	for _, n := range names {
+		if !m.Has(n) {
+			missing = append(missing, n)
+			continue
+		}
+		if ok, err = e.root.eval(m, n); err != nil {
+			return
+		}
+		if ok {
+			kept = append(kept, n)
+		}
+	}
+	return
+}
+
+func (o exprOperand) resolve(m *Metadata, name string) (v string, err error) {
+	if o.literal {
+		return o.value, nil
+	}
+	return m.Lookup(name, o.column)
+}
+
+func (c *exprCompare) eval(m *Metadata, name string) (ok bool, err error) {
+	var l, r string
+	var lf, rf float64
+	var errl, errr error
+
+	if l, err = c.left.resolve(m, name); err != nil {
+		return
+	}
+	if r, err = c.right.resolve(m, name); err != nil {
+		return
+	}
+
+	if c.op == "=~" {
+		re := c.regex
+		if re == nil {
+			if re, err = regexp.Compile(r); err != nil {
+				return
+			}
+		}
+		ok = re.MatchString(l)
+		return
+	}
+
+	lf, errl = strconv.ParseFloat(l, 64)
+	rf, errr = strconv.ParseFloat(r, 64)
+	if errl == nil && errr == nil {
+		switch c.op {
+		case "==":
+			ok = lf == rf
+		case "!=":
+			ok = lf != rf
+		case "<":
+			ok = lf < rf
+		case "<=":
+			ok = lf <= rf
+		case ">":
+			ok = lf > rf
+		case ">=":
+			ok = lf >= rf
+		}
+		return
+	}
+	cmp := strings.Compare(l, r)
+	switch c.op {
+	case "==":
+		ok = cmp == 0
+	case "!=":
+		ok = cmp != 0
+	case "<":
+		ok = cmp < 0
+	case "<=":
+		ok = cmp <= 0
+	case ">":
+		ok = cmp > 0
+	case ">=":
+		ok = cmp >= 0
+	}
+	return
+}
+
+func (a *exprAnd) eval(m *Metadata, name string) (ok bool, err error) {
+	if ok, err = a.left.eval(m, name); err != nil || !ok {
+		return
+	}
+	return a.right.eval(m, name)
+}
+
+func (o *exprOr) eval(m *Metadata, name string) (ok bool, err error) {
+	if ok, err = o.left.eval(m, name); err != nil || ok {
+		return
+	}
+	return o.right.eval(m, name)
+}
+
+func (n *exprNot) eval(m *Metadata, name string) (ok bool, err error) {
+	if ok, err = n.node.eval(m, name); err != nil {
+		return
+	}
+	ok = !ok
+	return
+}
+
+type exprParser struct {
+	tokens []exprToken
+	cur    int
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.cur]
+}
+
+func (p *exprParser) next() (t exprToken) {
+	t = p.tokens[p.cur]
+	if t.kind != exprTokenEnd {
+		p.cur++
+	}
+	return
+}
+
+func (p *exprParser) parseOr() (n exprNode, err error) {
+	var right exprNode
+	if n, err = p.parseAnd(); err != nil {
+		return
+	}
+	for p.peek().kind == exprTokenOr {
+		p.next()
+		if right, err = p.parseAnd(); err != nil {
+			return
+		}
+		n = &exprOr{n, right}
+	}
+	return
+}
+
+func (p *exprParser) parseAnd() (n exprNode, err error) {
+	var right exprNode
+	if n, err = p.parseNot(); err != nil {
+		return
+	}
+	for p.peek().kind == exprTokenAnd {
+		p.next()
+		if right, err = p.parseNot(); err != nil {
+			return
+		}
+		n = &exprAnd{n, right}
+	}
+	return
+}
+
+func (p *exprParser) parseNot() (n exprNode, err error) {
+	if p.peek().kind == exprTokenNot {
+		p.next()
+		if n, err = p.parseNot(); err != nil {
+			return
+		}
+		n = &exprNot{n}
+		return
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (n exprNode, err error) {
+	var left, right exprOperand
+	var op exprToken
+
+	if p.peek().kind == exprTokenOpen {
+		p.next()
+		if n, err = p.parseOr(); err != nil {
+			return
+		}
+		if t := p.next(); t.kind != exprTokenClose {
+			err = fmt.Errorf("missing ')' at position %d", t.pos)
+		}
+		return
+	}
+
+	if left, err = p.parseOperand(); err != nil {
+		return
+	}
+	if op = p.next(); op.kind != exprTokenOp {
+		err = fmt.Errorf("expected comparison operator at position %d, got '%s'", op.pos, op.value)
+		return
+	}
+	if right, err = p.parseOperand(); err != nil {
+		return
+	}
+	c := &exprCompare{op: op.value, left: left, right: right}
+	if op.value == "=~" && right.literal {
+		if c.regex, err = regexp.Compile(right.value); err != nil {
+			return
+		}
+	}
+	n = c
+	return
+}
+
+func (p *exprParser) parseOperand() (o exprOperand, err error) {
+	t := p.next()
+	switch t.kind {
+	case exprTokenIdent:
+		o = exprOperand{column: t.value}
+	case exprTokenString, exprTokenNumber:
+		o = exprOperand{value: t.value, literal: true}
+	default:
+		err = fmt.Errorf("expected column name or value at position %d, got '%s'", t.pos, t.value)
+	}
+	return
+}
+
+func tokenizeExpression(expr string) (tokens []exprToken, err error) {
+	var i int = 0
+	var r []rune = []rune(expr)
+
+	tokens = make([]exprToken, 0)
+	for i < len(r) {
+		c := r[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(':
+			tokens = append(tokens, exprToken{exprTokenOpen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, exprToken{exprTokenClose, ")", i})
+			i++
+		case c == '&' || c == '|':
+			if i+1 >= len(r) || r[i+1] != c {
+				err = fmt.Errorf("unexpected character '%c' at position %d", c, i)
+				return
+			}
+			if c == '&' {
+				tokens = append(tokens, exprToken{exprTokenAnd, "&&", i})
+			} else {
+				tokens = append(tokens, exprToken{exprTokenOr, "||", i})
+			}
+			i += 2
+		case c == '=' || c == '!' || c == '<' || c == '>':
+			if i+1 < len(r) && (r[i+1] == '=' || (c == '=' && r[i+1] == '~')) {
+				tokens = append(tokens, exprToken{exprTokenOp, string(r[i : i+2]), i})
+				i += 2
+			} else if c == '!' {
+				tokens = append(tokens, exprToken{exprTokenNot, "!", i})
+				i++
+			} else if c == '<' || c == '>' {
+				tokens = append(tokens, exprToken{exprTokenOp, string(c), i})
+				i++
+			} else {
+				err = fmt.Errorf("unexpected character '%c' at position %d (use ==)", c, i)
+				return
+			}
+		case c == '\'' || c == '"' || c == '`':
+			j := i + 1
+			var b strings.Builder
+			for j < len(r) && r[j] != c {
+				if r[j] == '\\' && j+1 < len(r) && r[j+1] == c {
+					j++
+				}
+				b.WriteRune(r[j])
+				j++
+			}
+			if j >= len(r) {
+				err = fmt.Errorf("unterminated string starting at position %d", i)
+				return
+			}
+			kind := exprTokenString
+			if c == '`' {
+				kind = exprTokenIdent
+			}
+			tokens = append(tokens, exprToken{kind, b.String(), i})
+			i = j + 1
+		case unicode.IsDigit(c) || c == '-' || c == '+':
+			j := i + 1
+			for j < len(r) && (unicode.IsDigit(r[j]) || r[j] == '.' || r[j] == 'e' || r[j] == 'E') {
+				j++
+			}
+			if _, err = strconv.ParseFloat(string(r[i:j]), 64); err != nil {
+				err = fmt.Errorf("malformed number '%s' at position %d", string(r[i:j]), i)
+				return
+			}
+			tokens = append(tokens, exprToken{exprTokenNumber, string(r[i:j]), i})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i + 1
+			for j < len(r) && (unicode.IsLetter(r[j]) || unicode.IsDigit(r[j]) || r[j] == '_' || r[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, exprToken{exprTokenIdent, string(r[i:j]), i})
+			i = j
+		default:
+			err = fmt.Errorf("unexpected character '%c' at position %d", c, i)
+			return
+		}
+	}
+	tokens = append(tokens, exprToken{exprTokenEnd, "end of expression", len(r)})
+	return
+}
@@ -0,0 +1,63 @@
+package metadata
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpression(t *testing.T) {
+	m, err := Parse(bufio.NewReader(strings.NewReader("name\tcountry\tdate\tlineage\tct\n"+
+		"s1\tFR\t2021-03-01\tB.1\t25\n"+
+		"s2\tFR\t2021-03-15\tB.1.1.7\t9\n"+
+		"s3\tUK\t2021-03-02\tB.1.1.7\t30.5\n"+
+		"s4\tFR\t2021-04-20\tB.1\t100\n")), "\t", "")
+	if err != nil {
+		t.Error(err)
+	}
+
+	tests := []struct {
+		expr     string
+		expected []string
+	}{
+		{"country=='FR'", []string{"s1", "s2", "s4"}},
+		{"country!=\"FR\"", []string{"s3"}},
+		{"date>='2021-03-02' && country=='FR'", []string{"s2", "s4"}},
+		{"ct>=25", []string{"s1", "s3", "s4"}},
+		{"ct<10 || lineage=~'^B\\.1$'", []string{"s1", "s2", "s4"}},
+		{"!(country=='FR') || month=='2021-04'", []string{"s3", "s4"}},
+		{"name=='s2'", []string{"s2"}},
+		{"`lineage`=='B.1.1.7' && !(ct > 10)", []string{"s2"}},
+	}
+
+	for _, test := range tests {
+		e, err := CompileExpression(test.expr)
+		if err != nil {
+			t.Errorf("Expression %s should compile: %v", test.expr, err)
+			continue
+		}
+		kept, missing, err := e.Filter(m, []string{"s1", "s2", "s3", "s4", "s5"})
+		if err != nil {
+			t.Errorf("Expression %s should be evaluated: %v", test.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(kept, test.expected) {
+			t.Errorf("Expression %s should select %v, selected %v", test.expr, test.expected, kept)
+		}
+		if !reflect.DeepEqual(missing, []string{"s5"}) {
+			t.Errorf("Expression %s: missing sequences should be [s5], are %v", test.expr, missing)
+		}
+	}
+
+	for _, expr := range []string{"country='FR'", "country==", "(country=='FR'", "country=='FR", "country & 'FR'", "country=~'('"} {
+		if _, err := CompileExpression(expr); err == nil {
+			t.Errorf("Expression %s should not compile", expr)
+		}
+	}
+
+	e, _ := CompileExpression("unknown=='FR'")
+	if _, err := e.Eval(m, "s1"); err == nil {
+		t.Errorf("Unknown column should return an error")
+	}
+}
@@ -0,0 +1,336 @@
+package metadata
+
+import (
+	"bufio"
+	"fmt"
+	goio "io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/evolbioinfo/goalign/gutils"
+	"github.com/evolbioinfo/goalign/io/utils"
+)
+
+// Metadata is a table of annotations (date, country, lineage, etc.) keyed by sequence name.
+//
+// Columns year, month, week and day may be used even if they do not exist in the
+// table: they are then derived from the date column (see SetDateColumn).
+type Metadata struct {
+	columns    []string            // Column names, in file order
+	colidx     map[string]int      // Column name => index
+	rows       map[string][]string // Sequence name => row values
+	names      []string            // Sequence names, in file order
+	keycolumn  int                 // Index of the column giving sequence names
+	datecolumn string              // Column used to derive year, month, week and day
+}
+
+// NewMetadata initializes an empty metadata table with the given header.
+// keycolumn is the name of the column giving the sequence names.
+func NewMetadata(columns []string, keycolumn string) (m *Metadata, err error) {
+	m = &Metadata{
+		columns:    columns,
+		colidx:     make(map[string]int),
+		rows:       make(map[string][]string),
+		names:      make([]string, 0, 100),
+		keycolumn:  -1,
+		datecolumn: "date",
+	}
+	for i, c := range columns {
+		if _, ok := m.colidx[c]; ok {
+			err = fmt.Errorf("column %s is present several times in the metadata header", c)
+			return
+		}
+		m.colidx[c] = i
+	}
+	if m.keycolumn, err = m.columnIndex(keycolumn); err != nil {
+		return
+	}
+	return
+}
+
+// FromFile parses a metadata file.
+//
+// The file must be tabulated (or comma separated if sep is ",", or if sep is ""
+// and the file has a .csv extension), with a header line. keycolumn is the name of
+// the column containing sequence names. If keycolumn is "", then the first column is
+// used.
+func FromFile(file string, sep string, keycolumn string) (m *Metadata, err error) {
+	var f goio.Closer
+	var r *bufio.Reader
+
+	if f, r, err = utils.GetReader(file); err != nil {
+		return
+	}
+	defer f.Close()
+
+	if sep == "" {
+		sep = "\t"
+		name := strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(file, ".gz"), ".xz"), ".bz2")
+		if strings.HasSuffix(name, ".csv") {
+			sep = ","
+		}
+	}
+	return Parse(r, sep, keycolumn)
+}
+
+// Parse parses a metadata table from the given reader (see FromFile)
+func Parse(r *bufio.Reader, sep string, keycolumn string) (m *Metadata, err error) {
+	var l string
+	var nl int = 1
+
+	if l, err = utils.Readln(r); err != nil {
+		err = fmt.Errorf("metadata file is empty")
+		return
+	}
+	header := splitLine(l, sep)
+	if keycolumn == "" {
+		keycolumn = header[0]
+	}
+	if m, err = NewMetadata(header, keycolumn); err != nil {
+		return
+	}
+
+	l, err = utils.Readln(r)
+	for err == nil {
+		nl++
+		if strings.TrimSpace(l) != "" {
+			if err = m.AddRow(splitLine(l, sep)); err != nil {
+				err = fmt.Errorf("metadata line %d: %v", nl, err)
+				return
+			}
+		}
+		l, err = utils.Readln(r)
+	}
+	if err == goio.EOF {
+		err = nil
+	}
+	return
+}
+
+func splitLine(l string, sep string) (cols []string) {
+	cols = strings.Split(strings.TrimRight(l, "\r"), sep)
+	for i, c := range cols {
+		cols[i] = strings.Trim(strings.TrimSpace(c), "\"")
+	}
+	return
+}
+
+// AddRow adds a row to the table. It must have as many fields as the header
+// and its sequence name must not already be present.
+func (m *Metadata) AddRow(row []string) (err error) {
+	if len(row) != len(m.columns) {
+		err = fmt.Errorf("row has %d fields while header has %d", len(row), len(m.columns))
+		return
+	}
+	name := row[m.keycolumn]
+	if _, ok := m.rows[name]; ok {
+		err = fmt.Errorf("sequence %s is present several times", name)
+		return
+	}
+	m.rows[name] = row
+	m.names = append(m.names, name)
+	return
+}
+
+// SetDateColumn sets the column from which year, month, week and day are derived (default "date")
+func (m *Metadata) SetDateColumn(column string) {
+	m.datecolumn = column
+}
+
+// Columns returns the column names of the table
+func (m *Metadata) Columns() []string {
+	return m.columns
+}
+
+// KeyColumn returns the name of the column giving sequence names
+func (m *Metadata) KeyColumn() string {
+	return m.columns[m.keycolumn]
+}
+
+// Names returns the sequence names of the table, in file order
+func (m *Metadata) Names() []string {
+	return m.names
+}
+
+// NbRows returns the number of rows of the table
+func (m *Metadata) NbRows() int {
+	return len(m.names)
+}
+
+// Has returns true if the given sequence is present in the table
+func (m *Metadata) Has(name string) (ok bool) {
+	_, ok = m.rows[name]
+	return
+}
+
+// HasColumn returns true if the given column exists in the table
+// or may be derived from the date column
+func (m *Metadata) HasColumn(column string) bool {
+	_, err := m.columnIndex(column)
+	return err == nil || isDerivedColumn(column)
+}
+
+// Row returns the raw values of the row corresponding to the given sequence
+func (m *Metadata) Row(name string) (row []string, ok bool) {
+	row, ok = m.rows[name]
+	return
+}
+
+// Value returns the value of the given column for the given sequence.
+//
+// If the column does not exist and is one of year, month, week or day, the
+// value is derived from the date column: 2021, 2021-03, 2021-W09 or 2021-03-01.
+//
+// Returns an error if the sequence or the column do not exist, or if the date
+// can not be parsed (or is not precise enough).
+func (m *Metadata) Value(name, column string) (v string, err error) {
+	var row []string
+	var ok bool
+	var i int
+
+	if row, ok = m.rows[name]; !ok {
+		err = fmt.Errorf("sequence %s is not present in the metadata", name)
+		return
+	}
+	if i, err = m.columnIndex(column); err == nil {
+		v = row[i]
+		return
+	}
+	if !isDerivedColumn(column) {
+		return
+	}
+	if i, err = m.columnIndex(m.datecolumn); err != nil {
+		err = fmt.Errorf("column %s can not be derived: %v", column, err)
+		return
+	}
+	v, err = deriveDate(row[i], column)
+	return
+}
+
+// Lookup returns the value of the given column for the given sequence, like Value,
+// except that if column is "name" and no such column exists, then it returns the
+// sequence name itself.
+func (m *Metadata) Lookup(name, column string) (v string, err error) {
+	if _, ok := m.colidx[column]; !ok && column == "name" {
+		if !m.Has(name) {
+			err = fmt.Errorf("sequence %s is not present in the metadata", name)
+			return
+		}
+		v = name
+		return
+	}
+	return m.Value(name, column)
+}
+
+// Write writes the rows of the given sequences (in the given order) in the given
+// writer, tab separated, with a header line.
+// Sequences that are not present in the metadata are ignored.
+func (m *Metadata) Write(w goio.StringWriter, names []string) (err error) {
+	if _, err = w.WriteString(strings.Join(m.columns, "\t") + "\n"); err != nil {
+		return
+	}
+	for _, n := range names {
+		if row, ok := m.rows[n]; ok {
+			if _, err = w.WriteString(strings.Join(row, "\t") + "\n"); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// Groups builds groups of sequences having the same values for the given columns.
+//
+// Only the sequences given in names are considered, in this order. Sequences absent
+// from the table, or whose date does not allow to derive a date column (see Value),
+// are returned in missing.
+// Group names are the values of the columns separated by "|", and are returned sorted
+// in keys.
+func (m *Metadata) Groups(names []string, columns []string) (groups map[string][]string, keys []string, missing []string, err error) {
+	var v string
+
+	groups = make(map[string][]string)
+	keys = make([]string, 0)
+	missing = make([]string, 0)
+
+	for _, c := range columns {
+		if !m.HasColumn(c) {
+			err = fmt.Errorf("column %s does not exist in the metadata", c)
+			return
+		}
+	}
+
+	for _, name := range names {
+		if !m.Has(name) {
+			missing = append(missing, name)
+			continue
+		}
+		values := make([]string, len(columns))
+		ok := true
+		for i, c := range columns {
+			if v, err = m.Value(name, c); err != nil {
+				ok = false
+				err = nil
+				break
+			}
+			values[i] = v
+		}
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		key := strings.Join(values, "|")
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], name)
+	}
+	sort.Strings(keys)
+	return
+}
+
+func (m *Metadata) columnIndex(column string) (i int, err error) {
+	var ok bool
+	if i, ok = m.colidx[column]; !ok {
+		err = fmt.Errorf("column %s does not exist in the metadata", column)
+	}
+	return
+}
+
+func isDerivedColumn(column string) bool {
+	return column == "year" || column == "month" || column == "week" || column == "day"
+}
+
+func deriveDate(date, column string) (v string, err error) {
+	var t time.Time
+	var precision int
+
+	if t, precision, err = gutils.ParseDate(date); err != nil {
+		return
+	}
+	switch column {
+	case "year":
+		v = gutils.FormatDate(t, gutils.DATE_PRECISION_YEAR)
+	case "month":
+		if precision < gutils.DATE_PRECISION_MONTH {
+			err = fmt.Errorf("date %s is not precise enough to derive the month", date)
+			return
+		}
+		v = gutils.FormatDate(t, gutils.DATE_PRECISION_MONTH)
+	case "week":
+		if precision < gutils.DATE_PRECISION_DAY {
+			err = fmt.Errorf("date %s is not precise enough to derive the week", date)
+			return
+		}
+		y, w := t.ISOWeek()
+		v = fmt.Sprintf("%d-W%02d", y, w)
+	case "day":
+		if precision < gutils.DATE_PRECISION_DAY {
+			err = fmt.Errorf("date %s is not precise enough to derive the day", date)
+			return
+		}
+		v = gutils.FormatDate(t, gutils.DATE_PRECISION_DAY)
+	}
+	return
+}
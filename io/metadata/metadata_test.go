@@ -0,0 +1,68 @@
+package metadata
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var metastring string = "name\tcountry\tdate\tlineage\n" +
+	"s1\tFR\t2021-03-01\tB.1\n" +
+	"s2\tFR\t2021-03-15\tB.1.1.7\n" +
+	"s3\tUK\t2021-03-02\tB.1.1.7\n" +
+	"s4\tFR\t2021-04-20\tB.1\n" +
+	"s5\tUK\t2021\tB.1\n"
+
+func TestParse(t *testing.T) {
+	m, err := Parse(bufio.NewReader(strings.NewReader(metastring)), "\t", "")
+	if err != nil {
+		t.Error(err)
+	}
+	if m.NbRows() != 5 {
+		t.Errorf("Metadata should have 5 rows, has %d", m.NbRows())
+	}
+	if m.KeyColumn() != "name" {
+		t.Errorf("Key column should be name, is %s", m.KeyColumn())
+	}
+	if v, err := m.Value("s3", "country"); err != nil || v != "UK" {
+		t.Errorf("Country of s3 should be UK, is %s (%v)", v, err)
+	}
+	if v, err := m.Value("s2", "month"); err != nil || v != "2021-03" {
+		t.Errorf("Month of s2 should be 2021-03, is %s (%v)", v, err)
+	}
+	if _, err := m.Value("s5", "month"); err == nil {
+		t.Errorf("Month of s5 should not be derived")
+	}
+	if _, err := m.Value("s1", "unknown"); err == nil {
+		t.Errorf("Unknown column should return an error")
+	}
+
+	if _, err = Parse(bufio.NewReader(strings.NewReader("name\tc\ns1\tA\ns1\tB\n")), "\t", ""); err == nil {
+		t.Errorf("Duplicated sequence names should return an error")
+	}
+	if _, err = Parse(bufio.NewReader(strings.NewReader("name,c\ns1,A\n")), ",", "c2"); err == nil {
+		t.Errorf("Unknown key column should return an error")
+	}
+}
+
+func TestGroups(t *testing.T) {
+	m, err := Parse(bufio.NewReader(strings.NewReader(metastring)), "\t", "name")
+	if err != nil {
+		t.Error(err)
+	}
+	groups, keys, missing, err := m.Groups([]string{"s1", "s2", "s3", "s4", "s5", "s6"}, []string{"country", "month"})
+	if err != nil {
+		t.Error(err)
+	}
+	expkeys := []string{"FR|2021-03", "FR|2021-04", "UK|2021-03"}
+	if !reflect.DeepEqual(keys, expkeys) {
+		t.Errorf("Group keys should be %v, are %v", expkeys, keys)
+	}
+	if !reflect.DeepEqual(groups["FR|2021-03"], []string{"s1", "s2"}) {
+		t.Errorf("Group FR|2021-03 should be [s1 s2], is %v", groups["FR|2021-03"])
+	}
+	if !reflect.DeepEqual(missing, []string{"s5", "s6"}) {
+		t.Errorf("Missing sequences should be [s5 s6], are %v", missing)
+	}
+}
@@ -0,0 +1,240 @@
+// Package report writes tabular results of reporting commands (stats,
+// compute, etc.) in structured formats: tab separated values or JSON.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	goio "io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	FORMAT_TEXT = 0 // Historical output of each command
+	FORMAT_TSV  = 1 // Tab separated values, with a header line
+	FORMAT_JSON = 2 // One JSON document per line (JSON lines)
+)
+
+// FormatFromString returns the format corresponding to the given
+// string: "text", "tsv" or "json"
+func FormatFromString(format string) (f int, err error) {
+	switch strings.ToLower(format) {
+	case "text":
+		f = FORMAT_TEXT
+	case "tsv":
+		f = FORMAT_TSV
+	case "json":
+		f = FORMAT_JSON
+	default:
+		err = fmt.Errorf("unknown output format: %s (should be text, tsv or json)", format)
+	}
+	return
+}
+
+// Table is a set of rows sharing the same columns. Values may
+// be strings, integers, floats or booleans. uint8 values are
+// considered as characters.
+type Table struct {
+	columns []string
+	rows    [][]interface{}
+}
+
+// NewTable initializes an empty table with the given columns
+func NewTable(columns ...string) *Table {
+	return &Table{
+		columns: columns,
+		rows:    make([][]interface{}, 0),
+	}
+}
+
+// AddRow adds a row to the table. It must have as many values as the
+// table has columns.
+func (t *Table) AddRow(values ...interface{}) (err error) {
+	if len(values) != len(t.columns) {
+		err = fmt.Errorf("row has %d values while table has %d columns", len(values), len(t.columns))
+		return
+	}
+	t.rows = append(t.rows, values)
+	return
+}
+
+// Columns returns the column names of the table
+func (t *Table) Columns() []string {
+	return t.columns
+}
+
+// NbRows returns the number of rows of the table
+func (t *Table) NbRows() int {
+	return len(t.rows)
+}
+
+// Row returns the values of the ith row
+func (t *Table) Row(i int) []interface{} {
+	return t.rows[i]
+}
+
+// WriteTabular writes the rows of the table as tab separated values,
+// without alignment column, and with a header line if header is true
+func (t *Table) WriteTabular(w goio.StringWriter, header bool) (err error) {
+	var b strings.Builder
+
+	if header {
+		b.WriteString(strings.Join(t.columns, "\t"))
+		b.WriteString("\n")
+	}
+	for _, row := range t.rows {
+		for j, v := range row {
+			if j > 0 {
+				b.WriteString("\t")
+			}
+			b.WriteString(tsvValue(v))
+		}
+		b.WriteString("\n")
+	}
+	_, err = w.WriteString(b.String())
+	return
+}
+
+// Writer writes tables in TSV or JSON format, one table per input alignment.
+//
+//   - TSV: a single table, whose first column ("alignment") gives the index
+//     of the input alignment (0-based). The header line is written once, and
+//     again only if columns change between alignments;
+//   - JSON: one document per line and per input alignment:
+//     {"command":"gaps","alignment":0,"columns":["sequence","gaps"],"rows":[{"sequence":"s1","gaps":2},...]}
+//     NaN and infinite values are written as null.
+type Writer struct {
+	w       goio.StringWriter
+	format  int
+	command string
+	header  []string // Last written TSV header
+}
+
+// NewWriter initializes a new table writer. command is the name of
+// the reporting command, written in JSON documents.
+func NewWriter(w goio.StringWriter, format int, command string) (rw *Writer, err error) {
+	if format != FORMAT_TSV && format != FORMAT_JSON {
+		err = fmt.Errorf("tables can only be written in tsv or json format")
+		return
+	}
+	rw = &Writer{w: w, format: format, command: command}
+	return
+}
+
+// Write writes the table corresponding to the given input alignment
+func (rw *Writer) Write(alignment int, t *Table) (err error) {
+	if rw.format == FORMAT_JSON {
+		return rw.writeJSON(alignment, t)
+	}
+	return rw.writeTSV(alignment, t)
+}
+
+func (rw *Writer) writeTSV(alignment int, t *Table) (err error) {
+	var b strings.Builder
+
+	if !sameColumns(rw.header, t.columns) {
+		b.WriteString("alignment")
+		for _, c := range t.columns {
+			b.WriteString("\t")
+			b.WriteString(c)
+		}
+		b.WriteString("\n")
+		rw.header = t.columns
+	}
+	for _, row := range t.rows {
+		b.WriteString(strconv.Itoa(alignment))
+		for _, v := range row {
+			b.WriteString("\t")
+			b.WriteString(tsvValue(v))
+		}
+		b.WriteString("\n")
+	}
+	_, err = rw.w.WriteString(b.String())
+	return
+}
+
+func (rw *Writer) writeJSON(alignment int, t *Table) (err error) {
+	var b strings.Builder
+	var buf []byte
+
+	if buf, err = json.Marshal(rw.command); err != nil {
+		return
+	}
+	b.WriteString(`{"command":`)
+	b.Write(buf)
+	b.WriteString(`,"alignment":`)
+	b.WriteString(strconv.Itoa(alignment))
+	if buf, err = json.Marshal(t.columns); err != nil {
+		return
+	}
+	b.WriteString(`,"columns":`)
+	b.Write(buf)
+	b.WriteString(`,"rows":[`)
+	for i, row := range t.rows {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("{")
+		for j, v := range row {
+			if j > 0 {
+				b.WriteString(",")
+			}
+			if buf, err = json.Marshal(t.columns[j]); err != nil {
+				return
+			}
+			b.Write(buf)
+			b.WriteString(":")
+			if buf, err = jsonValue(v); err != nil {
+				return
+			}
+			b.Write(buf)
+		}
+		b.WriteString("}")
+	}
+	b.WriteString("]}\n")
+	_, err = rw.w.WriteString(b.String())
+	return
+}
+
+func sameColumns(c1, c2 []string) bool {
+	if c1 == nil || len(c1) != len(c2) {
+		return false
+	}
+	for i := range c1 {
+		if c1[i] != c2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func tsvValue(v interface{}) string {
+	switch val := v.(type) {
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32)
+	case uint8:
+		// Characters
+		return string(rune(val))
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func jsonValue(v interface{}) ([]byte, error) {
+	switch val := v.(type) {
+	case float64:
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			return []byte("null"), nil
+		}
+	case uint8:
+		// Characters
+		return json.Marshal(string(rune(val)))
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,68 @@
+package report
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestWriteTSV(t *testing.T) {
+	var b strings.Builder
+	w, err := NewWriter(&b, FORMAT_TSV, "gaps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t1 := NewTable("sequence", "gaps", "freq")
+	t1.AddRow("s1", 2, 0.5)
+	t1.AddRow("s2", 0, 0.0)
+	t2 := NewTable("sequence", "gaps", "freq")
+	t2.AddRow("s1", 1, 0.25)
+	t3 := NewTable("sequence", "char")
+	t3.AddRow("s1", uint8('A'))
+
+	for i, tb := range []*Table{t1, t2, t3} {
+		if err = w.Write(i, tb); err != nil {
+			t.Error(err)
+		}
+	}
+	expected := "alignment\tsequence\tgaps\tfreq\n" +
+		"0\ts1\t2\t0.5\n" +
+		"0\ts2\t0\t0\n" +
+		"1\ts1\t1\t0.25\n" +
+		"alignment\tsequence\tchar\n" +
+		"2\ts1\tA\n"
+	if b.String() != expected {
+		t.Errorf("TSV output should be:\n%s\nbut is:\n%s", expected, b.String())
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var b strings.Builder
+	w, err := NewWriter(&b, FORMAT_JSON, "entropy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t1 := NewTable("site", "entropy")
+	t1.AddRow(0, 1.5)
+	t1.AddRow(1, math.NaN())
+	if err = w.Write(0, t1); err != nil {
+		t.Error(err)
+	}
+	expected := `{"command":"entropy","alignment":0,"columns":["site","entropy"],"rows":[{"site":0,"entropy":1.5},{"site":1,"entropy":null}]}` + "\n"
+	if b.String() != expected {
+		t.Errorf("JSON output should be:\n%s\nbut is:\n%s", expected, b.String())
+	}
+}
+
+func TestTable(t *testing.T) {
+	t1 := NewTable("a", "b")
+	if err := t1.AddRow(1); err == nil {
+		t.Errorf("Adding a row with a wrong number of values should return an error")
+	}
+	if _, err := FormatFromString("xml"); err == nil {
+		t.Errorf("Unknown format should return an error")
+	}
+	if f, err := FormatFromString("JSON"); err != nil || f != FORMAT_JSON {
+		t.Errorf("Format JSON should be recognized")
+	}
+}
@@ -1651,3 +1651,1871 @@ func ABMats() (dmat *mat.Dense, pi []float64) {
 	dmat = mat.NewDense(naa, naa, m)
 	return
 }
+
+/*********************************************************/
+/* cpREV model
+ * Jun Adachi, Peter J. Waddell, William Martin and Masami Hasegawa
+ * "Plastid genome phylogeny and a model of amino acid substitution for proteins
+ * encoded by chloroplast DNA"
+ * J Mol Evol (2000) 50:348-358 */
+func CpREVMats() (dmat *mat.Dense, pi []float64) {
+	var i, j, naa int
+	naa = 20
+	m := make([]float64, naa*naa)
+	pi = make([]float64, naa)
+
+	m[1*20+0] = 105
+	m[2*20+0] = 227
+	m[2*20+1] = 357
+	m[3*20+0] = 175
+	m[3*20+1] = 43
+	m[3*20+2] = 4435
+	m[4*20+0] = 669
+	m[4*20+1] = 823
+	m[4*20+2] = 538
+	m[4*20+3] = 10
+	m[5*20+0] = 157
+	m[5*20+1] = 1745
+	m[5*20+2] = 768
+	m[5*20+3] = 400
+	m[5*20+4] = 10
+	m[6*20+0] = 499
+	m[6*20+1] = 152
+	m[6*20+2] = 1055
+	m[6*20+3] = 3691
+	m[6*20+4] = 10
+	m[6*20+5] = 3122
+	m[7*20+0] = 665
+	m[7*20+1] = 243
+	m[7*20+2] = 653
+	m[7*20+3] = 431
+	m[7*20+4] = 303
+	m[7*20+5] = 133
+	m[7*20+6] = 379
+	m[8*20+0] = 66
+	m[8*20+1] = 715
+	m[8*20+2] = 1405
+	m[8*20+3] = 331
+	m[8*20+4] = 441
+	m[8*20+5] = 1269
+	m[8*20+6] = 162
+	m[8*20+7] = 19
+	m[9*20+0] = 145
+	m[9*20+1] = 136
+	m[9*20+2] = 168
+	m[9*20+3] = 10
+	m[9*20+4] = 280
+	m[9*20+5] = 92
+	m[9*20+6] = 148
+	m[9*20+7] = 40
+	m[9*20+8] = 29
+	m[10*20+0] = 197
+	m[10*20+1] = 203
+	m[10*20+2] = 113
+	m[10*20+3] = 10
+	m[10*20+4] = 396
+	m[10*20+5] = 286
+	m[10*20+6] = 82
+	m[10*20+7] = 20
+	m[10*20+8] = 66
+	m[10*20+9] = 1745
+	m[11*20+0] = 236
+	m[11*20+1] = 4482
+	m[11*20+2] = 2430
+	m[11*20+3] = 412
+	m[11*20+4] = 48
+	m[11*20+5] = 3313
+	m[11*20+6] = 2629
+	m[11*20+7] = 263
+	m[11*20+8] = 305
+	m[11*20+9] = 345
+	m[11*20+10] = 218
+	m[12*20+0] = 185
+	m[12*20+1] = 125
+	m[12*20+2] = 61
+	m[12*20+3] = 47
+	m[12*20+4] = 159
+	m[12*20+5] = 202
+	m[12*20+6] = 113
+	m[12*20+7] = 21
+	m[12*20+8] = 10
+	m[12*20+9] = 1772
+	m[12*20+10] = 1351
+	m[12*20+11] = 193
+	m[13*20+0] = 68
+	m[13*20+1] = 53
+	m[13*20+2] = 97
+	m[13*20+3] = 22
+	m[13*20+4] = 726
+	m[13*20+5] = 10
+	m[13*20+6] = 145
+	m[13*20+7] = 25
+	m[13*20+8] = 127
+	m[13*20+9] = 454
+	m[13*20+10] = 1268
+	m[13*20+11] = 72
+	m[13*20+12] = 327
+	m[14*20+0] = 490
+	m[14*20+1] = 87
+	m[14*20+2] = 173
+	m[14*20+3] = 170
+	m[14*20+4] = 285
+	m[14*20+5] = 323
+	m[14*20+6] = 185
+	m[14*20+7] = 28
+	m[14*20+8] = 152
+	m[14*20+9] = 117
+	m[14*20+10] = 219
+	m[14*20+11] = 302
+	m[14*20+12] = 100
+	m[14*20+13] = 43
+	m[15*20+0] = 2440
+	m[15*20+1] = 385
+	m[15*20+2] = 2085
+	m[15*20+3] = 590
+	m[15*20+4] = 2331
+	m[15*20+5] = 396
+	m[15*20+6] = 568
+	m[15*20+7] = 691
+	m[15*20+8] = 303
+	m[15*20+9] = 216
+	m[15*20+10] = 516
+	m[15*20+11] = 868
+	m[15*20+12] = 93
+	m[15*20+13] = 487
+	m[15*20+14] = 1202
+	m[16*20+0] = 1340
+	m[16*20+1] = 314
+	m[16*20+2] = 1393
+	m[16*20+3] = 266
+	m[16*20+4] = 576
+	m[16*20+5] = 241
+	m[16*20+6] = 369
+	m[16*20+7] = 92
+	m[16*20+8] = 32
+	m[16*20+9] = 1040
+	m[16*20+10] = 156
+	m[16*20+11] = 918
+	m[16*20+12] = 645
+	m[16*20+13] = 148
+	m[16*20+14] = 260
+	m[16*20+15] = 2151
+	m[17*20+0] = 14
+	m[17*20+1] = 230
+	m[17*20+2] = 40
+	m[17*20+3] = 18
+	m[17*20+4] = 435
+	m[17*20+5] = 53
+	m[17*20+6] = 63
+	m[17*20+7] = 82
+	m[17*20+8] = 69
+	m[17*20+9] = 42
+	m[17*20+10] = 159
+	m[17*20+11] = 10
+	m[17*20+12] = 86
+	m[17*20+13] = 468
+	m[17*20+14] = 49
+	m[17*20+15] = 73
+	m[17*20+16] = 29
+	m[18*20+0] = 56
+	m[18*20+1] = 323
+	m[18*20+2] = 754
+	m[18*20+3] = 281
+	m[18*20+4] = 1466
+	m[18*20+5] = 391
+	m[18*20+6] = 142
+	m[18*20+7] = 10
+	m[18*20+8] = 1971
+	m[18*20+9] = 89
+	m[18*20+10] = 189
+	m[18*20+11] = 247
+	m[18*20+12] = 215
+	m[18*20+13] = 2370
+	m[18*20+14] = 97
+	m[18*20+15] = 522
+	m[18*20+16] = 71
+	m[18*20+17] = 346
+	m[19*20+0] = 968
+	m[19*20+1] = 92
+	m[19*20+2] = 83
+	m[19*20+3] = 75
+	m[19*20+4] = 592
+	m[19*20+5] = 54
+	m[19*20+6] = 200
+	m[19*20+7] = 91
+	m[19*20+8] = 25
+	m[19*20+9] = 4797
+	m[19*20+10] = 865
+	m[19*20+11] = 249
+	m[19*20+12] = 475
+	m[19*20+13] = 317
+	m[19*20+14] = 122
+	m[19*20+15] = 167
+	m[19*20+16] = 760
+	m[19*20+17] = 10
+	m[19*20+18] = 119
+
+	for i = 0; i < naa; i++ {
+		for j = 0; j < i; j++ {
+			m[j*naa+i] = m[i*naa+j]
+		}
+	}
+
+	pi[0] = 0.0755
+	pi[1] = 0.0621
+	pi[2] = 0.0410
+	pi[3] = 0.0371
+	pi[4] = 0.0091
+	pi[5] = 0.0382
+	pi[6] = 0.0495
+	pi[7] = 0.0838
+	pi[8] = 0.0246
+	pi[9] = 0.0806
+	pi[10] = 0.1011
+	pi[11] = 0.0504
+	pi[12] = 0.0220
+	pi[13] = 0.0506
+	pi[14] = 0.0431
+	pi[15] = 0.0622
+	pi[16] = 0.0543
+	pi[17] = 0.0181
+	pi[18] = 0.0307
+	pi[19] = 0.0660
+
+	dmat = mat.NewDense(naa, naa, m)
+	return
+}
+
+/*********************************************************/
+/* rtREV model
+ * Mark W. Dimmic, J. S. Rest, David P. Mindell and Richard A. Goldstein
+ * "rtREV: an amino acid substitution matrix for inference of retrovirus
+ * and reverse transcriptase phylogeny"
+ * J Mol Evol (2002) 55:65-73 */
+func RtREVMats() (dmat *mat.Dense, pi []float64) {
+	var i, j, naa int
+	naa = 20
+	m := make([]float64, naa*naa)
+	pi = make([]float64, naa)
+
+	m[1*20+0] = 34
+	m[2*20+0] = 51
+	m[2*20+1] = 35
+	m[3*20+0] = 10
+	m[3*20+1] = 30
+	m[3*20+2] = 384
+	m[4*20+0] = 439
+	m[4*20+1] = 92
+	m[4*20+2] = 128
+	m[4*20+3] = 1
+	m[5*20+0] = 32
+	m[5*20+1] = 221
+	m[5*20+2] = 236
+	m[5*20+3] = 78
+	m[5*20+4] = 70
+	m[6*20+0] = 81
+	m[6*20+1] = 10
+	m[6*20+2] = 79
+	m[6*20+3] = 542
+	m[6*20+4] = 1
+	m[6*20+5] = 372
+	m[7*20+0] = 135
+	m[7*20+1] = 41
+	m[7*20+2] = 94
+	m[7*20+3] = 61
+	m[7*20+4] = 48
+	m[7*20+5] = 18
+	m[7*20+6] = 70
+	m[8*20+0] = 30
+	m[8*20+1] = 90
+	m[8*20+2] = 320
+	m[8*20+3] = 91
+	m[8*20+4] = 124
+	m[8*20+5] = 387
+	m[8*20+6] = 34
+	m[8*20+7] = 68
+	m[9*20+0] = 1
+	m[9*20+1] = 24
+	m[9*20+2] = 35
+	m[9*20+3] = 1
+	m[9*20+4] = 104
+	m[9*20+5] = 33
+	m[9*20+6] = 1
+	m[9*20+7] = 1
+	m[9*20+8] = 34
+	m[10*20+0] = 45
+	m[10*20+1] = 18
+	m[10*20+2] = 15
+	m[10*20+3] = 5
+	m[10*20+4] = 110
+	m[10*20+5] = 54
+	m[10*20+6] = 21
+	m[10*20+7] = 3
+	m[10*20+8] = 51
+	m[10*20+9] = 385
+	m[11*20+0] = 38
+	m[11*20+1] = 593
+	m[11*20+2] = 123
+	m[11*20+3] = 20
+	m[11*20+4] = 16
+	m[11*20+5] = 309
+	m[11*20+6] = 141
+	m[11*20+7] = 30
+	m[11*20+8] = 76
+	m[11*20+9] = 34
+	m[11*20+10] = 23
+	m[12*20+0] = 235
+	m[12*20+1] = 57
+	m[12*20+2] = 1
+	m[12*20+3] = 1
+	m[12*20+4] = 156
+	m[12*20+5] = 158
+	m[12*20+6] = 1
+	m[12*20+7] = 37
+	m[12*20+8] = 116
+	m[12*20+9] = 375
+	m[12*20+10] = 581
+	m[12*20+11] = 134
+	m[13*20+0] = 1
+	m[13*20+1] = 7
+	m[13*20+2] = 49
+	m[13*20+3] = 1
+	m[13*20+4] = 70
+	m[13*20+5] = 1
+	m[13*20+6] = 1
+	m[13*20+7] = 7
+	m[13*20+8] = 141
+	m[13*20+9] = 64
+	m[13*20+10] = 179
+	m[13*20+11] = 14
+	m[13*20+12] = 247
+	m[14*20+0] = 97
+	m[14*20+1] = 24
+	m[14*20+2] = 33
+	m[14*20+3] = 55
+	m[14*20+4] = 1
+	m[14*20+5] = 68
+	m[14*20+6] = 52
+	m[14*20+7] = 17
+	m[14*20+8] = 44
+	m[14*20+9] = 10
+	m[14*20+10] = 22
+	m[14*20+11] = 43
+	m[14*20+12] = 1
+	m[14*20+13] = 11
+	m[15*20+0] = 460
+	m[15*20+1] = 102
+	m[15*20+2] = 294
+	m[15*20+3] = 136
+	m[15*20+4] = 75
+	m[15*20+5] = 225
+	m[15*20+6] = 95
+	m[15*20+7] = 152
+	m[15*20+8] = 183
+	m[15*20+9] = 4
+	m[15*20+10] = 24
+	m[15*20+11] = 77
+	m[15*20+12] = 1
+	m[15*20+13] = 20
+	m[15*20+14] = 134
+	m[16*20+0] = 258
+	m[16*20+1] = 64
+	m[16*20+2] = 148
+	m[16*20+3] = 55
+	m[16*20+4] = 117
+	m[16*20+5] = 146
+	m[16*20+6] = 82
+	m[16*20+7] = 7
+	m[16*20+8] = 49
+	m[16*20+9] = 72
+	m[16*20+10] = 25
+	m[16*20+11] = 110
+	m[16*20+12] = 131
+	m[16*20+13] = 69
+	m[16*20+14] = 62
+	m[16*20+15] = 671
+	m[17*20+0] = 5
+	m[17*20+1] = 13
+	m[17*20+2] = 16
+	m[17*20+3] = 1
+	m[17*20+4] = 55
+	m[17*20+5] = 10
+	m[17*20+6] = 17
+	m[17*20+7] = 23
+	m[17*20+8] = 48
+	m[17*20+9] = 39
+	m[17*20+10] = 47
+	m[17*20+11] = 6
+	m[17*20+12] = 111
+	m[17*20+13] = 182
+	m[17*20+14] = 9
+	m[17*20+15] = 14
+	m[17*20+16] = 1
+	m[18*20+0] = 55
+	m[18*20+1] = 47
+	m[18*20+2] = 28
+	m[18*20+3] = 1
+	m[18*20+4] = 131
+	m[18*20+5] = 45
+	m[18*20+6] = 1
+	m[18*20+7] = 21
+	m[18*20+8] = 307
+	m[18*20+9] = 26
+	m[18*20+10] = 64
+	m[18*20+11] = 1
+	m[18*20+12] = 74
+	m[18*20+13] = 1017
+	m[18*20+14] = 14
+	m[18*20+15] = 31
+	m[18*20+16] = 34
+	m[18*20+17] = 176
+	m[19*20+0] = 197
+	m[19*20+1] = 29
+	m[19*20+2] = 21
+	m[19*20+3] = 6
+	m[19*20+4] = 295
+	m[19*20+5] = 36
+	m[19*20+6] = 35
+	m[19*20+7] = 3
+	m[19*20+8] = 1
+	m[19*20+9] = 1048
+	m[19*20+10] = 112
+	m[19*20+11] = 19
+	m[19*20+12] = 236
+	m[19*20+13] = 92
+	m[19*20+14] = 25
+	m[19*20+15] = 39
+	m[19*20+16] = 196
+	m[19*20+17] = 26
+	m[19*20+18] = 59
+
+	for i = 0; i < naa; i++ {
+		for j = 0; j < i; j++ {
+			m[j*naa+i] = m[i*naa+j]
+		}
+	}
+
+	pi[0] = 0.0646
+	pi[1] = 0.0453
+	pi[2] = 0.0376
+	pi[3] = 0.0422
+	pi[4] = 0.0114
+	pi[5] = 0.0606
+	pi[6] = 0.0607
+	pi[7] = 0.0639
+	pi[8] = 0.0273
+	pi[9] = 0.0679
+	pi[10] = 0.1018
+	pi[11] = 0.0751
+	pi[12] = 0.0150
+	pi[13] = 0.0287
+	pi[14] = 0.0681
+	pi[15] = 0.0488
+	pi[16] = 0.0622
+	pi[17] = 0.0251
+	pi[18] = 0.0318
+	pi[19] = 0.0619
+
+	dmat = mat.NewDense(naa, naa, m)
+	return
+}
+
+/*********************************************************/
+/* mtMAM model
+ * Ziheng Yang, Rasmus Nielsen and Masami Hasegawa
+ * "Models of amino acid substitution and applications to mitochondrial
+ * protein evolution"
+ * Mol Biol Evol (1998) 15:1600-1611 */
+func MtMAMMats() (dmat *mat.Dense, pi []float64) {
+	var i, j, naa int
+	naa = 20
+	m := make([]float64, naa*naa)
+	pi = make([]float64, naa)
+
+	m[1*20+0] = 32
+	m[2*20+0] = 2
+	m[2*20+1] = 4
+	m[3*20+0] = 11
+	m[3*20+1] = 0
+	m[3*20+2] = 864
+	m[4*20+0] = 0
+	m[4*20+1] = 186
+	m[4*20+2] = 0
+	m[4*20+3] = 0
+	m[5*20+0] = 0
+	m[5*20+1] = 246
+	m[5*20+2] = 8
+	m[5*20+3] = 49
+	m[5*20+4] = 0
+	m[6*20+0] = 0
+	m[6*20+1] = 0
+	m[6*20+2] = 0
+	m[6*20+3] = 569
+	m[6*20+4] = 0
+	m[6*20+5] = 274
+	m[7*20+0] = 78
+	m[7*20+1] = 18
+	m[7*20+2] = 47
+	m[7*20+3] = 79
+	m[7*20+4] = 0
+	m[7*20+5] = 0
+	m[7*20+6] = 22
+	m[8*20+0] = 8
+	m[8*20+1] = 232
+	m[8*20+2] = 458
+	m[8*20+3] = 11
+	m[8*20+4] = 305
+	m[8*20+5] = 550
+	m[8*20+6] = 22
+	m[8*20+7] = 0
+	m[9*20+0] = 75
+	m[9*20+1] = 0
+	m[9*20+2] = 19
+	m[9*20+3] = 0
+	m[9*20+4] = 41
+	m[9*20+5] = 0
+	m[9*20+6] = 0
+	m[9*20+7] = 0
+	m[9*20+8] = 0
+	m[10*20+0] = 21
+	m[10*20+1] = 6
+	m[10*20+2] = 0
+	m[10*20+3] = 0
+	m[10*20+4] = 27
+	m[10*20+5] = 20
+	m[10*20+6] = 0
+	m[10*20+7] = 0
+	m[10*20+8] = 26
+	m[10*20+9] = 232
+	m[11*20+0] = 0
+	m[11*20+1] = 50
+	m[11*20+2] = 408
+	m[11*20+3] = 0
+	m[11*20+4] = 0
+	m[11*20+5] = 242
+	m[11*20+6] = 215
+	m[11*20+7] = 0
+	m[11*20+8] = 0
+	m[11*20+9] = 6
+	m[11*20+10] = 4
+	m[12*20+0] = 76
+	m[12*20+1] = 0
+	m[12*20+2] = 21
+	m[12*20+3] = 0
+	m[12*20+4] = 0
+	m[12*20+5] = 22
+	m[12*20+6] = 0
+	m[12*20+7] = 0
+	m[12*20+8] = 0
+	m[12*20+9] = 378
+	m[12*20+10] = 609
+	m[12*20+11] = 59
+	m[13*20+0] = 0
+	m[13*20+1] = 0
+	m[13*20+2] = 6
+	m[13*20+3] = 5
+	m[13*20+4] = 7
+	m[13*20+5] = 0
+	m[13*20+6] = 0
+	m[13*20+7] = 0
+	m[13*20+8] = 0
+	m[13*20+9] = 57
+	m[13*20+10] = 246
+	m[13*20+11] = 0
+	m[13*20+12] = 11
+	m[14*20+0] = 53
+	m[14*20+1] = 9
+	m[14*20+2] = 33
+	m[14*20+3] = 2
+	m[14*20+4] = 0
+	m[14*20+5] = 51
+	m[14*20+6] = 0
+	m[14*20+7] = 0
+	m[14*20+8] = 53
+	m[14*20+9] = 5
+	m[14*20+10] = 43
+	m[14*20+11] = 18
+	m[14*20+12] = 0
+	m[14*20+13] = 17
+	m[15*20+0] = 342
+	m[15*20+1] = 3
+	m[15*20+2] = 446
+	m[15*20+3] = 16
+	m[15*20+4] = 347
+	m[15*20+5] = 30
+	m[15*20+6] = 21
+	m[15*20+7] = 112
+	m[15*20+8] = 20
+	m[15*20+9] = 0
+	m[15*20+10] = 74
+	m[15*20+11] = 65
+	m[15*20+12] = 47
+	m[15*20+13] = 90
+	m[15*20+14] = 202
+	m[16*20+0] = 681
+	m[16*20+1] = 0
+	m[16*20+2] = 110
+	m[16*20+3] = 0
+	m[16*20+4] = 114
+	m[16*20+5] = 0
+	m[16*20+6] = 4
+	m[16*20+7] = 0
+	m[16*20+8] = 1
+	m[16*20+9] = 360
+	m[16*20+10] = 34
+	m[16*20+11] = 50
+	m[16*20+12] = 691
+	m[16*20+13] = 8
+	m[16*20+14] = 78
+	m[16*20+15] = 614
+	m[17*20+0] = 5
+	m[17*20+1] = 16
+	m[17*20+2] = 6
+	m[17*20+3] = 0
+	m[17*20+4] = 65
+	m[17*20+5] = 0
+	m[17*20+6] = 0
+	m[17*20+7] = 0
+	m[17*20+8] = 0
+	m[17*20+9] = 0
+	m[17*20+10] = 12
+	m[17*20+11] = 0
+	m[17*20+12] = 13
+	m[17*20+13] = 0
+	m[17*20+14] = 7
+	m[17*20+15] = 17
+	m[17*20+16] = 0
+	m[18*20+0] = 0
+	m[18*20+1] = 0
+	m[18*20+2] = 156
+	m[18*20+3] = 0
+	m[18*20+4] = 530
+	m[18*20+5] = 54
+	m[18*20+6] = 0
+	m[18*20+7] = 1
+	m[18*20+8] = 1525
+	m[18*20+9] = 16
+	m[18*20+10] = 25
+	m[18*20+11] = 67
+	m[18*20+12] = 0
+	m[18*20+13] = 682
+	m[18*20+14] = 8
+	m[18*20+15] = 107
+	m[18*20+16] = 0
+	m[18*20+17] = 14
+	m[19*20+0] = 398
+	m[19*20+1] = 0
+	m[19*20+2] = 0
+	m[19*20+3] = 10
+	m[19*20+4] = 0
+	m[19*20+5] = 33
+	m[19*20+6] = 20
+	m[19*20+7] = 5
+	m[19*20+8] = 0
+	m[19*20+9] = 2220
+	m[19*20+10] = 100
+	m[19*20+11] = 0
+	m[19*20+12] = 832
+	m[19*20+13] = 6
+	m[19*20+14] = 0
+	m[19*20+15] = 0
+	m[19*20+16] = 237
+	m[19*20+17] = 0
+	m[19*20+18] = 0
+
+	for i = 0; i < naa; i++ {
+		for j = 0; j < i; j++ {
+			m[j*naa+i] = m[i*naa+j]
+		}
+	}
+
+	pi[0] = 0.0692
+	pi[1] = 0.0184
+	pi[2] = 0.0400
+	pi[3] = 0.0186
+	pi[4] = 0.0065
+	pi[5] = 0.0238
+	pi[6] = 0.0236
+	pi[7] = 0.0557
+	pi[8] = 0.0277
+	pi[9] = 0.0905
+	pi[10] = 0.1675
+	pi[11] = 0.0221
+	pi[12] = 0.0561
+	pi[13] = 0.0611
+	pi[14] = 0.0536
+	pi[15] = 0.0725
+	pi[16] = 0.0870
+	pi[17] = 0.0293
+	pi[18] = 0.0340
+	pi[19] = 0.0428
+
+	dmat = mat.NewDense(naa, naa, m)
+	return
+}
+
+/*********************************************************/
+/* HIVw model
+ * David C. Nickle, Laura Heath, Mark A. Jensen, Peter B. Gilbert, James I. Mullins
+ * and Sergei L. Kosakovsky Pond
+ * "HIV-specific probabilistic models of protein evolution"
+ * PLoS ONE (2007) 2:e503 (HIV within-patient model) */
+func HIVWMats() (dmat *mat.Dense, pi []float64) {
+	var i, j, naa int
+	naa = 20
+	m := make([]float64, naa*naa)
+	pi = make([]float64, naa)
+
+	m[1*20+0] = 0.0744808
+	m[2*20+0] = 0.617509
+	m[2*20+1] = 0.16024
+	m[3*20+0] = 4.43521
+	m[3*20+1] = 0.0674539
+	m[3*20+2] = 29.4087
+	m[4*20+0] = 0.167653
+	m[4*20+1] = 2.86364
+	m[4*20+2] = 0.0604932
+	m[4*20+3] = 0.005
+	m[5*20+0] = 0.005
+	m[5*20+1] = 10.6746
+	m[5*20+2] = 0.342068
+	m[5*20+3] = 0.005
+	m[5*20+4] = 0.005
+	m[6*20+0] = 5.56325
+	m[6*20+1] = 0.0251632
+	m[6*20+2] = 0.201526
+	m[6*20+3] = 12.1233
+	m[6*20+4] = 0.005
+	m[6*20+5] = 3.20656
+	m[7*20+0] = 1.8685
+	m[7*20+1] = 13.4379
+	m[7*20+2] = 0.0604932
+	m[7*20+3] = 10.3969
+	m[7*20+4] = 0.0489798
+	m[7*20+5] = 0.0604932
+	m[7*20+6] = 14.7801
+	m[8*20+0] = 0.005
+	m[8*20+1] = 6.84405
+	m[8*20+2] = 8.59876
+	m[8*20+3] = 2.31779
+	m[8*20+4] = 0.005
+	m[8*20+5] = 18.5465
+	m[8*20+6] = 0.005
+	m[8*20+7] = 0.005
+	m[9*20+0] = 0.005
+	m[9*20+1] = 1.34069
+	m[9*20+2] = 0.987028
+	m[9*20+3] = 0.145124
+	m[9*20+4] = 0.005
+	m[9*20+5] = 0.0342252
+	m[9*20+6] = 0.0390512
+	m[9*20+7] = 0.005
+	m[9*20+8] = 0.005
+	m[10*20+0] = 0.16024
+	m[10*20+1] = 0.586757
+	m[10*20+2] = 0.005
+	m[10*20+3] = 0.005
+	m[10*20+4] = 0.005
+	m[10*20+5] = 2.89048
+	m[10*20+6] = 0.129839
+	m[10*20+7] = 0.0489798
+	m[10*20+8] = 1.76382
+	m[10*20+9] = 9.10246
+	m[11*20+0] = 0.592784
+	m[11*20+1] = 39.8897
+	m[11*20+2] = 10.6655
+	m[11*20+3] = 0.894313
+	m[11*20+4] = 0.005
+	m[11*20+5] = 13.0705
+	m[11*20+6] = 23.9626
+	m[11*20+7] = 0.279425
+	m[11*20+8] = 0.22406
+	m[11*20+9] = 0.817481
+	m[11*20+10] = 0.005
+	m[12*20+0] = 0.005
+	m[12*20+1] = 3.28652
+	m[12*20+2] = 0.201526
+	m[12*20+3] = 0.005
+	m[12*20+4] = 0.005
+	m[12*20+5] = 0.005
+	m[12*20+6] = 0.005
+	m[12*20+7] = 0.0489798
+	m[12*20+8] = 0.005
+	m[12*20+9] = 17.3064
+	m[12*20+10] = 11.3839
+	m[12*20+11] = 4.09564
+	m[13*20+0] = 0.597923
+	m[13*20+1] = 0.005
+	m[13*20+2] = 0.005
+	m[13*20+3] = 0.005
+	m[13*20+4] = 0.362959
+	m[13*20+5] = 0.005
+	m[13*20+6] = 0.005
+	m[13*20+7] = 0.005
+	m[13*20+8] = 0.005
+	m[13*20+9] = 1.48288
+	m[13*20+10] = 7.48781
+	m[13*20+11] = 0.005
+	m[13*20+12] = 0.005
+	m[14*20+0] = 1.00981
+	m[14*20+1] = 0.404723
+	m[14*20+2] = 0.344848
+	m[14*20+3] = 0.005
+	m[14*20+4] = 0.005
+	m[14*20+5] = 3.04502
+	m[14*20+6] = 0.005
+	m[14*20+7] = 0.005
+	m[14*20+8] = 13.9444
+	m[14*20+9] = 0.005
+	m[14*20+10] = 9.83095
+	m[14*20+11] = 0.111928
+	m[14*20+12] = 0.005
+	m[14*20+13] = 0.0342252
+	m[15*20+0] = 8.5942
+	m[15*20+1] = 8.35024
+	m[15*20+2] = 14.5699
+	m[15*20+3] = 0.427881
+	m[15*20+4] = 1.12195
+	m[15*20+5] = 0.16024
+	m[15*20+6] = 0.005
+	m[15*20+7] = 6.27966
+	m[15*20+8] = 0.725157
+	m[15*20+9] = 0.740091
+	m[15*20+10] = 6.14396
+	m[15*20+11] = 0.005
+	m[15*20+12] = 0.392575
+	m[15*20+13] = 4.27939
+	m[15*20+14] = 14.249
+	m[16*20+0] = 24.1422
+	m[16*20+1] = 0.928203
+	m[16*20+2] = 4.54206
+	m[16*20+3] = 0.630395
+	m[16*20+4] = 0.005
+	m[16*20+5] = 0.203091
+	m[16*20+6] = 0.458743
+	m[16*20+7] = 0.0489798
+	m[16*20+8] = 0.95956
+	m[16*20+9] = 9.36345
+	m[16*20+10] = 0.005
+	m[16*20+11] = 4.04802
+	m[16*20+12] = 7.41313
+	m[16*20+13] = 0.114512
+	m[16*20+14] = 4.33701
+	m[16*20+15] = 6.34079
+	m[17*20+0] = 0.005
+	m[17*20+1] = 5.96564
+	m[17*20+2] = 0.005
+	m[17*20+3] = 0.005
+	m[17*20+4] = 5.49894
+	m[17*20+5] = 0.0443298
+	m[17*20+6] = 0.005
+	m[17*20+7] = 2.8258
+	m[17*20+8] = 0.005
+	m[17*20+9] = 0.005
+	m[17*20+10] = 1.37031
+	m[17*20+11] = 0.005
+	m[17*20+12] = 0.005
+	m[17*20+13] = 0.005
+	m[17*20+14] = 0.005
+	m[17*20+15] = 1.10156
+	m[17*20+16] = 0.005
+	m[18*20+0] = 0.005
+	m[18*20+1] = 0.005
+	m[18*20+2] = 5.06475
+	m[18*20+3] = 2.28154
+	m[18*20+4] = 8.34835
+	m[18*20+5] = 0.005
+	m[18*20+6] = 0.005
+	m[18*20+7] = 0.005
+	m[18*20+8] = 47.4889
+	m[18*20+9] = 0.114512
+	m[18*20+10] = 0.005
+	m[18*20+11] = 0.005
+	m[18*20+12] = 0.579198
+	m[18*20+13] = 4.12728
+	m[18*20+14] = 0.005
+	m[18*20+15] = 0.933142
+	m[18*20+16] = 0.490608
+	m[18*20+17] = 0.005
+	m[19*20+0] = 24.8094
+	m[19*20+1] = 0.279425
+	m[19*20+2] = 0.0744808
+	m[19*20+3] = 2.91786
+	m[19*20+4] = 0.005
+	m[19*20+5] = 0.005
+	m[19*20+6] = 2.19952
+	m[19*20+7] = 2.79622
+	m[19*20+8] = 0.827479
+	m[19*20+9] = 24.8231
+	m[19*20+10] = 2.95344
+	m[19*20+11] = 0.128065
+	m[19*20+12] = 14.7683
+	m[19*20+13] = 2.28
+	m[19*20+14] = 0.005
+	m[19*20+15] = 0.862637
+	m[19*20+16] = 0.005
+	m[19*20+17] = 0.005
+	m[19*20+18] = 1.35482
+
+	for i = 0; i < naa; i++ {
+		for j = 0; j < i; j++ {
+			m[j*naa+i] = m[i*naa+j]
+		}
+	}
+
+	pi[0] = 0.0377494
+	pi[1] = 0.057321
+	pi[2] = 0.0891129
+	pi[3] = 0.0342034
+	pi[4] = 0.0240105
+	pi[5] = 0.0437824
+	pi[6] = 0.0618606
+	pi[7] = 0.0838496
+	pi[8] = 0.0156076
+	pi[9] = 0.0983641
+	pi[10] = 0.0577867
+	pi[11] = 0.0641682
+	pi[12] = 0.0158419
+	pi[13] = 0.0422741
+	pi[14] = 0.0458601
+	pi[15] = 0.0550846
+	pi[16] = 0.0813774
+	pi[17] = 0.019597
+	pi[18] = 0.0205847
+	pi[19] = 0.0515638
+
+	dmat = mat.NewDense(naa, naa, m)
+	return
+}
+
+/*********************************************************/
+/* FLU model
+ * Cuong Cao Dang, Quang Si Le, Olivier Gascuel and Vinh Sy Le
+ * "FLU, an amino acid substitution model for influenza proteins"
+ * BMC Evol Biol (2010) 10:99 */
+func FLUMats() (dmat *mat.Dense, pi []float64) {
+	var i, j, naa int
+	naa = 20
+	m := make([]float64, naa*naa)
+	pi = make([]float64, naa)
+
+	m[1*20+0] = 0.138658765
+	m[2*20+0] = 0.053366579
+	m[2*20+1] = 0.161000889
+	m[3*20+0] = 0.584852306
+	m[3*20+1] = 0.006771843
+	m[3*20+2] = 7.737392871
+	m[4*20+0] = 0.026447095
+	m[4*20+1] = 0.167207008
+	m[4*20+2] = 1.30e-05
+	m[4*20+3] = 1.41e-02
+	m[5*20+0] = 0.353753982
+	m[5*20+1] = 3.292716942
+	m[5*20+2] = 0.530642655
+	m[5*20+3] = 0.145469388
+	m[5*20+4] = 0.002547334
+	m[6*20+0] = 1.484234503
+	m[6*20+1] = 0.124897617
+	m[6*20+2] = 0.061652192
+	m[6*20+3] = 5.370511279
+	m[6*20+4] = 3.91e-02
+	m[6*20+5] = 1.195629122
+	m[7*20+0] = 1.132313122
+	m[7*20+1] = 1.190624465
+	m[7*20+2] = 0.322524648
+	m[7*20+3] = 1.934832784
+	m[7*20+4] = 0.116941459
+	m[7*20+5] = 0.108051341
+	m[7*20+6] = 1.593098825
+	m[8*20+0] = 0.214757862
+	m[8*20+1] = 1.879569938
+	m[8*20+2] = 1.387096032
+	m[8*20+3] = 0.887570549
+	m[8*20+4] = 2.18e-02
+	m[8*20+5] = 5.330313412
+	m[8*20+6] = 0.256491863
+	m[8*20+7] = 0.058774527
+	m[9*20+0] = 0.149926734
+	m[9*20+1] = 0.246117172
+	m[9*20+2] = 0.218571975
+	m[9*20+3] = 0.014085917
+	m[9*20+4] = 0.001112158
+	m[9*20+5] = 0.02883995
+	m[9*20+6] = 1.42e-02
+	m[9*20+7] = 1.63e-05
+	m[9*20+8] = 0.243190142
+	m[10*20+0] = 0.023116952
+	m[10*20+1] = 0.296045557
+	m[10*20+2] = 8.36e-04
+	m[10*20+3] = 0.005730682
+	m[10*20+4] = 0.005613627
+	m[10*20+5] = 1.020366955
+	m[10*20+6] = 0.016499536
+	m[10*20+7] = 0.006516229
+	m[10*20+8] = 0.321611694
+	m[10*20+9] = 3.512072282
+	m[11*20+0] = 0.47433361
+	m[11*20+1] = 15.30009662
+	m[11*20+2] = 2.646847965
+	m[11*20+3] = 0.29004298
+	m[11*20+4] = 1.30e-05
+	m[11*20+5] = 2.559587177
+	m[11*20+6] = 3.881488809
+	m[11*20+7] = 0.264148929
+	m[11*20+8] = 0.347302791
+	m[11*20+9] = 0.227707997
+	m[11*20+10] = 0.129223639
+	m[12*20+0] = 0.058745423
+	m[12*20+1] = 0.890162346
+	m[12*20+2] = 0.005251688
+	m[12*20+3] = 0.041762964
+	m[12*20+4] = 0.11145731
+	m[12*20+5] = 0.190259181
+	m[12*20+6] = 0.313974351
+	m[12*20+7] = 0.001500467
+	m[12*20+8] = 0.001273509
+	m[12*20+9] = 9.017954203
+	m[12*20+10] = 6.746936485
+	m[12*20+11] = 1.331291619
+	m[13*20+0] = 0.080490909
+	m[13*20+1] = 0.016000184
+	m[13*20+2] = 0.006372948
+	m[13*20+3] = 8.33e-04
+	m[13*20+4] = 0.237571086
+	m[13*20+5] = 0.093500016
+	m[13*20+6] = 1.27e-04
+	m[13*20+7] = 7.99e-04
+	m[13*20+8] = 0.119930071
+	m[13*20+9] = 2.043006434
+	m[13*20+10] = 5.017102703
+	m[13*20+11] = 0.007037016
+	m[13*20+12] = 0.193181001
+	m[14*20+0] = 0.487534886
+	m[14*20+1] = 0.167201045
+	m[14*20+2] = 0.045810911
+	m[14*20+3] = 0.02233893
+	m[14*20+4] = 0.001275566
+	m[14*20+5] = 0.286058622
+	m[14*20+6] = 0.022338917
+	m[14*20+7] = 3.57e-05
+	m[14*20+8] = 0.269036315
+	m[14*20+9] = 0.014006022
+	m[14*20+10] = 0.127582026
+	m[14*20+11] = 0.07432373
+	m[14*20+12] = 0.04051437
+	m[14*20+13] = 0.011010418
+	m[15*20+0] = 1.126346126
+	m[15*20+1] = 0.309315004
+	m[15*20+2] = 2.617059286
+	m[15*20+3] = 0.206698462
+	m[15*20+4] = 0.883089651
+	m[15*20+5] = 0.122429574
+	m[15*20+6] = 0.025591457
+	m[15*20+7] = 0.655734715
+	m[15*20+8] = 0.045025626
+	m[15*20+9] = 0.049617924
+	m[15*20+10] = 0.095094364
+	m[15*20+11] = 0.141014064
+	m[15*20+12] = 0.00242716
+	m[15*20+13] = 0.093009839
+	m[15*20+14] = 0.556099522
+	m[16*20+0] = 6.154454002
+	m[16*20+1] = 0.223287954
+	m[16*20+2] = 1.144541025
+	m[16*20+3] = 0.002453063
+	m[16*20+4] = 3.90e-05
+	m[16*20+5] = 0.019779826
+	m[16*20+6] = 0.004545051
+	m[16*20+7] = 0.025549958
+	m[16*20+8] = 0.023223046
+	m[16*20+9] = 1.394089211
+	m[16*20+10] = 0.153233327
+	m[16*20+11] = 1.043226262
+	m[16*20+12] = 0.871812734
+	m[16*20+13] = 0.001451608
+	m[16*20+14] = 0.002669305
+	m[16*20+15] = 4.075245637
+	m[17*20+0] = 0.102212286
+	m[17*20+1] = 0.012600622
+	m[17*20+2] = 0.008034962
+	m[17*20+3] = 0.003010052
+	m[17*20+4] = 0.099233718
+	m[17*20+5] = 0.014052028
+	m[17*20+6] = 0.019013853
+	m[17*20+7] = 0.128201061
+	m[17*20+8] = 0.006103849
+	m[17*20+9] = 0.001212474
+	m[17*20+10] = 0.217287474
+	m[17*20+11] = 0.000120096
+	m[17*20+12] = 0.092021452
+	m[17*20+13] = 0.145022216
+	m[17*20+14] = 0.000135997
+	m[17*20+15] = 0.022339219
+	m[17*20+16] = 0.021017058
+	m[18*20+0] = 0.011222398
+	m[18*20+1] = 0.001114474
+	m[18*20+2] = 0.174103922
+	m[18*20+3] = 0.134009044
+	m[18*20+4] = 0.051211062
+	m[18*20+5] = 0.001129963
+	m[18*20+6] = 0.05211022
+	m[18*20+7] = 0.002205997
+	m[18*20+8] = 1.612158016
+	m[18*20+9] = 0.002235126
+	m[18*20+10] = 0.028999997
+	m[18*20+11] = 0.003005436
+	m[18*20+12] = 0.016001232
+	m[18*20+13] = 2.980138712
+	m[18*20+14] = 0.007036985
+	m[18*20+15] = 0.219313468
+	m[18*20+16] = 0.035004033
+	m[18*20+17] = 0.07806437
+	m[19*20+0] = 2.154046779
+	m[19*20+1] = 0.016101609
+	m[19*20+2] = 0.001252463
+	m[19*20+3] = 0.093505019
+	m[19*20+4] = 0.002405031
+	m[19*20+5] = 0.015304617
+	m[19*20+6] = 0.151711549
+	m[19*20+7] = 0.142510062
+	m[19*20+8] = 0.001018434
+	m[19*20+9] = 11.68808114
+	m[19*20+10] = 0.711039452
+	m[19*20+11] = 0.005004154
+	m[19*20+12] = 3.163119536
+	m[19*20+13] = 0.207007183
+	m[19*20+14] = 0.001002034
+	m[19*20+15] = 0.012200958
+	m[19*20+16] = 3.160012051
+	m[19*20+17] = 0.001101119
+	m[19*20+18] = 0.004217891
+
+	for i = 0; i < naa; i++ {
+		for j = 0; j < i; j++ {
+			m[j*naa+i] = m[i*naa+j]
+		}
+	}
+
+	pi[0] = 0.0470718
+	pi[1] = 0.0509102
+	pi[2] = 0.0742143
+	pi[3] = 0.0478596
+	pi[4] = 0.0250216
+	pi[5] = 0.0333036
+	pi[6] = 0.0545874
+	pi[7] = 0.0763734
+	pi[8] = 0.0199642
+	pi[9] = 0.0671336
+	pi[10] = 0.0714981
+	pi[11] = 0.0567845
+	pi[12] = 0.0181507
+	pi[13] = 0.0304961
+	pi[14] = 0.0506561
+	pi[15] = 0.0884091
+	pi[16] = 0.0743386
+	pi[17] = 0.0185237
+	pi[18] = 0.0314741
+	pi[19] = 0.0632292
+
+	dmat = mat.NewDense(naa, naa, m)
+	return
+}
+
+/*********************************************************/
+/* Blosum62 model
+ * Steven Henikoff and Jorja G. Henikoff
+ * "Amino acid substitution matrices from protein blocks"
+ * PNAS (1992) 89:10915-10919 */
+func Blosum62Mats() (dmat *mat.Dense, pi []float64) {
+	var i, j, naa int
+	naa = 20
+	m := make([]float64, naa*naa)
+	pi = make([]float64, naa)
+
+	m[1*20+0] = 0.735790389698
+	m[2*20+0] = 0.485391055466
+	m[2*20+1] = 1.297446705134
+	m[3*20+0] = 0.543161820899
+	m[3*20+1] = 0.500964408555
+	m[3*20+2] = 3.180100048216
+	m[4*20+0] = 1.459995310470
+	m[4*20+1] = 0.227826574209
+	m[4*20+2] = 0.397358949897
+	m[4*20+3] = 0.240836614802
+	m[5*20+0] = 1.199705704602
+	m[5*20+1] = 3.020833610064
+	m[5*20+2] = 1.839216146992
+	m[5*20+3] = 1.190945703396
+	m[5*20+4] = 0.329801504630
+	m[6*20+0] = 1.170949042800
+	m[6*20+1] = 1.360574190420
+	m[6*20+2] = 1.240488508640
+	m[6*20+3] = 3.761625208368
+	m[6*20+4] = 0.140748891814
+	m[6*20+5] = 5.528919177928
+	m[7*20+0] = 1.955883574960
+	m[7*20+1] = 0.418763308518
+	m[7*20+2] = 1.355872344485
+	m[7*20+3] = 0.798473248968
+	m[7*20+4] = 0.418203192284
+	m[7*20+5] = 0.609846305383
+	m[7*20+6] = 0.423579992176
+	m[8*20+0] = 0.716241444998
+	m[8*20+1] = 1.456141166336
+	m[8*20+2] = 2.414501434208
+	m[8*20+3] = 0.778142664022
+	m[8*20+4] = 0.354058109831
+	m[8*20+5] = 2.435341131140
+	m[8*20+6] = 1.626891056982
+	m[8*20+7] = 0.539859124954
+	m[9*20+0] = 0.605899003687
+	m[9*20+1] = 0.232036445142
+	m[9*20+2] = 0.283017326278
+	m[9*20+3] = 0.418555732462
+	m[9*20+4] = 0.774894022794
+	m[9*20+5] = 0.236202451204
+	m[9*20+6] = 0.186848046932
+	m[9*20+7] = 0.189296292376
+	m[9*20+8] = 0.252718447885
+	m[10*20+0] = 0.800016530518
+	m[10*20+1] = 0.622711669692
+	m[10*20+2] = 0.211888159615
+	m[10*20+3] = 0.218131577594
+	m[10*20+4] = 0.831842640142
+	m[10*20+5] = 0.580737093181
+	m[10*20+6] = 0.372625175087
+	m[10*20+7] = 0.217721159236
+	m[10*20+8] = 0.348072209797
+	m[10*20+9] = 3.890963773304
+	m[11*20+0] = 1.295201266783
+	m[11*20+1] = 5.411115141489
+	m[11*20+2] = 1.593137043457
+	m[11*20+3] = 1.032447924952
+	m[11*20+4] = 0.285078800906
+	m[11*20+5] = 3.945277674515
+	m[11*20+6] = 2.802427151679
+	m[11*20+7] = 0.752042440303
+	m[11*20+8] = 1.022507035889
+	m[11*20+9] = 0.406193586642
+	m[11*20+10] = 0.445570274261
+	m[12*20+0] = 1.253758266664
+	m[12*20+1] = 0.983692987457
+	m[12*20+2] = 0.648441278787
+	m[12*20+3] = 0.222621897958
+	m[12*20+4] = 0.767688823480
+	m[12*20+5] = 2.494896077113
+	m[12*20+6] = 0.555415397470
+	m[12*20+7] = 0.459436173579
+	m[12*20+8] = 0.984311525359
+	m[12*20+9] = 3.364797763104
+	m[12*20+10] = 6.030559379572
+	m[12*20+11] = 1.073061184332
+	m[13*20+0] = 0.492964679748
+	m[13*20+1] = 0.371644693209
+	m[13*20+2] = 0.354861249223
+	m[13*20+3] = 0.281730694207
+	m[13*20+4] = 0.441337471187
+	m[13*20+5] = 0.144356959750
+	m[13*20+6] = 0.291409084165
+	m[13*20+7] = 0.368166464453
+	m[13*20+8] = 0.714533703928
+	m[13*20+9] = 1.517359325954
+	m[13*20+10] = 2.064839703237
+	m[13*20+11] = 0.266924750511
+	m[13*20+12] = 1.773855168830
+	m[14*20+0] = 1.173275900924
+	m[14*20+1] = 0.448133661718
+	m[14*20+2] = 0.494887043702
+	m[14*20+3] = 0.730628272998
+	m[14*20+4] = 0.356008498769
+	m[14*20+5] = 0.858570575674
+	m[14*20+6] = 0.926563934846
+	m[14*20+7] = 0.504086599527
+	m[14*20+8] = 0.527007339151
+	m[14*20+9] = 0.388355409206
+	m[14*20+10] = 0.374555687471
+	m[14*20+11] = 1.047383450722
+	m[14*20+12] = 0.454123625103
+	m[14*20+13] = 0.233597909629
+	m[15*20+0] = 4.325092687057
+	m[15*20+1] = 1.122783104210
+	m[15*20+2] = 2.904101656456
+	m[15*20+3] = 1.582754142065
+	m[15*20+4] = 1.197188415094
+	m[15*20+5] = 1.934870924596
+	m[15*20+6] = 1.769893238937
+	m[15*20+7] = 1.509326253224
+	m[15*20+8] = 1.117029762910
+	m[15*20+9] = 0.357544412460
+	m[15*20+10] = 0.352969184527
+	m[15*20+11] = 1.752165917819
+	m[15*20+12] = 0.918723415746
+	m[15*20+13] = 0.540027644824
+	m[15*20+14] = 1.169129577716
+	m[16*20+0] = 1.729178019485
+	m[16*20+1] = 0.914665954563
+	m[16*20+2] = 1.898173634533
+	m[16*20+3] = 0.934187509431
+	m[16*20+4] = 1.119831358516
+	m[16*20+5] = 1.277480294596
+	m[16*20+6] = 1.071097236007
+	m[16*20+7] = 0.641436011405
+	m[16*20+8] = 0.585407090225
+	m[16*20+9] = 1.179091197260
+	m[16*20+10] = 0.915259857694
+	m[16*20+11] = 1.303875200799
+	m[16*20+12] = 1.488548053722
+	m[16*20+13] = 0.488206118793
+	m[16*20+14] = 1.005451683149
+	m[16*20+15] = 5.151556292270
+	m[17*20+0] = 0.465839367725
+	m[17*20+1] = 0.426382310122
+	m[17*20+2] = 0.191482046247
+	m[17*20+3] = 0.145345046279
+	m[17*20+4] = 0.527664418872
+	m[17*20+5] = 0.758653808642
+	m[17*20+6] = 0.407635648938
+	m[17*20+7] = 0.508358924638
+	m[17*20+8] = 0.301248600780
+	m[17*20+9] = 0.341985787540
+	m[17*20+10] = 0.691474634600
+	m[17*20+11] = 0.332243040634
+	m[17*20+12] = 0.888101098152
+	m[17*20+13] = 2.074324893497
+	m[17*20+14] = 0.252214830027
+	m[17*20+15] = 0.387925622098
+	m[17*20+16] = 0.513128126891
+	m[18*20+0] = 0.718206697586
+	m[18*20+1] = 0.720517441216
+	m[18*20+2] = 0.538222519037
+	m[18*20+3] = 0.261422208965
+	m[18*20+4] = 0.470237733696
+	m[18*20+5] = 0.958989742850
+	m[18*20+6] = 0.596719300346
+	m[18*20+7] = 0.308055737035
+	m[18*20+8] = 4.218953969389
+	m[18*20+9] = 0.674617093228
+	m[18*20+10] = 0.811245856323
+	m[18*20+11] = 0.717993486900
+	m[18*20+12] = 0.951682162246
+	m[18*20+13] = 6.747260430801
+	m[18*20+14] = 0.369405319355
+	m[18*20+15] = 0.796751520761
+	m[18*20+16] = 0.801010243199
+	m[18*20+17] = 4.054419006558
+	m[19*20+0] = 2.187774522005
+	m[19*20+1] = 0.438388343772
+	m[19*20+2] = 0.312858797993
+	m[19*20+3] = 0.258129289418
+	m[19*20+4] = 1.116352478606
+	m[19*20+5] = 0.530785790125
+	m[19*20+6] = 0.524253846338
+	m[19*20+7] = 0.253340790190
+	m[19*20+8] = 0.201555971750
+	m[19*20+9] = 8.311839405458
+	m[19*20+10] = 2.231405688913
+	m[19*20+11] = 0.498138475304
+	m[19*20+12] = 2.575850755315
+	m[19*20+13] = 0.838119610178
+	m[19*20+14] = 0.496908410676
+	m[19*20+15] = 0.561925457442
+	m[19*20+16] = 2.253074051176
+	m[19*20+17] = 0.266508731426
+	m[19*20+18] = 1.000000000000
+
+	for i = 0; i < naa; i++ {
+		for j = 0; j < i; j++ {
+			m[j*naa+i] = m[i*naa+j]
+		}
+	}
+
+	pi[0] = 0.074
+	pi[1] = 0.052
+	pi[2] = 0.045
+	pi[3] = 0.054
+	pi[4] = 0.025
+	pi[5] = 0.034
+	pi[6] = 0.054
+	pi[7] = 0.074
+	pi[8] = 0.026
+	pi[9] = 0.068
+	pi[10] = 0.099
+	pi[11] = 0.058
+	pi[12] = 0.025
+	pi[13] = 0.047
+	pi[14] = 0.039
+	pi[15] = 0.057
+	pi[16] = 0.051
+	pi[17] = 0.013
+	pi[18] = 0.032
+	pi[19] = 0.073
+
+	dmat = mat.NewDense(naa, naa, m)
+	return
+}
+
+/*********************************************************/
+/* VT model
+ * Tobias Muller and Martin Vingron
+ * "Modeling amino acid replacement"
+ * J Comput Biol (2000) 7:761-776 */
+func VTMats() (dmat *mat.Dense, pi []float64) {
+	var i, j, naa int
+	naa = 20
+	m := make([]float64, naa*naa)
+	pi = make([]float64, naa)
+
+	m[1*20+0] = 0.233108
+	m[2*20+0] = 0.199097
+	m[2*20+1] = 0.210797
+	m[3*20+0] = 0.265145
+	m[3*20+1] = 0.105191
+	m[3*20+2] = 0.883422
+	m[4*20+0] = 0.227333
+	m[4*20+1] = 0.031726
+	m[4*20+2] = 0.027495
+	m[4*20+3] = 0.010313
+	m[5*20+0] = 0.310084
+	m[5*20+1] = 0.493763
+	m[5*20+2] = 0.2757
+	m[5*20+3] = 0.205842
+	m[5*20+4] = 0.004315
+	m[6*20+0] = 0.567957
+	m[6*20+1] = 0.25524
+	m[6*20+2] = 0.270417
+	m[6*20+3] = 1.599461
+	m[6*20+4] = 0.005321
+	m[6*20+5] = 0.960976
+	m[7*20+0] = 0.876213
+	m[7*20+1] = 0.156945
+	m[7*20+2] = 0.362028
+	m[7*20+3] = 0.311718
+	m[7*20+4] = 0.050876
+	m[7*20+5] = 0.12866
+	m[7*20+6] = 0.250447
+	m[8*20+0] = 0.078692
+	m[8*20+1] = 0.213164
+	m[8*20+2] = 0.290006
+	m[8*20+3] = 0.134252
+	m[8*20+4] = 0.016695
+	m[8*20+5] = 0.315521
+	m[8*20+6] = 0.104458
+	m[8*20+7] = 0.058131
+	m[9*20+0] = 0.222972
+	m[9*20+1] = 0.08151
+	m[9*20+2] = 0.087225
+	m[9*20+3] = 0.01172
+	m[9*20+4] = 0.046398
+	m[9*20+5] = 0.054602
+	m[9*20+6] = 0.046589
+	m[9*20+7] = 0.051089
+	m[9*20+8] = 0.020039
+	m[10*20+0] = 0.42463
+	m[10*20+1] = 0.192364
+	m[10*20+2] = 0.069245
+	m[10*20+3] = 0.060863
+	m[10*20+4] = 0.091709
+	m[10*20+5] = 0.24353
+	m[10*20+6] = 0.151924
+	m[10*20+7] = 0.087056
+	m[10*20+8] = 0.103552
+	m[10*20+9] = 2.08989
+	m[11*20+0] = 0.393245
+	m[11*20+1] = 1.755838
+	m[11*20+2] = 0.50306
+	m[11*20+3] = 0.261101
+	m[11*20+4] = 0.004067
+	m[11*20+5] = 0.738208
+	m[11*20+6] = 0.88863
+	m[11*20+7] = 0.193243
+	m[11*20+8] = 0.153323
+	m[11*20+9] = 0.093181
+	m[11*20+10] = 0.201204
+	m[12*20+0] = 0.21155
+	m[12*20+1] = 0.08793
+	m[12*20+2] = 0.05742
+	m[12*20+3] = 0.012182
+	m[12*20+4] = 0.02369
+	m[12*20+5] = 0.120801
+	m[12*20+6] = 0.058643
+	m[12*20+7] = 0.04656
+	m[12*20+8] = 0.021157
+	m[12*20+9] = 0.493845
+	m[12*20+10] = 1.105667
+	m[12*20+11] = 0.096474
+	m[13*20+0] = 0.116646
+	m[13*20+1] = 0.042569
+	m[13*20+2] = 0.039769
+	m[13*20+3] = 0.016577
+	m[13*20+4] = 0.051127
+	m[13*20+5] = 0.026235
+	m[13*20+6] = 0.028168
+	m[13*20+7] = 0.050143
+	m[13*20+8] = 0.079807
+	m[13*20+9] = 0.32102
+	m[13*20+10] = 0.946499
+	m[13*20+11] = 0.038261
+	m[13*20+12] = 0.173052
+	m[14*20+0] = 0.399143
+	m[14*20+1] = 0.12848
+	m[14*20+2] = 0.083956
+	m[14*20+3] = 0.160063
+	m[14*20+4] = 0.011137
+	m[14*20+5] = 0.15657
+	m[14*20+6] = 0.205134
+	m[14*20+7] = 0.124492
+	m[14*20+8] = 0.078892
+	m[14*20+9] = 0.054797
+	m[14*20+10] = 0.169784
+	m[14*20+11] = 0.212302
+	m[14*20+12] = 0.010363
+	m[14*20+13] = 0.042564
+	m[15*20+0] = 1.817198
+	m[15*20+1] = 0.292327
+	m[15*20+2] = 0.847049
+	m[15*20+3] = 0.461519
+	m[15*20+4] = 0.17527
+	m[15*20+5] = 0.358017
+	m[15*20+6] = 0.406035
+	m[15*20+7] = 0.612025
+	m[15*20+8] = 0.167226
+	m[15*20+9] = 0.081567
+	m[15*20+10] = 0.214977
+	m[15*20+11] = 0.400072
+	m[15*20+12] = 0.090515
+	m[15*20+13] = 0.138119
+	m[15*20+14] = 0.430431
+	m[16*20+0] = 0.877877
+	m[16*20+1] = 0.204109
+	m[16*20+2] = 0.471268
+	m[16*20+3] = 0.178197
+	m[16*20+4] = 0.079511
+	m[16*20+5] = 0.248992
+	m[16*20+6] = 0.321028
+	m[16*20+7] = 0.136266
+	m[16*20+8] = 0.101117
+	m[16*20+9] = 0.376588
+	m[16*20+10] = 0.243227
+	m[16*20+11] = 0.446646
+	m[16*20+12] = 0.184609
+	m[16*20+13] = 0.08587
+	m[16*20+14] = 0.207143
+	m[16*20+15] = 1.767766
+	m[17*20+0] = 0.030309
+	m[17*20+1] = 0.046417
+	m[17*20+2] = 0.010459
+	m[17*20+3] = 0.011393
+	m[17*20+4] = 0.007732
+	m[17*20+5] = 0.021248
+	m[17*20+6] = 0.018844
+	m[17*20+7] = 0.02399
+	m[17*20+8] = 0.020009
+	m[17*20+9] = 0.034954
+	m[17*20+10] = 0.083439
+	m[17*20+11] = 0.023321
+	m[17*20+12] = 0.022019
+	m[17*20+13] = 0.12805
+	m[17*20+14] = 0.014584
+	m[17*20+15] = 0.035933
+	m[17*20+16] = 0.020437
+	m[18*20+0] = 0.087061
+	m[18*20+1] = 0.09701
+	m[18*20+2] = 0.093268
+	m[18*20+3] = 0.051664
+	m[18*20+4] = 0.042823
+	m[18*20+5] = 0.062544
+	m[18*20+6] = 0.0552
+	m[18*20+7] = 0.037568
+	m[18*20+8] = 0.286027
+	m[18*20+9] = 0.086237
+	m[18*20+10] = 0.189842
+	m[18*20+11] = 0.068689
+	m[18*20+12] = 0.073223
+	m[18*20+13] = 0.898663
+	m[18*20+14] = 0.032043
+	m[18*20+15] = 0.121979
+	m[18*20+16] = 0.094617
+	m[18*20+17] = 0.124746
+	m[19*20+0] = 1.230985
+	m[19*20+1] = 0.113146
+	m[19*20+2] = 0.049824
+	m[19*20+3] = 0.048769
+	m[19*20+4] = 0.163831
+	m[19*20+5] = 0.112027
+	m[19*20+6] = 0.205868
+	m[19*20+7] = 0.082579
+	m[19*20+8] = 0.068575
+	m[19*20+9] = 3.65443
+	m[19*20+10] = 1.337571
+	m[19*20+11] = 0.144587
+	m[19*20+12] = 0.307309
+	m[19*20+13] = 0.247329
+	m[19*20+14] = 0.129315
+	m[19*20+15] = 0.1277
+	m[19*20+16] = 0.740372
+	m[19*20+17] = 0.022134
+	m[19*20+18] = 0.125733
+
+	for i = 0; i < naa; i++ {
+		for j = 0; j < i; j++ {
+			m[j*naa+i] = m[i*naa+j]
+		}
+	}
+
+	pi[0] = 0.078837
+	pi[1] = 0.051238
+	pi[2] = 0.042313
+	pi[3] = 0.053066
+	pi[4] = 0.015175
+	pi[5] = 0.036713
+	pi[6] = 0.061924
+	pi[7] = 0.070852
+	pi[8] = 0.023082
+	pi[9] = 0.062056
+	pi[10] = 0.096371
+	pi[11] = 0.057324
+	pi[12] = 0.023771
+	pi[13] = 0.043296
+	pi[14] = 0.043911
+	pi[15] = 0.063403
+	pi[16] = 0.055897
+	pi[17] = 0.013272
+	pi[18] = 0.034399
+	pi[19] = 0.073101
+
+	dmat = mat.NewDense(naa, naa, m)
+	return
+}
+
+/*********************************************************/
+/* PMB model
+ * Cecilie Veerassamy, Andrew Smith and Elisabeth R. M. Tillier
+ * "A transition probability model for amino acid substitutions from blocks"
+ * J Comput Biol (2003) 10:997-1010 */
+func PMBMats() (dmat *mat.Dense, pi []float64) {
+	var i, j, naa int
+	naa = 20
+	m := make([]float64, naa*naa)
+	pi = make([]float64, naa)
+
+	m[1*20+0] = 0.674995699
+	m[2*20+0] = 0.589645178
+	m[2*20+1] = 1.189067034
+	m[3*20+0] = 0.462499504
+	m[3*20+1] = 0.605460903
+	m[3*20+2] = 3.573373315
+	m[4*20+0] = 1.065445546
+	m[4*20+1] = 0.31444833
+	m[4*20+2] = 0.589852457
+	m[4*20+3] = 0.246951424
+	m[5*20+0] = 1.111766964
+	m[5*20+1] = 2.967840934
+	m[5*20+2] = 2.299755865
+	m[5*20+3] = 1.686058219
+	m[5*20+4] = 0.245163782
+	m[6*20+0] = 1.046334652
+	m[6*20+1] = 1.201770702
+	m[6*20+2] = 1.277836748
+	m[6*20+3] = 4.399995525
+	m[6*20+4] = 0.091071867
+	m[6*20+5] = 4.15967899
+	m[7*20+0] = 1.587964372
+	m[7*20+1] = 0.523770553
+	m[7*20+2] = 1.374854049
+	m[7*20+3] = 0.734992057
+	m[7*20+4] = 0.31706632
+	m[7*20+5] = 0.596789898
+	m[7*20+6] = 0.463812837
+	m[8*20+0] = 0.580830874
+	m[8*20+1] = 1.457127446
+	m[8*20+2] = 2.283037894
+	m[8*20+3] = 0.839348444
+	m[8*20+4] = 0.411543728
+	m[8*20+5] = 1.812173605
+	m[8*20+6] = 0.877842609
+	m[8*20+7] = 0.476331437
+	m[9*20+0] = 0.464590585
+	m[9*20+1] = 0.35813128
+	m[9*20+2] = 0.253479061
+	m[9*20+3] = 0.07035281
+	m[9*20+4] = 0.708411618
+	m[9*20+5] = 0.25312079
+	m[9*20+6] = 0.191764075
+	m[9*20+7] = 0.114999331
+	m[9*20+8] = 0.221361497
+	m[10*20+0] = 0.676819383
+	m[10*20+1] = 0.672853385
+	m[10*20+2] = 0.164390926
+	m[10*20+3] = 0.131151961
+	m[10*20+4] = 0.692210269
+	m[10*20+5] = 0.666283627
+	m[10*20+6] = 0.335596562
+	m[10*20+7] = 0.168996011
+	m[10*20+8] = 0.460642787
+	m[10*20+9] = 3.451766017
+	m[11*20+0] = 1.198834733
+	m[11*20+1] = 5.088004564
+	m[11*20+2] = 2.126522102
+	m[11*20+3] = 1.132434452
+	m[11*20+4] = 0.2074823
+	m[11*20+5] = 3.062880652
+	m[11*20+6] = 3.062818003
+	m[11*20+7] = 0.723549553
+	m[11*20+8] = 1.078089922
+	m[11*20+9] = 0.398208587
+	m[11*20+10] = 0.387128017
+	m[12*20+0] = 1.174416713
+	m[12*20+1] = 0.751924186
+	m[12*20+2] = 0.489618042
+	m[12*20+3] = 0.279232813
+	m[12*20+4] = 0.695047937
+	m[12*20+5] = 1.473648453
+	m[12*20+6] = 0.621045016
+	m[12*20+7] = 0.34609802
+	m[12*20+8] = 0.562002624
+	m[12*20+9] = 4.013271081
+	m[12*20+10] = 5.856806917
+	m[12*20+11] = 0.908010307
+	m[13*20+0] = 0.432290468
+	m[13*20+1] = 0.212633373
+	m[13*20+2] = 0.299669599
+	m[13*20+3] = 0.137998155
+	m[13*20+4] = 0.466008787
+	m[13*20+5] = 0.248285848
+	m[13*20+6] = 0.192024853
+	m[13*20+7] = 0.245919069
+	m[13*20+8] = 0.734745054
+	m[13*20+9] = 1.617734047
+	m[13*20+10] = 2.828810219
+	m[13*20+11] = 0.199151659
+	m[13*20+12] = 1.608616516
+	m[14*20+0] = 1.261993806
+	m[14*20+1] = 0.528218574
+	m[14*20+2] = 0.582262766
+	m[14*20+3] = 0.685779339
+	m[14*20+4] = 0.307232013
+	m[14*20+5] = 0.871129718
+	m[14*20+6] = 0.880210581
+	m[14*20+7] = 0.530810419
+	m[14*20+8] = 0.599993416
+	m[14*20+9] = 0.267993069
+	m[14*20+10] = 0.388707043
+	m[14*20+11] = 0.922018226
+	m[14*20+12] = 0.250521879
+	m[14*20+13] = 0.248958412
+	m[15*20+0] = 3.063883456
+	m[15*20+1] = 0.930245548
+	m[15*20+2] = 2.137453716
+	m[15*20+3] = 1.290452612
+	m[15*20+4] = 1.361727451
+	m[15*20+5] = 1.372287938
+	m[15*20+6] = 1.073604823
+	m[15*20+7] = 1.450017224
+	m[15*20+8] = 1.088296609
+	m[15*20+9] = 0.368286449
+	m[15*20+10] = 0.387917244
+	m[15*20+11] = 1.315227843
+	m[15*20+12] = 0.813023838
+	m[15*20+13] = 0.577637548
+	m[15*20+14] = 1.212148154
+	m[16*20+0] = 1.767233612
+	m[16*20+1] = 0.859347416
+	m[16*20+2] = 1.683478064
+	m[16*20+3] = 0.940059694
+	m[16*20+4] = 1.08296005
+	m[16*20+5] = 1.006549638
+	m[16*20+6] = 0.83541006
+	m[16*20+7] = 0.446466024
+	m[16*20+8] = 0.620413584
+	m[16*20+9] = 1.442648219
+	m[16*20+10] = 0.739934212
+	m[16*20+11] = 1.227003213
+	m[16*20+12] = 1.488766027
+	m[16*20+13] = 0.422768093
+	m[16*20+14] = 1.004046791
+	m[16*20+15] = 3.870574791
+	m[17*20+0] = 0.317808768
+	m[17*20+1] = 0.551016547
+	m[17*20+2] = 0.186264049
+	m[17*20+3] = 0.244104785
+	m[17*20+4] = 0.546148399
+	m[17*20+5] = 0.597225001
+	m[17*20+6] = 0.279112883
+	m[17*20+7] = 0.593519497
+	m[17*20+8] = 0.408765604
+	m[17*20+9] = 0.416418085
+	m[17*20+10] = 0.907064895
+	m[17*20+11] = 0.305474011
+	m[17*20+12] = 0.657106829
+	m[17*20+13] = 2.024296706
+	m[17*20+14] = 0.328765216
+	m[17*20+15] = 0.491911017
+	m[17*20+16] = 0.405616617
+	m[18*20+0] = 0.520058925
+	m[18*20+1] = 0.619618218
+	m[18*20+2] = 0.724051163
+	m[18*20+3] = 0.419734357
+	m[18*20+4] = 0.661339063
+	m[18*20+5] = 0.775716573
+	m[18*20+6] = 0.474999978
+	m[18*20+7] = 0.317020297
+	m[18*20+8] = 4.134315127
+	m[18*20+9] = 0.602941052
+	m[18*20+10] = 0.733941624
+	m[18*20+11] = 0.545591493
+	m[18*20+12] = 0.86225294
+	m[18*20+13] = 5.044025011
+	m[18*20+14] = 0.36108731
+	m[18*20+15] = 0.720026026
+	m[18*20+16] = 0.614612883
+	m[18*20+17] = 3.006745823
+	m[19*20+0] = 1.766289155
+	m[19*20+1] = 0.279045536
+	m[19*20+2] = 0.292849744
+	m[19*20+3] = 0.203751993
+	m[19*20+4] = 1.065640008
+	m[19*20+5] = 0.494101116
+	m[19*20+6] = 0.461279587
+	m[19*20+7] = 0.291124087
+	m[19*20+8] = 0.226911898
+	m[19*20+9] = 8.218478048
+	m[19*20+10] = 1.862449566
+	m[19*20+11] = 0.326271898
+	m[19*20+12] = 2.120048393
+	m[19*20+13] = 0.795019306
+	m[19*20+14] = 0.422003839
+	m[19*20+15] = 0.506279094
+	m[19*20+16] = 1.694019437
+	m[19*20+17] = 0.455893636
+	m[19*20+18] = 0.55211315
+
+	for i = 0; i < naa; i++ {
+		for j = 0; j < i; j++ {
+			m[j*naa+i] = m[i*naa+j]
+		}
+	}
+
+	pi[0] = 0.0756
+	pi[1] = 0.0538
+	pi[2] = 0.0377
+	pi[3] = 0.0447
+	pi[4] = 0.0285
+	pi[5] = 0.0339
+	pi[6] = 0.0535
+	pi[7] = 0.0780
+	pi[8] = 0.0300
+	pi[9] = 0.0599
+	pi[10] = 0.0958
+	pi[11] = 0.0520
+	pi[12] = 0.0219
+	pi[13] = 0.0450
+	pi[14] = 0.0420
+	pi[15] = 0.0682
+	pi[16] = 0.0564
+	pi[17] = 0.0157
+	pi[18] = 0.0360
+	pi[19] = 0.0718
+
+	dmat = mat.NewDense(naa, naa, m)
+	return
+}
@@ -0,0 +1,55 @@
+package protein
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestPAMLBuiltinModels(t *testing.T) {
+	// Exchangeabilities R-A, K-R and V-I of the PAML .dat files
+	type exch struct {
+		i, j int
+		v    float64
+	}
+	for _, c := range []struct {
+		name  string
+		model int
+		mats  func() (*mat.Dense, []float64)
+		exch  []exch
+	}{
+		{"cprev", MODEL_CPREV, CpREVMats, []exch{{1, 0, 105}, {11, 1, 4482}, {19, 9, 4797}}},
+		{"rtrev", MODEL_RTREV, RtREVMats, []exch{{1, 0, 34}, {11, 1, 593}, {19, 9, 1048}}},
+		{"mtmam", MODEL_MTMAM, MtMAMMats, []exch{{1, 0, 32}, {11, 1, 50}, {19, 9, 2220}}},
+		{"hivw", MODEL_HIVW, HIVWMats, []exch{{1, 0, 0.0744808}, {11, 1, 39.8897}, {19, 9, 24.8231}}},
+		{"flu", MODEL_FLU, FLUMats, []exch{{1, 0, 0.138658765}, {11, 1, 15.30009662}, {19, 9, 11.68808114}}},
+		{"blosum62", MODEL_BLOSUM62, Blosum62Mats, []exch{{1, 0, 0.735790389698}, {11, 1, 5.411115141489}, {19, 9, 8.311839405458}}},
+		{"vt", MODEL_VT, VTMats, []exch{{1, 0, 0.233108}, {11, 1, 1.755838}, {19, 9, 3.65443}}},
+		{"pmb", MODEL_PMB, PMBMats, []exch{{1, 0, 0.674995699}, {11, 1, 5.088004564}, {19, 9, 8.218478048}}},
+	} {
+		if m := ModelStringToInt(c.name); m != c.model {
+			t.Errorf("ModelStringToInt(%s) = %d, want %d", c.name, m, c.model)
+		}
+		m, pi := c.mats()
+		for _, e := range c.exch {
+			if m.At(e.i, e.j) != e.v || m.At(e.j, e.i) != e.v {
+				t.Errorf("%s: exchangeability [%d][%d]=%v, want %v", c.name, e.i, e.j, m.At(e.i, e.j), e.v)
+			}
+		}
+		sum := 0.0
+		for _, p := range pi {
+			sum += p
+		}
+		if math.Abs(sum-1.) > 0.001 {
+			t.Errorf("%s: frequencies sum to %v", c.name, sum)
+		}
+		pm, err := NewProtModel(c.model, false, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = pm.InitModel(nil); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+		}
+	}
+}
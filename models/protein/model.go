@@ -15,6 +15,14 @@ const (
 	MODEL_WAG
 	MODEL_HIVB
 	MODEL_AB
+	MODEL_CPREV
+	MODEL_RTREV
+	MODEL_MTMAM
+	MODEL_HIVW
+	MODEL_FLU
+	MODEL_BLOSUM62
+	MODEL_VT
+	MODEL_PMB
 
 	BL_MIN  = 1.e-08
 	BL_MAX  = 100.0
@@ -36,7 +44,9 @@ type ProtModel struct {
 }
 
 // Initialize a new protein model, given the name of the model as const int:
-// MODEL_DAYHOFF, MODEL_JTT, MODEL_MTREV, MODEL_LG, MODEL_WAG or MODEL_HIVB
+// MODEL_DAYHOFF, MODEL_JTT, MODEL_MTREV, MODEL_LG, MODEL_WAG, MODEL_HIVB, MODEL_AB,
+// MODEL_CPREV, MODEL_RTREV, MODEL_MTMAM, MODEL_HIVW, MODEL_FLU, MODEL_BLOSUM62,
+// MODEL_VT or MODEL_PMB
 func NewProtModel(model int, usegamma bool, alpha float64) (*ProtModel, error) {
 	var m *mat.Dense
 	var pi []float64
@@ -55,6 +65,22 @@ func NewProtModel(model int, usegamma bool, alpha float64) (*ProtModel, error) {
 		m, pi = HIVBMats()
 	case MODEL_AB:
 		m, pi = ABMats()
+	case MODEL_CPREV:
+		m, pi = CpREVMats()
+	case MODEL_RTREV:
+		m, pi = RtREVMats()
+	case MODEL_MTMAM:
+		m, pi = MtMAMMats()
+	case MODEL_HIVW:
+		m, pi = HIVWMats()
+	case MODEL_FLU:
+		m, pi = FLUMats()
+	case MODEL_BLOSUM62:
+		m, pi = Blosum62Mats()
+	case MODEL_VT:
+		m, pi = VTMats()
+	case MODEL_PMB:
+		m, pi = PMBMats()
 
 	default:
 		return nil, fmt.Errorf("this protein model is not implemented")
@@ -90,6 +116,22 @@ func ModelStringToInt(model string) int {
 		return MODEL_HIVB
 	case "ab":
 		return MODEL_AB
+	case "cprev":
+		return MODEL_CPREV
+	case "rtrev":
+		return MODEL_RTREV
+	case "mtmam":
+		return MODEL_MTMAM
+	case "hivw":
+		return MODEL_HIVW
+	case "flu":
+		return MODEL_FLU
+	case "blosum62":
+		return MODEL_BLOSUM62
+	case "vt":
+		return MODEL_VT
+	case "pmb":
+		return MODEL_PMB
 	default:
 		return -1
 	}
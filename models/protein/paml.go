@@ -0,0 +1,96 @@
+package protein
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// ReadPAMLMats reads an empirical amino acid model in PAML format (.dat files):
+// the lower triangle of the exchangeability matrix (19 lines, line i having i values),
+// followed by the 20 amino acid frequencies, amino acids being in the order
+// A R N D C Q E G H I L K M F P S T W Y V. Values are separated by any whitespace,
+// and everything after the 210 values is ignored (PAML files usually end with comments).
+// As for built-in models, frequencies are used as is, and must sum to 1 (+/- 0.01).
+func ReadPAMLMats(r io.Reader) (dmat *mat.Dense, pi []float64, err error) {
+	var v float64
+	naa := 20
+	values := make([]float64, 0, naa*(naa-1)/2+naa)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+	for len(values) < cap(values) && scanner.Scan() {
+		if v, err = strconv.ParseFloat(scanner.Text(), 64); err != nil {
+			err = fmt.Errorf("invalid value in PAML model file: %s (%d values read, %d expected)", scanner.Text(), len(values), cap(values))
+			return
+		}
+		if v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+			err = fmt.Errorf("invalid value in PAML model file: %s", scanner.Text())
+			return
+		}
+		values = append(values, v)
+	}
+	if err = scanner.Err(); err != nil {
+		return
+	}
+	if len(values) < cap(values) {
+		err = fmt.Errorf("PAML model file is too short: %d values read, %d expected", len(values), cap(values))
+		return
+	}
+
+	m := make([]float64, naa*naa)
+	k := 0
+	for i := 1; i < naa; i++ {
+		for j := 0; j < i; j++ {
+			m[i*naa+j] = values[k]
+			m[j*naa+i] = values[k]
+			k++
+		}
+	}
+	pi = make([]float64, naa)
+	sum := 0.0
+	for i := 0; i < naa; i++ {
+		pi[i] = values[k+i]
+		sum += pi[i]
+	}
+	if math.Abs(sum-1.) > 0.01 {
+		err = fmt.Errorf("amino acid frequencies of the PAML model file do not sum to 1: %f", sum)
+		return
+	}
+	dmat = mat.NewDense(naa, naa, m)
+	return
+}
+
+// NewProtModelFromFile initializes a new protein model from an exchangeability matrix
+// and amino acid frequencies given in a PAML format file (see ReadPAMLMats).
+func NewProtModelFromFile(file string, usegamma bool, alpha float64) (model *ProtModel, err error) {
+	var f *os.File
+	var m *mat.Dense
+	var pi []float64
+
+	if f, err = os.Open(file); err != nil {
+		return
+	}
+	defer f.Close()
+	if m, pi, err = ReadPAMLMats(f); err != nil {
+		err = fmt.Errorf("%s: %v", file, err)
+		return
+	}
+	model = &ProtModel{
+		pi,
+		m,
+		-1.0,
+		nil,
+		nil,
+		nil,
+		nil,
+		alpha,
+		usegamma,
+	}
+	return
+}
@@ -0,0 +1,52 @@
+package protein
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReadPAMLMats(t *testing.T) {
+	m, pi := WAGMats()
+
+	var sb strings.Builder
+	for i := 1; i < 20; i++ {
+		for j := 0; j < i; j++ {
+			fmt.Fprintf(&sb, "%v ", m.At(i, j))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&sb, "%v ", pi[i])
+	}
+	sb.WriteString("\n\nWAG model, comments are ignored\n")
+
+	rm, rpi, err := ReadPAMLMats(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		if rpi[i] != pi[i] {
+			t.Errorf("ReadPAMLMats: pi[%d]=%v, want %v", i, rpi[i], pi[i])
+		}
+		for j := 0; j < 20; j++ {
+			if rm.At(i, j) != m.At(i, j) {
+				t.Errorf("ReadPAMLMats: m[%d][%d]=%v, want %v", i, j, rm.At(i, j), m.At(i, j))
+			}
+		}
+	}
+
+	// Too short
+	if _, _, err = ReadPAMLMats(strings.NewReader("0.1 0.2\n0.3")); err == nil {
+		t.Errorf("ReadPAMLMats: expected an error for a too short file")
+	}
+	// Non numeric value
+	if _, _, err = ReadPAMLMats(strings.NewReader("0.1\nA 0.2")); err == nil {
+		t.Errorf("ReadPAMLMats: expected an error for a non numeric value")
+	}
+	// Frequencies not summing to 1
+	if _, _, err = ReadPAMLMats(strings.NewReader(strings.Repeat("1 ", 210))); err == nil {
+		t.Errorf("ReadPAMLMats: expected an error for frequencies not summing to 1")
+	}
+}
@@ -0,0 +1,135 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+// MedianJoiningNetwork builds the median-joining network of the haplotypes of the
+// alignment (Bandelt et al. 1999, see Haplotypes):
+//  1. The minimum spanning network of the nodes is computed (see MinimumSpanningNetwork);
+//  2. Inferred nodes having less than 3 edges (obsolete median vectors) are removed;
+//  3. For each triplet of nodes with at least two of them connected in the minimum
+//     spanning network, the median vector is computed: at each site, the majority
+//     character of the triplet, or the character of the first node if all differ.
+//     Median vectors that are not already in the network and whose connection
+//     cost (sum of the distances to the triplet) is at most the minimum connection cost
+//     plus epsilon are added to the network;
+//  4. Steps 1-3 are repeated until no new median vector is added.
+//
+// Median vectors are named mv1, mv2, etc. A median vector that has been removed
+// is never added again. Edge weights are the number of differences between haplotypes.
+func MedianJoiningNetwork(al align.Alignment, epsilon int) (n *Network, err error) {
+	if epsilon < 0 {
+		err = fmt.Errorf("epsilon must be >= 0: %d", epsilon)
+		return
+	}
+	n = &Network{}
+	if n.Nodes, n.Sites, err = Haplotypes(al); err != nil {
+		return
+	}
+
+	// All the median vectors ever generated
+	generated := make(map[string]bool)
+	for _, node := range n.Nodes {
+		generated[string(node.Sequence)] = true
+	}
+	nmedians := 0
+	for {
+		n.removeObsoleteMedians(epsilon)
+
+		linked := make([][]bool, len(n.Nodes))
+		for i := range linked {
+			linked[i] = make([]bool, len(n.Nodes))
+		}
+		for _, e := range n.Edges {
+			linked[e.Source][e.Target] = true
+			linked[e.Target][e.Source] = true
+		}
+
+		var medians [][]uint8
+		var costs []int
+		mincost := -1
+		seen := make(map[string]bool)
+		for i := range n.Nodes {
+			for j := i + 1; j < len(n.Nodes); j++ {
+				for k := j + 1; k < len(n.Nodes); k++ {
+					nlinks := 0
+					for _, l := range []bool{linked[i][j], linked[i][k], linked[j][k]} {
+						if l {
+							nlinks++
+						}
+					}
+					if nlinks < 2 {
+						continue
+					}
+					m := median(n.Nodes[i].Sequence, n.Nodes[j].Sequence, n.Nodes[k].Sequence)
+					if generated[string(m)] || seen[string(m)] {
+						continue
+					}
+					seen[string(m)] = true
+					cost := hamming(m, n.Nodes[i].Sequence) + hamming(m, n.Nodes[j].Sequence) + hamming(m, n.Nodes[k].Sequence)
+					medians = append(medians, m)
+					costs = append(costs, cost)
+					if mincost < 0 || cost < mincost {
+						mincost = cost
+					}
+				}
+			}
+		}
+
+		added := false
+		for i, m := range medians {
+			if costs[i] <= mincost+epsilon {
+				nmedians++
+				generated[string(m)] = true
+				n.Nodes = append(n.Nodes, &Node{Name: fmt.Sprintf("mv%d", nmedians), Sequence: m, Inferred: true})
+				added = true
+			}
+		}
+		if !added {
+			break
+		}
+	}
+	n.removeObsoleteMedians(epsilon)
+	n.sortEdges()
+	return
+}
+
+// removeObsoleteMedians computes the minimum spanning network of the nodes, and
+// removes inferred nodes having less than 3 edges, until there is none
+func (n *Network) removeObsoleteMedians(epsilon int) {
+	for {
+		n.Edges = feasibleLinks(distances(n.Nodes), epsilon)
+		degree := make([]int, len(n.Nodes))
+		for _, e := range n.Edges {
+			degree[e.Source]++
+			degree[e.Target]++
+		}
+		var nodes []*Node
+		for i, node := range n.Nodes {
+			if !node.Inferred || degree[i] >= 3 {
+				nodes = append(nodes, node)
+			}
+		}
+		if len(nodes) == len(n.Nodes) {
+			return
+		}
+		n.Nodes = nodes
+	}
+}
+
+// median returns the median vector of three haplotypes: at each site, the majority
+// character, or the character of the first haplotype if all three differ
+func median(s1, s2, s3 []uint8) (m []uint8) {
+	m = make([]uint8, len(s1))
+	for i := range s1 {
+		if s2[i] == s3[i] {
+			m[i] = s2[i]
+		} else {
+			m[i] = s1[i]
+		}
+	}
+	return
+}
@@ -0,0 +1,68 @@
+package network
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+// MinimumSpanningNetwork builds the minimum spanning network of the haplotypes of the
+// alignment (Bandelt et al. 1999, see Haplotypes): the union of all minimum spanning
+// trees. An edge between two haplotypes at distance d is added if they are not connected
+// by edges shorter than d-epsilon. With epsilon=0, it is the union of all the minimum
+// spanning trees, and larger values of epsilon add alternative connections.
+//
+// Edge weights are the number of differences between haplotypes.
+func MinimumSpanningNetwork(al align.Alignment, epsilon int) (n *Network, err error) {
+	if epsilon < 0 {
+		err = fmt.Errorf("epsilon must be >= 0: %d", epsilon)
+		return
+	}
+	n = &Network{}
+	if n.Nodes, n.Sites, err = Haplotypes(al); err != nil {
+		return
+	}
+	n.Edges = feasibleLinks(distances(n.Nodes), epsilon)
+	n.sortEdges()
+	return
+}
+
+// feasibleLinks returns the edges of the minimum spanning network of the nodes,
+// given their pairwise distances: an edge of length d between two nodes is added if
+// they are not connected by edges of length < d-epsilon.
+func feasibleLinks(d [][]int, epsilon int) (edges []Edge) {
+	var pairs []Edge
+
+	for i := range d {
+		for j := i + 1; j < len(d); j++ {
+			pairs = append(pairs, Edge{Source: i, Target: j, Weight: d[i][j]})
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].Weight < pairs[j].Weight })
+
+	// Union-find on nodes, connected by all pairs shorter than the current threshold
+	parent := make([]int, len(d))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	merged := 0
+	for _, p := range pairs {
+		for merged < len(pairs) && pairs[merged].Weight < p.Weight-epsilon {
+			parent[find(pairs[merged].Source)] = find(pairs[merged].Target)
+			merged++
+		}
+		if find(p.Source) != find(p.Target) {
+			edges = append(edges, p)
+		}
+	}
+	return
+}
@@ -0,0 +1,175 @@
+// Package network builds haplotype networks from alignments: minimum spanning
+// networks, median-joining networks and TCS-like statistical parsimony networks.
+//
+// Identical sequences are collapsed into haplotypes. Sites where at least one
+// sequence has a gap or an ambiguous character (any character that is not an
+// unambiguous character of the alignment alphabet) are masked before comparing
+// haplotypes, so that distances between haplotypes are numbers of differences
+// on the remaining sites.
+package network
+
+import (
+	"fmt"
+	"sort"
+	"unicode"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+// Node is a node of a haplotype network: either a sampled haplotype, or
+// an inferred haplotype (median vector for median-joining networks, or
+// intermediate haplotype for TCS networks).
+type Node struct {
+	Name     string
+	Sequence []uint8 // Haplotype, on the unmasked sites
+	Indices  []int   // Indices of the sequences of the alignment having this haplotype (empty if inferred)
+	Inferred bool    // True if the haplotype is not sampled (median vector or intermediate)
+	Groups   []int   // Number of sequences of each group (see Network.SetGroups)
+}
+
+// Frequency returns the number of sequences having the haplotype of this node
+func (n *Node) Frequency() int {
+	return len(n.Indices)
+}
+
+// Edge is an undirected edge of a haplotype network
+type Edge struct {
+	Source, Target int // Indices of the nodes
+	Weight         int // Number of differences between the haplotypes of the nodes
+}
+
+// Network is a haplotype network
+type Network struct {
+	Nodes  []*Node
+	Edges  []Edge
+	Sites  int      // Number of unmasked sites used to compare haplotypes
+	Groups []string // Names of the groups (see SetGroups)
+}
+
+// Haplotypes collapses identical sequences of the alignment into haplotypes
+// (see align.Deduplicate), after masking the sites where at least one sequence
+// has a gap or an ambiguous character. It returns one node per haplotype, named
+// after the first sequence having it, and the number of unmasked sites.
+func Haplotypes(al align.Alignment) (nodes []*Node, sites int, err error) {
+	var kept []int
+	var identical [][]string
+
+	if al.NbSequences() == 0 {
+		err = fmt.Errorf("the alignment has no sequence")
+		return
+	}
+	seqs := make([][]uint8, al.NbSequences())
+	for i := range seqs {
+		seqs[i], _ = al.GetSequenceCharById(i)
+	}
+	for site := 0; site < al.Length(); site++ {
+		ok := true
+		for _, s := range seqs {
+			if al.AlphabetCharToIndex(s[site]) < 0 {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			kept = append(kept, site)
+		}
+	}
+
+	m := align.NewAlign(al.Alphabet())
+	for i, s := range al.Sequences() {
+		h := make([]uint8, len(kept))
+		for j, site := range kept {
+			h[j] = uint8(unicode.ToUpper(rune(seqs[i][site])))
+		}
+		if err = m.AddSequenceChar(s.Name(), h, ""); err != nil {
+			return
+		}
+	}
+
+	// Indices of the sequences of each name, in the alignment order
+	indices := make(map[string][]int)
+	for i, s := range al.Sequences() {
+		indices[s.Name()] = append(indices[s.Name()], i)
+	}
+	if identical, err = m.Deduplicate(false); err != nil {
+		return
+	}
+	nodes = make([]*Node, len(identical))
+	for i, names := range identical {
+		h, _ := m.GetSequenceCharById(i)
+		nodes[i] = &Node{Name: names[0], Sequence: h}
+		for _, name := range names {
+			nodes[i].Indices = append(nodes[i].Indices, indices[name][0])
+			indices[name] = indices[name][1:]
+		}
+	}
+	sites = len(kept)
+	return
+}
+
+// SetGroups computes the number of sequences of each group in each node of the network.
+// groups gives the indices of the sequences of the alignment in each group, and keys
+// the names of the groups, in the output order.
+func (n *Network) SetGroups(groups map[string][]int, keys []string) {
+	seqgroup := make(map[int]int)
+	for g, k := range keys {
+		for _, i := range groups[k] {
+			seqgroup[i] = g
+		}
+	}
+	n.Groups = keys
+	for _, node := range n.Nodes {
+		node.Groups = make([]int, len(keys))
+		for _, i := range node.Indices {
+			if g, ok := seqgroup[i]; ok {
+				node.Groups[g]++
+			}
+		}
+	}
+}
+
+// addEdge adds an edge between the two nodes if it does not already exist
+func (n *Network) addEdge(source, target, weight int) {
+	if source > target {
+		source, target = target, source
+	}
+	for _, e := range n.Edges {
+		if e.Source == source && e.Target == target {
+			return
+		}
+	}
+	n.Edges = append(n.Edges, Edge{Source: source, Target: target, Weight: weight})
+}
+
+// sortEdges sorts edges by source and target nodes
+func (n *Network) sortEdges() {
+	sort.Slice(n.Edges, func(i, j int) bool {
+		if n.Edges[i].Source != n.Edges[j].Source {
+			return n.Edges[i].Source < n.Edges[j].Source
+		}
+		return n.Edges[i].Target < n.Edges[j].Target
+	})
+}
+
+// hamming returns the number of differences between two haplotypes
+func hamming(s1, s2 []uint8) (d int) {
+	for i := range s1 {
+		if s1[i] != s2[i] {
+			d++
+		}
+	}
+	return
+}
+
+// distances returns the matrix of pairwise number of differences between the given haplotypes
+func distances(nodes []*Node) (d [][]int) {
+	d = make([][]int, len(nodes))
+	for i := range nodes {
+		d[i] = make([]int, len(nodes))
+		for j := 0; j < i; j++ {
+			d[i][j] = hamming(nodes[i].Sequence, nodes[j].Sequence)
+			d[j][i] = d[i][j]
+		}
+	}
+	return
+}
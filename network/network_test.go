@@ -0,0 +1,124 @@
+package network
+
+import (
+	"math"
+	"testing"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+func testAlign() align.Alignment {
+	a := align.NewAlign(align.NUCLEOTIDS)
+	a.AddSequence("s1_A", "ACGTACGTAC", "")
+	a.AddSequence("s2_A", "ACGTACGTAC", "")
+	a.AddSequence("s3_B", "ACGTACGTTC", "")
+	a.AddSequence("s4_B", "ACGAACGTTC", "")
+	a.AddSequence("s5_A", "TCGAACGTAC", "")
+	a.AddSequence("s6_C", "ACGAACCTAC", "")
+	a.AddSequence("s7_C", "ACGTACGTAN", "")
+	return a
+}
+
+func TestHaplotypes(t *testing.T) {
+	nodes, sites, err := Haplotypes(testAlign())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sites != 9 {
+		t.Errorf("Wrong number of unmasked sites: %d, expected 9", sites)
+	}
+	if len(nodes) != 5 {
+		t.Fatalf("Wrong number of haplotypes: %d, expected 5", len(nodes))
+	}
+	if nodes[0].Name != "s1_A" || nodes[0].Frequency() != 3 || nodes[0].Indices[2] != 6 {
+		t.Errorf("Wrong first haplotype: %+v", nodes[0])
+	}
+}
+
+func TestMinimumSpanningNetwork(t *testing.T) {
+	n, err := MinimumSpanningNetwork(testAlign(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []Edge{{0, 1, 1}, {0, 3, 2}, {0, 4, 2}, {1, 2, 1}, {2, 3, 2}, {2, 4, 2}, {3, 4, 2}}
+	checkEdges(t, n, exp)
+}
+
+func TestMedianJoiningNetwork(t *testing.T) {
+	n, err := MedianJoiningNetwork(testAlign(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(n.Nodes) != 6 || !n.Nodes[5].Inferred || string(n.Nodes[5].Sequence) != "ACGAACGTA" {
+		t.Fatalf("Wrong median vectors: %d nodes", len(n.Nodes))
+	}
+	exp := []Edge{{0, 1, 1}, {0, 5, 1}, {1, 2, 1}, {2, 5, 1}, {3, 5, 1}, {4, 5, 1}}
+	checkEdges(t, n, exp)
+}
+
+func TestTCSNetwork(t *testing.T) {
+	n, err := TCSNetwork(testAlign(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range n.Edges {
+		if e.Weight != 1 || hamming(n.Nodes[e.Source].Sequence, n.Nodes[e.Target].Sequence) != 1 {
+			t.Errorf("TCS edges should be single mutational steps: %+v", e)
+		}
+	}
+	for i := 1; i < 5; i++ {
+		if n.steps(0, i) < 0 {
+			t.Errorf("Haplotype %d should be connected to haplotype 0", i)
+		}
+	}
+
+	if n, err = TCSNetwork(testAlign(), 1); err != nil {
+		t.Fatal(err)
+	}
+	checkEdges(t, n, []Edge{{0, 1, 1}, {1, 2, 1}})
+
+	// 9 sites: a single difference is not parsimonious at 95%
+	if _, err = TCSNetwork(testAlign(), -1); err == nil {
+		t.Errorf("TCS with computed connection limit on 9 sites should fail")
+	}
+}
+
+func TestParsimonyLimit(t *testing.T) {
+	if p := ParsimonyProbability(1, 100); math.Abs(p-0.98476) > 1e-4 {
+		t.Errorf("Wrong probability of parsimony: %f, expected 0.98476", p)
+	}
+	if p := ParsimonyProbability(101, 100); !math.IsNaN(p) {
+		t.Errorf("Probability of parsimony should be NaN with more differences than sites: %f", p)
+	}
+	for _, c := range []struct{ m, limit int }{{20, 0}, {100, 2}, {500, 8}, {1000, 12}, {2000, 18}} {
+		if l := ParsimonyLimit(c.m, DEFAULT_PARSIMONY_PROB); l != c.limit {
+			t.Errorf("Wrong connection limit for %d sites: %d, expected %d", c.m, l, c.limit)
+		}
+	}
+	// The limit increases with the number of sites
+	if ParsimonyLimit(1000, 0.95) < ParsimonyLimit(500, 0.95) {
+		t.Errorf("Connection limit should increase with the number of sites")
+	}
+}
+
+func TestSetGroups(t *testing.T) {
+	n, err := MinimumSpanningNetwork(testAlign(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.SetGroups(map[string][]int{"A": {0, 1, 4}, "C": {5, 6}}, []string{"A", "C"})
+	if n.Nodes[0].Groups[0] != 2 || n.Nodes[0].Groups[1] != 1 || n.Nodes[4].Groups[1] != 1 {
+		t.Errorf("Wrong group counts: %v %v", n.Nodes[0].Groups, n.Nodes[4].Groups)
+	}
+}
+
+func checkEdges(t *testing.T, n *Network, exp []Edge) {
+	if len(n.Edges) != len(exp) {
+		t.Fatalf("Wrong number of edges: %v, expected %v", n.Edges, exp)
+	}
+	for i, e := range n.Edges {
+		if e != exp[i] {
+			t.Errorf("Wrong edge %d: %v, expected %v", i, e, exp[i])
+		}
+	}
+}
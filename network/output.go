@@ -0,0 +1,122 @@
+package network
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// GraphML returns the network in GraphML format. Nodes have the attributes:
+// name, frequency (number of sequences), inferred (median vector or intermediate),
+// sequences (comma separated names of the sequences), and one attribute per group
+// giving the number of sequences of the group (see SetGroups). Edges have a weight
+// attribute (number of differences). names gives the names of the sequences of the
+// alignment.
+func (n *Network) GraphML(names []string) string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	buffer.WriteString("<graphml xmlns=\"http://graphml.graphdrawing.org/xmlns\">\n")
+	buffer.WriteString("  <key id=\"name\" for=\"node\" attr.name=\"name\" attr.type=\"string\"/>\n")
+	buffer.WriteString("  <key id=\"frequency\" for=\"node\" attr.name=\"frequency\" attr.type=\"int\"/>\n")
+	buffer.WriteString("  <key id=\"inferred\" for=\"node\" attr.name=\"inferred\" attr.type=\"boolean\"/>\n")
+	buffer.WriteString("  <key id=\"sequences\" for=\"node\" attr.name=\"sequences\" attr.type=\"string\"/>\n")
+	for g, group := range n.Groups {
+		buffer.WriteString(fmt.Sprintf("  <key id=\"g%d\" for=\"node\" attr.name=\"%s\" attr.type=\"int\"/>\n", g, xmlEscape(group)))
+	}
+	buffer.WriteString("  <key id=\"weight\" for=\"edge\" attr.name=\"weight\" attr.type=\"int\"/>\n")
+	buffer.WriteString("  <graph id=\"network\" edgedefault=\"undirected\">\n")
+	for i, node := range n.Nodes {
+		buffer.WriteString(fmt.Sprintf("    <node id=\"n%d\">\n", i))
+		buffer.WriteString(fmt.Sprintf("      <data key=\"name\">%s</data>\n", xmlEscape(node.Name)))
+		buffer.WriteString(fmt.Sprintf("      <data key=\"frequency\">%d</data>\n", node.Frequency()))
+		buffer.WriteString(fmt.Sprintf("      <data key=\"inferred\">%t</data>\n", node.Inferred))
+		buffer.WriteString(fmt.Sprintf("      <data key=\"sequences\">%s</data>\n", xmlEscape(strings.Join(node.sequenceNames(names), ","))))
+		for g, c := range node.Groups {
+			buffer.WriteString(fmt.Sprintf("      <data key=\"g%d\">%d</data>\n", g, c))
+		}
+		buffer.WriteString("    </node>\n")
+	}
+	for _, e := range n.Edges {
+		buffer.WriteString(fmt.Sprintf("    <edge source=\"n%d\" target=\"n%d\">\n", e.Source, e.Target))
+		buffer.WriteString(fmt.Sprintf("      <data key=\"weight\">%d</data>\n", e.Weight))
+		buffer.WriteString("    </edge>\n")
+	}
+	buffer.WriteString("  </graph>\n")
+	buffer.WriteString("</graphml>\n")
+	return buffer.String()
+}
+
+// JSON returns the network in Cytoscape JSON format (elements with nodes and edges),
+// with the same attributes as GraphML, group counts being given in a "groups" object.
+// names gives the names of the sequences of the alignment.
+func (n *Network) JSON(names []string) (out []byte, err error) {
+	type nodeData struct {
+		ID        string         `json:"id"`
+		Name      string         `json:"name"`
+		Frequency int            `json:"frequency"`
+		Inferred  bool           `json:"inferred"`
+		Sequences []string       `json:"sequences"`
+		Groups    map[string]int `json:"groups,omitempty"`
+	}
+	type edgeData struct {
+		ID     string `json:"id"`
+		Source string `json:"source"`
+		Target string `json:"target"`
+		Weight int    `json:"weight"`
+	}
+	type element struct {
+		Data interface{} `json:"data"`
+	}
+	type elements struct {
+		Nodes []element `json:"nodes"`
+		Edges []element `json:"edges"`
+	}
+
+	elts := elements{Nodes: []element{}, Edges: []element{}}
+	for i, node := range n.Nodes {
+		data := nodeData{
+			ID:        fmt.Sprintf("n%d", i),
+			Name:      node.Name,
+			Frequency: node.Frequency(),
+			Inferred:  node.Inferred,
+			Sequences: node.sequenceNames(names),
+		}
+		if len(n.Groups) > 0 {
+			data.Groups = make(map[string]int)
+			for g, c := range node.Groups {
+				data.Groups[n.Groups[g]] = c
+			}
+		}
+		elts.Nodes = append(elts.Nodes, element{data})
+	}
+	for i, e := range n.Edges {
+		elts.Edges = append(elts.Edges, element{edgeData{
+			ID:     fmt.Sprintf("e%d", i),
+			Source: fmt.Sprintf("n%d", e.Source),
+			Target: fmt.Sprintf("n%d", e.Target),
+			Weight: e.Weight,
+		}})
+	}
+	return json.Marshal(struct {
+		Elements elements `json:"elements"`
+	}{elts})
+}
+
+// sequenceNames returns the names of the sequences having the haplotype of the node
+func (node *Node) sequenceNames(names []string) (seqs []string) {
+	seqs = make([]string, len(node.Indices))
+	for i, idx := range node.Indices {
+		seqs[i] = names[idx]
+	}
+	return
+}
+
+// xmlEscape escapes special xml characters of the given string
+func xmlEscape(s string) string {
+	var buffer bytes.Buffer
+	xml.EscapeText(&buffer, []byte(s))
+	return buffer.String()
+}
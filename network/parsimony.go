@@ -0,0 +1,89 @@
+package network
+
+import (
+	"math"
+)
+
+// Default probability used to compute the TCS connection limit
+const DEFAULT_PARSIMONY_PROB = 0.95
+
+// Number of intervals used for the numerical integration over q
+const parsimonyIntervals = 2000
+
+/*
+ParsimonyProbability returns the probability of parsimony P_j of Templeton et al. (1992):
+the probability that two haplotypes of m sites, differing at j sites, are separated by
+exactly j mutations, i.e. without any superimposed or hidden change.
+
+Each site is supposed to change with probability q on each of the two branches separating
+the haplotypes, and multiple changes at a site can restore the ancestral state. The
+probability of observing j differences with no hidden change (m-j sites without any change)
+is divided by the probability of observing j differences. As in Templeton et al. (1992),
+q is integrated out (uniform prior on [0,0.5]) rather than fixed:
+
+	P_j = Int (2q(1-q))^j (1-q)^(2(m-j)) dq / Int (2q(1-q))^j ((1-q)^2+q^2)^(m-j) dq
+*/
+func ParsimonyProbability(j, m int) float64 {
+	if j < 0 || m <= 0 || j > m {
+		return math.NaN()
+	}
+	// Both integrands are concentrated around q=(j+1)/(2m)
+	upper := (float64(j) + 1. + 10.*math.Sqrt(float64(j)+1.) + 30.) / (2. * float64(m))
+	if upper > 0.5 {
+		upper = 0.5
+	}
+	num := logIntegrate(func(q float64) float64 {
+		return logPow(2.*q*(1.-q), j) + 2.*float64(m-j)*math.Log(1.-q)
+	}, upper)
+	den := logIntegrate(func(q float64) float64 {
+		return logPow(2.*q*(1.-q), j) + float64(m-j)*math.Log((1.-q)*(1.-q)+q*q)
+	}, upper)
+	return math.Exp(num - den)
+}
+
+// ParsimonyLimit returns the connection limit of statistical parsimony for haplotypes
+// of m sites: the largest number of differences j such that ParsimonyProbability(j, m)
+// is >= prob (for all numbers of differences up to j). It is 0 if even a single
+// difference has a probability of parsimony < prob.
+func ParsimonyLimit(m int, prob float64) (limit int) {
+	for j := 1; j <= m; j++ {
+		if ParsimonyProbability(j, m) < prob {
+			break
+		}
+		limit = j
+	}
+	return
+}
+
+// logPow returns j*log(x), with 0*log(0)=0
+func logPow(x float64, j int) float64 {
+	if j == 0 {
+		return 0.
+	}
+	return float64(j) * math.Log(x)
+}
+
+// logIntegrate returns the log of the integral of exp(logf) between 0 and upper,
+// using the Simpson rule. Values are scaled by their maximum to avoid underflows.
+func logIntegrate(logf func(q float64) float64, upper float64) float64 {
+	h := upper / parsimonyIntervals
+	vals := make([]float64, parsimonyIntervals+1)
+	max := math.Inf(-1)
+	for i := range vals {
+		vals[i] = logf(float64(i) * h)
+		if vals[i] > max {
+			max = vals[i]
+		}
+	}
+	sum := 0.
+	for i, v := range vals {
+		w := 2.
+		if i == 0 || i == parsimonyIntervals {
+			w = 1.
+		} else if i%2 == 1 {
+			w = 4.
+		}
+		sum += w * math.Exp(v-max)
+	}
+	return max + math.Log(sum*h/3.)
+}
@@ -0,0 +1,135 @@
+package network
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+// TCSNetwork builds a statistical parsimony network of the haplotypes of the alignment,
+// following the TCS approach (Templeton et al. 1992, Clement et al. 2000, see Haplotypes):
+//  1. Pairs of sampled haplotypes are considered by increasing number of differences d,
+//     up to the connection limit: no limit if limit is 0, and if limit < 0, the largest
+//     number of differences whose probability of parsimony is >= 95% given the number of
+//     unmasked sites (see ParsimonyLimit);
+//  2. At each distance d, two haplotypes are connected if they belong to different
+//     networks before considering distance d, or if they belong to the same network and
+//     are more than d steps apart in the network (alternative connections);
+//  3. Two haplotypes at distance d are connected by a path of d single mutational steps,
+//     through d-1 intermediate (unsampled) haplotypes, differences being introduced in the
+//     order of the sites. Intermediates that already exist in the network are reused.
+//
+// All edges have a weight of 1. Intermediate haplotypes are named int1, int2, etc. With a
+// connection limit, the result may consist of several disconnected networks.
+func TCSNetwork(al align.Alignment, limit int) (n *Network, err error) {
+	var pairs []Edge
+
+	n = &Network{}
+	if n.Nodes, n.Sites, err = Haplotypes(al); err != nil {
+		return
+	}
+	if limit < 0 {
+		if limit = ParsimonyLimit(n.Sites, DEFAULT_PARSIMONY_PROB); limit == 0 {
+			err = fmt.Errorf("no parsimony connection limit with %d sites: probability of parsimony of a single difference < %.2f", n.Sites, DEFAULT_PARSIMONY_PROB)
+			return
+		}
+	}
+	nsampled := len(n.Nodes)
+	d := distances(n.Nodes)
+	for i := 0; i < nsampled; i++ {
+		for j := i + 1; j < nsampled; j++ {
+			if limit == 0 || d[i][j] <= limit {
+				pairs = append(pairs, Edge{Source: i, Target: j, Weight: d[i][j]})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].Weight < pairs[j].Weight })
+
+	index := make(map[string]int)
+	for i, node := range n.Nodes {
+		index[string(node.Sequence)] = i
+	}
+	parent := make([]int, nsampled)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	nintermediates := 0
+	for start := 0; start < len(pairs); {
+		end := start
+		for end < len(pairs) && pairs[end].Weight == pairs[start].Weight {
+			end++
+		}
+		// Networks before considering this distance
+		comp := make([]int, nsampled)
+		for i := range comp {
+			comp[i] = find(i)
+		}
+		for _, p := range pairs[start:end] {
+			if comp[p.Source] == comp[p.Target] && n.steps(p.Source, p.Target) <= p.Weight {
+				continue
+			}
+			// Path of single steps from source to target
+			prev := p.Source
+			cur := append([]uint8(nil), n.Nodes[p.Source].Sequence...)
+			target := n.Nodes[p.Target].Sequence
+			for site := range cur {
+				if cur[site] == target[site] {
+					continue
+				}
+				cur[site] = target[site]
+				next, ok := index[string(cur)]
+				if !ok {
+					nintermediates++
+					next = len(n.Nodes)
+					n.Nodes = append(n.Nodes, &Node{Name: fmt.Sprintf("int%d", nintermediates), Sequence: append([]uint8(nil), cur...), Inferred: true})
+					index[string(cur)] = next
+				}
+				n.addEdge(prev, next, 1)
+				prev = next
+			}
+			parent[find(p.Source)] = find(p.Target)
+		}
+		start = end
+	}
+	n.sortEdges()
+	return
+}
+
+// steps returns the number of edges on the shortest path between the two nodes
+// of the network, or -1 if they are not connected
+func (n *Network) steps(source, target int) int {
+	neighbors := make([][]int, len(n.Nodes))
+	for _, e := range n.Edges {
+		neighbors[e.Source] = append(neighbors[e.Source], e.Target)
+		neighbors[e.Target] = append(neighbors[e.Target], e.Source)
+	}
+	dist := make([]int, len(n.Nodes))
+	for i := range dist {
+		dist[i] = -1
+	}
+	dist[source] = 0
+	queue := []int{source}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur == target {
+			return dist[cur]
+		}
+		for _, next := range neighbors[cur] {
+			if dist[next] < 0 {
+				dist[next] = dist[cur] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,162 @@
+package popgen
+
+import (
+	"math"
+	"unicode"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+// Diversity gathers diversity statistics and neutrality tests computed on
+// a set of sequences.
+type Diversity struct {
+	NbSeqs      int     // Number of sequences
+	SampleSize  int     // Sample size used in neutrality tests (NbSeqs, or average number of non missing characters per site if missing data are allowed)
+	Sites       int     // Number of analyzed sites
+	Segregating int     // Number of segregating sites (S)
+	Mutations   int     // Total number of mutations (Eta, >S if multi-allelic sites)
+	Singletons  int     // Number of singleton mutations (Eta_s)
+	Pi          float64 // Nucleotide diversity: average number of pairwise differences
+	ThetaW      float64 // Watterson's theta: S/a_n
+	TajimaD     float64 // Tajima's D (NaN if not defined)
+	FuLiDStar   float64 // Fu & Li's D* (without outgroup, NaN if not defined)
+	FuLiFStar   float64 // Fu & Li's F* (without outgroup, NaN if not defined)
+	Haplotypes  int     // Number of distinct haplotypes (on sites without missing data)
+	Hd          float64 // Haplotype diversity (NaN if less than 2 sequences)
+}
+
+// ComputeDiversity computes diversity statistics (segregating sites, nucleotide
+// diversity, Watterson's theta, haplotype diversity) and neutrality tests (Tajima's
+// D, Fu & Li's D* and F*) on the sequences of the alignment having the given indices
+// (all sequences if nil).
+//
+// Missing data (gaps, ambiguities) are handled as follows:
+//   - Sites having a proportion of missing data > maxMissing are not analyzed
+//     (maxMissing=0: only sites without missing data are analyzed);
+//   - At each analyzed site, statistics are computed on non missing characters,
+//     pi and theta being the sum over sites of per-site values (pairwise deletion);
+//   - Neutrality tests are computed with a sample size equal to the average
+//     number of non missing characters per analyzed site (rounded);
+//   - Haplotypes are defined on analyzed sites that do not contain missing data.
+func ComputeDiversity(al align.Alignment, indices []int, maxMissing float64) (d Diversity, err error) {
+	var seqs [][]uint8
+	var counts []int = make([]int, len(al.AlphabetCharacters()))
+	var complete []int = make([]int, 0)
+	var sumn int
+
+	if seqs, err = sequences(al, indices); err != nil {
+		return
+	}
+	d.NbSeqs = len(seqs)
+	if d.NbSeqs == 0 {
+		d.TajimaD, d.FuLiDStar, d.FuLiFStar, d.Hd = math.NaN(), math.NaN(), math.NaN(), math.NaN()
+		return
+	}
+
+	for site := 0; site < al.Length(); site++ {
+		n := siteCounts(al, seqs, site, counts)
+		if n < 2 || float64(d.NbSeqs-n)/float64(d.NbSeqs) > maxMissing {
+			continue
+		}
+		d.Sites++
+		sumn += n
+		if n == d.NbSeqs {
+			complete = append(complete, site)
+		}
+
+		alleles, singletons := 0, 0
+		for _, c := range counts {
+			if c > 0 {
+				alleles++
+				if c == 1 {
+					singletons++
+				}
+			}
+		}
+		if alleles > 1 {
+			d.Segregating++
+			d.Mutations += alleles - 1
+			// A biallelic site with n=2 has 2 singletons but only 1 mutation
+			d.Singletons += min(singletons, alleles-1)
+			d.Pi += siteDiversity(counts, n)
+			d.ThetaW += 1.0 / an(n)
+		}
+	}
+
+	if d.Sites > 0 {
+		d.SampleSize = int(math.Round(float64(sumn) / float64(d.Sites)))
+	}
+	d.TajimaD = tajimaD(d.SampleSize, d.Segregating, d.Pi)
+	d.FuLiDStar, d.FuLiFStar = fuLiStar(d.SampleSize, d.Mutations, d.Singletons, d.Pi)
+	d.Haplotypes, d.Hd = haplotypeDiversity(seqs, complete)
+	return
+}
+
+// tajimaD computes Tajima's D given the sample size n, the number of
+// segregating sites s, and the nucleotide diversity pi (Tajima, 1989).
+// Returns NaN if n < 4 or s = 0.
+func tajimaD(n, s int, pi float64) float64 {
+	if n < 4 || s == 0 {
+		return math.NaN()
+	}
+	nf, sf := float64(n), float64(s)
+	a1, a2 := an(n), bn(n)
+	b1 := (nf + 1) / (3 * (nf - 1))
+	b2 := 2 * (nf*nf + nf + 3) / (9 * nf * (nf - 1))
+	c1 := b1 - 1/a1
+	c2 := b2 - (nf+2)/(a1*nf) + a2/(a1*a1)
+	e1 := c1 / a1
+	e2 := c2 / (a1*a1 + a2)
+	return (pi - sf/a1) / math.Sqrt(e1*sf+e2*sf*(sf-1))
+}
+
+// fuLiStar computes Fu & Li's D* and F* statistics (without outgroup) given
+// the sample size n, the total number of mutations eta, the number of
+// singleton mutations etas and the nucleotide diversity pi (Fu & Li 1993,
+// with the corrections of Simonsen et al. 1995).
+// Returns NaN if n < 4 or eta = 0.
+func fuLiStar(n, eta, etas int, pi float64) (dstar, fstar float64) {
+	if n < 4 || eta == 0 {
+		return math.NaN(), math.NaN()
+	}
+	nf, e, es := float64(n), float64(eta), float64(etas)
+	a, b := an(n), bn(n)
+	an1 := an(n + 1)
+	cn := 2 * (nf*a - 2*(nf-1)) / ((nf - 1) * (nf - 2))
+	dn := cn + (nf-2)/((nf-1)*(nf-1)) + 2/(nf-1)*(1.5-(2*an1-3)/(nf-2)-1/nf)
+
+	vd := ((nf/(nf-1))*(nf/(nf-1))*b + a*a*dn - 2*nf*a*(a+1)/((nf-1)*(nf-1))) / (a*a + b)
+	ud := nf/(nf-1)*(a-nf/(nf-1)) - vd
+	dstar = (nf/(nf-1)*e - a*es) / math.Sqrt(ud*e+vd*e*e)
+
+	vf := ((2*nf*nf*nf+110*nf*nf-255*nf+153)/(9*nf*nf*(nf-1)) + 2*(nf-1)*a/(nf*nf) - 8*b/nf) / (a*a + b)
+	uf := (4*nf*nf+19*nf+3-12*(nf+1)*an1)/(3*nf*(nf-1))/a - vf
+	fstar = (pi - (nf-1)/nf*es) / math.Sqrt(uf*e+vf*e*e)
+	return
+}
+
+// haplotypeDiversity computes the number of distinct haplotypes and the
+// haplotype diversity (Nei, 1987) of the sequences, restricted to the given sites.
+func haplotypeDiversity(seqs [][]uint8, sites []int) (nhap int, hd float64) {
+	var haplotypes map[string]int = make(map[string]int)
+	var hap []uint8 = make([]uint8, len(sites))
+
+	n := len(seqs)
+	for _, s := range seqs {
+		for i, site := range sites {
+			hap[i] = uint8(unicode.ToUpper(rune(s[site])))
+		}
+		haplotypes[string(hap)]++
+	}
+	nhap = len(haplotypes)
+	if n < 2 {
+		return nhap, math.NaN()
+	}
+	hom := 0.0
+	for _, c := range haplotypes {
+		p := float64(c) / float64(n)
+		hom += p * p
+	}
+	hd = float64(n) / float64(n-1) * (1 - hom)
+	return
+}
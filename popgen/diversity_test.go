@@ -0,0 +1,88 @@
+package popgen
+
+import (
+	"math"
+	"testing"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+func testAlign() align.Alignment {
+	a := align.NewAlign(align.NUCLEOTIDS)
+	a.AddSequence("s1", "ACGTACGTAC", "")
+	a.AddSequence("s2", "ACGTACGTTC", "")
+	a.AddSequence("s3", "ACGAACGTTC", "")
+	a.AddSequence("s4", "TCGAACGTAC", "")
+	a.AddSequence("s5", "ACGAACCTAC", "")
+	return a
+}
+
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestComputeDiversity(t *testing.T) {
+	var d Diversity
+	var err error
+
+	a := testAlign()
+	if d, err = ComputeDiversity(a, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	if d.NbSeqs != 5 || d.SampleSize != 5 || d.Sites != 10 || d.Segregating != 4 || d.Mutations != 4 || d.Singletons != 2 {
+		t.Errorf("Wrong counts: %+v", d)
+	}
+	if !closeTo(d.Pi, 2.0) {
+		t.Errorf("Pi should be 2.0, is %f", d.Pi)
+	}
+	if !closeTo(d.ThetaW, 1.92) {
+		t.Errorf("ThetaW should be 1.92, is %f", d.ThetaW)
+	}
+	if !closeTo(d.TajimaD, 0.2734497664558783) {
+		t.Errorf("Tajima's D should be 0.27345, is %f", d.TajimaD)
+	}
+	if !closeTo(d.FuLiDStar, 0.2734497664558792) {
+		t.Errorf("Fu & Li's D* should be 0.27345, is %f", d.FuLiDStar)
+	}
+	if !closeTo(d.FuLiFStar, 0.278337250203161) {
+		t.Errorf("Fu & Li's F* should be 0.27834, is %f", d.FuLiFStar)
+	}
+	if d.Haplotypes != 5 || !closeTo(d.Hd, 1.0) {
+		t.Errorf("There should be 5 haplotypes and Hd=1.0: %d, %f", d.Haplotypes, d.Hd)
+	}
+
+	// Subset of sequences: s1 and s2
+	if d, err = ComputeDiversity(a, []int{0, 1}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if d.Segregating != 1 || !closeTo(d.Pi, 1.0) || !math.IsNaN(d.TajimaD) {
+		t.Errorf("Wrong statistics on sequence subset: %+v", d)
+	}
+}
+
+func TestComputeDiversityMissing(t *testing.T) {
+	var d Diversity
+	var err error
+
+	a := testAlign()
+	a.AddSequence("s6", "ACG-ACGTNC", "")
+
+	// Sites 3 and 8 are not analyzed
+	if d, err = ComputeDiversity(a, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	if d.Sites != 8 || d.Segregating != 2 || !closeTo(d.Pi, 2.0/3.0) || !closeTo(d.ThetaW, 2.0/an(6)) {
+		t.Errorf("Wrong statistics with missing data: %+v", d)
+	}
+	if d.Haplotypes != 3 || !closeTo(d.Hd, 0.6) {
+		t.Errorf("There should be 3 haplotypes and Hd=0.6: %d, %f", d.Haplotypes, d.Hd)
+	}
+
+	// All sites are analyzed, with pairwise deletion
+	if d, err = ComputeDiversity(a, nil, 0.2); err != nil {
+		t.Fatal(err)
+	}
+	if d.Sites != 10 || d.Segregating != 4 || d.SampleSize != 6 || !closeTo(d.Pi, 1.2+2.0/3.0) || !closeTo(d.ThetaW, 2.0/an(6)+2.0/an(5)) {
+		t.Errorf("Wrong statistics with missing data: %+v", d)
+	}
+}
@@ -0,0 +1,194 @@
+package popgen
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+// DStatistics gives the result of the ABBA-BABA test of introgression between
+// populations P1, P2 and P3, given an outgroup population O, with topology (((P1,P2),P3),O)
+type DStatistics struct {
+	Sites         int     // Number of biallelic sites with data in all the populations
+	ABBA, BABA    float64 // Sums of ABBA and BABA site pattern frequencies
+	D             float64 // Patterson's D: (ABBA-BABA)/(ABBA+BABA)
+	DStdErr       float64 // Block jackknife standard error of D
+	DZ            float64 // Z-score of D: D/DStdErr
+	DPValue       float64 // Two-sided p-value of the Z-score
+	F4Ratio       float64 // f4-ratio: admixture proportion of P3 into P2
+	F4RatioStdErr float64 // Block jackknife standard error of the f4-ratio
+	Blocks        int     // Number of jackknife blocks having at least one site
+}
+
+// ComputeDStatistics computes Patterson's D statistic (ABBA-BABA test, Green et al. 2010,
+// Durand et al. 2011) and the f4-ratio (Patterson et al. 2012) between populations p1, p2,
+// p3 and the outgroup, given as indices of the sequences of the alignment.
+//
+// At each biallelic site (exactly two unambiguous characters over the four populations)
+// having data in each population, the ancestral allele is the major allele of the outgroup
+// (the first one in alphabet order if tied), and p1, p2, p3 and p4 are the frequencies of
+// the derived allele in the populations (0 or 1 for single sequences), computed on the
+// sequences without missing data. The site contributes (1-p1)p2p3(1-p4) to ABBA and
+// p1(1-p2)p3(1-p4) to BABA.
+//
+// The f4-ratio is computed by splitting p3 into two halves, P3a (first sequences) and P3b:
+// f4-ratio = S(P1,P2,P3a,O)/S(P1,P3b,P3a,O), S being ABBA-BABA. It is NaN if p3 has
+// less than 2 sequences.
+//
+// Standard errors are computed by a delete-one block jackknife: the alignment is split into
+// nblocks contiguous blocks of sites of equal length, and statistics are computed removing
+// each block in turn (blocks without any site are ignored). The p-value of D is the
+// two-sided p-value of its Z-score under a normal distribution.
+func ComputeDStatistics(al align.Alignment, p1, p2, p3, outgroup []int, nblocks int) (ds DStatistics, err error) {
+	var pops [6][][]uint8
+	var p [6]float64
+	var ok [6]bool
+
+	if len(p1) == 0 || len(p2) == 0 || len(p3) == 0 || len(outgroup) == 0 {
+		err = fmt.Errorf("populations P1, P2, P3 and outgroup must have at least one sequence")
+		return
+	}
+	if nblocks < 1 {
+		err = fmt.Errorf("number of jackknife blocks must be >= 1: %d", nblocks)
+		return
+	}
+	// P1, P2, P3, O, P3a, P3b
+	for i, pop := range [][]int{p1, p2, p3, outgroup, p3[:len(p3)/2], p3[len(p3)/2:]} {
+		if pops[i], err = sequences(al, pop); err != nil {
+			return
+		}
+	}
+
+	abba := make([]float64, nblocks)
+	baba := make([]float64, nblocks)
+	num := make([]float64, nblocks)
+	den := make([]float64, nblocks)
+	sites := make([]int, nblocks)
+
+	counts := make([]int, len(al.AlphabetCharacters()))
+	for site := 0; site < al.Length(); site++ {
+		for i := range counts {
+			counts[i] = 0
+		}
+		for _, pop := range pops[:4] {
+			for _, s := range pop {
+				if c := al.AlphabetCharToIndex(s[site]); c >= 0 {
+					counts[c]++
+				}
+			}
+		}
+		var alleles []int
+		for c, n := range counts {
+			if n > 0 {
+				alleles = append(alleles, c)
+			}
+		}
+		if len(alleles) != 2 {
+			continue
+		}
+		// The derived allele is the minor allele of the outgroup
+		derived := alleles[1]
+		if p[3], ok[3] = derivedFrequency(al, pops[3], site, alleles[0]); ok[3] && p[3] < 0.5 {
+			derived = alleles[0]
+		}
+		for i := range pops {
+			p[i], ok[i] = derivedFrequency(al, pops[i], site, derived)
+		}
+		if !ok[0] || !ok[1] || !ok[2] || !ok[3] {
+			continue
+		}
+		b := site * nblocks / al.Length()
+		sites[b]++
+		abba[b] += (1 - p[0]) * p[1] * p[2] * (1 - p[3])
+		baba[b] += p[0] * (1 - p[1]) * p[2] * (1 - p[3])
+		if len(p3) > 1 && ok[4] && ok[5] {
+			// S(P1,P2,P3a,O) and S(P1,P3b,P3a,O)
+			num[b] += ((1-p[0])*p[1] - p[0]*(1-p[1])) * p[4] * (1 - p[3])
+			den[b] += ((1-p[0])*p[5] - p[0]*(1-p[5])) * p[4] * (1 - p[3])
+		}
+	}
+
+	var sumnum, sumden float64
+	for b := 0; b < nblocks; b++ {
+		ds.Sites += sites[b]
+		ds.ABBA += abba[b]
+		ds.BABA += baba[b]
+		sumnum += num[b]
+		sumden += den[b]
+	}
+	ds.D = dstat(ds.ABBA, ds.BABA)
+	ds.F4Ratio = math.NaN()
+	if len(p3) > 1 {
+		ds.F4Ratio = ratio(sumnum, sumden)
+	}
+
+	// Delete-one block jackknife
+	var jd, jf []float64
+	for b := 0; b < nblocks; b++ {
+		if sites[b] == 0 {
+			continue
+		}
+		jd = append(jd, dstat(ds.ABBA-abba[b], ds.BABA-baba[b]))
+		jf = append(jf, ratio(sumnum-num[b], sumden-den[b]))
+	}
+	ds.Blocks = len(jd)
+	ds.DStdErr = jackknifeStdErr(jd)
+	ds.F4RatioStdErr = math.NaN()
+	if len(p3) > 1 {
+		ds.F4RatioStdErr = jackknifeStdErr(jf)
+	}
+	ds.DZ = ds.D / ds.DStdErr
+	ds.DPValue = math.Erfc(math.Abs(ds.DZ) / math.Sqrt2)
+	return
+}
+
+// derivedFrequency returns the frequency of the given allele at the given site of the
+// sequences, without missing data, and false if all the sequences have missing data
+func derivedFrequency(al align.Alignment, seqs [][]uint8, site int, allele int) (p float64, ok bool) {
+	var n, nd int
+	for _, s := range seqs {
+		if c := al.AlphabetCharToIndex(s[site]); c >= 0 {
+			n++
+			if c == allele {
+				nd++
+			}
+		}
+	}
+	if n == 0 {
+		return
+	}
+	return float64(nd) / float64(n), true
+}
+
+// dstat returns (abba-baba)/(abba+baba), NaN if abba+baba is 0
+func dstat(abba, baba float64) float64 {
+	return ratio(abba-baba, abba+baba)
+}
+
+// ratio returns num/den, NaN if den is 0
+func ratio(num, den float64) float64 {
+	if den == 0 {
+		return math.NaN()
+	}
+	return num / den
+}
+
+// jackknifeStdErr returns the delete-one jackknife standard error given the
+// values of the statistic computed removing each block, NaN if less than 2 blocks
+func jackknifeStdErr(values []float64) float64 {
+	n := float64(len(values))
+	if len(values) < 2 {
+		return math.NaN()
+	}
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= n
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return math.Sqrt((n - 1) / n * variance)
+}
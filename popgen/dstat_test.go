@@ -0,0 +1,53 @@
+package popgen
+
+import (
+	"math"
+	"testing"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+func TestComputeDStatistics(t *testing.T) {
+	a := align.NewAlign(align.NUCLEOTIDS)
+	a.AddSequence("a1", "AAAAAAAAAAAAAAAAAAAA", "")
+	a.AddSequence("a2", "AAAAAAAAAAAAAAAAAAAA", "")
+	a.AddSequence("b1", "AAAAAAAAAAAATTTTTTTT", "")
+	a.AddSequence("b2", "AAAAAAAAAAAATTTTTTTA", "")
+	a.AddSequence("c1", "TTTTTTTTTTTTTTTTTTTT", "")
+	a.AddSequence("c2", "TTTTTTTTTTTTTTTTAATT", "")
+	a.AddSequence("o1", "AAAAAAAAAAAAAAAAAAAA", "")
+
+	ds, err := ComputeDStatistics(a, []int{0, 1}, []int{2, 3}, []int{4, 5}, []int{6}, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.Sites != 20 || ds.Blocks != 5 || !closeTo(ds.ABBA, 6.5) || !closeTo(ds.BABA, 0) || !closeTo(ds.D, 1) {
+		t.Errorf("Wrong D statistics: %+v", ds)
+	}
+	if !closeTo(ds.F4Ratio, 7.5/18.0) {
+		t.Errorf("Wrong f4-ratio: %f, expected %f", ds.F4Ratio, 7.5/18.0)
+	}
+
+	// P1 and P2 swapped: D = -1
+	if ds, err = ComputeDStatistics(a, []int{2}, []int{0}, []int{4}, []int{6}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if !closeTo(ds.ABBA, 0) || !closeTo(ds.BABA, 8) || !closeTo(ds.D, -1) {
+		t.Errorf("Wrong D statistics: %+v", ds)
+	}
+	if !math.IsNaN(ds.DStdErr) || !math.IsNaN(ds.F4Ratio) {
+		t.Errorf("Standard error with one block and f4-ratio with one P3 sequence should be NaN: %+v", ds)
+	}
+
+	if _, err = ComputeDStatistics(a, []int{0}, nil, []int{4}, []int{6}, 1); err == nil {
+		t.Errorf("Empty population should give an error")
+	}
+}
+
+func TestJackknifeStdErr(t *testing.T) {
+	// Delete-one jackknife of the mean of 1, 2, 3, 4: standard error of the mean
+	values := []float64{3, 8.0 / 3.0, 7.0 / 3.0, 2}
+	if se := jackknifeStdErr(values); !closeTo(se, math.Sqrt(5.0/12.0)) {
+		t.Errorf("Wrong jackknife standard error: %f, expected %f", se, math.Sqrt(5.0/12.0))
+	}
+}
@@ -0,0 +1,105 @@
+package popgen
+
+import (
+	"math"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+// Differentiation gathers statistics of differentiation between two
+// groups of sequences.
+type Differentiation struct {
+	NbSeqs1, NbSeqs2   int     // Number of sequences of each group
+	Sites              int     // Number of analyzed sites
+	Pi1, Pi2           float64 // Nucleotide diversity within each group
+	Dxy                float64 // Average number of differences between sequences of the two groups
+	Da                 float64 // Net divergence: Dxy - (Pi1+Pi2)/2
+	Fst                float64 // Hudson's Fst: 1 - ((Pi1+Pi2)/2)/Dxy (NaN if Dxy=0)
+	Fixed              int     // Number of sites monomorphic in both groups, with different characters
+	Shared             int     // Number of sites polymorphic in both groups
+	Private1, Private2 int     // Number of sites polymorphic only in group 1 (resp. group 2)
+}
+
+// ComputeDifferentiation computes differentiation statistics between the
+// sequences of the alignment having indices group1 and those having indices
+// group2: nucleotide diversities, Dxy, Da, Hudson's Fst (Hudson et al. 1992,
+// computed as a ratio of averages over sites, Bhatia et al. 2013), and
+// numbers of fixed differences, shared and private polymorphisms.
+//
+// Missing data are handled as in ComputeDiversity: sites having, in any group,
+// a proportion of missing data > maxMissing, or no data, are not analyzed,
+// and statistics are computed on non missing characters at each analyzed site.
+func ComputeDifferentiation(al align.Alignment, group1, group2 []int, maxMissing float64) (d Differentiation, err error) {
+	var seqs1, seqs2 [][]uint8
+	var counts1 []int = make([]int, len(al.AlphabetCharacters()))
+	var counts2 []int = make([]int, len(al.AlphabetCharacters()))
+
+	if seqs1, err = sequences(al, group1); err != nil {
+		return
+	}
+	if seqs2, err = sequences(al, group2); err != nil {
+		return
+	}
+	d.NbSeqs1, d.NbSeqs2 = len(seqs1), len(seqs2)
+	d.Fst = math.NaN()
+	if d.NbSeqs1 == 0 || d.NbSeqs2 == 0 {
+		return
+	}
+
+	for site := 0; site < al.Length(); site++ {
+		n1 := siteCounts(al, seqs1, site, counts1)
+		n2 := siteCounts(al, seqs2, site, counts2)
+		if n1 == 0 || n2 == 0 ||
+			float64(d.NbSeqs1-n1)/float64(d.NbSeqs1) > maxMissing ||
+			float64(d.NbSeqs2-n2)/float64(d.NbSeqs2) > maxMissing {
+			continue
+		}
+		d.Sites++
+
+		d.Pi1 += siteDiversity(counts1, n1)
+		d.Pi2 += siteDiversity(counts2, n2)
+		same := 0.0
+		alleles1, alleles2 := 0, 0
+		for i := range counts1 {
+			same += float64(counts1[i]) / float64(n1) * float64(counts2[i]) / float64(n2)
+			if counts1[i] > 0 {
+				alleles1++
+			}
+			if counts2[i] > 0 {
+				alleles2++
+			}
+		}
+		d.Dxy += 1.0 - same
+
+		switch {
+		case alleles1 > 1 && alleles2 > 1:
+			d.Shared++
+		case alleles1 > 1:
+			d.Private1++
+		case alleles2 > 1:
+			d.Private2++
+		case same == 0:
+			d.Fixed++
+		}
+	}
+	d.Da = d.Dxy - (d.Pi1+d.Pi2)/2.0
+	if d.Dxy > 0 {
+		d.Fst = d.Da / d.Dxy
+	}
+	return
+}
+
+// siteDiversity returns the probability that two characters sampled
+// without replacement are different, given the character counts
+// and their sum n. Returns 0 if n < 2.
+func siteDiversity(counts []int, n int) float64 {
+	if n < 2 {
+		return 0
+	}
+	hom := 0.0
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		hom += p * p
+	}
+	return float64(n) / float64(n-1) * (1.0 - hom)
+}
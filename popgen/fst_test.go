@@ -0,0 +1,39 @@
+package popgen
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputeDifferentiation(t *testing.T) {
+	var d Differentiation
+	var err error
+
+	a := testAlign()
+	if d, err = ComputeDifferentiation(a, []int{0, 1, 2}, []int{3, 4}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if d.NbSeqs1 != 3 || d.NbSeqs2 != 2 || d.Sites != 10 {
+		t.Errorf("Wrong counts: %+v", d)
+	}
+	if !closeTo(d.Pi1, 4.0/3.0) || !closeTo(d.Pi2, 2.0) || !closeTo(d.Dxy, 7.0/3.0) || !closeTo(d.Da, 2.0/3.0) || !closeTo(d.Fst, 2.0/7.0) {
+		t.Errorf("Wrong differentiation statistics: %+v", d)
+	}
+	if d.Fixed != 0 || d.Shared != 0 || d.Private1 != 2 || d.Private2 != 2 {
+		t.Errorf("Wrong polymorphism counts: %+v", d)
+	}
+
+	if d, err = ComputeDifferentiation(a, []int{0}, []int{3}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if d.Fixed != 2 || !closeTo(d.Dxy, 2.0) || !closeTo(d.Fst, 1.0) {
+		t.Errorf("Wrong fixed differences: %+v", d)
+	}
+
+	if d, err = ComputeDifferentiation(a, []int{0}, []int{0}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsNaN(d.Fst) {
+		t.Errorf("Fst between identical sequences should be NaN, is %f", d.Fst)
+	}
+}
@@ -0,0 +1,157 @@
+package popgen
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+// LD gives the linkage disequilibrium between two biallelic sites
+type LD struct {
+	Site1, Site2       int      // Alignment sites (0-based)
+	Alleles1, Alleles2 [2]uint8 // Major and minor alleles of each site (on all the sequences)
+	N                  int      // Number of sequences without missing data at both sites
+	D                  float64  // Coefficient of linkage disequilibrium: p(A1B1) - p(A1)p(B1)
+	DPrime             float64  // D/Dmax (Lewontin 1964)
+	R2                 float64  // Squared correlation: D^2/(p(A1)p(A2)p(B1)p(B2))
+}
+
+// BiallelicSites returns the sites of the alignment having exactly two different
+// unambiguous characters, and their major and minor alleles (ties are broken by
+// alphabet order). Only sites whose position is >= 0 are considered if positions
+// is not nil.
+func BiallelicSites(al align.Alignment, positions []int) (sites []int, alleles [][2]uint8, err error) {
+	var seqs [][]uint8
+	var counts []int
+
+	if seqs, err = sequences(al, nil); err != nil {
+		return
+	}
+	chars := al.AlphabetCharacters()
+	counts = make([]int, len(chars))
+	for site := 0; site < al.Length(); site++ {
+		if positions != nil && positions[site] < 0 {
+			continue
+		}
+		siteCounts(al, seqs, site, counts)
+		var a1, a2 = -1, -1
+		nalleles := 0
+		for c, n := range counts {
+			if n == 0 {
+				continue
+			}
+			nalleles++
+			if a1 < 0 || n > counts[a1] {
+				a1, a2 = c, a1
+			} else if a2 < 0 || n > counts[a2] {
+				a2 = c
+			}
+		}
+		if nalleles == 2 {
+			sites = append(sites, site)
+			alleles = append(alleles, [2]uint8{chars[a1], chars[a2]})
+		}
+	}
+	return
+}
+
+// ComputeLD computes the linkage disequilibrium (D, D' and r²) between all the pairs
+// of biallelic sites (see BiallelicSites) of the alignment.
+//
+// If positions is not nil, it gives the increasing coordinate of each site of the alignment
+// (ex: on a reference sequence), sites having a negative position being ignored. If maxdist
+// is > 0, only the pairs of sites whose distance (difference of positions) is <= maxdist
+// are considered.
+//
+// Missing data (gaps and ambiguous characters) are handled by pairwise deletion: for
+// each pair of sites, only the sequences having an unambiguous character at both sites
+// are considered, and allele frequencies are computed on these sequences. If one of the
+// sites is monomorphic on these sequences, D is 0 and D' and r² are NaN.
+func ComputeLD(al align.Alignment, positions []int, maxdist int) (ld []LD, err error) {
+	var sites []int
+	var alleles [][2]uint8
+	var seqs [][]uint8
+
+	if positions != nil && len(positions) != al.Length() {
+		err = fmt.Errorf("number of positions (%d) is different from the alignment length (%d)", len(positions), al.Length())
+		return
+	}
+	if sites, alleles, err = BiallelicSites(al, positions); err != nil {
+		return
+	}
+	if seqs, err = sequences(al, nil); err != nil {
+		return
+	}
+	pos := func(site int) int {
+		if positions == nil {
+			return site
+		}
+		return positions[site]
+	}
+
+	// Allele of each sequence at each biallelic site: 0 (major), 1 (minor) or -1 (missing)
+	states := make([][]int, len(sites))
+	for i, site := range sites {
+		a1, a2 := al.AlphabetCharToIndex(alleles[i][0]), al.AlphabetCharToIndex(alleles[i][1])
+		states[i] = make([]int, len(seqs))
+		for s, seq := range seqs {
+			switch al.AlphabetCharToIndex(seq[site]) {
+			case a1:
+				states[i][s] = 0
+			case a2:
+				states[i][s] = 1
+			default:
+				states[i][s] = -1
+			}
+		}
+	}
+
+	for i := range sites {
+		for j := i + 1; j < len(sites); j++ {
+			if maxdist > 0 && pos(sites[j])-pos(sites[i]) > maxdist {
+				break
+			}
+			l := LD{Site1: sites[i], Site2: sites[j], Alleles1: alleles[i], Alleles2: alleles[j]}
+			l.D, l.DPrime, l.R2, l.N = linkageDisequilibrium(states[i], states[j])
+			ld = append(ld, l)
+		}
+	}
+	return
+}
+
+// linkageDisequilibrium computes D, D' and r² between two sites, given the allele
+// (0, 1 or -1 for missing) of each sequence at each site
+func linkageDisequilibrium(site1, site2 []int) (d, dprime, r2 float64, n int) {
+	var n1, m1, n11 int // Sequences with allele 0 at site 1, at site 2, at both
+	for s := range site1 {
+		if site1[s] < 0 || site2[s] < 0 {
+			continue
+		}
+		n++
+		if site1[s] == 0 {
+			n1++
+		}
+		if site2[s] == 0 {
+			m1++
+		}
+		if site1[s] == 0 && site2[s] == 0 {
+			n11++
+		}
+	}
+	dprime, r2 = math.NaN(), math.NaN()
+	if n == 0 || n1 == 0 || n1 == n || m1 == 0 || m1 == n {
+		return
+	}
+	pa, pb, pab := float64(n1)/float64(n), float64(m1)/float64(n), float64(n11)/float64(n)
+	d = pab - pa*pb
+	var dmax float64
+	if d >= 0 {
+		dmax = math.Min(pa*(1-pb), (1-pa)*pb)
+	} else {
+		dmax = math.Min(pa*pb, (1-pa)*(1-pb))
+	}
+	dprime = d / dmax
+	r2 = d * d / (pa * (1 - pa) * pb * (1 - pb))
+	return
+}
@@ -0,0 +1,86 @@
+package popgen
+
+import (
+	"math"
+	"testing"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+func TestBiallelicSites(t *testing.T) {
+	a := testAlign()
+	sites, alleles, err := BiallelicSites(a, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expsites := []int{0, 3, 6, 8}
+	expalleles := [][2]uint8{{'A', 'T'}, {'A', 'T'}, {'G', 'C'}, {'A', 'T'}}
+	if len(sites) != len(expsites) {
+		t.Fatalf("Wrong biallelic sites: %v, expected %v", sites, expsites)
+	}
+	for i := range sites {
+		if sites[i] != expsites[i] || alleles[i] != expalleles[i] {
+			t.Errorf("Wrong biallelic site %d: %d %c, expected %d %c", i, sites[i], alleles[i], expsites[i], expalleles[i])
+		}
+	}
+}
+
+func TestComputeLD(t *testing.T) {
+	a := testAlign()
+	ld, err := ComputeLD(a, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ld) != 6 {
+		t.Fatalf("Wrong number of pairs of sites: %d, expected 6", len(ld))
+	}
+	l := ld[0]
+	if l.Site1 != 0 || l.Site2 != 3 || l.N != 5 {
+		t.Errorf("Wrong pair of sites: %+v", l)
+	}
+	if !closeTo(l.D, -0.08) || !closeTo(l.DPrime, -1) || !closeTo(l.R2, 1.0/6.0) {
+		t.Errorf("Wrong linkage disequilibrium: %+v", l)
+	}
+
+	// Maximum distance
+	if ld, err = ComputeLD(a, nil, 3); err != nil {
+		t.Fatal(err)
+	}
+	if len(ld) != 3 {
+		t.Errorf("Wrong number of pairs of sites at distance <= 3: %d, expected 3", len(ld))
+	}
+
+	// Ignored sites
+	positions := []int{-1, 0, 1, 2, 3, 4, 5, 6, 7, 8}
+	if ld, err = ComputeLD(a, positions, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(ld) != 3 || ld[0].Site1 != 3 {
+		t.Errorf("Wrong pairs of sites with ignored sites: %+v", ld)
+	}
+}
+
+func TestComputeLDMissing(t *testing.T) {
+	a := align.NewAlign(align.NUCLEOTIDS)
+	a.AddSequence("s1", "A-CGTAC", "")
+	a.AddSequence("s2", "A-CGTTT", "")
+	a.AddSequence("s3", "AACATTT", "")
+	a.AddSequence("s4", "ATCANAC", "")
+	a.AddSequence("s5", "A-CG-TC", "")
+
+	ld, err := ComputeLD(a, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ld) != 6 {
+		t.Fatalf("Wrong number of pairs of sites: %d, expected 6", len(ld))
+	}
+	// Site 1 vs site 3: the two sequences without missing data are monomorphic at site 3
+	if ld[0].N != 2 || ld[0].D != 0 || !math.IsNaN(ld[0].DPrime) || !math.IsNaN(ld[0].R2) {
+		t.Errorf("Wrong linkage disequilibrium with missing data: %+v", ld[0])
+	}
+	// Site 1 vs site 5: complete association on the two sequences without missing data
+	if ld[1].N != 2 || !closeTo(ld[1].D, 0.25) || !closeTo(ld[1].DPrime, 1) || !closeTo(ld[1].R2, 1) {
+		t.Errorf("Wrong linkage disequilibrium with missing data: %+v", ld[1])
+	}
+}
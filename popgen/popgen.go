@@ -0,0 +1,65 @@
+// Package popgen computes population genetics statistics from alignments
+// (diversity, neutrality tests, etc.).
+//
+// Only unambiguous characters of the alignment alphabet (A, C, G, T for
+// nucleotides, the 20 standard amino acids for proteins) are considered as
+// data: gaps, N/X and other ambiguity codes are considered as missing data.
+package popgen
+
+import (
+	"fmt"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+// sequences returns the characters of the sequences of the alignment having
+// the given indices. If indices is nil, returns all the sequences.
+func sequences(al align.Alignment, indices []int) (seqs [][]uint8, err error) {
+	if indices == nil {
+		indices = make([]int, al.NbSequences())
+		for i := range indices {
+			indices[i] = i
+		}
+	}
+	seqs = make([][]uint8, len(indices))
+	for i, idx := range indices {
+		var ok bool
+		if seqs[i], ok = al.GetSequenceCharById(idx); !ok {
+			err = fmt.Errorf("sequence %d does not exist in the alignment", idx)
+			return
+		}
+	}
+	return
+}
+
+// siteCounts fills counts with the number of occurences of each character
+// of the alphabet at the given site of the sequences, and returns the
+// number of sequences having a non missing character at this site.
+func siteCounts(al align.Alignment, seqs [][]uint8, site int, counts []int) (n int) {
+	for i := range counts {
+		counts[i] = 0
+	}
+	for _, s := range seqs {
+		if idx := al.AlphabetCharToIndex(s[site]); idx >= 0 {
+			counts[idx]++
+			n++
+		}
+	}
+	return
+}
+
+// an returns sum(1/i), for i in [1,n-1]
+func an(n int) (a float64) {
+	for i := 1; i < n; i++ {
+		a += 1.0 / float64(i)
+	}
+	return
+}
+
+// bn returns sum(1/i^2), for i in [1,n-1]
+func bn(n int) (b float64) {
+	for i := 1; i < n; i++ {
+		b += 1.0 / (float64(i) * float64(i))
+	}
+	return
+}
@@ -0,0 +1,140 @@
+package recomb
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/evolbioinfo/goalign/align"
+	"github.com/evolbioinfo/goalign/distance/dna"
+)
+
+// BootscanWindow gives the bootscan support of a reference in a window of the alignment
+type BootscanWindow struct {
+	Start, End int     // First (0-based) and last (excluded) sites of the window
+	Reference  string  // Name of the reference
+	Distance   float64 // Distance between the query and the reference in the window
+	Support    float64 // Percentage of bootstrap replicates in which the reference is the closest to the query
+}
+
+// Bootscan computes, for each sliding window of the alignment, the percentage of
+// bootstrap replicates of the window in which the query sequence clusters with each
+// reference, i.e. in which the reference is the closest to the query (nearest neighbor
+// distance clustering).
+//
+// Distances are computed with the given nucleotide distance model (see dna.Model).
+// The distance between the query and a group of reference sequences is the minimum
+// distance between the query and the sequences of the group. If several references
+// are equally close to the query, the replicate is shared between them.
+//
+// Bootstrap replicates are drawn by resampling the sites of the window with replacement
+// (site weights). Windows are [start,start+windowsize[, with start incremented by windowstep.
+func Bootscan(al align.Alignment, query int, refs []Reference, distmodel string, windowsize, windowstep, nboot int, rand *rand.Rand, cpus int) (windows []BootscanWindow, err error) {
+	var model dna.DistModel
+	var subal align.Alignment
+	var dists, support []float64
+
+	if al.Alphabet() != align.NUCLEOTIDS {
+		err = fmt.Errorf("alignment must be nucleotidic")
+		return
+	}
+	if err = checkReferences(al, query, refs, 2); err != nil {
+		return
+	}
+	if windowsize <= 0 || windowsize > al.Length() {
+		err = fmt.Errorf("window size must be > 0 and <= alignment length: %d", windowsize)
+		return
+	}
+	if windowstep <= 0 {
+		err = fmt.Errorf("window step must be > 0: %d", windowstep)
+		return
+	}
+	if nboot <= 0 {
+		err = fmt.Errorf("number of bootstrap replicates must be > 0: %d", nboot)
+		return
+	}
+	if model, err = dna.Model(distmodel, false); err != nil {
+		return
+	}
+
+	weights := make([]float64, windowsize)
+	support = make([]float64, len(refs))
+	for start := 0; start+windowsize <= al.Length(); start += windowstep {
+		if subal, err = al.SubAlign(start, windowsize); err != nil {
+			return
+		}
+		// Distances on the original window
+		if dists, err = queryDistances(subal, query, refs, model, nil, cpus); err != nil {
+			return
+		}
+		for i := range support {
+			support[i] = 0
+		}
+		for b := 0; b < nboot; b++ {
+			var bootdists []float64
+			for i := range weights {
+				weights[i] = 0
+			}
+			for i := 0; i < windowsize; i++ {
+				weights[rand.Intn(windowsize)]++
+			}
+			if bootdists, err = queryDistances(subal, query, refs, model, weights, cpus); err != nil {
+				return
+			}
+			closest := closestReferences(bootdists)
+			for _, r := range closest {
+				support[r] += 1.0 / float64(len(closest))
+			}
+		}
+		for i, r := range refs {
+			windows = append(windows, BootscanWindow{
+				Start:     start,
+				End:       start + windowsize,
+				Reference: r.Name,
+				Distance:  dists[i],
+				Support:   100.0 * support[i] / float64(nboot),
+			})
+		}
+	}
+	return
+}
+
+// queryDistances returns the distances between the query and each reference,
+// computed on the given sub alignment with the given site weights
+func queryDistances(al align.Alignment, query int, refs []Reference, model dna.DistModel, weights []float64, cpus int) (dists []float64, err error) {
+	var matrix [][]float64
+
+	if matrix, err = dna.DistMatrix(al, weights, model, query, query, 0, al.NbSequences()-1, false, 0.0, cpus); err != nil {
+		return
+	}
+	dists = make([]float64, len(refs))
+	for i, r := range refs {
+		dists[i] = math.Inf(1)
+		for _, idx := range r.Indices {
+			if d := matrix[query][idx]; !math.IsNaN(d) && d < dists[i] {
+				dists[i] = d
+			}
+		}
+		if math.IsInf(dists[i], 1) {
+			dists[i] = math.NaN()
+		}
+	}
+	return
+}
+
+// closestReferences returns the indices of the references having
+// the minimum distance (NaN distances are ignored)
+func closestReferences(dists []float64) (closest []int) {
+	min := math.Inf(1)
+	for i, d := range dists {
+		switch {
+		case math.IsNaN(d):
+		case d < min-1e-12:
+			min = d
+			closest = []int{i}
+		case math.Abs(d-min) <= 1e-12:
+			closest = append(closest, i)
+		}
+	}
+	return
+}
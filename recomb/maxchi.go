@@ -0,0 +1,136 @@
+package recomb
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+// Breakpoint is a recombination breakpoint proposed by MaxChi, between the
+// parts of the query sequence closer to Reference1 and to Reference2
+type Breakpoint struct {
+	Reference1, Reference2 string
+	Sites                  int     // Number of sites informative for the pair of references
+	Position               int     // First site (0-based) after the breakpoint
+	Left1, Left2           int     // Number of informative sites before the breakpoint where the query matches reference 1 / reference 2
+	Right1, Right2         int     // Number of informative sites after the breakpoint where the query matches reference 1 / reference 2
+	ChiSquare              float64 // Maximum chi-square
+	PValue                 float64 // Permutation p-value of the maximum chi-square
+}
+
+// MaxChi proposes a recombination breakpoint in the query sequence, for each pair
+// of references, using the maximum chi-square method (Maynard Smith 1992).
+//
+// For a pair of references, sites informative for the pair are the sites where the
+// two references have different characters (majority characters for groups), and where
+// the query has the character of one of them. For each possible breakpoint between two
+// consecutive informative sites, a 2x2 contingency table is built: number of sites where
+// the query matches reference 1 / reference 2, before / after the breakpoint. The proposed
+// breakpoint is the one maximizing the chi-square of this table.
+//
+// The p-value is computed by permuting the order of the informative sites nperm times,
+// as the proportion of permutations whose maximum chi-square is >= the observed one:
+// (count+1)/(nperm+1). If nperm <= 0, the p-value is NaN.
+//
+// Pairs of references having less than 2 informative sites, or for which the query
+// matches only one of the references, give a breakpoint with position -1, a chi-square
+// of 0 and a NaN p-value.
+func MaxChi(al align.Alignment, query int, refs []Reference, nperm int, rand *rand.Rand) (breakpoints []Breakpoint, err error) {
+	var qseq []uint8
+	var refchars [][]int
+
+	if err = checkReferences(al, query, refs, 2); err != nil {
+		return
+	}
+	qseq, _ = al.GetSequenceCharById(query)
+	refchars = make([][]int, len(refs))
+	for i, r := range refs {
+		refchars[i] = referenceCharacters(al, r)
+	}
+
+	for i := range refs {
+		for j := i + 1; j < len(refs); j++ {
+			var sites []int
+			var matches []bool
+			for site, c := range qseq {
+				q := al.AlphabetCharToIndex(c)
+				c1, c2 := refchars[i][site], refchars[j][site]
+				if q < 0 || c1 < 0 || c2 < 0 || c1 == c2 || (q != c1 && q != c2) {
+					continue
+				}
+				sites = append(sites, site)
+				matches = append(matches, q == c1)
+			}
+
+			bp := Breakpoint{Reference1: refs[i].Name, Reference2: refs[j].Name, Sites: len(sites), Position: -1, PValue: math.NaN()}
+			k, chi := maxChiSquare(matches)
+			if k > 0 {
+				bp.Position = sites[k]
+				bp.ChiSquare = chi
+				for l, m := range matches {
+					switch {
+					case l < k && m:
+						bp.Left1++
+					case l < k:
+						bp.Left2++
+					case m:
+						bp.Right1++
+					default:
+						bp.Right2++
+					}
+				}
+				if nperm > 0 {
+					count := 0
+					perm := make([]bool, len(matches))
+					copy(perm, matches)
+					for p := 0; p < nperm; p++ {
+						rand.Shuffle(len(perm), func(a, b int) { perm[a], perm[b] = perm[b], perm[a] })
+						if _, c := maxChiSquare(perm); c >= chi-1e-9 {
+							count++
+						}
+					}
+					bp.PValue = float64(count+1) / float64(nperm+1)
+				}
+			}
+			breakpoints = append(breakpoints, bp)
+		}
+	}
+	return
+}
+
+// maxChiSquare returns the position (index in matches) of the first site after the
+// breakpoint maximizing the chi-square, and the maximum chi-square, given the sequence
+// of matches of the query to reference 1 (true) or reference 2 (false).
+func maxChiSquare(matches []bool) (pos int, max float64) {
+	var n1 int
+	pos = -1
+	for _, m := range matches {
+		if m {
+			n1++
+		}
+	}
+	n := len(matches)
+	left1 := 0
+	for k := 1; k < n; k++ {
+		if matches[k-1] {
+			left1++
+		}
+		if chi := chiSquare2x2(left1, k-left1, n1-left1, n-k-(n1-left1)); chi > max {
+			max = chi
+			pos = k
+		}
+	}
+	return
+}
+
+// chiSquare2x2 returns the chi-square statistic of the contingency table [[a,b],[c,d]],
+// or 0 if a marginal count is null
+func chiSquare2x2(a, b, c, d int) float64 {
+	r1, r2, c1, c2 := float64(a+b), float64(c+d), float64(a+c), float64(b+d)
+	if r1 == 0 || r2 == 0 || c1 == 0 || c2 == 0 {
+		return 0
+	}
+	diff := float64(a)*float64(d) - float64(b)*float64(c)
+	return (r1 + r2) * diff * diff / (r1 * r2 * c1 * c2)
+}
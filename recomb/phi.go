@@ -0,0 +1,152 @@
+package recomb
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+// PhiTest gives the result of the Pairwise Homoplasy Index test
+type PhiTest struct {
+	Sites        int     // Number of informative sites
+	Window       int     // Window size (in informative sites)
+	Phi          float64 // Observed PHI statistic
+	Mean, Stddev float64 // Mean and standard deviation of PHI over permutations
+	PValue       float64 // Permutation p-value: proportion of permutations with PHI <= observed PHI
+}
+
+// Phi computes the Pairwise Homoplasy Index test (Bruen et al. 2006).
+//
+// The PHI statistic is the mean refined incompatibility between pairs of informative
+// sites (see align.InformativeSites) that are at most window informative sites apart.
+// The refined incompatibility of two sites is the minimum number of homoplasies needed
+// to explain them on a tree: the number of edges minus the number of vertices plus the
+// number of connected components of the bipartite graph linking the characters of the
+// first site to the characters of the second site observed in the same sequences
+// (1 for two biallelic sites showing the 4 gametes, 0 for compatible sites). Sequences
+// having a gap or an ambiguous character at one of the sites are ignored.
+//
+// Without recombination, the order of the sites does not matter, and the p-value is
+// computed by permuting the informative sites nperm times, as the proportion of
+// permutations giving a PHI <= observed PHI: (count+1)/(nperm+1).
+// If there are less than 3 informative sites, PHI and p-value are NaN.
+func Phi(al align.Alignment, window, nperm int, rand *rand.Rand) (test PhiTest, err error) {
+	var sites []int
+	var incompat [][]float64
+
+	if window < 1 {
+		err = fmt.Errorf("PHI window size must be >= 1: %d", window)
+		return
+	}
+
+	sites = al.InformativeSites()
+	test = PhiTest{Sites: len(sites), Window: window, Phi: math.NaN(), Mean: math.NaN(), Stddev: math.NaN(), PValue: math.NaN()}
+	if len(sites) < 3 {
+		return
+	}
+	incompat = siteIncompatibilities(al, sites)
+
+	order := make([]int, len(sites))
+	for i := range order {
+		order[i] = i
+	}
+	test.Phi = phiStatistic(incompat, order, window)
+
+	if nperm > 0 {
+		count := 0
+		sum, sum2 := 0.0, 0.0
+		for p := 0; p < nperm; p++ {
+			rand.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+			phi := phiStatistic(incompat, order, window)
+			sum += phi
+			sum2 += phi * phi
+			if phi <= test.Phi+1e-12 {
+				count++
+			}
+		}
+		test.Mean = sum / float64(nperm)
+		test.Stddev = math.Sqrt(math.Max(0, sum2/float64(nperm)-test.Mean*test.Mean))
+		test.PValue = float64(count+1) / float64(nperm+1)
+	}
+	return
+}
+
+// phiStatistic returns the mean incompatibility between pairs of sites that are at
+// most window sites apart, the sites being in the given order
+func phiStatistic(incompat [][]float64, order []int, window int) float64 {
+	sum, n := 0.0, 0
+	for i := range order {
+		for j := i + 1; j < len(order) && j-i <= window; j++ {
+			sum += incompat[order[i]][order[j]]
+			n++
+		}
+	}
+	return sum / float64(n)
+}
+
+// siteIncompatibilities returns the refined incompatibility matrix of the given sites
+func siteIncompatibilities(al align.Alignment, sites []int) (incompat [][]float64) {
+	// Character indices of the sequences at each site
+	chars := make([][]int, len(sites))
+	seqs := al.Sequences()
+	for i, site := range sites {
+		chars[i] = make([]int, len(seqs))
+		for s, seq := range seqs {
+			chars[i][s] = al.AlphabetCharToIndex(seq.SequenceChar()[site])
+		}
+	}
+
+	incompat = make([][]float64, len(sites))
+	for i := range incompat {
+		incompat[i] = make([]float64, len(sites))
+	}
+	for i := range sites {
+		for j := i + 1; j < len(sites); j++ {
+			incompat[i][j] = float64(refinedIncompatibility(chars[i], chars[j]))
+			incompat[j][i] = incompat[i][j]
+		}
+	}
+	return
+}
+
+// refinedIncompatibility returns the cyclomatic number of the bipartite graph linking
+// the characters of site 1 to the characters of site 2 observed in the same sequences
+// (negative character indices are missing data)
+func refinedIncompatibility(site1, site2 []int) int {
+	// Vertices: characters of site 1 (c) and characters of site 2 (1000+c)
+	parent := make(map[int]int)
+	var find func(v int) int
+	find = func(v int) int {
+		if parent[v] != v {
+			parent[v] = find(parent[v])
+		}
+		return parent[v]
+	}
+	edges := make(map[[2]int]bool)
+	for s := range site1 {
+		c1, c2 := site1[s], site2[s]
+		if c1 < 0 || c2 < 0 {
+			continue
+		}
+		v1, v2 := c1, 1000+c2
+		if _, ok := parent[v1]; !ok {
+			parent[v1] = v1
+		}
+		if _, ok := parent[v2]; !ok {
+			parent[v2] = v2
+		}
+		if !edges[[2]int{v1, v2}] {
+			edges[[2]int{v1, v2}] = true
+			parent[find(v1)] = find(v2)
+		}
+	}
+	components := 0
+	for v := range parent {
+		if find(v) == v {
+			components++
+		}
+	}
+	return len(edges) - len(parent) + components
+}
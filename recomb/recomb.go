@@ -0,0 +1,91 @@
+// Package recomb implements recombination analyses on alignments
+// (bootscanning, breakpoint detection, PHI and 3SEQ-like tests).
+//
+// References may be single sequences or groups of sequences. Only
+// unambiguous characters of the alignment alphabet are considered as
+// data: gaps, N/X and other ambiguity codes are considered as missing data.
+package recomb
+
+import (
+	"fmt"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+// Reference is a reference sequence, or a group of reference sequences,
+// to which a query sequence is compared.
+type Reference struct {
+	Name    string
+	Indices []int // Indices of the sequences of the reference in the alignment
+}
+
+// SequenceReferences returns one reference per sequence of the alignment,
+// except the query sequence.
+func SequenceReferences(al align.Alignment, query int) (refs []Reference) {
+	for i, s := range al.Sequences() {
+		if i != query {
+			refs = append(refs, Reference{Name: s.Name(), Indices: []int{i}})
+		}
+	}
+	return
+}
+
+// checkReferences returns an error if the query or the references are not
+// valid sequence indices of the alignment, or if there are less than nmin references.
+func checkReferences(al align.Alignment, query int, refs []Reference, nmin int) (err error) {
+	if query < 0 || query >= al.NbSequences() {
+		return fmt.Errorf("query sequence %d does not exist in the alignment", query)
+	}
+	if len(refs) < nmin {
+		return fmt.Errorf("at least %d references are needed", nmin)
+	}
+	for _, r := range refs {
+		if len(r.Indices) == 0 {
+			return fmt.Errorf("reference %s has no sequence", r.Name)
+		}
+		for _, i := range r.Indices {
+			if i < 0 || i >= al.NbSequences() {
+				return fmt.Errorf("sequence %d of reference %s does not exist in the alignment", i, r.Name)
+			}
+			if i == query {
+				return fmt.Errorf("reference %s contains the query sequence", r.Name)
+			}
+		}
+	}
+	return
+}
+
+// referenceCharacters returns, for each site of the alignment, the index of the
+// majority character of the sequences of the reference (see align.AlphabetCharToIndex),
+// or -1 if all characters are missing or if several characters are equally frequent.
+func referenceCharacters(al align.Alignment, ref Reference) (chars []int) {
+	var counts = make([]int, len(al.AlphabetCharacters()))
+
+	seqs := make([][]uint8, len(ref.Indices))
+	for i, idx := range ref.Indices {
+		seqs[i], _ = al.GetSequenceCharById(idx)
+	}
+
+	chars = make([]int, al.Length())
+	for site := range chars {
+		for i := range counts {
+			counts[i] = 0
+		}
+		for _, s := range seqs {
+			if c := al.AlphabetCharToIndex(s[site]); c >= 0 {
+				counts[c]++
+			}
+		}
+		chars[site] = -1
+		max := 0
+		for c, n := range counts {
+			if n > max {
+				max = n
+				chars[site] = c
+			} else if n == max && n > 0 {
+				chars[site] = -1
+			}
+		}
+	}
+	return
+}
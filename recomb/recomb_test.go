@@ -0,0 +1,181 @@
+package recomb
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+// Query: first half identical to r1, second half identical to r2
+func recombinantAlign() align.Alignment {
+	r1 := strings.Repeat("ACGTACGTAC", 10)
+	r2 := strings.Repeat("ACGTTCGAAC", 10)
+	q := r1[:50] + r2[50:]
+	al := align.NewAlign(align.NUCLEOTIDS)
+	al.AddSequence("q", q, "")
+	al.AddSequence("r1", r1, "")
+	al.AddSequence("r2", r2, "")
+	return al
+}
+
+func TestBootscan(t *testing.T) {
+	al := recombinantAlign()
+	refs := SequenceReferences(al, 0)
+
+	windows, err := Bootscan(al, 0, refs, "pdist", 20, 20, 50, rand.New(rand.NewSource(10)), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(windows) != 10 {
+		t.Fatalf("Expected 10 window/reference pairs, got %d", len(windows))
+	}
+	for _, w := range windows {
+		closest := "r1"
+		if w.Start >= 60 {
+			closest = "r2"
+		}
+		if w.End <= 40 || w.Start >= 60 {
+			// Replicates without informative sites are shared between references
+			if w.Reference == closest && (w.Support < 90 || w.Distance != 0) {
+				t.Errorf("Window %d-%d: expected >90%% support and distance 0 for %s, got %v", w.Start, w.End, closest, w)
+			}
+			if w.Reference != closest && w.Support > 10 {
+				t.Errorf("Window %d-%d: expected <10%% support for %s, got %v", w.Start, w.End, w.Reference, w)
+			}
+		}
+	}
+
+	if _, err = Bootscan(al, 0, refs[:1], "pdist", 20, 20, 50, rand.New(rand.NewSource(10)), 1); err == nil {
+		t.Errorf("Expected an error with only one reference")
+	}
+}
+
+func TestMaxChi(t *testing.T) {
+	al := recombinantAlign()
+	refs := SequenceReferences(al, 0)
+
+	bps, err := MaxChi(al, 0, refs, 100, rand.New(rand.NewSource(10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bps) != 1 {
+		t.Fatalf("Expected 1 breakpoint, got %d", len(bps))
+	}
+	bp := bps[0]
+	// Informative sites: 4, 7 in each block of 10
+	if bp.Sites != 20 || bp.Position != 54 || bp.Left1 != 10 || bp.Left2 != 0 || bp.Right1 != 0 || bp.Right2 != 10 {
+		t.Errorf("Unexpected breakpoint: %v", bp)
+	}
+	if math.Abs(bp.ChiSquare-20) > 1e-9 {
+		t.Errorf("Expected chi-square 20, got %f", bp.ChiSquare)
+	}
+	if bp.PValue > 0.05 {
+		t.Errorf("Expected significant p-value, got %f", bp.PValue)
+	}
+}
+
+func TestChiSquare2x2(t *testing.T) {
+	// Classical example: [[10,20],[30,40]]
+	if chi := chiSquare2x2(10, 20, 30, 40); math.Abs(chi-0.7936507936507936) > 1e-9 {
+		t.Errorf("Expected chi-square 0.7937, got %f", chi)
+	}
+	if chi := chiSquare2x2(0, 0, 3, 4); chi != 0 {
+		t.Errorf("Expected chi-square 0, got %f", chi)
+	}
+}
+
+func TestPhi(t *testing.T) {
+	// Two blocks of sites with different histories
+	al := align.NewAlign(align.NUCLEOTIDS)
+	al.AddSequence("s1", "AAAAAAAAAAAAAAAAAAAA", "")
+	al.AddSequence("s2", "AAAAAAAAAACCCCCCCCCC", "")
+	al.AddSequence("s3", "CCCCCCCCCCAAAAAAAAAA", "")
+	al.AddSequence("s4", "CCCCCCCCCCCCCCCCCCCC", "")
+
+	test, err := Phi(al, 5, 200, rand.New(rand.NewSource(10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if test.Sites != 20 {
+		t.Errorf("Expected 20 informative sites, got %d", test.Sites)
+	}
+	// Pairs at distance <= 5: 85, incompatible pairs: 1+2+3+4+5
+	if math.Abs(test.Phi-15.0/85.0) > 1e-9 {
+		t.Errorf("Expected PHI %f, got %f", 15.0/85.0, test.Phi)
+	}
+	if test.PValue > 0.05 {
+		t.Errorf("Expected significant p-value, got %f", test.PValue)
+	}
+}
+
+func TestRefinedIncompatibility(t *testing.T) {
+	if i := refinedIncompatibility([]int{0, 0, 1, 1}, []int{0, 1, 0, 1}); i != 1 {
+		t.Errorf("Expected incompatibility 1 (4 gametes), got %d", i)
+	}
+	if i := refinedIncompatibility([]int{0, 0, 1, 1}, []int{0, 0, 1, -1}); i != 0 {
+		t.Errorf("Expected incompatibility 0, got %d", i)
+	}
+	// 3 states x 3 states, all combinations: 9 edges - 6 vertices + 1
+	if i := refinedIncompatibility([]int{0, 0, 0, 1, 1, 1, 2, 2, 2}, []int{0, 1, 2, 0, 1, 2, 0, 1, 2}); i != 4 {
+		t.Errorf("Expected incompatibility 4, got %d", i)
+	}
+}
+
+func TestMaxDescentPValue(t *testing.T) {
+	// Brute force over all orderings of m ups and n downs
+	for m := 0; m <= 5; m++ {
+		for n := 0; n <= 5; n++ {
+			for k := 1; k <= n+1; k++ {
+				total, count := 0, 0
+				for mask := 0; mask < 1<<(m+n); mask++ {
+					walk := make([]bool, m+n)
+					ups := 0
+					for i := range walk {
+						walk[i] = mask&(1<<i) != 0
+						if walk[i] {
+							ups++
+						}
+					}
+					if ups != m {
+						continue
+					}
+					total++
+					if _, _, d, _, _ := maxDescent(walk); d >= k {
+						count++
+					}
+				}
+				expected := float64(count) / float64(total)
+				if p := maxDescentPValue(m, n, k); math.Abs(p-expected) > 1e-9 {
+					t.Errorf("m=%d n=%d k=%d: expected %f, got %f", m, n, k, expected, p)
+				}
+			}
+		}
+	}
+}
+
+func TestThreeSeq(t *testing.T) {
+	al := recombinantAlign()
+	al.AddSequence("o", strings.Repeat("TCGTACGAAG", 10), "")
+
+	triplets, err := ThreeSeq(al, []int{0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(triplets) != 1 {
+		t.Fatalf("Expected 1 triplet, got %d", len(triplets))
+	}
+	tr := triplets[0]
+	if tr.Child != "q" || tr.Parent1 != "r1" || tr.Parent2 != "r2" || tr.Up != 10 || tr.Down != 10 || tr.Descent != 10 || tr.Start != 54 || tr.End != 97 {
+		t.Errorf("Unexpected triplet: %v", tr)
+	}
+	// Probability that the 10 downs are consecutive: 11/C(20,10)
+	if math.Abs(tr.PValue-11.0/184756.0) > 1e-12 {
+		t.Errorf("Expected p-value %g, got %g", 11.0/184756.0, tr.PValue)
+	}
+	if tr.Triplets != 6 {
+		t.Errorf("Expected 6 tested triplets, got %d", tr.Triplets)
+	}
+}
@@ -0,0 +1,211 @@
+package recomb
+
+import (
+	"math"
+
+	"github.com/evolbioinfo/goalign/align"
+)
+
+// Triplet is the most significant triplet found by ThreeSeq for a child sequence:
+// the child is a putative recombinant of Parent1 and Parent2
+type Triplet struct {
+	Child, Parent1, Parent2 string
+	Up, Down                int     // Number of informative sites where the child matches parent 1 / parent 2
+	Descent                 int     // Maximum descent of the random walk
+	Start, End              int     // Informative sites (alignment positions, 0-based) where the maximum descent begins and ends
+	PValue                  float64 // Probability of a maximum descent >= Descent under the null hypothesis
+	CorrectedPValue         float64 // PValue corrected for the number of tested triplets (Dunn-Sidak)
+	Triplets                int     // Number of tested triplets
+}
+
+// ThreeSeq searches, for each sequence of the alignment (child), the most significant
+// triplet (child, parent 1, parent 2) with a 3SEQ-like test (Boni et al. 2007).
+//
+// For an ordered pair of parents, sites informative for the triplet are the sites where
+// the parents have different unambiguous characters, and where the child has the
+// character of one of them. Along these sites, a random walk goes up when the child
+// matches parent 1 and down when it matches parent 2. If the child is a recombinant
+// (first part from parent 1, second part from parent 2), the walk goes up then down.
+// The statistic is the maximum descent of the walk (maximum of W_i - W_j, i < j).
+//
+// Under the null hypothesis (no recombination), all orderings of the Up and Down steps
+// are equally likely, and the p-value is the exact probability that the maximum descent
+// is >= the observed one. For each child, the triplet with the smallest p-value is
+// returned, its p-value being corrected for the number of tested triplets in the whole
+// alignment with the Dunn-Sidak correction: 1-(1-p)^n.
+//
+// If indices is not nil, only the given sequences are tested as children (all the
+// sequences are still considered as parents).
+func ThreeSeq(al align.Alignment, indices []int) (triplets []Triplet, err error) {
+	var seqs []align.Sequence
+	var chars [][]int
+	var ntriplets int
+	var cache = make(map[[3]int]float64)
+
+	seqs = al.Sequences()
+	chars = make([][]int, len(seqs))
+	for i, s := range seqs {
+		chars[i] = make([]int, al.Length())
+		for site, c := range s.SequenceChar() {
+			chars[i][site] = al.AlphabetCharToIndex(c)
+		}
+	}
+	if indices == nil {
+		indices = make([]int, len(seqs))
+		for i := range indices {
+			indices[i] = i
+		}
+	}
+
+	best := make([]Triplet, len(indices))
+	children := make(map[int]int)
+	for i, c := range indices {
+		best[i] = Triplet{Child: seqs[c].Name(), PValue: math.NaN(), Start: -1, End: -1}
+		children[c] = i
+	}
+
+	// Tests a triplet given the walk of the child along the informative sites
+	test := func(slot, c, p1, p2 int, walk []bool, sites []int) {
+		ntriplets++
+		up, down, descent, start, end := maxDescent(walk)
+		if descent == 0 {
+			return
+		}
+		key := [3]int{up, down, descent}
+		p, ok := cache[key]
+		if !ok {
+			p = maxDescentPValue(up, down, descent)
+			cache[key] = p
+		}
+		if math.IsNaN(best[slot].PValue) || p < best[slot].PValue {
+			best[slot] = Triplet{Child: seqs[c].Name(), Parent1: seqs[p1].Name(), Parent2: seqs[p2].Name(),
+				Up: up, Down: down, Descent: descent, Start: sites[start], End: sites[end], PValue: p}
+		}
+	}
+
+	for p1 := range seqs {
+		for p2 := p1 + 1; p2 < len(seqs); p2++ {
+			// Sites where both parents are defined and differ
+			var diff []int
+			for site := range chars[p1] {
+				if c1, c2 := chars[p1][site], chars[p2][site]; c1 >= 0 && c2 >= 0 && c1 != c2 {
+					diff = append(diff, site)
+				}
+			}
+			for c, slot := range children {
+				if c == p1 || c == p2 {
+					continue
+				}
+				var walk, reverse []bool
+				var sites []int
+				for _, site := range diff {
+					if cc := chars[c][site]; cc == chars[p1][site] || cc == chars[p2][site] {
+						walk = append(walk, cc == chars[p1][site])
+						reverse = append(reverse, cc != chars[p1][site])
+						sites = append(sites, site)
+					}
+				}
+				// Both orders of the parents
+				test(slot, c, p1, p2, walk, sites)
+				test(slot, c, p2, p1, reverse, sites)
+			}
+		}
+	}
+	triplets = best
+
+	for i := range triplets {
+		triplets[i].Triplets = ntriplets
+		triplets[i].CorrectedPValue = math.NaN()
+		if !math.IsNaN(triplets[i].PValue) {
+			// 1-(1-p)^n computed accurately for small p
+			triplets[i].CorrectedPValue = -math.Expm1(float64(ntriplets) * math.Log1p(-triplets[i].PValue))
+		}
+	}
+	return
+}
+
+// maxDescent returns the number of up (true) and down (false) steps of the walk, its
+// maximum descent, and the indices of the steps where the maximum descent begins
+// (first down step after the maximum) and ends (last down step)
+func maxDescent(walk []bool) (up, down, descent, start, end int) {
+	var height, max, maxpos int
+	start, end = -1, -1
+	for i, s := range walk {
+		if s {
+			up++
+			height++
+		} else {
+			down++
+			height--
+		}
+		if height > max {
+			max = height
+			maxpos = i + 1
+		}
+		if max-height > descent {
+			descent = max - height
+			start, end = maxpos, i
+		}
+	}
+	return
+}
+
+// maxDescentPValue returns the probability that a random ordering of m up steps and
+// n down steps has a maximum descent >= k, by dynamic programming on the number of
+// up and down steps already drawn and the current descent (from the running maximum).
+func maxDescentPValue(m, n, k int) float64 {
+	if k <= 0 {
+		return 1
+	}
+	if k > n {
+		return 0
+	}
+	// prob[d][dn]: probability of being at current descent d < k, after
+	// u up steps (outer loop) and dn down steps, without having reached k
+	prob := make([][]float64, k)
+	next := make([][]float64, k)
+	for d := range prob {
+		prob[d] = make([]float64, n+1)
+		next[d] = make([]float64, n+1)
+	}
+	prob[0][0] = 1
+	for u := 0; u <= m; u++ {
+		// Down steps at fixed u
+		for dn := 0; dn <= n; dn++ {
+			for d := 0; d < k; d++ {
+				p := prob[d][dn]
+				if p == 0 {
+					continue
+				}
+				remaining := float64(m - u + n - dn)
+				if remaining == 0 {
+					continue
+				}
+				if dn < n && d+1 < k {
+					prob[d+1][dn+1] += p * float64(n-dn) / remaining
+				}
+				if u < m {
+					nd := d - 1
+					if nd < 0 {
+						nd = 0
+					}
+					next[nd][dn] += p * float64(m-u) / remaining
+				}
+			}
+		}
+		if u == m {
+			break
+		}
+		prob, next = next, prob
+		for d := range next {
+			for dn := range next[d] {
+				next[d][dn] = 0
+			}
+		}
+	}
+	ok := 0.0
+	for d := 0; d < k; d++ {
+		ok += prob[d][n]
+	}
+	return math.Max(0, math.Min(1, 1-ok))
+}
@@ -31,6 +31,28 @@ diff -q -b result expected
 rm -f expected result mapfile
 
 
+echo "->goalign addid --metadata"
+cat > input <<EOF
+>s1
+GATTA
+>s2
+CCGTA
+EOF
+cat > meta.tsv <<EOF
+name	country
+s1	FR
+s2	UK
+EOF
+cat > expected <<EOF
+>s1_FR
+GATTA
+>s2_UK
+CCGTA
+EOF
+${GOALIGN} addid -i input --metadata meta.tsv -n "_{country}" -r > result
+diff -q -b result expected
+rm -f input expected result meta.tsv
+
 echo "->goalign addid unaligned"
 cat > input <<EOF
 >Seq0000
@@ -1458,6 +1480,205 @@ ${GOALIGN} compute distance -m tn93 -i ${TESTDATA}/test_distance.phy.gz -p > res
 diff -q -b result expected
 rm -f expected result mapfile
 
+echo "->goalign compute distance -m t92"
+cat > expected <<EOF
+5
+Tip4	0.000000000000	0.175073671691	0.193243695570	0.233018533852	0.235555260487
+Tip0	0.175073671691	0.000000000000	0.082453663453	0.128432133791	0.142784480299
+Tip3	0.193243695570	0.082453663453	0.000000000000	0.071292816997	0.086845720769
+Tip2	0.233018533852	0.128432133791	0.071292816997	0.000000000000	0.111968468413
+Tip1	0.235555260487	0.142784480299	0.086845720769	0.111968468413	0.000000000000
+EOF
+${GOALIGN} compute distance -m t92 -i ${TESTDATA}/test_distance.phy.gz -p > result
+diff -q -b result expected
+rm -f expected result
+
+echo "->goalign compute distance -m logdet"
+cat > expected <<EOF
+5
+Tip4	0.000000000000	0.178497398664	0.193033262631	0.234636442323	0.235954237405
+Tip0	0.178497398664	0.000000000000	0.082081828519	0.128693618834	0.143401463527
+Tip3	0.193033262631	0.082081828519	0.000000000000	0.070784926483	0.087436647726
+Tip2	0.234636442323	0.128693618834	0.070784926483	0.000000000000	0.111936600219
+Tip1	0.235954237405	0.143401463527	0.087436647726	0.111936600219	0.000000000000
+EOF
+${GOALIGN} compute distance -m logdet -i ${TESTDATA}/test_distance.phy.gz -p > result
+diff -q -b result expected
+rm -f expected result
+
+echo "->goalign compute distance -m ml-gtr"
+cat > expected <<EOF
+5
+Tip4	0.000000000000	0.176396741469	0.193176760206	0.232913127234	0.236323637963
+Tip0	0.176396741469	0.000000000000	0.082514397526	0.128701166973	0.143162226326
+Tip3	0.193176760206	0.082514397526	0.000000000000	0.071297001966	0.086907288895
+Tip2	0.232913127234	0.128701166973	0.071297001966	0.000000000000	0.112368527461
+Tip1	0.236323637963	0.143162226326	0.086907288895	0.112368527461	0.000000000000
+EOF
+${GOALIGN} compute distance -m ml-gtr -i ${TESTDATA}/test_distance.phy.gz -p > result
+diff -q -b result expected
+rm -f expected result
+
+echo "->goalign compute distance -m ml-gtr --alpha 0.5"
+cat > expected <<EOF
+5
+Tip4	0.000000000000	0.226427112704	0.252344404487	0.322001428361	0.329063012092
+Tip0	0.226427112704	0.000000000000	0.092858300506	0.154530352634	0.175488167603
+Tip3	0.252344404487	0.092858300506	0.000000000000	0.079365421457	0.098853117882
+Tip2	0.322001428361	0.154530352634	0.079365421457	0.000000000000	0.133651683524
+Tip1	0.329063012092	0.175488167603	0.098853117882	0.133651683524	0.000000000000
+EOF
+${GOALIGN} compute distance -m ml-gtr --alpha 0.5 -i ${TESTDATA}/test_distance.phy.gz -p > result
+diff -q -b result expected
+rm -f expected result
+
+echo "->goalign compute distance --model-file"
+cat > input <<EOF
+>s1
+MKVLAAGIVGLLLAQSHAEDRKLTWYPQGSNM
+>s2
+MKVLAAGIVGLMLAQSHSEDRKLTWYPQGTNM
+>s3
+MKILAAGLVGLMLSQSHSEERKLTWFPQGTNM
+>s4
+MRILSAGLVGIMLSQTHSEERRLSWFPEGTNL
+EOF
+cat > lg.dat <<EOF
+0.425093
+0.276818 0.751878
+0.395144 0.123954 5.076149
+2.489084 0.534551 0.528768 0.062556
+0.969894 2.807908 1.695752 0.523386 0.084808
+1.038545 0.36397 0.541712 5.24387 0.003499 4.128591
+2.06604 0.390192 1.437645 0.844926 0.569265 0.267959 0.348847
+0.358858 2.426601 4.509238 0.927114 0.640543 4.813505 0.423881 0.311484
+0.14983 0.126991 0.191503 0.01069 0.320627 0.072854 0.044265 0.008705 0.108882
+0.395337 0.301848 0.068427 0.015076 0.594007 0.582457 0.069673 0.044261 0.366317 4.145067
+0.536518 6.326067 2.145078 0.282959 0.013266 3.234294 1.807177 0.296636 0.697264 0.159069 0.1375
+1.124035 0.484133 0.371004 0.025548 0.89368 1.672569 0.173735 0.139538 0.442472 4.273607 6.312358 0.656604
+0.253701 0.052722 0.089525 0.017416 1.105251 0.035855 0.018811 0.089586 0.682139 1.112727 2.592692 0.023918 1.798853
+1.177651 0.332533 0.161787 0.394456 0.075382 0.624294 0.419409 0.196961 0.508851 0.078281 0.24906 0.390322 0.099849 0.094464
+4.727182 0.858151 4.008358 1.240275 2.784478 1.223828 0.611973 1.73999 0.990012 0.064105 0.182287 0.748683 0.34696 0.361819 1.338132
+2.139501 0.578987 2.000679 0.42586 1.14348 1.080136 0.604545 0.129836 0.584262 1.033739 0.302936 1.136863 2.020366 0.165001 0.571468 6.472279
+0.180717 0.593607 0.045376 0.02989 0.670128 0.236199 0.077852 0.268491 0.597054 0.11166 0.619632 0.049906 0.696175 2.457121 0.095131 0.248862 0.140825
+0.218959 0.31444 0.612025 0.135107 1.165532 0.257336 0.120037 0.054679 5.306834 0.232523 0.299648 0.131932 0.481306 7.803902 0.089613 0.400547 0.245841 3.151815
+2.54787 0.170887 0.083688 0.037967 1.959291 0.210332 0.245034 0.076701 0.119013 10.649107 1.702745 0.185202 1.898718 0.654683 0.296501 0.098369 2.188158 0.18951 0.249313
+
+0.079066 0.055941 0.041977 0.053052 0.012937 0.040767 0.071586 0.057337 0.022355 0.062157 0.099081 0.0646 0.022951 0.042302 0.04404 0.061197 0.053287 0.012066 0.034155 0.069147
+
+LG model (Le and Gascuel 2008)
+EOF
+${GOALIGN} compute distance -m lg -i input > expected
+${GOALIGN} compute distance --model-file lg.dat -i input > result
+diff -q -b result expected
+${GOALIGN} compute distance -m lg --alpha 0.5 -i input > expected
+${GOALIGN} compute distance --model-file lg.dat --alpha 0.5 -i input > result
+diff -q -b result expected
+rm -f input lg.dat expected result
+
+echo "->goalign compute distance -m vt"
+cat > input <<EOF
+>s1
+MKVLAAGIVGLLLAQSHAEDRKLTWYPQGSNM
+>s2
+MKVLAAGIVGLMLAQSHSEDRKLTWYPQGTNM
+>s3
+MKILAAGLVGLMLSQSHSEERKLTWFPQGTNM
+>s4
+MRILSAGLVGIMLSQTHSEERRLSWFPEGTNL
+EOF
+cat > vt.dat <<EOF
+0.233108
+0.199097 0.210797
+0.265145 0.105191 0.883422
+0.227333 0.031726 0.027495 0.010313
+0.310084 0.493763 0.2757 0.205842 0.004315
+0.567957 0.25524 0.270417 1.599461 0.005321 0.960976
+0.876213 0.156945 0.362028 0.311718 0.050876 0.12866 0.250447
+0.078692 0.213164 0.290006 0.134252 0.016695 0.315521 0.104458 0.058131
+0.222972 0.08151 0.087225 0.01172 0.046398 0.054602 0.046589 0.051089 0.020039
+0.42463 0.192364 0.069245 0.060863 0.091709 0.24353 0.151924 0.087056 0.103552 2.08989
+0.393245 1.755838 0.50306 0.261101 0.004067 0.738208 0.88863 0.193243 0.153323 0.093181 0.201204
+0.21155 0.08793 0.05742 0.012182 0.02369 0.120801 0.058643 0.04656 0.021157 0.493845 1.105667 0.096474
+0.116646 0.042569 0.039769 0.016577 0.051127 0.026235 0.028168 0.050143 0.079807 0.32102 0.946499 0.038261 0.173052
+0.399143 0.12848 0.083956 0.160063 0.011137 0.15657 0.205134 0.124492 0.078892 0.054797 0.169784 0.212302 0.010363 0.042564
+1.817198 0.292327 0.847049 0.461519 0.17527 0.358017 0.406035 0.612025 0.167226 0.081567 0.214977 0.400072 0.090515 0.138119 0.430431
+0.877877 0.204109 0.471268 0.178197 0.079511 0.248992 0.321028 0.136266 0.101117 0.376588 0.243227 0.446646 0.184609 0.08587 0.207143 1.767766
+0.030309 0.046417 0.010459 0.011393 0.007732 0.021248 0.018844 0.02399 0.020009 0.034954 0.083439 0.023321 0.022019 0.12805 0.014584 0.035933 0.020437
+0.087061 0.09701 0.093268 0.051664 0.042823 0.062544 0.0552 0.037568 0.286027 0.086237 0.189842 0.068689 0.073223 0.898663 0.032043 0.121979 0.094617 0.124746
+1.230985 0.113146 0.049824 0.048769 0.163831 0.112027 0.205868 0.082579 0.068575 3.65443 1.337571 0.144587 0.307309 0.247329 0.129315 0.1277 0.740372 0.022134 0.125733
+
+0.078837 0.051238 0.042313 0.053066 0.015175 0.036713 0.061924 0.070852 0.023082 0.062056
+0.096371 0.057324 0.023771 0.043296 0.043911 0.063403 0.055897 0.013272 0.034399 0.073101
+
+VT model (Muller and Vingron 2000)
+EOF
+${GOALIGN} compute distance --model-file vt.dat -i input > expected
+${GOALIGN} compute distance -m vt -i input > result
+diff -q -b result expected
+rm -f input vt.dat expected result
+
+echo "->goalign compute distance --output-format lower"
+cat > expected <<EOF
+5
+Tip4
+Tip0	0.175065910654
+Tip3	0.193245190192	0.082453892766
+Tip2	0.233028942469	0.128434558659	0.071292715047
+Tip1	0.235571330668	0.142789108437	0.086845484497	0.111966480828
+EOF
+${GOALIGN} compute distance -m k2p -i ${TESTDATA}/test_distance.phy.gz -p --output-format lower > result
+diff -q -b result expected
+rm -f expected result
+
+echo "->goalign compute distance --output-format long --max-dist"
+cat > expected <<EOF
+seq1	seq2	distance
+Tip0	Tip3	0.082453892766
+Tip0	Tip2	0.128434558659
+Tip3	Tip2	0.071292715047
+Tip3	Tip1	0.086845484497
+Tip2	Tip1	0.111966480828
+EOF
+${GOALIGN} compute distance -m k2p -i ${TESTDATA}/test_distance.phy.gz -p --output-format long --max-dist 0.13 > result
+diff -q -b result expected
+rm -f expected result
+
+echo "->goalign compute distance --output-format mega"
+cat > expected <<EOF
+#mega
+!Title: Distance matrix;
+!Format DataType=Distance DataFormat=LowerLeft NTaxa=5;
+
+[1] #Tip4
+[2] #Tip0
+[3] #Tip3
+[4] #Tip2
+[5] #Tip1
+
+[1]
+[2] 0.175065910654
+[3] 0.193245190192 0.082453892766
+[4] 0.233028942469 0.128434558659 0.071292715047
+[5] 0.235571330668 0.142789108437 0.086845484497 0.111966480828
+EOF
+${GOALIGN} compute distance -m k2p -i ${TESTDATA}/test_distance.phy.gz -p --output-format mega > result
+diff -q -b result expected
+rm -f expected result
+
+echo "->goalign compute distance --query --knn"
+cat > expected <<EOF
+query	reference	distance	rank
+Tip0	Tip0	-0.000000000000	1
+Tip0	Tip3	0.082453892766	2
+Tip2	Tip2	-0.000000000000	1
+Tip2	Tip3	0.071292715047	2
+EOF
+${GOALIGN} subset -i ${TESTDATA}/test_distance.phy.gz -p Tip0 Tip2 > query
+${GOALIGN} compute distance -m k2p -i ${TESTDATA}/test_distance.phy.gz -p --query query --knn 2 > result
+diff -q -b result expected
+rm -f expected result query
+
 echo "->goalign compute entropy"
 cat > expected <<EOF
 Alignment	Site	Entropy
@@ -1516,6 +1737,390 @@ diff -q -b result expected
 rm -f expected result restmp
 
 
+echo "->goalign compute popgen"
+cat > input <<EOF
+>s1_A
+ACGTACGTAC
+>s2_A
+ACGTACGTTC
+>s3_A
+ACGAACGTTC
+>s4_B
+TCGAACGTAC
+>s5_B
+ACGAACCTAC
+>s6_B
+ACG-ACGTNC
+EOF
+cat > expected <<EOF
+alignment	group	region	start	end	nseqs	samplesize	sites	S	eta	singletons	nhap
+0	A	window	0	5	3	3	5	1	1	1	2
+0	A	window	5	10	3	3	5	1	1	1	2
+0	B	window	0	5	3	3	5	1	1	1	2
+0	B	window	5	10	3	3	5	1	1	1	2
+EOF
+${GOALIGN} compute popgen -i input --group-sep _ --group-field 2 --window-size 5 --max-missing 0.5 | cut -f 1-11,19 > result
+diff -q -b result expected
+cat > expected <<EOF
+alignment	region	start	end	nseqs	samplesize	sites	S	eta	singletons	pi	pisite	thetaw	thetawsite	tajimad	fulid	fulif	nhap	hd
+0	all	0	10	5	5	10	4	4	2	1.9999999999999996	0.19999999999999996	1.9200000000000004	0.19200000000000003	0.2734497664558768	0.2734497664558792	0.2783372502031607	5	0.9999999999999999
+EOF
+head -n 10 input > input2
+${GOALIGN} compute popgen -i input2 > result
+diff -q -b result expected
+rm -f input input2 expected result
+
+echo "->goalign compute fst"
+cat > input <<EOF
+>s1_A
+ACGTACGTAC
+>s2_A
+ACGTACGTTC
+>s3_A
+ACGAACGTTC
+>s4_B
+TCGAACGTAC
+>s5_B
+ACGAACCTAC
+>s6_C
+TCGAACGTAC
+EOF
+cat > groups <<EOF
+s1_A	A
+s2_A	A
+s3_A	A
+s4_B	B
+s5_B	B
+s6_C	C
+EOF
+cat > expected <<EOF
+alignment	group1	group2	region	start	end	n1	n2	sites	fixed	shared	private1	private2
+0	A	B	all	0	10	3	2	10	0	0	2	2
+0	A	C	all	0	10	3	1	10	1	0	2	0
+0	B	C	all	0	10	2	1	10	0	0	2	0
+EOF
+${GOALIGN} compute fst -i input --groups groups | cut -f 1-9,15- > result
+diff -q -b result expected
+cat > expected <<EOF
+alignment	group1	group2	region	start	end	n1	n2	sites	pi1	pi2	dxy	da	fst	fixed	shared	private1	private2
+0	A	B	window	0	5	3	2	5	0.6666666666666666	1	1.1666666666666667	0.3333333333333335	0.2857142857142858	0	0	1	1
+0	A	B	window	5	10	3	2	5	0.6666666666666666	1	1.1666666666666667	0.3333333333333335	0.2857142857142858	0	0	1	1
+EOF
+head -n 10 input > input2
+${GOALIGN} compute fst -i input2 --group-sep _ --group-field 2 --window-size 5 > result
+diff -q -b result expected
+rm -f input input2 groups expected result
+
+echo "->goalign compute dnds"
+cat > input <<EOF
+>s1
+ATGTTTCTAGCTGCTGCTGCT
+>s2
+ATGCTCCTGGCTGCTGCTGCC
+>s3
+ATGCTCCTGGCTGCTGCT---
+EOF
+cat > expected <<EOF
+alignment	seq1	seq2	codons	S	Sd	Nd	pS	pN
+0	s1	s2	7	6	3	1	0.5	0.06666666666666667
+0	s1	s3	6	5	2	1	0.4	0.07692307692307693
+0	s2	s3	6	5.333333333333333	0	0	0	0
+EOF
+cat > expected_sites <<EOF
+alignment	codon	position	ncodons	S	N	Sd	Nd
+0	0	0	3	0	3	0	0
+0	1	3	3	0.7777777777777777	2.222222222222222	0.6666666666666666	0.6666666666666666
+0	2	6	3	1.3333333333333333	1.6666666666666667	0.6666666666666666	0
+0	3	9	3	1	2	0	0
+0	4	12	3	1	2	0	0
+0	5	15	3	1	2	0	0
+0	6	18	2	1	2	1	0
+EOF
+${GOALIGN} compute dnds -i input --long --sites-output result_sites | cut -f 1-5,7-10 > result
+diff -q -b result expected
+diff -q -b result_sites expected_sites
+${GOALIGN} compute dnds -i input -m lwl85 --long --correction none | cut -f 1-5,7-10 > result
+diff -q -b result expected
+rm -f input expected result expected_sites result_sites
+
+echo "->goalign compute bootscan"
+cat > input <<EOF
+>q
+ACGTACGTACACGTACGTACACGTACGTACACGTACGTACACGTACGTACACGTTCGAACACGTTCGAACACGTTCGAACACGTTCGAACACGTTCGAAC
+>r1
+ACGTACGTACACGTACGTACACGTACGTACACGTACGTACACGTACGTACACGTACGTACACGTACGTACACGTACGTACACGTACGTACACGTACGTAC
+>r2
+ACGTTCGAACACGTTCGAACACGTTCGAACACGTTCGAACACGTTCGAACACGTTCGAACACGTTCGAACACGTTCGAACACGTTCGAACACGTTCGAAC
+EOF
+cat > expected <<EOF
+alignment	start	end	reference	distance	support
+0	0	50	r1	0	100
+0	0	50	r2	0.2	0
+0	50	100	r1	0.2	0
+0	50	100	r2	0	100
+EOF
+cat > expectedbp <<EOF
+alignment	reference1	reference2	sites	position	left1	left2	right1	right2	chi2	pvalue
+0	r1	r2	20	54	10	0	0	10	20	0.01
+EOF
+${GOALIGN} compute bootscan -i input --query q -w 50 -s 50 -m pdist -b 20 --seed 1 --breakpoints resultbp --permutations 99 -o result
+diff -q -b expected result
+diff -q -b expectedbp resultbp
+rm -f input expected result expectedbp resultbp
+
+echo "->goalign compute recomb-test"
+cat > input <<EOF
+>q
+ACGTACGTACACGTACGTACACGTACGTACACGTACGTACACGTACGTACACGTTCGAACACGTTCGAACACGTTCGAACACGTTCGAACACGTTCGAAC
+>r1
+ACGTACGTACACGTACGTACACGTACGTACACGTACGTACACGTACGTACACGTACGTACACGTACGTACACGTACGTACACGTACGTACACGTACGTAC
+>r2
+ACGTTCGAACACGTTCGAACACGTTCGAACACGTTCGAACACGTTCGAACACGTTCGAACACGTTCGAACACGTTCGAACACGTTCGAACACGTTCGAAC
+>o
+TCGTACGAAGTCGTACGAAGTCGTACGAAGTCGTACGAAGTCGTACGAAGTCGTACGAAGTCGTACGAAGTCGTACGAAGTCGTACGAAGTCGTACGAAG
+EOF
+cat > expected <<EOF
+alignment	sites	window	phi	mean	sd	pvalue
+0	10	10	0.5555555555555556	NaN	NaN	NaN
+EOF
+cat > expected3seq <<EOF
+alignment	child	parent1	parent2	up	down	descent	start	end	pvalue	corrected	triplets
+0	q	r1	r2	10	10	10	54	97	0.00005953798523472553	0.001427933715676935	24
+0	r1	r2	q	0	10	10	4	47	1	1	24
+0	r2	r1	q	0	10	10	54	97	1	1	24
+0	o	q	r1	5	5	1	54	54	1	1	24
+EOF
+${GOALIGN} compute recomb-test -i input --window 10 --permutations 0 --3seq result3seq -o result
+diff -q -b expected result
+diff -q -b expected3seq result3seq
+rm -f input expected result expected3seq result3seq
+
+echo "->goalign compute ld"
+cat > input <<EOF
+>ref
+A-CGTAC
+>s2
+A-CGTTT
+>s3
+AACATTT
+>s4
+ATCANAC
+>s5
+A-CG-TC
+EOF
+cat > expected <<EOF
+alignment	site1	site2	refpos1	refpos2	distance	n	alleles1	alleles2	d	dprime	r2
+0	3	5	2	4	2	5	G/A	T/A	0.040000000000000036	0.16666666666666682	0.02777777777777783
+0	5	6	4	5	1	5	T/A	C/T	-0.15999999999999998	-0.9999999999999997	0.4444444444444443
+EOF
+${GOALIGN} compute ld -i input --ref-sequence ref --max-dist 2 --image result.png -o result
+diff -q -b expected result
+test -s result.png
+rm -f input expected result result.png
+
+echo "->goalign compute network"
+cat > input <<EOF
+>s1_A
+ACGTACGTAC
+>s2_A
+ACGTACGTAC
+>s3_B
+ACGTACGTTC
+>s4_B
+ACGAACGTTC
+>s5_A
+TCGAACGTAC
+>s6_C
+ACGAACCTAC
+EOF
+cat > expected <<EOF
+{"elements":{"nodes":[{"data":{"id":"n0","name":"s1_A","frequency":2,"inferred":false,"sequences":["s1_A","s2_A"],"groups":{"A":2,"B":0,"C":0}}},{"data":{"id":"n1","name":"s3_B","frequency":1,"inferred":false,"sequences":["s3_B"],"groups":{"A":0,"B":1,"C":0}}},{"data":{"id":"n2","name":"s4_B","frequency":1,"inferred":false,"sequences":["s4_B"],"groups":{"A":0,"B":1,"C":0}}},{"data":{"id":"n3","name":"s5_A","frequency":1,"inferred":false,"sequences":["s5_A"],"groups":{"A":1,"B":0,"C":0}}},{"data":{"id":"n4","name":"s6_C","frequency":1,"inferred":false,"sequences":["s6_C"],"groups":{"A":0,"B":0,"C":1}}}],"edges":[{"data":{"id":"e0","source":"n0","target":"n1","weight":1}},{"data":{"id":"e1","source":"n0","target":"n3","weight":2}},{"data":{"id":"e2","source":"n0","target":"n4","weight":2}},{"data":{"id":"e3","source":"n1","target":"n2","weight":1}},{"data":{"id":"e4","source":"n2","target":"n3","weight":2}},{"data":{"id":"e5","source":"n2","target":"n4","weight":2}},{"data":{"id":"e6","source":"n3","target":"n4","weight":2}}]}}
+EOF
+cat > expectedmjn <<EOF
+<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="name" for="node" attr.name="name" attr.type="string"/>
+  <key id="frequency" for="node" attr.name="frequency" attr.type="int"/>
+  <key id="inferred" for="node" attr.name="inferred" attr.type="boolean"/>
+  <key id="sequences" for="node" attr.name="sequences" attr.type="string"/>
+  <key id="weight" for="edge" attr.name="weight" attr.type="int"/>
+  <graph id="network" edgedefault="undirected">
+    <node id="n0">
+      <data key="name">s1_A</data>
+      <data key="frequency">2</data>
+      <data key="inferred">false</data>
+      <data key="sequences">s1_A,s2_A</data>
+    </node>
+    <node id="n1">
+      <data key="name">s3_B</data>
+      <data key="frequency">1</data>
+      <data key="inferred">false</data>
+      <data key="sequences">s3_B</data>
+    </node>
+    <node id="n2">
+      <data key="name">s4_B</data>
+      <data key="frequency">1</data>
+      <data key="inferred">false</data>
+      <data key="sequences">s4_B</data>
+    </node>
+    <node id="n3">
+      <data key="name">s5_A</data>
+      <data key="frequency">1</data>
+      <data key="inferred">false</data>
+      <data key="sequences">s5_A</data>
+    </node>
+    <node id="n4">
+      <data key="name">s6_C</data>
+      <data key="frequency">1</data>
+      <data key="inferred">false</data>
+      <data key="sequences">s6_C</data>
+    </node>
+    <node id="n5">
+      <data key="name">mv1</data>
+      <data key="frequency">0</data>
+      <data key="inferred">true</data>
+      <data key="sequences"></data>
+    </node>
+    <edge source="n0" target="n1">
+      <data key="weight">1</data>
+    </edge>
+    <edge source="n0" target="n5">
+      <data key="weight">1</data>
+    </edge>
+    <edge source="n1" target="n2">
+      <data key="weight">1</data>
+    </edge>
+    <edge source="n2" target="n5">
+      <data key="weight">1</data>
+    </edge>
+    <edge source="n3" target="n5">
+      <data key="weight">1</data>
+    </edge>
+    <edge source="n4" target="n5">
+      <data key="weight">1</data>
+    </edge>
+  </graph>
+</graphml>
+EOF
+${GOALIGN} compute network -i input -m msn --group-sep _ --group-field 2 --json -o result
+${GOALIGN} compute network -i input -m mjn -o resultmjn
+diff -q -b expected result
+diff -q -b expectedmjn resultmjn
+rm -f input expected result expectedmjn resultmjn
+
+echo "->goalign compute dstat"
+cat > input <<EOF
+>a1_P1
+AAAAAAAAAAAAAAAAAAAA
+>a2_P1
+AAAAAAAAAAAAAAAAAAAA
+>b1_P2
+AAAAAAAAAAAATTTTTTTT
+>b2_P2
+AAAAAAAAAAAATTTTTTTA
+>c1_P3
+TTTTTTTTTTTTTTTTTTTT
+>c2_P3
+TTTTTTTTTTTTTTTTAATT
+>o1_O
+AAAAAAAAAAAAAAAAAAAA
+EOF
+cat > expected <<EOF
+alignment	p1	p2	p3	outgroup	sites	abba	baba	d	d_se	z	pvalue	f4ratio	f4ratio_se	blocks
+0	P1	P2	P3	O	20	6.5	0	1	0	+Inf	0	0.4166666666666667	0.27994168488950605	5
+EOF
+${GOALIGN} compute dstat -i input --group-sep _ --group-field 2 --p1 P1 --p2 P2 --p3 P3 --outgroup O --blocks 5 -o result
+diff -q -b expected result
+rm -f input expected result
+
+echo "->goalign compute saturation"
+cat > input <<EOF
+>A
+ACGTACGTACGTACGTAACC
+>B
+ACGTACGTACTTACGTAACC
+>C
+ACGAACGTGCGTACCTAACG
+>D
+TCGAACGTGCGTTCCTAAGG
+EOF
+cat > expected <<EOF
+alignment	nseqs	sites	h	hfss	iss	se	iss_c_sym	t_sym	df_sym	pvalue_sym	iss_c_asym	t_asym	df_asym	pvalue_asym
+0	4	20	0.36225562489182656	1.3152212759737933	0.27543321531474735	0.07808704288393156	0.8	6.71771865487297	19	0.000002023160040038943	0.74	5.949345339863668	19	0.00001000017060367313
+EOF
+cat > expected.pairs <<EOF
+alignment	seq1	seq2	sites	transitions	transversions	distance
+0	A	B	20	0	1	0.05199804518619293
+0	A	C	20	1	3	0.23772249171100673
+0	A	D	20	1	6	0.4925135063405768
+0	B	C	20	1	4	0.3115764216810029
+0	B	D	20	1	7	0.6099011832104544
+0	C	D	20	0	3	0.17055672639115227
+EOF
+${GOALIGN} compute saturation -i input --iss-c-sym 0.8 --pairs result.pairs -o result
+diff -q -b expected result
+diff -q -b expected.pairs result.pairs
+rm -f input expected result expected.pairs result.pairs
+
+echo "->goalign compute kdist"
+cat > input <<EOF
+>A
+ACGTACGTTGCA
+>B
+ACGTACGATGCA
+>C
+TGCAACGT
+EOF
+cat > expected <<EOF
+3
+A	0.000000000000	0.454545454545	0.600000000000
+B	0.454545454545	0.000000000000	0.636363636364
+C	0.600000000000	0.636363636364	0.000000000000
+EOF
+cat > expected2 <<EOF
+3
+A	0.000000000000	0.116102231423	0.186538595978
+B	0.116102231423	0.000000000000	0.209536219807
+C	0.186538595978	0.209536219807	0.000000000000
+EOF
+${GOALIGN} compute kdist -i input --unaligned -m jaccard --kmer-size 3 > result
+${GOALIGN} compute kdist -i input --unaligned -m mash --kmer-size 3 > result2
+diff -q -b result expected
+diff -q -b result2 expected2
+rm -f input expected expected2 result result2
+
+echo "->goalign compute kdist --canonical"
+cat > input <<EOF
+>A
+ACGTACGTTGCA
+>B
+ACGTACGATGCA
+>C
+TGCAACGT
+EOF
+cat > expected <<EOF
+seq1	seq2	distance
+A	B	0.625000000000
+A	C	0.200000000000
+B	C	0.750000000000
+EOF
+${GOALIGN} compute kdist -i input --unaligned -m jaccard --kmer-size 3 --canonical --output-format long > result
+diff -q -b result expected
+rm -f input expected result
+
+echo "->goalign compute kdist -m spectrum -t 4"
+cat > expected <<EOF
+5
+Tip4
+Tip0	0.893877551020
+Tip3	0.871428571429	0.542857142857
+Tip2	0.938775510204	0.736734693878	0.442857142857
+Tip1	0.916326530612	0.779591836735	0.577551020408	0.691836734694
+EOF
+${GOALIGN} compute kdist -i ${TESTDATA}/test_distance.phy.gz -p -m spectrum --kmer-size 11 -t 4 --output-format lower > result
+diff -q -b result expected
+rm -f expected result
+
 echo "->goalign concat 1"
 cat > expected <<EOF
 >Seq0000
@@ -2170,6 +2775,22 @@ diff -q -b output.2 expected.2
 rm -f expected expected.2 output output.2
 
 
+echo "->goalign names fields"
+cat > input <<EOF
+>hCoV-19/France/XYZ/2021|EPI_ISL_123|2021/3/1
+GATTA
+>hCoV-19/UK/ABC/2021|EPI_ISL_456
+CCGTA
+EOF
+cat > expected <<EOF
+name	strain	id	field3
+hCoV-19/France/XYZ/2021|EPI_ISL_123|2021/3/1	hCoV-19/France/XYZ/2021	EPI_ISL_123	2021-03-01
+hCoV-19/UK/ABC/2021|EPI_ISL_456	hCoV-19/UK/ABC/2021	EPI_ISL_456	
+EOF
+${GOALIGN} names fields -i input --split '|' --columns strain,id --date-fields 3 > result
+diff -q -b result expected
+rm -f input expected result
+
 echo "->goalign rename"
 cat > mapfile <<EOF
 Seq0000	New0000
@@ -2220,6 +2841,61 @@ diff -q -b <(sort mapfile) <(sort mapfile2)
 rm -f expected result mapfile mapfile2
 
 
+echo "->goalign rename --template"
+cat > input <<EOF
+>s1
+GATTA
+>s2
+CCGTA
+>s3
+GGCCA
+EOF
+cat > meta.csv <<EOF
+id,country,date
+s1,FR,2021-03-01
+s3,UK,2021-04-02
+EOF
+cat > expected <<EOF
+>FR|s1|2021-03
+GATTA
+>s2
+CCGTA
+>UK|s3|2021-04
+GGCCA
+EOF
+cat > expectedmap <<EOF
+s1	FR|s1|2021-03
+s3	UK|s3|2021-04
+EOF
+${GOALIGN} rename -i input --metadata meta.csv --template "{country}|{name}|{month}" -o result --map-file outmap
+diff -q -b result expected
+diff -q -b <(sort outmap) expectedmap
+rm -f input expected expectedmap result outmap meta.csv
+
+echo "->goalign rename --split --template"
+cat > input <<EOF
+>hCoV-19/France/XYZ/2021|EPI_ISL_123|2021-03-01
+GATTA
+>hCoV-19/UK/ABC/2021|EPI_ISL_456|01/03/2021
+CCGTA
+EOF
+cat > expected <<EOF
+>2021-03-01_hCoV-19/France/XYZ/2021
+GATTA
+>2021-03-01_hCoV-19/UK/ABC/2021
+CCGTA
+EOF
+cat > expectedmap <<EOF
+hCoV-19/France/XYZ/2021|EPI_ISL_123|2021-03-01	2021-03-01_hCoV-19/France/XYZ/2021
+hCoV-19/UK/ABC/2021|EPI_ISL_456|01/03/2021	2021-03-01_hCoV-19/UK/ABC/2021
+EOF
+${GOALIGN} rename -i input --split '|' --template '{3:date}_{1}' -o result --map-file outmap
+diff -q -b result expected
+diff -q -b <(sort outmap) expectedmap
+${GOALIGN} rename -i result --map-file outmap --revert > result2
+diff -q -b result2 input
+rm -f input expected expectedmap result result2 outmap
+
 echo "->goalign rename --clean-names"
 cat > input <<EOF
 > S e q 0	0	00[]();.,
@@ -2337,6 +3013,73 @@ rm -f expected result input
 
 
 
+echo "->goalign sample seqs --where"
+cat > input <<EOF
+>s1
+ACGTACGTAC
+>s2
+ACGTACGTAA
+>s3
+ACGTACGTCC
+>s4
+ACGTACGTGC
+EOF
+cat > meta.tsv <<EOF
+name	country
+s1	FR
+s2	UK
+s3	UK
+s4	FR
+EOF
+${GOALIGN} sample seqs -i input -n 2 --metadata meta.tsv --where "country=='UK'" --metadata-out resultmeta --seed 10 > result
+diff -q -b <(${GOALIGN} stats nseq -i result) <(echo 2)
+diff -q -b <(grep ">" result | sort) <(printf ">s2\n>s3\n")
+diff -q -b <(tail -n +2 resultmeta | cut -f 2 | uniq) <(echo UK)
+rm -f input result resultmeta meta.tsv
+
+echo "->goalign sample stratified"
+cat > input <<EOF
+>s1
+ACGTACGTAC
+>s2
+ACGTACGTAA
+>s3
+ACGTACGTCC
+>s4
+ACGTACGTGC
+>s5
+ACGTACGTTC
+>s6
+ACGTACGATC
+>s7
+ACGTACCTTC
+EOF
+cat > meta.tsv <<EOF
+name	country	date	weight
+s1	FR	2021-03-01	1
+s2	FR	2021-03-15	0
+s3	UK	2021-03-02	1
+s4	FR	2021-04-20	2
+s5	UK	2021-03-28	1
+s6	FR	2021-03-11	1
+EOF
+cat > expected <<EOF
+>s1
+ACGTACGTAC
+>s3
+ACGTACGTCC
+>s4
+ACGTACGTGC
+>s5
+ACGTACGTTC
+>s6
+ACGTACGATC
+EOF
+${GOALIGN} sample stratified -i input --metadata meta.tsv --group-by country,month --max-per-group 2 --weight-column weight --seed 10 > result
+diff -q -b result expected
+rm -f expected result input meta.tsv
+
+
 echo "->goalign sample sites"
 cat > expected <<EOF
 >Seq0000
@@ -2695,6 +3438,148 @@ diff -q -b result expected
 rm -f expected result
 
 
+echo "->goalign stats --format tsv/json"
+cat > input <<EOF
+2 5
+A AAAC-
+B -AAT-
+2 5
+A AAACG
+B -AATC
+EOF
+cat > expected <<EOF
+alignment	sequence	gaps
+0	A	1
+0	B	2
+1	A	0
+1	B	1
+EOF
+${GOALIGN} stats gaps -p -i input --format tsv > result
+diff -q -b result expected
+cat > expected <<EOF
+{"command":"gaps","alignment":0,"columns":["sequence","gapsstart"],"rows":[{"sequence":"A","gapsstart":0},{"sequence":"B","gapsstart":1}]}
+{"command":"gaps","alignment":1,"columns":["sequence","gapsstart"],"rows":[{"sequence":"A","gapsstart":0},{"sequence":"B","gapsstart":1}]}
+EOF
+${GOALIGN} stats gaps -p -i input --from-start --format json > result
+diff -q -b result expected
+cat > expected <<EOF
+alignment	length	nseqs	avgalleles	variablesites	alphabet
+0	5	2	1.25	1	nucleotide
+1	5	2	1.4	2	nucleotide
+EOF
+${GOALIGN} stats -p -i input --format tsv > result
+diff -q -b result expected
+cat > expected <<EOF
+alignment	sequence	ref	pos	alt
+0	B	A	0	-
+0	B	C	3	T
+1	B	A	0	-
+1	B	C	3	T
+1	B	G	4	C
+EOF
+${GOALIGN} stats mutations list -p -i input --ref-sequence A --format tsv > result
+diff -q -b result expected
+cat > expected <<EOF
+alignment	char	nb	freq
+0	-	3	0.3
+0	A	5	0.5
+0	C	1	0.1
+0	T	1	0.1
+1	-	1	0.1
+1	A	5	0.5
+1	C	2	0.2
+1	G	1	0.1
+1	T	1	0.1
+EOF
+${GOALIGN} stats char -p -i input --format tsv > result
+diff -q -b result expected
+cat > expected <<EOF
+{"command":"entropy","alignment":0,"columns":["avgentropy"],"rows":[{"avgentropy":0.17328679513998632}]}
+{"command":"entropy","alignment":1,"columns":["avgentropy"],"rows":[{"avgentropy":0.2772588722239781}]}
+EOF
+${GOALIGN} compute entropy -p -i input -a -g --format json > result
+diff -q -b result expected
+rm -f input expected result
+
+echo "->goalign stats sites"
+cat > input <<EOF
+>A
+ACGT-a
+>B
+ACGTTA
+>C
+A-GCTA
+>D
+AAGCTC
+EOF
+cat > expected <<EOF
+alignment	site	refpos	nseqs	-	A	C	G	T	majority	majoritynb	gapfreq	entropy	conservation	variable	informative
+0	0	0	4	0	4	0	0	0	A	4	0	0	identical	false	false
+0	1		4	1	1	2	0	0	C	2	0.25	1.0397207708399179	notconserved	true	false
+0	2	1	4	0	0	0	4	0	G	4	0	0	identical	false	false
+0	3	2	4	0	0	2	0	2	C	2	0	0.6931471805599453	notconserved	true	true
+0	4	3	4	1	0	0	0	3	T	3	0.25	0.5623351446188083	notconserved	false	false
+0	5	4	4	0	3	1	0	0	A	3	0	1.0397207708399179	notconserved	true	false
+EOF
+${GOALIGN} stats sites -i input --ref-sequence C > result
+diff -q -b result expected
+rm -f input expected result
+
+echo "->goalign stats composition"
+cat > input <<EOF
+>s1
+ACGTACGTAC
+>s2
+ACGTACGTTC
+>s3
+GGGCGCGCGG
+>s4
+ACGAAC-TNC
+EOF
+cat > expected <<EOF
+alignment	sequence	length	A	C	G	T	chi2	df	pvalue	failed	gc	gcskew	atskew	A1	C1	G1	T1	A2	C2	G2	T2	A3	C3	G3	T3
+0	s1	10	0.3	0.3	0.2	0.2	0.9250000000000002	3	0.8193909242475786	false	0.5	-0.2	0.2	0.25	0.25	0.25	0.25	0.3333333333333333	0.3333333333333333	0	0.3333333333333333	0.3333333333333333	0.3333333333333333	0.3333333333333333	0
+0	s2	10	0.2	0.3	0.2	0.3	1.7166666666666668	3	0.6332347932975566	false	0.5	-0.2	-0.2	0.25	0.25	0.25	0.25	0.3333333333333333	0.3333333333333333	0	0.3333333333333333	0	0.3333333333333333	0.3333333333333333	0.3333333333333333
+0	s3	10	0	0.3	0.7	0	8.366666666666667	3	0.03901153364433556	true	1	0.4	NaN	0	0.25	0.75	0	0	0.3333333333333333	0.6666666666666666	0	0	0.3333333333333333	0.6666666666666666	0
+0	s4	8	0.375	0.375	0.125	0.125	2.09375	3	0.5531782154115579	false	0.5	-0.5	0.5	0.6666666666666666	0.3333333333333333	0	0	0.3333333333333333	0.3333333333333333	0	0.3333333333333333	0	0.5	0.5	0
+0	all	38	0.21052631578947367	0.3157894736842105	0.3157894736842105	0.15789473684210525	13.102083333333335	9	0.15804124627674185	false	0.631578947368421	0	0.14285714285714285	0.26666666666666666	0.26666666666666666	0.3333333333333333	0.13333333333333333	0.25	0.3333333333333333	0.16666666666666666	0.25	0.09090909090909091	0.36363636363636365	0.45454545454545453	0.09090909090909091
+EOF
+${GOALIGN} stats composition -i input > result
+diff -q -b result expected
+rm -f input expected result
+
+echo "->goalign stats codons"
+cat > input <<EOF
+>s1
+ATGAAAAAGTTTTAA
+>s2
+ATGAAGAAGTTCTAG
+EOF
+cat > ref <<EOF
+AAA	10
+AAG	30
+TTT	5
+TTC	20
+EOF
+cat > expected <<EOF
+alignment	sequence	codons	gc	gc1	gc2	gc3	gc3s	enc	cai
+0	s1	5	0.13333333333333333	0	0	0.4	0.3333333333333333	NaN	0.43679023236814946
+0	s2	5	0.3333333333333333	0	0	1	1	NaN	1
+0	all	10	0.23333333333333334	0	0	0.7	0.6666666666666666	NaN	0.6609010760833648
+EOF
+${GOALIGN} stats codons -i input --reference ref > result
+diff -q -b result expected
+cat > expected <<EOF
+alignment	sequence	codon	aa	count	rscu
+0	all	AAA	K	1	0.5
+0	all	AAG	K	3	1.5
+0	all	TAA	*	1	1.5
+0	all	TTT	F	1	1
+EOF
+${GOALIGN} stats codons -i input --usage | awk 'NR==1 || $2=="all" && ($3=="AAA" || $3=="AAG" || $3=="TAA" || $3=="TTT")' > result
+diff -q -b result expected
+rm -f input expected result ref
+
 echo "->goalign subseq"
 cat > expected <<EOF
 >Seq0000
@@ -2996,6 +3881,42 @@ diff -q -b result expected
 rm -f expected result regfile
 
 
+echo "->goalign subset --where"
+cat > input <<EOF
+>s1
+ACGTACGTAC
+>s2
+ACGTACGTAA
+>s3
+ACGTACGTCC
+>s4
+ACGTACGTGC
+>s5
+ACGTACGTTC
+EOF
+cat > meta.tsv <<EOF
+name	country	date	ct
+s1	FR	2021-03-01	25
+s2	FR	2021-03-15	9
+s3	UK	2021-03-02	30
+s4	FR	2021-04-20	100
+EOF
+cat > expected <<EOF
+>s1
+ACGTACGTAC
+>s4
+ACGTACGTGC
+EOF
+cat > expectedmeta <<EOF
+name	country	date	ct
+s1	FR	2021-03-01	25
+s4	FR	2021-04-20	100
+EOF
+${GOALIGN} subset -i input --metadata meta.tsv --where "country=='FR' && ct>=20" --metadata-out resultmeta > result
+diff -q -b result expected
+diff -q -b resultmeta expectedmeta
+rm -f expected expectedmeta result resultmeta input meta.tsv
+
 echo "->goalign trim name"
 cat > expected <<EOF
 >S01
@@ -3504,6 +4425,79 @@ ${GOALIGN} translate -i input --phase 0 --unaligned -o result
 diff -q -b expected result
 rm -f input expected result
 
+echo "->goalign translate NCBI genetic codes"
+cat > input <<EOF
+>s1
+TGAATAAGATAATAGCTG
+EOF
+cat > expected <<EOF
+>s1
+WIR**L
+EOF
+${GOALIGN} translate -i input --genetic-code 4 -o result
+diff -q -b expected result
+cat > expected <<EOF
+>s1
+*IRQQL
+EOF
+${GOALIGN} translate -i input --genetic-code 6 -o result
+diff -q -b expected result
+rm -f input expected result
+
+echo "->goalign translate IUPAC ambiguity codes"
+cat > input <<EOF
+>s1
+GGNYTRRAYSARMTTWTANNNTAR---
+>s2
+GGAATG---AAYCARATTATAACGTAA
+EOF
+cat > expected <<EOF
+>s1
+GLBZJJX*-
+>s2
+GM-NQIIT*
+EOF
+cat > expected.report <<EOF
+alignment	sequence	codons	ambiguous	resolved	aaclasses	unresolved
+0	s1	8	8	3	4	1
+0	s2	8	2	2	0	0
+EOF
+${GOALIGN} translate -i input --aa-ambiguity --ambiguity-report result.report -o result
+diff -q -b expected result
+diff -q -b expected.report result.report
+rm -f input expected result expected.report result.report
+
+echo "->goalign translate --alt-start"
+cat > input <<EOF
+>s1
+TTGAAATAA
+>s2
+GTGAAATTG
+>s3
+KTGAAATAA
+EOF
+cat > expected <<EOF
+>s1
+MK*
+>s2
+MKL
+>s3
+MK*
+EOF
+cat > expected.noalt <<EOF
+>s1
+LK*
+>s2
+VKL
+>s3
+XK*
+EOF
+${GOALIGN} translate -i input --genetic-code 11 --alt-start -o result
+${GOALIGN} translate -i input --genetic-code 11 -o result.noalt
+diff -q -b expected result
+diff -q -b expected.noalt result.noalt
+rm -f input expected result expected.noalt result.noalt
+
 echo "->goalign translate --ref-seq"
 cat > expected1 <<EOF
 >s1
@@ -4049,6 +5043,53 @@ ${GOALIGN} codonalign -i input.aa -f input.nt -o result
 diff -q -b expected result
 rm -f expected result input.aa input.nt
 
+echo "->goalign codonalign diagnostics"
+cat > input <<EOF
+>s1
+MK-FLG
+>s3
+MKPFLG
+>s4
+MKPFLG
+EOF
+cat > nt.fa <<EOF
+>s1
+TTGAAATTTCTGGGGTAA
+>s3
+ATGAAACAATTTCTGGGG
+>s4
+ATGAACCCTTTCTGGGG
+EOF
+cat > expected <<EOF
+>s1
+TTGAAA---TTTCTGGGG
+>s3
+ATGAAACAATTTCTGGGG
+>s4
+ATGAANCCCTTTCTGGGG
+EOF
+cat > expecteddiag <<EOF
+alignment	sequence	position	residue	codon	translation	type
+0	s1	1	M	TTG	L	alt_start
+0	s1			TAA	*	terminal_stop
+0	s3	3	P	CAA	Q	mismatch
+0	s4	2	K	AAN		frameshift_deletion
+EOF
+cat > expectedrt <<EOF
+>s1
+ATGAAR---TTYYTNGGN
+>s3
+ATGAARCCNTTYYTNGGN
+>s4
+ATGAARCCNTTYYTNGGN
+EOF
+${GOALIGN} codonalign -i input -f nt.fa --diagnostics resultdiag --fix-frameshifts -o result
+diff -q -b expected result
+diff -q -b expecteddiag resultdiag
+${GOALIGN} codonalign -i input --reverse-translate -o resultrt
+diff -q -b expectedrt resultrt
+rm -f input nt.fa expected result expecteddiag resultdiag expectedrt resultrt
+
 echo "->goalign identical"
 cat > input1 <<EOF
 >Seq0000
@@ -4142,6 +5183,33 @@ diff -q -b expected result
 diff -q -b expected.aa result.aa
 rm -f input expected result expected.aa result.aa
 
+echo "->goalign phase --alt-start"
+cat > input <<EOF
+>s1
+CCGTGAAACCCGGGTTTATGGATGACTTTTTCTGTTGCCCTCCCCCACCGCAACAGTCTTCCTCATCGTAA
+>s2
+AAAAGTGAAACCCGGGTTTATGGATGACTTTTTCTGTTGCCCTCCCCCACCGCAACAGTCTTCCTCATCGTAAGG
+EOF
+cat > expected <<EOF
+>s1
+GTGAAACCCGGGTTTATGGATGACTTTTTCTGTTGCCCTCCCCCACCGCAACAGTCTTCCTCATCGTAA
+>s2
+GTGAAACCCGGGTTTATGGATGACTTTTTCTGTTGCCCTCCCCCACCGCAACAGTCTTCCTCATCGTAAGG
+EOF
+cat > expected.aa <<EOF
+>s1
+MKPGFMDDFFCCPPPPQQSSSS*
+>s2
+MKPGFMDDFFCCPPPPQQSSSS*
+EOF
+${GOALIGN} phase -i input --unaligned --genetic-code 11 --alt-start -o result --aa-output result.aa
+diff -q -b expected result
+diff -q -b expected.aa result.aa
+${GOALIGN} phasent -i input --unaligned --genetic-code 11 --alt-start -o result --aa-output result.aa
+diff -q -b expected result
+diff -q -b expected.aa result.aa
+rm -f input expected result expected.aa result.aa
+
 echo "->goalign phasent"
 cat > input <<EOF
 >allcodons
@@ -4409,6 +5477,64 @@ ${GOALIGN} orf -i input -o result --reverse
 diff -q -b expected result
 rm -f input expected result
 
+echo "->goalign orf genetic code"
+cat > input <<EOF
+>s
+CCGTGAAAATGATTTGATAGCC
+EOF
+cat > expected <<EOF
+>s
+ATGATTTGA
+EOF
+${GOALIGN} orf -i input -o result
+diff -q -b expected result
+cat > expected <<EOF
+>s
+GTGAAAATGATTTGATAG
+EOF
+${GOALIGN} orf -i input -o result --genetic-code 4 --alt-start
+diff -q -b expected result
+rm -f input expected result
+
+echo "->goalign orf all"
+cat > input <<EOF
+>s1
+CCATGAAATTTTAGGGATGCCCTGAAACTAAAAA
+>s2
+TTTCTACATCATTT
+EOF
+cat > expected <<EOF
+>s1_orf1
+ATGAAATTTTAG
+>s1_orf2
+ATGCCCTGA
+>s2_orf1
+ATGATGTAG
+>s2_orf2
+ATGTAG
+EOF
+cat > expectedaa <<EOF
+>s1_orf1
+MKF
+>s1_orf2
+MP
+>s2_orf1
+MM
+>s2_orf2
+M
+EOF
+cat > expectedbed <<EOF
+s1	2	14	s1_orf1	0	+
+s1	16	25	s1_orf2	0	+
+s2	3	12	s2_orf1	0	-
+s2	3	9	s2_orf2	0	-
+EOF
+${GOALIGN} orf -i input --all --min-len 6 --reverse --nested -o result --aa-output resultaa --bed resultbed
+diff -q -b expected result
+diff -q -b expectedaa resultaa
+diff -q -b expectedbed resultbed
+rm -f input expected result expectedaa resultaa expectedbed resultbed
+
 echo "->goalign mask / prot"
 cat > input <<EOF
    10   20